@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -15,6 +16,111 @@ type Config struct {
 	App AppConfig
 	Redis RedisConfig
 	BookAPIs BookAPIsConfig
+	Clubs ClubsConfig
+	Comments CommentsConfig
+	Reactions ReactionsConfig
+	Posts PostsConfig
+	Hashtags HashtagsConfig
+	Views ViewsConfig
+	Feed FeedConfig
+	Hot HotConfig
+	ContentFilter ContentFilterConfig
+	Attachments AttachmentsConfig
+}
+
+// AttachmentsConfig covers files uploaded to posts and comments. Storage is
+// "local" (files under LocalDir, served by this server) or "s3" (any
+// S3-compatible bucket). Clubs can narrow AllowedTypes for themselves, and
+// platform admins can change a club's quota.
+type AttachmentsConfig struct {
+	Storage                string
+	LocalDir               string
+	PublicBaseURL          string // where clients download files from; defaults to /uploads for local storage
+	S3Endpoint             string
+	S3Region               string
+	S3Bucket               string
+	S3AccessKey            string
+	S3SecretKey            string
+	S3PathStyle            bool
+	MaxFileMB              int
+	AllowedTypes           []string // MIME types, checked against the file contents rather than the name
+	ClubQuotaMB            int      // total size of the files a club may keep
+	MaxPerPost             int      // attachments on one post or comment
+	ThumbnailSize          int      // the longer side of image thumbnails, in pixels
+	OrphanAfterHours       int      // uploads never attached to a post or comment are deleted after this long
+	CleanupIntervalSeconds int      // how often orphaned uploads are deleted
+}
+
+// ContentFilterConfig is the platform-wide content filter. Clubs can add words
+// and change the rule actions for their own posts and comments. Actions are
+// "flag" (publish and queue for review), "hold" (queue and keep unpublished
+// until a moderator approves), "block" (reject) or "off".
+type ContentFilterConfig struct {
+	Enabled              bool
+	BlockWords           []string // words and phrases in any language; matched after folding Turkish characters and case
+	HoldWords            []string
+	FlagWords            []string
+	MaxLinks             int      // more links than this in one post or comment triggers LinkAction
+	ShortenerDomains     []string // any link to these hosts triggers LinkAction
+	LinkAction           string
+	RepeatWindowHours    int // how far back to look for repeated content
+	RepeatMax            int // the same text posted this many times in the window triggers RepeatAction
+	RepeatMinLength      int // shorter texts, like "thanks!", never count as repeats
+	RepeatAction         string
+	NewAccountDays       int // accounts younger than this are subject to the new account rules
+	NewAccountMaxPerHour int // posts and comments per hour before NewAccountRateAction
+	NewAccountRateAction string
+	NewAccountLinkAction string // new accounts posting any link
+}
+
+type HotConfig struct {
+	Gravity                float64 // how fast hot scores fall with age; higher favors newer posts
+	LikeWeight             float64
+	CommentWeight          float64
+	ReactionWeight         float64 // reactions other than ❤️, which count as likes
+	ViewWeight             float64
+	WindowDays             int // posts older than this drop out of hot ranking
+	RefreshIntervalSeconds int // how often hot scores of recent posts are recomputed
+}
+
+type FeedConfig struct {
+	WindowHours          int     // posts older than this are left out of home feeds
+	MaxItems             int     // a ranked feed is cut to this many posts
+	CacheTTLSeconds      int     // how long a ranked feed is reused before it is rebuilt
+	FanoutMaxMembers     int     // posts in clubs up to this size are pushed to members' inboxes; larger clubs are read at feed time
+	SourceLimit          int     // how many posts each fan-in source contributes at most
+	RecencyHalfLifeHours float64 // the recency signal halves after this long
+	RecencyWeight        float64
+	EngagementWeight     float64
+	AffinityWeight       float64 // how much the reader's activity in the post's club counts
+	UnreadWeight         float64 // boost for posts not yet shown to the reader
+}
+
+type ViewsConfig struct {
+	DedupWindowMinutes   int // a viewer is counted once per post in this window
+	FlushIntervalSeconds int // how often buffered counts are written to the database
+}
+
+type HashtagsConfig struct {
+	TrendingWindowHours   int     // only uses this recent count towards trending
+	TrendingHalfLifeHours float64 // a use counts half as much after this long
+}
+
+type PostsConfig struct {
+	SchedulerIntervalSeconds int // how often due scheduled posts are published
+}
+
+type ReactionsConfig struct {
+	Allowed []string
+}
+
+type CommentsConfig struct {
+	MaxDepth int // replies nested deeper than this are rejected; 0 is top level
+}
+
+type ClubsConfig struct {
+	RatingMinMembershipDays int
+	FormerMemberRatingWeight float64 // 0 excludes ratings of members who left
 }
 
 type BookAPIsConfig struct {
@@ -100,6 +206,83 @@ func Load() *Config {
 			ISBNDBAPIKey:      getEnv("ISBNDB_API_KEY", ""),
 			PreferredSource:   getEnv("BOOK_API_SOURCE", "google"),
 		},
+		Clubs: ClubsConfig{
+			RatingMinMembershipDays:  getEnvAsInt("CLUB_RATING_MIN_MEMBERSHIP_DAYS", 7),
+			FormerMemberRatingWeight: getEnvAsFloat("CLUB_RATING_FORMER_MEMBER_WEIGHT", 0),
+		},
+		Comments: CommentsConfig{
+			MaxDepth: getEnvAsInt("COMMENT_MAX_DEPTH", 3),
+		},
+		Reactions: ReactionsConfig{
+			Allowed: getEnvAsSlice("REACTIONS_ALLOWED", []string{"❤️", "😂", "😮", "📚", "🔥"}),
+		},
+		Posts: PostsConfig{
+			SchedulerIntervalSeconds: getEnvAsInt("POST_SCHEDULER_INTERVAL_SECONDS", 30),
+		},
+		Hashtags: HashtagsConfig{
+			TrendingWindowHours:   getEnvAsInt("HASHTAG_TRENDING_WINDOW_HOURS", 72),
+			TrendingHalfLifeHours: getEnvAsFloat("HASHTAG_TRENDING_HALF_LIFE_HOURS", 12),
+		},
+		Views: ViewsConfig{
+			DedupWindowMinutes:   getEnvAsInt("POST_VIEW_DEDUP_WINDOW_MINUTES", 30),
+			FlushIntervalSeconds: getEnvAsInt("POST_VIEW_FLUSH_INTERVAL_SECONDS", 60),
+		},
+		Feed: FeedConfig{
+			WindowHours:          getEnvAsInt("FEED_WINDOW_HOURS", 168),
+			MaxItems:             getEnvAsInt("FEED_MAX_ITEMS", 300),
+			CacheTTLSeconds:      getEnvAsInt("FEED_CACHE_TTL_SECONDS", 300),
+			FanoutMaxMembers:     getEnvAsInt("FEED_FANOUT_MAX_MEMBERS", 200),
+			SourceLimit:          getEnvAsInt("FEED_SOURCE_LIMIT", 200),
+			RecencyHalfLifeHours: getEnvAsFloat("FEED_RECENCY_HALF_LIFE_HOURS", 24),
+			RecencyWeight:        getEnvAsFloat("FEED_RECENCY_WEIGHT", 1),
+			EngagementWeight:     getEnvAsFloat("FEED_ENGAGEMENT_WEIGHT", 0.5),
+			AffinityWeight:       getEnvAsFloat("FEED_AFFINITY_WEIGHT", 0.5),
+			UnreadWeight:         getEnvAsFloat("FEED_UNREAD_WEIGHT", 0.3),
+		},
+		Hot: HotConfig{
+			Gravity:                getEnvAsFloat("HOT_GRAVITY", 1.8),
+			LikeWeight:             getEnvAsFloat("HOT_LIKE_WEIGHT", 1),
+			CommentWeight:          getEnvAsFloat("HOT_COMMENT_WEIGHT", 2),
+			ReactionWeight:         getEnvAsFloat("HOT_REACTION_WEIGHT", 0.5),
+			ViewWeight:             getEnvAsFloat("HOT_VIEW_WEIGHT", 0.05),
+			WindowDays:             getEnvAsInt("HOT_WINDOW_DAYS", 30),
+			RefreshIntervalSeconds: getEnvAsInt("HOT_REFRESH_INTERVAL_SECONDS", 600),
+		},
+		ContentFilter: ContentFilterConfig{
+			Enabled:              getEnvAsBool("CONTENT_FILTER_ENABLED", true),
+			BlockWords:           getEnvAsSlice("CONTENT_FILTER_BLOCK_WORDS", nil),
+			HoldWords:            getEnvAsSlice("CONTENT_FILTER_HOLD_WORDS", nil),
+			FlagWords:            getEnvAsSlice("CONTENT_FILTER_FLAG_WORDS", nil),
+			MaxLinks:             getEnvAsInt("CONTENT_FILTER_MAX_LINKS", 3),
+			ShortenerDomains:     getEnvAsSlice("CONTENT_FILTER_SHORTENER_DOMAINS", []string{"bit.ly", "tinyurl.com", "t.co", "goo.gl", "is.gd", "ow.ly", "cutt.ly"}),
+			LinkAction:           getEnv("CONTENT_FILTER_LINK_ACTION", "flag"),
+			RepeatWindowHours:    getEnvAsInt("CONTENT_FILTER_REPEAT_WINDOW_HOURS", 24),
+			RepeatMax:            getEnvAsInt("CONTENT_FILTER_REPEAT_MAX", 3),
+			RepeatMinLength:      getEnvAsInt("CONTENT_FILTER_REPEAT_MIN_LENGTH", 20),
+			RepeatAction:         getEnv("CONTENT_FILTER_REPEAT_ACTION", "hold"),
+			NewAccountDays:       getEnvAsInt("CONTENT_FILTER_NEW_ACCOUNT_DAYS", 3),
+			NewAccountMaxPerHour: getEnvAsInt("CONTENT_FILTER_NEW_ACCOUNT_MAX_PER_HOUR", 10),
+			NewAccountRateAction: getEnv("CONTENT_FILTER_NEW_ACCOUNT_RATE_ACTION", "hold"),
+			NewAccountLinkAction: getEnv("CONTENT_FILTER_NEW_ACCOUNT_LINK_ACTION", "flag"),
+		},
+		Attachments: AttachmentsConfig{
+			Storage:                getEnv("ATTACHMENTS_STORAGE", "local"),
+			LocalDir:               getEnv("ATTACHMENTS_LOCAL_DIR", "uploads"),
+			PublicBaseURL:          getEnv("ATTACHMENTS_PUBLIC_BASE_URL", ""),
+			S3Endpoint:             getEnv("ATTACHMENTS_S3_ENDPOINT", ""),
+			S3Region:               getEnv("ATTACHMENTS_S3_REGION", "us-east-1"),
+			S3Bucket:               getEnv("ATTACHMENTS_S3_BUCKET", ""),
+			S3AccessKey:            getEnv("ATTACHMENTS_S3_ACCESS_KEY", ""),
+			S3SecretKey:            getEnv("ATTACHMENTS_S3_SECRET_KEY", ""),
+			S3PathStyle:            getEnvAsBool("ATTACHMENTS_S3_PATH_STYLE", false),
+			MaxFileMB:              getEnvAsInt("ATTACHMENTS_MAX_FILE_MB", 10),
+			AllowedTypes:           getEnvAsSlice("ATTACHMENTS_ALLOWED_TYPES", []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf"}),
+			ClubQuotaMB:            getEnvAsInt("ATTACHMENTS_CLUB_QUOTA_MB", 1024),
+			MaxPerPost:             getEnvAsInt("ATTACHMENTS_MAX_PER_POST", 10),
+			ThumbnailSize:          getEnvAsInt("ATTACHMENTS_THUMBNAIL_SIZE", 320),
+			OrphanAfterHours:       getEnvAsInt("ATTACHMENTS_ORPHAN_AFTER_HOURS", 24),
+			CleanupIntervalSeconds: getEnvAsInt("ATTACHMENTS_CLEANUP_INTERVAL_SECONDS", 3600),
+		},
 	}
 }
 
@@ -120,6 +303,34 @@ func getEnvAsInt(name string, defaultVal int) int {
 	return defaultVal
 }
 
+func getEnvAsFloat(name string, defaultVal float64) float64 {
+	if value := os.Getenv(name); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+		log.Printf("invalid float value for %s: %s. using default: %g", name, value, defaultVal)
+	}
+	return defaultVal
+}
+
+func getEnvAsSlice(name string, defaultVal []string) []string {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultVal
+	}
+
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	if len(out) == 0 {
+		return defaultVal
+	}
+	return out
+}
+
 func getEnvAsBool(name string, defaultVal bool) bool {
 	if value := os.Getenv(name); value != "" {
 		switch value {
@@ -79,7 +79,13 @@ func Connect(cfg *config.Config) (*gorm.DB, error) {
 }
 
 func autoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	return db.AutoMigrate(Models()...)
+}
+
+// Models lists every model in the schema, in migration order. Test databases
+// are migrated from the same list.
+func Models() []interface{} {
+	return []interface{}{
 		models.User{},
 		models.Book{},
 		models.Club{},
@@ -87,13 +93,32 @@ func autoMigrate(db *gorm.DB) error {
 		models.Event{},
 		models.EventRSVP{},
 		models.Comment{},
-		models.CommentLike{},
 		models.Post{},
-		models.PostLike{},
+		models.Reaction{},
+		models.Revision{},
+		models.Mention{},
+		models.UserBlock{},
+		models.UserFollow{},
+		models.BookmarkCollection{},
+		models.Bookmark{},
+		models.Report{},
+		models.ModerationLog{},
+		models.Notification{},
+		models.ClubContentFilter{},
+		models.PostHashtag{},
+		models.PostViewStat{},
 		models.PollVote{},
+		models.PollOptionCount{},
+		models.Attachment{},
+		models.ClubAttachmentSettings{},
+		models.BookRating{},
 		models.UserBookProgress{},
 		models.ClubBookAssignment{},
 		models.ReadingLog{},
 		models.ClubRating{},
-	)
+		models.ClubMembershipLog{},
+		models.ClubRatingReport{},
+		models.Tag{},
+		models.TagAlias{},
+	}
 }
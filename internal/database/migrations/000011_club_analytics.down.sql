@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_comments_post_created;
+DROP INDEX IF EXISTS idx_posts_club_created;
+
+ALTER TABLE event_rsvps DROP COLUMN IF EXISTS attended;
+
+DROP TABLE IF EXISTS club_membership_logs;
+
+COMMIT;
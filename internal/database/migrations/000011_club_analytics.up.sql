@@ -0,0 +1,27 @@
+BEGIN;
+
+-- membership changes; leaving a club deletes the membership row, so joins and
+-- leaves are logged separately for analytics
+CREATE TABLE IF NOT EXISTS club_membership_logs (
+  id BIGSERIAL PRIMARY KEY,
+  club_id BIGINT NOT NULL REFERENCES clubs(id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  action VARCHAR(20) NOT NULL CHECK (action IN ('joined', 'left')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_club_membership_logs_club_created ON club_membership_logs(club_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_club_membership_logs_user_id ON club_membership_logs(user_id);
+
+-- backfill joins for current members
+INSERT INTO club_membership_logs (club_id, user_id, action, created_at)
+SELECT club_id, user_id, 'joined', joined_at
+FROM club_memberships
+WHERE is_approved = TRUE;
+
+ALTER TABLE event_rsvps ADD COLUMN IF NOT EXISTS attended BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_posts_club_created ON posts(club_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_comments_post_created ON comments(post_id, created_at);
+
+COMMIT;
@@ -0,0 +1,59 @@
+BEGIN;
+
+-- aggregates are now refreshed by the application, which weights ratings of
+-- former members according to configuration
+DROP TRIGGER IF EXISTS trg_club_ratings_ins ON club_ratings;
+DROP TRIGGER IF EXISTS trg_club_ratings_upd ON club_ratings;
+DROP TRIGGER IF EXISTS trg_club_ratings_del ON club_ratings;
+DROP FUNCTION IF EXISTS refresh_club_rating_agg();
+
+ALTER TABLE club_ratings
+  ADD COLUMN IF NOT EXISTS reply TEXT,
+  ADD COLUMN IF NOT EXISTS replied_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS replied_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS member_left_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS reports_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE clubs ADD COLUMN IF NOT EXISTS rating_histogram JSONB;
+
+CREATE TABLE IF NOT EXISTS club_rating_reports (
+  id BIGSERIAL PRIMARY KEY,
+  rating_id BIGINT NOT NULL REFERENCES club_ratings(id) ON DELETE CASCADE,
+  reporter_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  reason VARCHAR(500),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_club_rating_reports_rating_reporter ON club_rating_reports(rating_id, reporter_id);
+
+-- flag ratings left by users who are no longer approved members
+UPDATE club_ratings cr SET member_left_at = NOW()
+WHERE NOT EXISTS (
+  SELECT 1 FROM club_memberships m
+  WHERE m.club_id = cr.club_id AND m.user_id = cr.user_id AND m.is_approved = TRUE
+);
+
+-- recompute aggregates without former members
+UPDATE clubs c SET
+  rating = COALESCE(sub.avg_rating, 0),
+  ratings_count = COALESCE(sub.cnt, 0),
+  rating_histogram = jsonb_build_object(
+    '1', COALESCE(sub.s1, 0), '2', COALESCE(sub.s2, 0), '3', COALESCE(sub.s3, 0),
+    '4', COALESCE(sub.s4, 0), '5', COALESCE(sub.s5, 0))
+FROM clubs c2
+LEFT JOIN (
+  SELECT club_id,
+    AVG(rating)::float AS avg_rating,
+    COUNT(*) AS cnt,
+    COUNT(*) FILTER (WHERE ROUND(rating) = 1) AS s1,
+    COUNT(*) FILTER (WHERE ROUND(rating) = 2) AS s2,
+    COUNT(*) FILTER (WHERE ROUND(rating) = 3) AS s3,
+    COUNT(*) FILTER (WHERE ROUND(rating) = 4) AS s4,
+    COUNT(*) FILTER (WHERE ROUND(rating) = 5) AS s5
+  FROM club_ratings
+  WHERE member_left_at IS NULL
+  GROUP BY club_id
+) sub ON sub.club_id = c2.id
+WHERE c.id = c2.id;
+
+COMMIT;
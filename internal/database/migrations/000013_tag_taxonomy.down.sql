@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS tag_aliases;
+DROP TABLE IF EXISTS tags;
+
+COMMIT;
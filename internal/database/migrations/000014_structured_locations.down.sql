@@ -0,0 +1,17 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_events_district_id;
+DROP INDEX IF EXISTS idx_events_city_id;
+DROP INDEX IF EXISTS idx_clubs_district_id;
+DROP INDEX IF EXISTS idx_clubs_city_id;
+DROP INDEX IF EXISTS idx_users_district_id;
+DROP INDEX IF EXISTS idx_users_city_id;
+
+ALTER TABLE events DROP COLUMN IF EXISTS district_id;
+ALTER TABLE events DROP COLUMN IF EXISTS city_id;
+ALTER TABLE clubs DROP COLUMN IF EXISTS district_id;
+ALTER TABLE clubs DROP COLUMN IF EXISTS city_id;
+ALTER TABLE users DROP COLUMN IF EXISTS district_id;
+ALTER TABLE users DROP COLUMN IF EXISTS city_id;
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS city_id VARCHAR(10);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS district_id VARCHAR(10);
+ALTER TABLE clubs ADD COLUMN IF NOT EXISTS city_id VARCHAR(10);
+ALTER TABLE clubs ADD COLUMN IF NOT EXISTS district_id VARCHAR(10);
+ALTER TABLE events ADD COLUMN IF NOT EXISTS city_id VARCHAR(10);
+ALTER TABLE events ADD COLUMN IF NOT EXISTS district_id VARCHAR(10);
+
+CREATE INDEX IF NOT EXISTS idx_users_city_id ON users(city_id);
+CREATE INDEX IF NOT EXISTS idx_users_district_id ON users(district_id);
+CREATE INDEX IF NOT EXISTS idx_clubs_city_id ON clubs(city_id);
+CREATE INDEX IF NOT EXISTS idx_clubs_district_id ON clubs(district_id);
+CREATE INDEX IF NOT EXISTS idx_events_city_id ON events(city_id);
+CREATE INDEX IF NOT EXISTS idx_events_district_id ON events(district_id);
+
+COMMIT;
@@ -0,0 +1,34 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS post_likes (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT,
+  post_id BIGINT,
+  created_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_post_like ON post_likes(user_id, post_id);
+
+CREATE TABLE IF NOT EXISTS comment_likes (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT,
+  comment_id BIGINT,
+  created_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ
+);
+
+INSERT INTO post_likes (user_id, post_id, created_at, updated_at)
+SELECT user_id, target_id, created_at, updated_at FROM reactions
+WHERE target_type = 'post' AND emoji = '❤️'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO comment_likes (user_id, comment_id, created_at, updated_at)
+SELECT user_id, target_id, created_at, updated_at FROM reactions
+WHERE target_type = 'comment' AND emoji = '❤️';
+
+ALTER TABLE comments DROP COLUMN IF EXISTS reaction_counts;
+ALTER TABLE posts DROP COLUMN IF EXISTS reaction_counts;
+
+DROP TABLE IF EXISTS reactions;
+
+COMMIT;
@@ -0,0 +1,66 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS reactions (
+  id BIGSERIAL PRIMARY KEY,
+  target_type VARCHAR(20) NOT NULL,
+  target_id BIGINT NOT NULL,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  emoji VARCHAR(32) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reaction_unique ON reactions(target_type, target_id, user_id, emoji);
+CREATE INDEX IF NOT EXISTS idx_reaction_target ON reactions(target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_reactions_user_id ON reactions(user_id);
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS reaction_counts JSONB;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS reaction_counts JSONB;
+
+-- existing likes become ❤️ reactions
+DO $$
+BEGIN
+  IF to_regclass('post_likes') IS NOT NULL THEN
+    INSERT INTO reactions (target_type, target_id, user_id, emoji, created_at, updated_at)
+    SELECT 'post', post_id, user_id, '❤️', created_at, updated_at FROM post_likes
+    ON CONFLICT DO NOTHING;
+    DROP TABLE post_likes;
+  END IF;
+
+  IF to_regclass('comment_likes') IS NOT NULL THEN
+    INSERT INTO reactions (target_type, target_id, user_id, emoji, created_at, updated_at)
+    SELECT 'comment', comment_id, user_id, '❤️', created_at, updated_at FROM comment_likes
+    ON CONFLICT DO NOTHING;
+    DROP TABLE comment_likes;
+  END IF;
+END $$;
+
+UPDATE posts p
+SET reaction_counts = agg.counts,
+    likes_count = COALESCE((agg.counts->>'❤️')::INT, 0)
+FROM (
+  SELECT target_id, jsonb_object_agg(emoji, total) AS counts
+  FROM (
+    SELECT target_id, emoji, COUNT(*) AS total
+    FROM reactions WHERE target_type = 'post'
+    GROUP BY target_id, emoji
+  ) per_emoji
+  GROUP BY target_id
+) agg
+WHERE p.id = agg.target_id;
+
+UPDATE comments c
+SET reaction_counts = agg.counts,
+    likes_count = COALESCE((agg.counts->>'❤️')::INT, 0)
+FROM (
+  SELECT target_id, jsonb_object_agg(emoji, total) AS counts
+  FROM (
+    SELECT target_id, emoji, COUNT(*) AS total
+    FROM reactions WHERE target_type = 'comment'
+    GROUP BY target_id, emoji
+  ) per_emoji
+  GROUP BY target_id
+) agg
+WHERE c.id = agg.target_id;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TABLE clubs DROP COLUMN IF EXISTS revision_visibility;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS edited_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS edit_count;
+ALTER TABLE posts DROP COLUMN IF EXISTS edited_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS edit_count;
+
+DROP TABLE IF EXISTS revisions;
+
+COMMIT;
@@ -0,0 +1,24 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS revisions (
+  id BIGSERIAL PRIMARY KEY,
+  target_type VARCHAR(20) NOT NULL,
+  target_id BIGINT NOT NULL,
+  version INTEGER NOT NULL,
+  title VARCHAR(255),
+  content TEXT NOT NULL,
+  type_data JSONB,
+  editor_id BIGINT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_revision_version ON revisions(target_type, target_id, version);
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS edit_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS edit_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ;
+
+ALTER TABLE clubs ADD COLUMN IF NOT EXISTS revision_visibility VARCHAR(20) NOT NULL DEFAULT 'members';
+
+COMMIT;
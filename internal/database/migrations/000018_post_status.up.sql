@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ;
+
+UPDATE posts SET published_at = created_at WHERE published_at IS NULL AND status = 'published';
+
+CREATE INDEX IF NOT EXISTS idx_posts_status ON posts(status);
+CREATE INDEX IF NOT EXISTS idx_posts_publish_at ON posts(publish_at);
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS render_version;
+ALTER TABLE comments DROP COLUMN IF EXISTS content_html;
+
+ALTER TABLE posts DROP COLUMN IF EXISTS render_version;
+ALTER TABLE posts DROP COLUMN IF EXISTS excerpt;
+ALTER TABLE posts DROP COLUMN IF EXISTS content_html;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+-- content stays Markdown; these hold the sanitized rendering. Existing rows
+-- keep render_version 0 and are rendered on read or by POST /content/rerender.
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS content_html TEXT;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS excerpt VARCHAR(320);
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS render_version INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS content_html TEXT;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS render_version INTEGER NOT NULL DEFAULT 0;
+
+COMMIT;
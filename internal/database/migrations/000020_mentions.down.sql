@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS mentions;
+DROP TABLE IF EXISTS user_blocks;
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS post_hashtags (
+  id BIGSERIAL PRIMARY KEY,
+  post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+  tag VARCHAR(50) NOT NULL,
+  club_id BIGINT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_post_hashtag ON post_hashtags(post_id, tag);
+CREATE INDEX IF NOT EXISTS idx_post_hashtags_tag ON post_hashtags(tag);
+
+COMMIT;
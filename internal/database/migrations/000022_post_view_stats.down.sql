@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS post_view_stats;
+
+COMMIT;
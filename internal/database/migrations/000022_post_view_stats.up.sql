@@ -0,0 +1,11 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS post_view_stats (
+  post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+  day TIMESTAMPTZ NOT NULL,
+  views INTEGER NOT NULL DEFAULT 0,
+  impressions INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (post_id, day)
+);
+
+COMMIT;
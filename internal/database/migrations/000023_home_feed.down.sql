@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_posts_user_published;
+DROP INDEX IF EXISTS idx_posts_club_published;
+DROP TABLE IF EXISTS user_follows;
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS user_follows (
+  id BIGSERIAL PRIMARY KEY,
+  follower_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  followee_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_follow ON user_follows(follower_id, followee_id);
+CREATE INDEX IF NOT EXISTS idx_user_follows_followee_id ON user_follows(followee_id);
+
+-- fan-in reads recent posts per club and per author
+CREATE INDEX IF NOT EXISTS idx_posts_club_published ON posts(club_id, published_at) WHERE status = 'published';
+CREATE INDEX IF NOT EXISTS idx_posts_user_published ON posts(user_id, published_at) WHERE status = 'published';
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_posts_hot;
+
+ALTER TABLE posts DROP COLUMN IF EXISTS hot_score;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- existing posts start at 0 and are scored by the first refresh
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS hot_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_posts_hot ON posts(hot_score DESC, id DESC) WHERE status = 'published';
+
+COMMIT;
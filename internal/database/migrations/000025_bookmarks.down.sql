@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS bookmarks;
+DROP TABLE IF EXISTS bookmark_collections;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE users DROP COLUMN IF EXISTS suspended_until;
+ALTER TABLE club_ratings DROP COLUMN IF EXISTS is_hidden;
+ALTER TABLE comments DROP COLUMN IF EXISTS is_hidden;
+
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS moderation_logs;
+DROP TABLE IF EXISTS reports;
+
+COMMIT;
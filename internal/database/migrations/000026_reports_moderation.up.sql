@@ -0,0 +1,74 @@
+BEGIN;
+
+-- target_id points at a post, comment, club rating or user, so it has no
+-- foreign key; snapshot keeps what was reported
+CREATE TABLE IF NOT EXISTS reports (
+  id BIGSERIAL PRIMARY KEY,
+  reporter_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  target_type VARCHAR(20) NOT NULL,
+  target_id BIGINT NOT NULL,
+  target_user_id BIGINT NOT NULL,
+  club_id BIGINT REFERENCES clubs(id) ON DELETE SET NULL,
+  reason VARCHAR(20) NOT NULL,
+  details VARCHAR(1000),
+  snapshot TEXT,
+  status VARCHAR(20) NOT NULL DEFAULT 'open',
+  action VARCHAR(20),
+  resolved_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  resolved_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_report_unique ON reports(reporter_id, target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_report_target ON reports(target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_reports_target_user_id ON reports(target_user_id);
+CREATE INDEX IF NOT EXISTS idx_reports_club_id ON reports(club_id);
+CREATE INDEX IF NOT EXISTS idx_reports_status ON reports(status);
+
+CREATE TABLE IF NOT EXISTS moderation_logs (
+  id BIGSERIAL PRIMARY KEY,
+  moderator_id BIGINT NOT NULL REFERENCES users(id),
+  report_id BIGINT NOT NULL REFERENCES reports(id) ON DELETE CASCADE,
+  target_type VARCHAR(20) NOT NULL,
+  target_id BIGINT NOT NULL,
+  target_user_id BIGINT NOT NULL,
+  club_id BIGINT REFERENCES clubs(id) ON DELETE SET NULL,
+  action VARCHAR(20) NOT NULL,
+  note VARCHAR(1000),
+  reports_count INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_logs_moderator_id ON moderation_logs(moderator_id);
+CREATE INDEX IF NOT EXISTS idx_moderation_logs_report_id ON moderation_logs(report_id);
+CREATE INDEX IF NOT EXISTS idx_moderation_logs_target_user_id ON moderation_logs(target_user_id);
+CREATE INDEX IF NOT EXISTS idx_moderation_logs_club_id ON moderation_logs(club_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  type VARCHAR(50) NOT NULL,
+  message TEXT NOT NULL,
+  target_type VARCHAR(20),
+  target_id BIGINT,
+  read_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id);
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS is_hidden BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE club_ratings ADD COLUMN IF NOT EXISTS is_hidden BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMPTZ;
+
+-- rating reports filed before the moderation queue existed go into it
+INSERT INTO reports (reporter_id, target_type, target_id, target_user_id, club_id, reason, details, snapshot, created_at, updated_at)
+SELECT r.reporter_id, 'rating', r.rating_id, cr.user_id, cr.club_id, 'other', r.reason,
+  ROUND(cr.rating::numeric, 1) || ' stars' || COALESCE(E'\n\n' || cr.comment, ''),
+  r.created_at, r.created_at
+FROM club_rating_reports r
+JOIN club_ratings cr ON cr.id = r.rating_id
+ON CONFLICT DO NOTHING;
+
+COMMIT;
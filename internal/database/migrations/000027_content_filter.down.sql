@@ -0,0 +1,13 @@
+BEGIN;
+
+DELETE FROM reports WHERE reporter_id IS NULL;
+ALTER TABLE reports ALTER COLUMN reporter_id SET NOT NULL;
+
+-- held content stays out of sight
+UPDATE posts SET status = 'hidden' WHERE status = 'held';
+UPDATE comments SET is_hidden = TRUE WHERE is_held;
+ALTER TABLE comments DROP COLUMN IF EXISTS is_held;
+
+DROP TABLE IF EXISTS club_content_filters;
+
+COMMIT;
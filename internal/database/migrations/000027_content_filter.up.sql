@@ -0,0 +1,24 @@
+BEGIN;
+
+-- word lists add to the platform lists; NULL settings keep the platform ones
+CREATE TABLE IF NOT EXISTS club_content_filters (
+  club_id BIGINT PRIMARY KEY REFERENCES clubs(id) ON DELETE CASCADE,
+  block_words TEXT[],
+  hold_words TEXT[],
+  flag_words TEXT[],
+  allow_words TEXT[],
+  max_links INTEGER,
+  link_action VARCHAR(10),
+  repeat_action VARCHAR(10),
+  new_account_rate_action VARCHAR(10),
+  new_account_link_action VARCHAR(10),
+  updated_by BIGINT,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS is_held BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- reports filed by the content filter have no reporter
+ALTER TABLE reports ALTER COLUMN reporter_id DROP NOT NULL;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_poll_votes_post_user;
+
+ALTER TABLE poll_votes DROP COLUMN IF EXISTS rank;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- ranked-choice ballots store one row per ranked option; plurality votes keep rank 0
+ALTER TABLE poll_votes ADD COLUMN IF NOT EXISTS rank INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_poll_votes_post_user ON poll_votes(post_id, user_id, rank);
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- put the counts back into type data for code that still reads them there
+UPDATE posts
+SET type_data = jsonb_set(type_data, '{options}', (
+  SELECT jsonb_agg(jsonb_set(o, '{votes}', to_jsonb(COALESCE(c.votes, 0))) ORDER BY n)
+  FROM jsonb_array_elements(type_data->'options') WITH ORDINALITY AS t(o, n)
+  LEFT JOIN poll_option_counts c ON c.post_id = posts.id AND c.option_id = o->>'id'
+))
+WHERE type = 'poll' AND jsonb_typeof(type_data->'options') = 'array' AND jsonb_array_length(type_data->'options') > 0;
+
+DROP TABLE IF EXISTS poll_option_counts;
+
+COMMIT;
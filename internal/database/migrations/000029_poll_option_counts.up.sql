@@ -0,0 +1,27 @@
+BEGIN;
+
+-- counted votes per option, moved in the same transaction as the votes;
+-- ranked-choice polls count first preferences only
+CREATE TABLE IF NOT EXISTS poll_option_counts (
+  post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+  option_id VARCHAR(255) NOT NULL,
+  votes INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (post_id, option_id)
+);
+
+INSERT INTO poll_option_counts (post_id, option_id, votes)
+SELECT post_id, option_id, COUNT(*)
+FROM poll_votes
+WHERE rank <= 1
+GROUP BY post_id, option_id
+ON CONFLICT (post_id, option_id) DO UPDATE SET votes = EXCLUDED.votes;
+
+-- counts are read from the counters now; drop the copies kept in type data
+UPDATE posts
+SET type_data = jsonb_set(type_data, '{options}', (
+  SELECT jsonb_agg(jsonb_set(o, '{votes}', '0'::jsonb) ORDER BY n)
+  FROM jsonb_array_elements(type_data->'options') WITH ORDINALITY AS t(o, n)
+))
+WHERE type = 'poll' AND jsonb_typeof(type_data->'options') = 'array' AND jsonb_array_length(type_data->'options') > 0;
+
+COMMIT;
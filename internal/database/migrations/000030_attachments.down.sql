@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS club_attachment_settings;
+DROP TABLE IF EXISTS attachments;
+
+COMMIT;
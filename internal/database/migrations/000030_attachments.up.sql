@@ -0,0 +1,42 @@
+BEGIN;
+
+-- uploads start out unattached; ones still unattached after a grace period
+-- are deleted together with their files, so hard deletes of posts and
+-- comments only detach them
+CREATE TABLE IF NOT EXISTS attachments (
+  id BIGSERIAL PRIMARY KEY,
+  club_id BIGINT NOT NULL REFERENCES clubs(id) ON DELETE CASCADE,
+  uploader_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  post_id BIGINT REFERENCES posts(id) ON DELETE SET NULL,
+  comment_id BIGINT REFERENCES comments(id) ON DELETE SET NULL,
+  position INTEGER NOT NULL DEFAULT 0,
+  kind VARCHAR(10) NOT NULL,
+  file_name VARCHAR(255) NOT NULL,
+  content_type VARCHAR(100) NOT NULL,
+  size BIGINT NOT NULL,
+  storage_key VARCHAR(255) NOT NULL,
+  url VARCHAR(1024) NOT NULL,
+  thumbnail_key VARCHAR(255),
+  thumbnail_url VARCHAR(1024),
+  thumbnail_size BIGINT NOT NULL DEFAULT 0,
+  width INTEGER,
+  height INTEGER,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_club_id ON attachments(club_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_uploader_id ON attachments(uploader_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_post_id ON attachments(post_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_comment_id ON attachments(comment_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_orphans ON attachments(created_at) WHERE post_id IS NULL AND comment_id IS NULL;
+
+-- NULL settings keep the platform ones
+CREATE TABLE IF NOT EXISTS club_attachment_settings (
+  club_id BIGINT PRIMARY KEY REFERENCES clubs(id) ON DELETE CASCADE,
+  allowed_types TEXT[],
+  quota_mb INTEGER,
+  updated_by BIGINT,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- copy the originals back into type data for code that still reads them there
+UPDATE posts
+SET type_data = jsonb_build_object('post_id', r.shared_post_id, 'post_title', o.title, 'post_content', o.content)
+FROM posts r
+LEFT JOIN posts o ON o.id = r.shared_post_id
+WHERE posts.id = r.id AND r.shared_post_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_posts_shared_post_id;
+ALTER TABLE posts DROP COLUMN IF EXISTS share_count;
+ALTER TABLE posts DROP COLUMN IF EXISTS shared_post_id;
+
+COMMIT;
@@ -0,0 +1,28 @@
+BEGIN;
+
+-- reshares point at their original instead of copying it; the reference is
+-- kept when the original is deleted so the reshare can say it was removed
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS shared_post_id BIGINT;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS share_count INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_posts_shared_post_id ON posts(shared_post_id);
+
+UPDATE posts
+SET shared_post_id = (type_data->>'post_id')::BIGINT
+WHERE type = 'post' AND shared_post_id IS NULL AND (type_data->>'post_id') ~ '^[1-9][0-9]*$';
+
+-- the copied title and content went stale; previews are read live now
+UPDATE posts
+SET type_data = NULL
+WHERE type = 'post' AND shared_post_id IS NOT NULL;
+
+UPDATE posts
+SET share_count = s.shares
+FROM (
+  SELECT shared_post_id, COUNT(*) AS shares
+  FROM posts
+  WHERE shared_post_id IS NOT NULL AND deleted_at IS NULL
+  GROUP BY shared_post_id
+) s
+WHERE posts.id = s.shared_post_id;
+
+COMMIT;
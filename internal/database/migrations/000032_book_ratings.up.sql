@@ -0,0 +1,41 @@
+BEGIN;
+
+-- the rating from each review post; a user keeps one review per book until
+-- they delete it
+CREATE TABLE IF NOT EXISTS book_ratings (
+  post_id BIGINT PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+  book_id BIGINT NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL,
+  rating REAL NOT NULL,
+  created_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_book_ratings_book_id ON book_ratings(book_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_book_ratings_book_user ON book_ratings(book_id, user_id);
+
+-- existing reviews count once per user and book, taking the latest; older
+-- reviews of the same book keep no rating but can still be edited
+INSERT INTO book_ratings (post_id, book_id, user_id, rating, created_at, updated_at)
+SELECT DISTINCT ON (b.id, p.user_id)
+  p.id, b.id, p.user_id, (p.type_data->>'rating')::REAL, p.created_at, p.updated_at
+FROM posts p
+JOIN books b ON b.id = (p.type_data->>'book_id')::BIGINT AND b.deleted_at IS NULL
+WHERE p.type = 'review' AND p.deleted_at IS NULL
+  AND (p.type_data->>'book_id') ~ '^[1-9][0-9]*$'
+  AND (p.type_data->>'rating') ~ '^[0-9]+(\.[0-9]+)?$'
+  AND (p.type_data->>'rating')::REAL BETWEEN 1 AND 5
+ORDER BY b.id, p.user_id, p.created_at DESC, p.id DESC
+ON CONFLICT DO NOTHING;
+
+UPDATE books
+SET local_rating = r.average, rating_count = r.ratings
+FROM (
+  SELECT br.book_id, ROUND(AVG(br.rating)::NUMERIC, 1) AS average, COUNT(*) AS ratings
+  FROM book_ratings br
+  JOIN posts p ON p.id = br.post_id
+  WHERE p.status = 'published' AND p.deleted_at IS NULL
+  GROUP BY br.book_id
+) r
+WHERE books.id = r.book_id;
+
+COMMIT;
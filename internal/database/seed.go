@@ -99,8 +99,11 @@ func SeedForTest(db *gorm.DB) error {
 func truncateAll(db *gorm.DB) error {
     return db.Exec(`
         TRUNCATE TABLE 
-            post_likes,
-            comment_likes,
+            post_view_stats,
+            post_hashtags,
+            mentions,
+            user_blocks,
+            reactions,
             comments,
             posts,
             event_rsvps,
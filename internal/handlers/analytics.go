@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+)
+
+type AnalyticsHandler struct {
+	analyticsService *services.AnalyticsService
+	validator        *validator.Validate
+}
+
+func NewAnalyticsHandler(analyticsService *services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+		validator:        validator.New(),
+	}
+}
+
+// @Summary Get club analytics
+// @Description Activity and engagement time series for a club. Only club admins can access it.
+// @Tags Clubs
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param from query string false "Start date (YYYY-MM-DD, inclusive), defaults to 30 days before to"
+// @Param to query string false "End date (YYYY-MM-DD, inclusive), defaults to today"
+// @Param granularity query string false "Bucket size" Enums(day, week, month) default(day)
+// @Param top query int false "Number of most active members to return" default(10)
+// @Success 200 {object} models.ClubAnalyticsResponse "Club analytics"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Club not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/v1/clubs/{id}/analytics [get]
+func (h *AnalyticsHandler) GetClubAnalytics(c *gin.Context) {
+	clubIDParam := c.Param("id")
+	clubID, err := strconv.ParseUint(clubIDParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid club ID"})
+		return
+	}
+
+	var req models.ClubAnalyticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters"})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	analytics, err := h.analyticsService.GetClubAnalytics(uint(clubID), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrClubNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidAnalyticsRange):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date range; from must be before to and span at most 366 days"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"analytics": analytics})
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+)
+
+// multipartOverhead is room for the multipart headers around an uploaded
+// file of the largest allowed size.
+const multipartOverhead = 64 << 10
+
+type AttachmentHandler struct {
+	attachmentService *services.AttachmentService
+	validator         *validator.Validate
+}
+
+func NewAttachmentHandler(attachmentService *services.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		validator:         validator.New(),
+	}
+}
+
+// @Summary Upload an attachment
+// @Description Upload an image or file to the club for a post or comment. Pass the returned ID in attachment_ids when creating the post or comment; uploads not attached within a day are deleted. The type is taken from the file contents and must be one the club allows. Images get a thumbnail.
+// @Tags Attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param file formData file true "File"
+// @Success 201 {object} models.AttachmentResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string "Club quota exceeded"
+// @Failure 413 {object} map[string]string "File too large"
+// @Failure 415 {object} map[string]string "File type not allowed"
+// @Security BearerAuth
+// @Router /api/v1/clubs/{id}/attachments [post]
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	clubID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid club ID"})
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	maxBytes := h.attachmentService.MaxFileBytes()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrAttachmentTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a file is required"})
+		return
+	}
+	if header.Size > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrAttachmentTooLarge.Error()})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentService.Upload(c.Request.Context(), userID, uint(clubID), header.Filename, file)
+	if err != nil {
+		attachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// @Summary Delete an attachment
+// @Description Delete one of your uploads. It is taken off the post or comment it was attached to.
+// @Tags Attachments
+// @Produce json
+// @Param id path int true "Attachment ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/attachments/{id} [delete]
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	userID, id, ok := parseOwnedTarget(c, "attachment")
+	if !ok {
+		return
+	}
+
+	if err := h.attachmentService.DeleteUpload(id, userID); err != nil {
+		attachmentError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get a club's attachment settings
+// @Description Get the file types the club accepts, the size limits and how much of its storage quota it uses.
+// @Tags Attachments
+// @Produce json
+// @Param id path int true "Club ID"
+// @Success 200 {object} models.ClubAttachmentSettingsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/clubs/{id}/attachment-settings [get]
+func (h *AttachmentHandler) GetClubAttachmentSettings(c *gin.Context) {
+	clubID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid club ID"})
+		return
+	}
+
+	settings, err := h.attachmentService.GetClubSettings(uint(clubID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// @Summary Set a club's attachment settings
+// @Description Replace the club's attachment settings. allowed_types narrows the platform's allowed types; an empty list allows all of them. Only platform admins can set quota_mb; omit it to keep the club's quota.
+// @Tags Attachments
+// @Accept json
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param settings body models.UpdateClubAttachmentSettingsRequest true "Settings"
+// @Success 200 {object} models.ClubAttachmentSettingsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/clubs/{id}/attachment-settings [put]
+func (h *AttachmentHandler) UpdateClubAttachmentSettings(c *gin.Context) {
+	clubID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid club ID"})
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	role, _ := c.Get("user_role")
+	roleStr, _ := role.(string)
+
+	var req models.UpdateClubAttachmentSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.attachmentService.UpdateClubSettings(uint(clubID), userID, roleStr, &req)
+	if err != nil {
+		attachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func attachmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrAttachmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAttachmentTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAttachmentTypeNotAllowed):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAttachmentQuotaExceeded):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnknownAttachmentType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAttachmentQuotaAdminOnly):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
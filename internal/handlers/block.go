@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+)
+
+type BlockHandler struct {
+	blockService *services.BlockService
+}
+
+func NewBlockHandler(blockService *services.BlockService) *BlockHandler {
+	return &BlockHandler{
+		blockService: blockService,
+	}
+}
+
+// @Summary Block a user
+// @Description Block a user. Their posts and comments can no longer mention you.
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/block [post]
+func (h *BlockHandler) BlockUser(c *gin.Context) {
+	blockerID, blockedID, ok := parseUserTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.blockService.BlockUser(blockerID, blockedID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrCannotBlockSelf):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err.Error() == "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user blocked successfully"})
+}
+
+// @Summary Unblock a user
+// @Description Remove a block on a user
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/block [delete]
+func (h *BlockHandler) UnblockUser(c *gin.Context) {
+	blockerID, blockedID, ok := parseUserTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.blockService.UnblockUser(blockerID, blockedID); err != nil {
+		if errors.Is(err, services.ErrBlockNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user unblocked successfully"})
+}
+
+// parseUserTarget returns the caller's ID and the user ID in the path. On
+// failure it writes the error response itself and returns false.
+func parseUserTarget(c *gin.Context) (uint, uint, bool) {
+	uidRaw, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return 0, 0, false
+	}
+
+	userID, ok := uidRaw.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return 0, 0, false
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return 0, 0, false
+	}
+
+	return userID, uint(targetID), true
+}
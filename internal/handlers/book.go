@@ -56,7 +56,7 @@ func (h *BookHandler) CreateBook(c *gin.Context) {
 }
 
 // @Summary Get a book by ID
-// @Description Retrieve a book's details by its ID
+// @Description Retrieve a book's details by its ID. Signed in users also get their own rating and reading status.
 // @Tags Books
 // @Produce json
 // @Param id path int true "Book ID"
@@ -72,7 +72,8 @@ func (h *BookHandler) GetBookByID(c *gin.Context) {
 		return
 	}
 
-	book, err := h.bookService.GetBookByID(uint(id))
+	viewerID, _ := optionalViewer(c)
+	book, err := h.bookService.GetBookByID(uint(id), viewerID)
 	if err != nil {
 		if err.Error() == "book not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -163,20 +164,31 @@ func (h *BookHandler) DeleteBook(c *gin.Context) {
 }
 
 // @Summary List all books
-// @Description Retrieve a list of all books
+// @Description Retrieve a page of books ordered by title
 // @Tags Books
 // @Produce json
-// @Success 200 {object} map[string]interface{} "List of books"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.BookResponse] "List of books"
+// @Failure 400 {object} map[string]string "Bad request"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/v1/books [get]
 func (h *BookHandler) ListBooks(c *gin.Context) {
-	books, err := h.bookService.ListBooks()
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	books, err := h.bookService.ListBooks(page)
 	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"books": books})
+	c.JSON(http.StatusOK, books)
 }
 
 // @Summary Search for books
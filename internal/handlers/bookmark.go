@@ -0,0 +1,418 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+)
+
+type BookmarkHandler struct {
+	bookmarkService *services.BookmarkService
+	validator       *validator.Validate
+}
+
+func NewBookmarkHandler(bookmarkService *services.BookmarkService) *BookmarkHandler {
+	return &BookmarkHandler{
+		bookmarkService: bookmarkService,
+		validator:       validator.New(),
+	}
+}
+
+// @Summary Bookmark a post or comment
+// @Description Save a post or comment you can read, optionally into one of your collections. A target can be bookmarked once; move it between collections instead of bookmarking it again.
+// @Tags Bookmarks
+// @Accept json
+// @Produce json
+// @Param bookmark body models.CreateBookmarkRequest true "Bookmark target"
+// @Success 201 {object} models.BookmarkResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/me/bookmarks [post]
+func (h *BookmarkHandler) CreateBookmark(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookmark, err := h.bookmarkService.CreateBookmark(userID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrBookmarkTargetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": req.TargetType + " not found"})
+			return
+		}
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, bookmark)
+}
+
+// @Summary List my bookmarks
+// @Description List your bookmarks, newest first, or one collection in the order you arranged it. Bookmarks whose post or comment was deleted or is no longer readable come back with unavailable set and no content.
+// @Tags Bookmarks
+// @Produce json
+// @Param collection_id query int false "Only list this collection"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.BookmarkResponse]
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/me/bookmarks [get]
+func (h *BookmarkHandler) ListBookmarks(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ListBookmarksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookmarks, err := h.bookmarkService.ListBookmarks(userID, &req)
+	if err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bookmarks)
+}
+
+// @Summary Move a bookmark
+// @Description Move a bookmark to the end of another collection, or out of any collection with a null collection_id
+// @Tags Bookmarks
+// @Accept json
+// @Produce json
+// @Param id path int true "Bookmark ID"
+// @Param move body models.MoveBookmarkRequest true "Destination collection"
+// @Success 200 {object} models.BookmarkResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/me/bookmarks/{id} [put]
+func (h *BookmarkHandler) MoveBookmark(c *gin.Context) {
+	userID, id, ok := parseOwnedTarget(c, "bookmark")
+	if !ok {
+		return
+	}
+
+	var req models.MoveBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookmark, err := h.bookmarkService.MoveBookmark(userID, id, &req)
+	if err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bookmark)
+}
+
+// @Summary Remove a bookmark
+// @Tags Bookmarks
+// @Produce json
+// @Param id path int true "Bookmark ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/me/bookmarks/{id} [delete]
+func (h *BookmarkHandler) DeleteBookmark(c *gin.Context) {
+	userID, id, ok := parseOwnedTarget(c, "bookmark")
+	if !ok {
+		return
+	}
+
+	if err := h.bookmarkService.DeleteBookmark(userID, id); err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "bookmark removed successfully"})
+}
+
+// @Summary Create a bookmark collection
+// @Description Create a named collection, added after your existing ones. Collections are private to you.
+// @Tags Bookmarks
+// @Accept json
+// @Produce json
+// @Param collection body models.BookmarkCollectionRequest true "Collection name"
+// @Success 201 {object} models.BookmarkCollection
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/me/collections [post]
+func (h *BookmarkHandler) CreateCollection(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.BookmarkCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection, err := h.bookmarkService.CreateCollection(userID, &req)
+	if err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, collection)
+}
+
+// @Summary List my bookmark collections
+// @Description List your collections in the order you arranged them, with how many bookmarks each holds
+// @Tags Bookmarks
+// @Produce json
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.BookmarkCollectionResponse]
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/me/collections [get]
+func (h *BookmarkHandler) ListCollections(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req pagination.Request
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collections, err := h.bookmarkService.ListCollections(userID, req)
+	if err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collections)
+}
+
+// @Summary Rename a bookmark collection
+// @Tags Bookmarks
+// @Accept json
+// @Produce json
+// @Param id path int true "Collection ID"
+// @Param collection body models.BookmarkCollectionRequest true "New name"
+// @Success 200 {object} models.BookmarkCollection
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/me/collections/{id} [put]
+func (h *BookmarkHandler) RenameCollection(c *gin.Context) {
+	userID, id, ok := parseOwnedTarget(c, "collection")
+	if !ok {
+		return
+	}
+
+	var req models.BookmarkCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection, err := h.bookmarkService.RenameCollection(userID, id, &req)
+	if err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// @Summary Delete a bookmark collection
+// @Description Delete a collection together with the bookmarks in it
+// @Tags Bookmarks
+// @Produce json
+// @Param id path int true "Collection ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/me/collections/{id} [delete]
+func (h *BookmarkHandler) DeleteCollection(c *gin.Context) {
+	userID, id, ok := parseOwnedTarget(c, "collection")
+	if !ok {
+		return
+	}
+
+	if err := h.bookmarkService.DeleteCollection(userID, id); err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "collection deleted successfully"})
+}
+
+// @Summary Reorder my bookmark collections
+// @Description Arrange your collections in the order given. ids must list every collection exactly once.
+// @Tags Bookmarks
+// @Accept json
+// @Produce json
+// @Param order body models.ReorderRequest true "Collection IDs in their new order"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/me/collections/order [put]
+func (h *BookmarkHandler) ReorderCollections(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.bookmarkService.ReorderCollections(userID, &req); err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "collections reordered successfully"})
+}
+
+// @Summary Reorder the bookmarks in a collection
+// @Description Arrange a collection's bookmarks in the order given. ids must list every bookmark in the collection exactly once.
+// @Tags Bookmarks
+// @Accept json
+// @Produce json
+// @Param id path int true "Collection ID"
+// @Param order body models.ReorderRequest true "Bookmark IDs in their new order"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/me/collections/{id}/order [put]
+func (h *BookmarkHandler) ReorderBookmarks(c *gin.Context) {
+	userID, id, ok := parseOwnedTarget(c, "collection")
+	if !ok {
+		return
+	}
+
+	var req models.ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.bookmarkService.ReorderBookmarks(userID, id, &req); err != nil {
+		writeBookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "bookmarks reordered successfully"})
+}
+
+func writeBookmarkError(c *gin.Context, err error) {
+	switch {
+	case invalidCursor(c, err):
+	case errors.Is(err, services.ErrBookmarkNotFound), errors.Is(err, services.ErrCollectionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadyBookmarked), errors.Is(err, services.ErrCollectionNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReorderMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// currentUserID returns the caller's ID. On failure it writes the error
+// response itself and returns false.
+func currentUserID(c *gin.Context) (uint, bool) {
+	uidRaw, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return 0, false
+	}
+
+	userID, ok := uidRaw.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return 0, false
+	}
+	return userID, true
+}
+
+// parseOwnedTarget returns the caller's ID and the ID in the path of one of
+// their own resources, named by what in the error message.
+func parseOwnedTarget(c *gin.Context, what string) (uint, uint, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + what + " ID"})
+		return 0, 0, false
+	}
+	return userID, uint(id), true
+}
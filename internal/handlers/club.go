@@ -9,6 +9,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
 	"github.com/nevzattalhaozcan/forgotten/internal/services"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
 )
 
@@ -58,7 +59,7 @@ func (h *ClubHandler) CreateClub(c *gin.Context) {
 
 	club, err := h.clubService.CreateClub(ownerID, &req)
 	if err != nil {
-		if errors.Is(err, services.ErrClubNameExists) {
+		if errors.Is(err, services.ErrClubNameExists) || errors.Is(err, services.ErrInvalidLocation) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
@@ -99,38 +100,48 @@ func (h *ClubHandler) GetClub(c *gin.Context) {
 }
 
 // @Summary Get all clubs
-// @Description Retrieve a list of all clubs with optional filters
+// @Description Retrieve a page of clubs, newest first, with optional filters
 // @Tags Clubs
 // @Produce json
 // @Param location query string false "Filter by location (partial match)"
+// @Param city_id query string false "Filter by city ID"
+// @Param district_id query string false "Filter by district ID"
 // @Param genre query string false "Filter by genre (partial match)"
 // @Param meeting_type query string false "Filter by meeting type" Enums(online, in-person, hybrid)
 // @Param min_members query int false "Minimum member count"
 // @Param max_members query int false "Maximum member count"
 // @Param limit query int false "Number of results to return" default(20)
-// @Param offset query int false "Number of results to skip" default(0)
-// @Success 200 {object} map[string]interface{} "Clubs retrieved successfully"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.ClubResponse] "Clubs retrieved successfully"
 // @Failure 400 {object} map[string]string "Bad request - invalid filter parameters"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/v1/clubs [get]
 func (h *ClubHandler) GetAllClubs(c *gin.Context) {
 	location := c.Query("location")
+	cityID := c.Query("city_id")
+	districtID := c.Query("district_id")
 	genre := c.Query("genre")
 	meetingType := c.Query("meeting_type")
 	minMembers, _ := strconv.Atoi(c.DefaultQuery("min_members", "0"))
 	maxMembers, _ := strconv.Atoi(c.DefaultQuery("max_members", "0"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-	hasFilters := location != "" || genre != "" || meetingType != "" || minMembers > 0 || maxMembers > 0
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	hasFilters := location != "" || cityID != "" || districtID != "" || genre != "" || meetingType != "" || minMembers > 0 || maxMembers > 0
 
 	if !hasFilters {
-		clubs, err := h.clubService.GetAllClubs()
+		clubs, err := h.clubService.GetAllClubs(page)
 		if err != nil {
+			if invalidCursor(c, err) {
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve clubs"})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"clubs": clubs})
+		c.JSON(http.StatusOK, clubs)
 		return
 	}
 
@@ -139,18 +150,17 @@ func (h *ClubHandler) GetAllClubs(c *gin.Context) {
 		return
 	}
 
-	clubs, err := h.clubService.GetClubsWithFilters(location, genre, meetingType, minMembers, maxMembers, limit, offset)
+	clubs, err := h.clubService.GetClubsWithFilters(location, cityID, districtID, genre, meetingType, minMembers, maxMembers, page)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidLocation) || errors.Is(err, pagination.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve clubs"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"clubs":  clubs,
-		"count":  len(clubs),
-		"limit":  limit,
-		"offset": offset,
-	})
+	c.JSON(http.StatusOK, clubs)
 }
 
 // @Summary Update club
@@ -205,6 +215,10 @@ func (h *ClubHandler) UpdateClub(c *gin.Context) {
 
 	club, err := h.clubService.UpdateClub(uint(id), &req)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidLocation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -365,11 +379,13 @@ func (h *ClubHandler) LeaveClub(c *gin.Context) {
 }
 
 // @Summary List club members
-// @Description List all members of a club by its ID
+// @Description List the approved members of a club in the order they joined
 // @Tags Clubs
 // @Produce json
 // @Param id path int true "Club ID"
-// @Success 200 {object} map[string]interface{} "List of club members"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.ClubMembership] "List of club members"
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/v1/clubs/{id}/members [get]
@@ -381,13 +397,21 @@ func (h *ClubHandler) ListClubMembers(c *gin.Context) {
 		return
 	}
 
-	members, err := h.clubService.ListClubMembers(uint(clubID))
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	members, err := h.clubService.ListClubMembers(uint(clubID), page)
 	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"members": members})
+	c.JSON(http.StatusOK, members)
 }
 
 // @Summary Update club member
@@ -558,13 +582,13 @@ func (h *ClubHandler) RateClub(c *gin.Context) {
 }
 
 // @Summary List club ratings
-// @Description List all ratings for a club by its ID
+// @Description List the ratings of a club, most recently updated first
 // @Tags Clubs
 // @Produce json
 // @Param id path int true "Club ID"
-// @Param limit query int false "Limit" default(20)
-// @Param offset query int false "Offset" default(0)
-// @Success 200 {object} map[string]interface{} "List of club ratings"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.ClubRating] "List of club ratings"
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/v1/clubs/{id}/ratings [get]
@@ -577,25 +601,123 @@ func (h *ClubHandler) ListClubRatings(c *gin.Context) {
 	}
 	clubID := uint(clubID64)
 
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	ratings, err := h.clubService.ListClubRatings(clubID, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ratings)
+}
+
+// @Summary Get club rating breakdown
+// @Description Get the average rating, rating count and star distribution of a club
+// @Tags Clubs
+// @Produce json
+// @Param id path int true "Club ID"
+// @Success 200 {object} models.ClubRatingBreakdownResponse "Club rating breakdown"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Club not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/v1/clubs/{id}/ratings/histogram [get]
+func (h *ClubHandler) GetRatingBreakdown(c *gin.Context) {
+	clubIDParam := c.Param("id")
+	clubID64, err := strconv.ParseUint(clubIDParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid club ID"})
+		return
+	}
+
+	breakdown, err := h.clubService.GetRatingBreakdown(uint(clubID64))
+	if err != nil {
+		if errors.Is(err, services.ErrClubNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, breakdown)
+}
 
-	ratings, err := h.clubService.ListClubRatings(clubID, limit, offset)
+// @Summary Reply to a club rating
+// @Description Club admins can publicly reply to a rating comment
+// @Tags Clubs
+// @Accept json
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param rating_id path int true "Rating ID"
+// @Param request body models.ReplyClubRatingRequest true "Reply"
+// @Success 200 {object} map[string]interface{} "Reply saved"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Rating not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/clubs/{id}/ratings/{rating_id}/reply [put]
+func (h *ClubHandler) ReplyToRating(c *gin.Context) {
+	clubID64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid club ID"})
+		return
+	}
+	ratingID64, err := strconv.ParseUint(c.Param("rating_id"), 10, 32)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rating ID"})
+		return
+	}
+
+	userIDRaw, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	userID, ok := userIDRaw.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req models.ReplyClubRatingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	rating, err := h.clubService.ReplyToRating(uint(clubID64), uint(ratingID64), userID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrRatingNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"ratings": ratings,
+		"message": "reply saved successfully",
+		"rating":  rating,
 	})
 }
 
 // @Summary Get user's clubs
-// @Description Retrieve a list of clubs the authenticated user is a member of
+// @Description Retrieve the clubs the authenticated user is a member of, by name
 // @Tags Users
 // @Produce json
-// @Success 200 {object} map[string]interface{} "List of user's clubs"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.ClubResponse] "List of user's clubs"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/v1/users/my-clubs [get]
@@ -611,11 +733,19 @@ func (h *ClubHandler) GetMyClubs(c *gin.Context) {
 		return
 	}
 
-	clubs, err := h.clubService.ListUserClubs(userID)
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	clubs, err := h.clubService.ListUserClubs(userID, page)
 	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"clubs": clubs})
+	c.JSON(http.StatusOK, clubs)
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -24,7 +25,7 @@ func NewCommentHandler(commentService *services.CommentService) *CommentHandler
 
 
 // @Summary Create a new comment
-// @Description Create a new comment for a specific post
+// @Description Create a new comment for a specific post. Set parent_id to reply to another comment on the same post.
 // @Tags Comments
 // @Accept json
 // @Produce json
@@ -33,6 +34,7 @@ func NewCommentHandler(commentService *services.CommentService) *CommentHandler
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /posts/{id}/comments [post]
 func (c *CommentHandler) CreateComment(ctx *gin.Context) {
@@ -68,6 +70,18 @@ func (c *CommentHandler) CreateComment(ctx *gin.Context) {
 
 	comment, err := c.CommentService.CreateComment(uint(postID), userID, &req)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidParentComment) || errors.Is(err, services.ErrCommentDepthExceeded) || errors.Is(err, services.ErrInvalidAttachment) || errors.Is(err, services.ErrTooManyAttachments) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrContentBlocked) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "post not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -122,9 +136,22 @@ func (c *CommentHandler) GetCommentByID(ctx *gin.Context) {
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /comments/{id} [put]
 func (c *CommentHandler) UpdateComment(ctx *gin.Context) {
+	uidRaw, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, ok := uidRaw.(uint)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
 	idParam := ctx.Param("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
@@ -143,8 +170,12 @@ func (c *CommentHandler) UpdateComment(ctx *gin.Context) {
 		return
 	}
 
-	comment, err := c.CommentService.UpdateComment(uint(id), &req)
+	comment, err := c.CommentService.UpdateComment(uint(id), userID, &req)
 	if err != nil {
+		if errors.Is(err, services.ErrContentBlocked) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 		if err.Error() == "comment not found" {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
 			return
@@ -192,12 +223,15 @@ func (c *CommentHandler) DeleteComment(ctx *gin.Context) {
 }
 
 // @Summary List comments by post ID
-// @Description Retrieve all comments for a specific post
+// @Description Retrieve a page of top-level comments for a post, each with its first replies
 // @Tags Comments
 // @Accept json
 // @Produce json
 // @Param id path int true "Post ID"
-// @Success 200 {object} map[string]interface{}
+// @Param limit query int false "Top-level comments per page" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param replies_limit query int false "Replies included per comment" default(3)
+// @Success 200 {object} pagination.Page[models.CommentResponse]
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -210,8 +244,22 @@ func (c *CommentHandler) ListCommentsByPostID(ctx *gin.Context) {
 		return
 	}
 
-	comments, err := c.CommentService.ListCommentsByPostID(uint(postID))
+	var req models.CommentThreadRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comments, err := c.CommentService.ListCommentsByPostID(uint(postID), &req)
 	if err != nil {
+		if invalidCursor(ctx, err) {
+			return
+		}
 		if err.Error() == "post not found" {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
 			return
@@ -220,18 +268,100 @@ func (c *CommentHandler) ListCommentsByPostID(ctx *gin.Context) {
 		return
 	}
 
+	ctx.JSON(http.StatusOK, comments)
+}
+
+// @Summary List replies to a comment
+// @Description Retrieve a page of direct replies to a comment, oldest first
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Param limit query int false "Replies per page" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.CommentResponse]
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /comments/{id}/replies [get]
+func (c *CommentHandler) ListReplies(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	commentID, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	var req models.CommentThreadRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	replies, err := c.CommentService.ListReplies(uint(commentID), &req)
+	if err != nil {
+		if invalidCursor(ctx, err) {
+			return
+		}
+		if err.Error() == "comment not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, replies)
+}
+
+// @Summary Get a comment thread
+// @Description Retrieve a comment with its full reply subtree
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /comments/{id}/thread [get]
+func (c *CommentHandler) GetCommentThread(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	commentID, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	thread, err := c.CommentService.GetCommentThread(uint(commentID))
+	if err != nil {
+		if err.Error() == "comment not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
-		"comments": comments,
+		"comment": thread,
 	})
 }
 
 // @Summary List comments by user ID
-// @Description Retrieve all comments made by a specific user
+// @Description Retrieve a page of comments made by a specific user, newest first
 // @Tags Comments
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
-// @Success 200 {object} map[string]interface{}
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.Comment]
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -244,8 +374,16 @@ func (c *CommentHandler) ListCommentsByUserID(ctx *gin.Context) {
 		return
 	}
 
-	comments, err := c.CommentService.ListCommentsByUserID(uint(userID))
+	page, ok := bindPage(ctx, c.validator)
+	if !ok {
+		return
+	}
+
+	comments, err := c.CommentService.ListCommentsByUserID(uint(userID), page)
 	if err != nil {
+		if invalidCursor(ctx, err) {
+			return
+		}
 		if err.Error() == "user not found" {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 			return
@@ -254,9 +392,7 @@ func (c *CommentHandler) ListCommentsByUserID(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"comments": comments,
-	})
+	ctx.JSON(http.StatusOK, comments)
 }
 
 // @Summary Like a comment
@@ -366,12 +502,14 @@ func (c *CommentHandler) UnlikeComment(ctx *gin.Context) {
 }
 
 // @Summary List likes by comment ID
-// @Description Retrieve all likes for a specific comment
+// @Description Retrieve a page of likes for a specific comment, oldest first
 // @Tags Comments
 // @Accept json
 // @Produce json
 // @Param id path int true "Comment ID"
-// @Success 200 {object} map[string]interface{}
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.CommentLikeResponse]
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -384,8 +522,16 @@ func (c *CommentHandler) ListLikesByCommentID(ctx *gin.Context) {
 		return
 	}
 
-	likes, err := c.CommentService.ListLikesByCommentID(uint(commentID))
+	page, ok := bindPage(ctx, c.validator)
+	if !ok {
+		return
+	}
+
+	likes, err := c.CommentService.ListLikesByCommentID(uint(commentID), page)
 	if err != nil {
+		if invalidCursor(ctx, err) {
+			return
+		}
 		if err.Error() == "comment not found" {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
 			return
@@ -394,7 +540,5 @@ func (c *CommentHandler) ListLikesByCommentID(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"likes": likes,
-	})
+	ctx.JSON(http.StatusOK, likes)
 }
\ No newline at end of file
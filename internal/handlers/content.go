@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+)
+
+type ContentHandler struct {
+	renderService *services.ContentRenderService
+}
+
+func NewContentHandler(renderService *services.ContentRenderService) *ContentHandler {
+	return &ContentHandler{
+		renderService: renderService,
+	}
+}
+
+// @Summary Re-render post and comment HTML
+// @Description Re-render the stored HTML of posts and comments rendered under an older Markdown policy (admin only)
+// @Tags Content
+// @Produce json
+// @Success 200 {object} models.ContentRerenderResponse "Re-render results"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/content/rerender [post]
+func (h *ContentHandler) RerenderContent(c *gin.Context) {
+	result, err := h.renderService.Run()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to re-render content"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
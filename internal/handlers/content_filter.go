@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+)
+
+type ContentFilterHandler struct {
+	contentFilter *services.ContentFilter
+	validator     *validator.Validate
+}
+
+func NewContentFilterHandler(contentFilter *services.ContentFilter) *ContentFilterHandler {
+	return &ContentFilterHandler{
+		contentFilter: contentFilter,
+		validator:     validator.New(),
+	}
+}
+
+// @Summary Get a club's content filter overrides
+// @Description Get how the club changes the platform content filter for its posts and comments. A club without overrides gets empty ones.
+// @Tags Moderation
+// @Produce json
+// @Param id path int true "Club ID"
+// @Success 200 {object} models.ClubContentFilter
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/clubs/{id}/content-filter [get]
+func (h *ContentFilterHandler) GetClubContentFilter(c *gin.Context) {
+	clubID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid club ID"})
+		return
+	}
+
+	filter, err := h.contentFilter.GetClubFilter(uint(clubID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, filter)
+}
+
+// @Summary Set a club's content filter overrides
+// @Description Replace the club's content filter overrides. Word lists add to the platform lists and allow_words lifts platform hold and flag words; platform block words always apply. Actions are flag, hold, block or off; omitted ones keep the platform setting.
+// @Tags Moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param filter body models.UpdateClubContentFilterRequest true "Overrides"
+// @Success 200 {object} models.ClubContentFilter
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/clubs/{id}/content-filter [put]
+func (h *ContentFilterHandler) UpdateClubContentFilter(c *gin.Context) {
+	clubID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid club ID"})
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateClubContentFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := h.contentFilter.UpdateClubFilter(uint(clubID), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, filter)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
 	"github.com/nevzattalhaozcan/forgotten/internal/services"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
 )
 
@@ -55,6 +57,10 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 
 	event, err := h.eventService.CreateEvent(uint(clubID), &req)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidLocation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -66,11 +72,13 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 }
 
 // @Summary List events for a club
-// @Description Retrieve all events for a specific club
+// @Description Retrieve a page of a club's events, latest date first
 // @Tags Events
 // @Produce json
 // @Param id path int true "Club ID"
-// @Success 200 {object} map[string]interface{} "List of events"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.EventResponse] "List of events"
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/v1/clubs/{id}/events [get]
@@ -82,13 +90,21 @@ func (h *EventHandler) GetClubEvents(c *gin.Context) {
 		return
 	}
 
-	events, err := h.eventService.GetClubEvents(uint(clubID))
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	events, err := h.eventService.GetClubEvents(uint(clubID), page)
 	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"events": events})
+	c.JSON(http.StatusOK, events)
 }
 
 // @Summary Get event details
@@ -149,6 +165,10 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 
 	event, err := h.eventService.UpdateEvent(uint(id), &req)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidLocation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -261,11 +281,13 @@ func (h *EventHandler) RSVPToEvent(c *gin.Context) {
 }
 
 // @Summary Get event attendees
-// @Description Retrieve a list of attendees for a specific event
+// @Description Retrieve a page of RSVPs for a specific event, oldest first
 // @Tags Events
 // @Produce json
 // @Param id path int true "Event ID"
-// @Success 200 {object} map[string]interface{} "List of attendees"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.EventRSVP] "List of attendees"
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/v1/events/{id}/attendees [get]
@@ -277,28 +299,92 @@ func (h *EventHandler) GetEventAttendees(c *gin.Context) {
 		return
 	}
 
-	attendees, err := h.eventService.GetEventAttendees(uint(eventID))
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	attendees, err := h.eventService.GetEventAttendees(uint(eventID), page)
 	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"attendees": attendees})
+	c.JSON(http.StatusOK, attendees)
+}
+
+// @Summary Mark event attendance
+// @Description Record which RSVP'd members actually attended an event
+// @Tags Events
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param request body models.MarkAttendanceRequest true "Attendance data"
+// @Success 200 {object} map[string]interface{} "Attendance updated"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Event not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/v1/events/{id}/attendance [post]
+func (h *EventHandler) MarkAttendance(c *gin.Context) {
+	eventParam := c.Param("id")
+	eventID, err := strconv.ParseUint(eventParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event_id"})
+		return
+	}
+
+	var req models.MarkAttendanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.eventService.MarkAttendance(uint(eventID), &req)
+	if err != nil {
+		if err.Error() == "event not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "attendance updated",
+		"updated": updated,
+	})
 }
 
 // @Summary Get public events
-// @Description Retrieve a list of all public events
+// @Description Retrieve a page of public events, latest date first, optionally filtered by city or district
 // @Tags Events
 // @Produce json
-// @Success 200 {object} map[string]interface{} "List of public events"
+// @Param city_id query string false "Filter by city ID"
+// @Param district_id query string false "Filter by district ID"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.EventResponse] "List of public events"
+// @Failure 400 {object} map[string]string "Invalid city or district"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/v1/events/public [get]
 func (h *EventHandler) GetPublicEvents(c *gin.Context) {
-	events, err := h.eventService.GetPublicEvents()
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	events, err := h.eventService.GetPublicEvents(c.Query("city_id"), c.Query("district_id"), page)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidLocation) || errors.Is(err, pagination.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"events": events})
+	c.JSON(http.StatusOK, events)
 }
\ No newline at end of file
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type ExportHandler struct {
+	exportService *services.ExportService
+	validator     *validator.Validate
+}
+
+func NewExportHandler(exportService *services.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+		validator:     validator.New(),
+	}
+}
+
+// @Summary Export club data
+// @Description Download club members, event attendance, reading progress or post activity as CSV. Only club admins can access it.
+// @Tags Clubs
+// @Produce text/csv
+// @Param id path int true "Club ID"
+// @Param type query string true "Export type" Enums(members, attendance, reading, posts)
+// @Param format query string false "csv (comma separated) or excel (semicolon separated)" Enums(csv, excel) default(csv)
+// @Success 200 {file} file "CSV file"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Club not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/clubs/{id}/export [get]
+func (h *ExportHandler) ExportClub(c *gin.Context) {
+	clubID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid club ID"})
+		return
+	}
+
+	var req models.ClubExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters"})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrInvalidExportType.Error()})
+		return
+	}
+
+	export, err := h.exportService.ClubExport(uint(clubID), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrClubNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidExportType):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.Filename))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	// the status is already sent once rows start streaming, so failures can only be logged
+	if err := export.WriteCSV(c.Writer); err != nil {
+		logger.Error("club export failed",
+			zap.Uint64("club_id", clubID),
+			zap.String("type", req.Type),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+)
+
+type FeedHandler struct {
+	feedService *services.FeedService
+	viewService *services.ViewService
+	validator   *validator.Validate
+}
+
+func NewFeedHandler(feedService *services.FeedService, viewService *services.ViewService) *FeedHandler {
+	return &FeedHandler{
+		feedService: feedService,
+		viewService: viewService,
+		validator:   validator.New(),
+	}
+}
+
+// @Summary Get my home feed
+// @Description Posts from your clubs and the people you follow plus trending public posts, ranked by recency, engagement, your activity in the club and whether you have seen them. The ranking is cached for a few minutes and pages are cut from it; pass refresh=true on the first page to rebuild it.
+// @Tags Feed
+// @Produce json
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param refresh query bool false "Rebuild the ranking instead of reusing the cached one (first page only)"
+// @Param reveal_spoilers query bool false "Show posts past the viewer's reading progress instead of collapsing them"
+// @Success 200 {object} pagination.Page[models.FeedItem]
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/me/feed [get]
+func (h *FeedHandler) GetHomeFeed(c *gin.Context) {
+	viewerID, _ := optionalViewer(c)
+	if viewerID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req models.HomeFeedRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feed, err := h.feedService.HomeFeed(*viewerID, &req, time.Now())
+	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	shown := make([]services.ViewedPost, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		shown = append(shown, services.ViewedPost{ID: item.Post.ID, AuthorID: item.Post.UserID})
+	}
+	h.viewService.RecordImpressions(shown, viewerID, viewerKey(c, viewerID))
+
+	c.JSON(http.StatusOK, feed)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+)
+
+type FollowHandler struct {
+	followService *services.FollowService
+}
+
+func NewFollowHandler(followService *services.FollowService) *FollowHandler {
+	return &FollowHandler{
+		followService: followService,
+	}
+}
+
+// @Summary Follow a user
+// @Description Follow a user. Their posts in clubs you can read show up in your home feed.
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/follow [post]
+func (h *FollowHandler) FollowUser(c *gin.Context) {
+	followerID, followeeID, ok := parseUserTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.followService.FollowUser(followerID, followeeID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrCannotFollowSelf):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrFollowBlocked):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case err.Error() == "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user followed successfully"})
+}
+
+// @Summary Unfollow a user
+// @Description Stop following a user
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/follow [delete]
+func (h *FollowHandler) UnfollowUser(c *gin.Context) {
+	followerID, followeeID, ok := parseUserTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.followService.UnfollowUser(followerID, followeeID); err != nil {
+		if errors.Is(err, services.ErrFollowNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user unfollowed successfully"})
+}
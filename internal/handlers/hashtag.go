@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+)
+
+type HashtagHandler struct {
+	hashtagService *services.HashtagService
+	viewService    *services.ViewService
+	validator      *validator.Validate
+}
+
+func NewHashtagHandler(hashtagService *services.HashtagService, viewService *services.ViewService) *HashtagHandler {
+	return &HashtagHandler{
+		hashtagService: hashtagService,
+		viewService:    viewService,
+		validator:      validator.New(),
+	}
+}
+
+// @Summary List posts by hashtag
+// @Description List published posts using a hashtag, newest first. Tags match regardless of case (Turkish casing rules). Private clubs are only included for their members.
+// @Tags Hashtags
+// @Produce json
+// @Param tag path string true "Hashtag, with or without the leading #"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param reveal_spoilers query bool false "Show posts past the viewer's reading progress instead of collapsing them"
+// @Success 200 {object} models.HashtagPostsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/hashtags/{tag}/posts [get]
+func (h *HashtagHandler) ListPostsByHashtag(c *gin.Context) {
+	var req models.ListHashtagPostsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	viewerID, _ := optionalViewer(c)
+	posts, err := h.hashtagService.ListPostsByTag(c.Param("tag"), viewerID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidHashtag) || errors.Is(err, pagination.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	shown := make([]services.ViewedPost, 0, len(posts.Items))
+	for _, p := range posts.Items {
+		shown = append(shown, services.ViewedPost{ID: p.ID, AuthorID: p.UserID})
+	}
+	h.viewService.RecordImpressions(shown, viewerID, viewerKey(c, viewerID))
+
+	c.JSON(http.StatusOK, posts)
+}
+
+// @Summary List trending hashtags
+// @Description Rank hashtags used in public clubs by a time-decayed score over a sliding window
+// @Tags Hashtags
+// @Produce json
+// @Param limit query int false "Number of hashtags" default(10)
+// @Success 200 {object} models.TrendingHashtagsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/hashtags/trending [get]
+func (h *HashtagHandler) ListTrendingHashtags(c *gin.Context) {
+	var req models.TrendingHashtagsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trending, err := h.hashtagService.TrendingHashtags(&req, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, trending)
+}
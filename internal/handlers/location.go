@@ -13,13 +13,15 @@ import (
 
 type LocationHandler struct {
 	locationService *services.LocationService
+	backfillService *services.LocationBackfillService
 	locationCache   *cache.LocationCache
 	validator       *validator.Validate
 }
 
-func NewLocationHandler(locationService *services.LocationService, locationCache *cache.LocationCache) *LocationHandler {
+func NewLocationHandler(locationService *services.LocationService, backfillService *services.LocationBackfillService, locationCache *cache.LocationCache) *LocationHandler {
 	return &LocationHandler{
 		locationService: locationService,
+		backfillService: backfillService,
 		locationCache:   locationCache,
 		validator:       validator.New(),
 	}
@@ -71,4 +73,24 @@ func (h *LocationHandler) SearchLocations(c *gin.Context) {
 		"count":   len(results),
 		"query":   req.Query,
 	})
-}
\ No newline at end of file
+}
+
+// @Summary Backfill structured locations
+// @Description Parse the free-text location of users, clubs and events into city and district IDs (admin only)
+// @Tags Locations
+// @Produce json
+// @Success 200 {object} models.LocationBackfillResponse "Backfill results"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/locations/backfill [post]
+func (h *LocationHandler) BackfillLocations(c *gin.Context) {
+	result, err := h.backfillService.Run()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to backfill locations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
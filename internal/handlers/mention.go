@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+)
+
+type MentionHandler struct {
+	mentionService *services.MentionService
+	validator      *validator.Validate
+}
+
+func NewMentionHandler(mentionService *services.MentionService) *MentionHandler {
+	return &MentionHandler{
+		mentionService: mentionService,
+		validator:      validator.New(),
+	}
+}
+
+// @Summary List my mentions
+// @Description List the published posts and comments that mention the authenticated user, newest first
+// @Tags Mentions
+// @Produce json
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.MentionResponse]
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/me/mentions [get]
+func (h *MentionHandler) ListMyMentions(c *gin.Context) {
+	uidRaw, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, ok := uidRaw.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req models.ListMentionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mentions, err := h.mentionService.ListMentions(userID, &req)
+	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, mentions)
+}
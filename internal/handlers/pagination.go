@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+)
+
+// bindPage reads the cursor and limit query parameters of a list endpoint.
+// On bad input it writes the 400 response itself and returns false.
+func bindPage(c *gin.Context, v *validator.Validate) (pagination.Request, bool) {
+	var req pagination.Request
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+	if err := v.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+	return req, true
+}
+
+// invalidCursor answers 400 when a list call failed on the cursor the client
+// sent, and reports whether it did.
+func invalidCursor(c *gin.Context, err error) bool {
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return true
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -13,18 +14,20 @@ import (
 
 type PostHandler struct {
 	postService *services.PostService
+	viewService *services.ViewService
 	validator   *validator.Validate
 }
 
-func NewPostHandler(postService *services.PostService) *PostHandler {
+func NewPostHandler(postService *services.PostService, viewService *services.ViewService) *PostHandler {
 	return &PostHandler{
 		postService: postService,
+		viewService: viewService,
 		validator:   validator.New(),
 	}
 }
 
 // @Summary Create a new post
-// @Description Create a new post associated with the authenticated user
+// @Description Create a new post associated with the authenticated user. Posts of type post reshare the post named in shared_post_id, which is shown live in the reshare; posts in private clubs can only be reshared within their club.
 // @Tags Posts
 // @Accept json
 // @Produce json
@@ -32,6 +35,10 @@ func NewPostHandler(postService *services.PostService) *PostHandler {
 // @Success 201 {object} map[string]interface{} "Post created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Post to share is in a private club"
+// @Failure 404 {object} map[string]interface{} "Post to share not found"
+// @Failure 409 {object} map[string]interface{} "Book already reviewed"
+// @Failure 422 {object} map[string]interface{} "Rejected by the content filter"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /posts [post]
 func (h *PostHandler) CreatePost(c *gin.Context) {
@@ -60,6 +67,27 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 
 	post, err := h.postService.CreatePost(userID, &req)
 	if err != nil {
+		if errors.Is(err, services.ErrPublishAtRequired) || errors.Is(err, services.ErrInvalidPoll) || errors.Is(err, services.ErrInvalidAttachment) || errors.Is(err, services.ErrTooManyAttachments) ||
+			errors.Is(err, services.ErrSharedPostRequired) || errors.Is(err, services.ErrReshareType) || errors.Is(err, services.ErrInvalidReview) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrAlreadyReviewed) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrSharedPostNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrShareNotPublic) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrContentBlocked) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -76,6 +104,7 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Post ID"
+// @Param reveal_spoilers query bool false "Show posts past the viewer's reading progress instead of collapsing them"
 // @Success 200 {object} map[string]interface{} "Post retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 404 {object} map[string]interface{} "Post not found"
@@ -89,7 +118,8 @@ func (h *PostHandler) GetPostByID(c *gin.Context) {
 		return
 	}
 
-	post, err := h.postService.GetPostByID(uint(id))
+	viewerID, _ := optionalViewer(c)
+	post, err := h.postService.GetPostByID(uint(id), viewerID, revealSpoilers(c))
 	if err != nil {
 		if err.Error() == "post not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -98,6 +128,7 @@ func (h *PostHandler) GetPostByID(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.viewService.RecordView(services.ViewedPost{ID: post.ID, AuthorID: post.UserID}, viewerID, viewerKey(c, viewerID))
 
 	c.JSON(http.StatusOK, gin.H{"post": post})
 }
@@ -112,9 +143,23 @@ func (h *PostHandler) GetPostByID(c *gin.Context) {
 // @Success 200 {object} models.Post "Post updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 404 {object} map[string]interface{} "Post not found"
+// @Failure 409 {object} map[string]interface{} "Post hidden, awaiting review, or book already reviewed"
+// @Failure 422 {object} map[string]interface{} "Rejected by the content filter"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /posts/{id} [put]
 func (h *PostHandler) UpdatePost(c *gin.Context) {
+	uidRaw, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, ok := uidRaw.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
 	idParam := c.Param("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
@@ -133,12 +178,25 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 		return
 	}
 
-	post, err := h.postService.UpdatePost(uint(id), &req)
+	post, err := h.postService.UpdatePost(uint(id), userID, &req)
 	if err != nil {
 		if err.Error() == "post not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, services.ErrPublishAtRequired) || errors.Is(err, services.ErrInvalidAttachment) || errors.Is(err, services.ErrTooManyAttachments) || errors.Is(err, services.ErrReshareType) ||
+			errors.Is(err, services.ErrInvalidReview) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrContentBlocked) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrPostAlreadyPublished) || errors.Is(err, services.ErrPostHidden) || errors.Is(err, services.ErrPostHeld) || errors.Is(err, services.ErrAlreadyReviewed) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -181,12 +239,14 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 }
 
 // @Summary List posts by user ID
-// @Description Retrieve all posts created by a specific user
+// @Description Retrieve a page of posts created by a specific user, newest first
 // @Tags Posts
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
-// @Success 200 {array} models.Post "Posts retrieved successfully"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.PostResponse] "Posts retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /users/{id}/posts [get]
@@ -198,22 +258,92 @@ func (h *PostHandler) ListPostsByUserID(c *gin.Context) {
 		return
 	}
 
-	posts, err := h.postService.ListPostsByUserID(uint(userID))
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	posts, err := h.postService.ListPostsByUserID(uint(userID), page)
 	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"posts": posts})
+	c.JSON(http.StatusOK, posts)
+}
+
+// @Summary List my drafts
+// @Description Retrieve the authenticated user's draft posts, most recently edited first
+// @Tags Posts
+// @Produce json
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.PostResponse] "Drafts retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /me/posts/drafts [get]
+func (h *PostHandler) ListMyDrafts(c *gin.Context) {
+	h.listMyPostsByStatus(c, models.PostStatusDraft)
+}
+
+// @Summary List my scheduled posts
+// @Description Retrieve the authenticated user's scheduled posts, next to be published first
+// @Tags Posts
+// @Produce json
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.PostResponse] "Scheduled posts retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /me/posts/scheduled [get]
+func (h *PostHandler) ListMyScheduledPosts(c *gin.Context) {
+	h.listMyPostsByStatus(c, models.PostStatusScheduled)
+}
+
+func (h *PostHandler) listMyPostsByStatus(c *gin.Context, status string) {
+	useridRaw, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, ok := useridRaw.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	posts, err := h.postService.ListPostsByStatus(userID, status, page)
+	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, posts)
 }
 
 // @Summary List posts by club ID
-// @Description Retrieve all posts associated with a specific club
+// @Description Retrieve a page of posts in a specific club, newest first
 // @Tags Posts
 // @Accept json
 // @Produce json
 // @Param id path int true "Club ID"
-// @Success 200 {array} models.Post "Posts retrieved successfully"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.PostResponse] "Posts retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /clubs/{id}/posts [get]
@@ -225,31 +355,49 @@ func (h *PostHandler) ListPostsByClubID(c *gin.Context) {
 		return
 	}
 
-	posts, err := h.postService.ListPostsByClubID(uint(clubID))
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	posts, err := h.postService.ListPostsByClubID(uint(clubID), page)
 	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"posts": posts})
+	c.JSON(http.StatusOK, posts)
 }
 
 // @Summary List all posts
-// @Description Retrieve all posts in the system
+// @Description Retrieve a page of all published posts, newest first
 // @Tags Posts
 // @Accept json
 // @Produce json
-// @Success 200 {array} map[string]interface{} "Posts retrieved successfully"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.PostResponse] "Posts retrieved successfully"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /posts [get]
 func (h *PostHandler) ListAllPosts(c *gin.Context) {
-	posts, err := h.postService.ListAllPosts()
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	posts, err := h.postService.ListAllPosts(page)
 	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"posts": posts})
+	c.JSON(http.StatusOK, posts)
 }
 
 // @Summary List post summaries
@@ -259,8 +407,12 @@ func (h *PostHandler) ListAllPosts(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Club ID"
 // @Param limit query int false "Number of posts to retrieve" default(20)
-// @Param offset query int false "Number of posts to skip" default(0)
-// @Success 200 {array} models.PostSummary "Post summaries retrieved successfully"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "hot, top or new" default(new)
+// @Param window query string false "Time window for sort=top: day, week, month or all" default(all)
+// @Param reveal_spoilers query bool false "Show posts past the viewer's reading progress instead of collapsing them"
+// @Success 200 {object} pagination.Page[models.PostSummary] "Post summaries retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /clubs/{id}/posts/summaries [get]
 func (h *PostHandler) ListPostSummaries(c *gin.Context) {
@@ -283,40 +435,61 @@ func (h *PostHandler) ListPostSummaries(c *gin.Context) {
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "20")
-	offsetStr := c.DefaultQuery("offset", "0")
-	limit, _ := strconv.Atoi(limitStr)
-	offset, _ := strconv.Atoi(offsetStr)
+	req, ok := bindPostList(c, h.validator)
+	if !ok {
+		return
+	}
 
-	posts, err := h.postService.ListPostSummaries(uint(clubID), &userID, limit, offset)
+	posts, err := h.postService.ListPostSummaries(uint(clubID), &userID, &req, revealSpoilers(c))
 	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	shown := make([]services.ViewedPost, 0, len(posts.Items))
+	for _, p := range posts.Items {
+		shown = append(shown, services.ViewedPost{ID: p.ID, AuthorID: p.UserID})
+	}
+	h.viewService.RecordImpressions(shown, &userID, viewerKey(c, &userID))
 
-	c.JSON(http.StatusOK, gin.H{"posts": posts})
+	c.JSON(http.StatusOK, posts)
 }
 
 // @Summary List public posts
-// @Description Retrieve all posts from public clubs
+// @Description Retrieve a page of posts from public clubs, hottest first by default
 // @Tags Posts
 // @Accept json
 // @Produce json
-// @Success 200 {array} models.Post "Public posts retrieved successfully"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "hot, top or new" default(hot)
+// @Param window query string false "Time window for sort=top: day, week, month or all" default(all)
+// @Success 200 {object} pagination.Page[models.PostResponse] "Public posts retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /posts/public [get]
 func (h *PostHandler) ListPublicPosts(c *gin.Context) {
-	posts, err := h.postService.ListPublicPosts()
+	req, ok := bindPostList(c, h.validator)
+	if !ok {
+		return
+	}
+
+	posts, err := h.postService.ListPublicPosts(&req)
 	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"posts": posts})
+	c.JSON(http.StatusOK, posts)
 }
 
 // @Summary List popular public posts
-// @Description Retrieve popular posts from public clubs based on number of likes
+// @Description Retrieve the hottest posts from public clubs, where engagement counts for less as posts age
 // @Tags Posts
 // @Accept json
 // @Produce json
@@ -428,14 +601,16 @@ func (h *PostHandler) UnlikePost(c *gin.Context) {
 }
 
 // @Summary List likes by post ID
-// @Description Retrieve all likes associated with a specific post
+// @Description Retrieve a page of likes on a specific post, oldest first
 // @Tags Posts
 // @Accept json
 // @Produce json
 // @Param id path int true "Post ID"
-// @Success 200 {array} models.PostLikeResponse "Likes retrieved successfully"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.PostLikeResponse] "Likes retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Bad request"
-// @Failure 404 {object} map[string]interface{} "Post not found or no likes found"
+// @Failure 404 {object} map[string]interface{} "Post not found"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /posts/{id}/likes [get]
 func (h *PostHandler) ListLikesByPostID(c *gin.Context) {
@@ -446,13 +621,17 @@ func (h *PostHandler) ListLikesByPostID(c *gin.Context) {
 		return
 	}
 
-	likes, err := h.postService.ListLikesByPostID(uint(postID))
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	likes, err := h.postService.ListLikesByPostID(uint(postID), page)
 	if err != nil {
-		if err.Error() == "post not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		if invalidCursor(c, err) {
 			return
 		}
-		if err.Error() == "no likes found for this post" {
+		if err.Error() == "post not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
@@ -460,11 +639,11 @@ func (h *PostHandler) ListLikesByPostID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"likes": likes})
+	c.JSON(http.StatusOK, likes)
 }
 
 // @Summary Vote on a poll
-// @Description Vote on a poll post
+// @Description Vote on a poll post. On ranked-choice polls option_ids is the ballot in order of preference and replaces the voter's earlier ballot.
 // @Tags Posts
 // @Accept json
 // @Produce json
@@ -474,6 +653,7 @@ func (h *PostHandler) ListLikesByPostID(c *gin.Context) {
 // @Failure 400 {object} models.ErrorResponse "Bad request"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 404 {object} models.ErrorResponse "Post not found"
+// @Failure 409 {object} models.ErrorResponse "Poll is closed"
 // @Router /posts/{id}/vote [post]
 func (h *PostHandler) VoteOnPoll(c *gin.Context) {
     postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -491,6 +671,9 @@ func (h *PostHandler) VoteOnPoll(c *gin.Context) {
     }
 
     if err := h.postService.VoteOnPoll(uint(postID), userID.(uint), &req); err != nil {
+        if pollError(c, err) {
+            return
+        }
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
@@ -499,11 +682,14 @@ func (h *PostHandler) VoteOnPoll(c *gin.Context) {
 }
 
 // @Summary Get reviews by book
-// @Description Get all review posts for a specific book
+// @Description Get a page of review posts for a specific book, newest first
 // @Tags Posts
 // @Produce json
 // @Param book_id query int true "Book ID"
-// @Success 200 {array} models.PostResponse "Reviews retrieved successfully"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param reveal_spoilers query bool false "Show reviews of books the viewer has not finished instead of collapsing them"
+// @Success 200 {object} pagination.Page[models.PostResponse] "Reviews retrieved successfully"
 // @Router /posts/reviews [get]
 func (h *PostHandler) GetReviewsByBook(c *gin.Context) {
     bookID, err := strconv.ParseUint(c.Query("book_id"), 10, 32)
@@ -512,13 +698,22 @@ func (h *PostHandler) GetReviewsByBook(c *gin.Context) {
         return
     }
 
-    reviews, err := h.postService.GetReviewsByBook(uint(bookID))
+    page, ok := bindPage(c, h.validator)
+    if !ok {
+        return
+    }
+
+    viewerID, _ := optionalViewer(c)
+    reviews, err := h.postService.GetReviewsByBook(uint(bookID), viewerID, page, revealSpoilers(c))
     if err != nil {
+        if invalidCursor(c, err) {
+            return
+        }
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"reviews": reviews})
+    c.JSON(http.StatusOK, reviews)
 }
 
 // @Summary Get posts by type
@@ -526,9 +721,10 @@ func (h *PostHandler) GetReviewsByBook(c *gin.Context) {
 // @Tags Posts
 // @Produce json
 // @Param type query string true "Post type (e.g., 'announcement', 'discussion', 'poll', 'review')"
-// @Param limit query int true "Number of posts to return"
-// @Param offset query int true "Number of posts to skip"
-// @Success 200 {array} models.PostResponse "Posts retrieved successfully"
+// @Param limit query int false "Number of posts to return" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param reveal_spoilers query bool false "Show posts past the viewer's reading progress instead of collapsing them"
+// @Success 200 {object} pagination.Page[models.PostResponse] "Posts retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /posts/by-type [get]
@@ -538,45 +734,34 @@ func (h *PostHandler) GetPostsByType(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "type is required"})
 		return
 	}
-	limitStr := c.Query("limit")
-	if limitStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "limit is required"})
-		return
-	}
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be an integer"})
-		return
-	}
-
-	offsetStr := c.Query("offset")
-	if offsetStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "offset is required"})
-		return
-	}
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be an integer"})
+	page, ok := bindPage(c, h.validator)
+	if !ok {
 		return
 	}
 
-	posts, err := h.postService.GetPostsByType(postType, limit, offset)
+	viewerID, _ := optionalViewer(c)
+	posts, err := h.postService.GetPostsByType(postType, page, viewerID, revealSpoilers(c))
 	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"posts": posts})
+	c.JSON(http.StatusOK, posts)
 }
 
 // @Summary Get poll posts by club ID
-// @Description Retrieve all poll posts associated with a specific club
+// @Description Retrieve a page of poll posts in a specific club, newest first. Each post's poll field holds the poll state and the results the caller may see.
 // @Tags Posts
 // @Accept json
 // @Produce json
 // @Param id path int true "Club ID"
-// @Param include_expired query bool false "Include expired polls"
-// @Success 200 {array} models.Post "Poll posts retrieved successfully"
+// @Param include_expired query bool false "Include expired and closed polls"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.PostResponse] "Poll posts retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /clubs/{id}/posts/polls [get]
@@ -588,14 +773,26 @@ func (h *PostHandler) GetPollPostsByClubID(c *gin.Context) {
 		return
 	}
 	includeExpired := c.Query("include_expired") == "true"
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
 
-	posts, err := h.postService.GetPollPostsByClubID(uint(clubID), includeExpired)
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	posts, err := h.postService.GetPollPostsByClubID(uint(clubID), userID, includeExpired, page)
 	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"posts": posts})
+	c.JSON(http.StatusOK, posts)
 }
 
 // @Summary Get user poll votes
@@ -680,9 +877,136 @@ func (h *PostHandler) RemoveVoteFromPoll(c *gin.Context) {
 	}
 
 	if err := h.postService.RemoveVoteFromPoll(uint(postID), userID, req.OptionIDs[0]); err != nil {
+		if pollError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "vote removed successfully"})
-}
\ No newline at end of file
+}
+
+// @Summary Close a poll
+// @Description End voting on a poll before it expires and save its final results. The poll's author, the club's owner and moderators, and platform moderators can close it.
+// @Tags Posts
+// @Produce json
+// @Param id path int true "Post ID"
+// @Success 200 {object} models.PollView
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the author or a moderator"
+// @Failure 404 {object} map[string]interface{} "Post not found"
+// @Failure 409 {object} map[string]interface{} "Poll is already closed"
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/{id}/poll/close [post]
+func (h *PostHandler) ClosePoll(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		return
+	}
+	userID, role, ok := currentModerator(c)
+	if !ok {
+		return
+	}
+
+	poll, err := h.postService.ClosePoll(uint(postID), userID, role)
+	if err != nil {
+		if pollError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, poll)
+}
+
+// @Summary List a poll option's voters
+// @Description List who chose an option, in the order they voted. Anonymous polls never list their voters, and polls hiding their results only list them once the caller can see the results. On ranked-choice polls each voter's rank for the option is included.
+// @Tags Posts
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param option_id query string true "Option ID"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.PollVoter]
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Voters or results are hidden"
+// @Failure 404 {object} map[string]interface{} "Post not found"
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/{id}/poll/voters [get]
+func (h *PostHandler) ListPollVoters(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ListPollVotersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	voters, err := h.postService.ListPollVoters(uint(postID), userID, &req)
+	if err != nil {
+		if invalidCursor(c, err) || pollError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, voters)
+}
+
+// pollError writes the response for the poll errors that are not bad
+// requests and reports whether err was one of them.
+func pollError(c *gin.Context, err error) bool {
+	switch {
+	case err.Error() == "post not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPollClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotPollManager), errors.Is(err, services.ErrPollVotersHidden), errors.Is(err, services.ErrPollResultsHidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotPoll):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
+
+// revealSpoilers reports whether the caller asked to see spoilers expanded.
+func revealSpoilers(c *gin.Context) bool {
+	reveal, _ := strconv.ParseBool(c.Query("reveal_spoilers"))
+	return reveal
+}
+
+// bindPostList binds a page of a post list along with its sort and window.
+func bindPostList(c *gin.Context, v *validator.Validate) (models.PostListRequest, bool) {
+	var req models.PostListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+	if err := v.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+	return req, true
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+)
+
+type ReactionHandler struct {
+	reactionService *services.ReactionService
+	validator       *validator.Validate
+}
+
+func NewReactionHandler(reactionService *services.ReactionService) *ReactionHandler {
+	return &ReactionHandler{
+		reactionService: reactionService,
+		validator:       validator.New(),
+	}
+}
+
+// @Summary List available reactions
+// @Description Get the emojis that can be used as reactions
+// @Tags Reactions
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/reactions [get]
+func (h *ReactionHandler) ListAvailableReactions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"reactions": h.reactionService.AllowedReactions()})
+}
+
+// @Summary React to a post
+// @Description Add a reaction to a post. A user can add several different reactions to the same post.
+// @Tags Reactions
+// @Accept json
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param reaction body models.ReactionRequest true "Reaction"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/posts/{id}/reactions [post]
+func (h *ReactionHandler) ReactToPost(c *gin.Context) {
+	h.react(c, models.ReactionTargetPost)
+}
+
+// @Summary Remove a reaction from a post
+// @Description Remove one of the current user's reactions from a post
+// @Tags Reactions
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param emoji query string true "Reaction emoji"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/posts/{id}/reactions [delete]
+func (h *ReactionHandler) UnreactToPost(c *gin.Context) {
+	h.unreact(c, models.ReactionTargetPost)
+}
+
+// @Summary List reactions on a post
+// @Description List who reacted to a post, optionally filtered by emoji
+// @Tags Reactions
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param emoji query string false "Reaction emoji"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.ReactionResponse]
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/posts/{id}/reactions [get]
+func (h *ReactionHandler) ListPostReactions(c *gin.Context) {
+	h.list(c, models.ReactionTargetPost)
+}
+
+// @Summary React to a comment
+// @Description Add a reaction to a comment. A user can add several different reactions to the same comment.
+// @Tags Reactions
+// @Accept json
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Param reaction body models.ReactionRequest true "Reaction"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/comments/{id}/reactions [post]
+func (h *ReactionHandler) ReactToComment(c *gin.Context) {
+	h.react(c, models.ReactionTargetComment)
+}
+
+// @Summary Remove a reaction from a comment
+// @Description Remove one of the current user's reactions from a comment
+// @Tags Reactions
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Param emoji query string true "Reaction emoji"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/comments/{id}/reactions [delete]
+func (h *ReactionHandler) UnreactToComment(c *gin.Context) {
+	h.unreact(c, models.ReactionTargetComment)
+}
+
+// @Summary List reactions on a comment
+// @Description List who reacted to a comment, optionally filtered by emoji
+// @Tags Reactions
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Param emoji query string false "Reaction emoji"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.ReactionResponse]
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/comments/{id}/reactions [get]
+func (h *ReactionHandler) ListCommentReactions(c *gin.Context) {
+	h.list(c, models.ReactionTargetComment)
+}
+
+func (h *ReactionHandler) react(c *gin.Context, targetType string) {
+	uidRaw, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, ok := uidRaw.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + targetType + " ID"})
+		return
+	}
+
+	var req models.ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	summaries, err := h.reactionService.React(userID, targetType, uint(targetID), req.Emoji)
+	if err != nil {
+		h.writeError(c, targetType, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "reaction added successfully",
+		"reactions": summaries,
+	})
+}
+
+func (h *ReactionHandler) unreact(c *gin.Context, targetType string) {
+	uidRaw, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, ok := uidRaw.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + targetType + " ID"})
+		return
+	}
+
+	var req models.ReactionRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	summaries, err := h.reactionService.Unreact(userID, targetType, uint(targetID), req.Emoji)
+	if err != nil {
+		h.writeError(c, targetType, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "reaction removed successfully",
+		"reactions": summaries,
+	})
+}
+
+func (h *ReactionHandler) list(c *gin.Context, targetType string) {
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + targetType + " ID"})
+		return
+	}
+
+	var req models.ListReactionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reactions, err := h.reactionService.ListReactions(targetType, uint(targetID), &req)
+	if err != nil {
+		h.writeError(c, targetType, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reactions)
+}
+
+func (h *ReactionHandler) writeError(c *gin.Context, targetType string, err error) {
+	switch {
+	case errors.Is(err, services.ErrReactionTargetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": targetType + " not found"})
+	case errors.Is(err, services.ErrReactionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReactionNotAllowed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "allowed": h.reactionService.AllowedReactions()})
+	case errors.Is(err, services.ErrAlreadyReacted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, pagination.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
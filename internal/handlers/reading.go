@@ -138,17 +138,23 @@ func (h *ReadingHandler) CompleteReading(c *gin.Context) {
 }
 
 // @Summary List User Reading Progress
-// @Description List all reading progress entries for a user.
+// @Description List the reading progress entries of a user, most recently updated first.
 // @Tags Reading
 // @Produce json
 // @Param id path int true "User ID"
-// @Success 200 {array} models.UserBookProgressResponse
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} pagination.Page[models.UserBookProgressResponse]
 // @Failure 400 {object} models.ErrorResponse
 // @Router /users/{id}/reading [get]
 // @Security Bearer
 func (h *ReadingHandler) ListUserProgress(c *gin.Context) {
 	userID, _ := strconv.ParseUint(c.Param("id"), 10, 64)
-	resp, err := h.readingService.ListUserProgress(uint(userID))
+	req, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+	resp, err := h.readingService.ListUserProgress(uint(userID), req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -157,17 +163,23 @@ func (h *ReadingHandler) ListUserProgress(c *gin.Context) {
 }
 
 // @Summary Get User Reading History
-// @Description Retrieve the reading history of a user.
+// @Description Retrieve the reading history of a user, latest finished first.
 // @Tags Reading
 // @Produce json
 // @Param id path int true "User ID"
-// @Success 200 {array} models.UserReadingHistoryItem
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} pagination.Page[models.UserReadingHistoryItem]
 // @Failure 400 {object} models.ErrorResponse
 // @Router /users/{id}/reading/history [get]
 // @Security Bearer
 func (h *ReadingHandler) UserReadingHistory(c *gin.Context) {
 	userID, _ := strconv.ParseUint(c.Param("id"), 10, 64)
-	resp, err := h.readingService.UserReadingHistory(uint(userID))
+	req, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+	resp, err := h.readingService.UserReadingHistory(uint(userID), req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -227,17 +239,23 @@ func (h *ReadingHandler) CompleteClubAssignment(c *gin.Context) {
 }
 
 // @Summary List Club Book Assignments
-// @Description List all book assignments for a club.
+// @Description List the book assignments of a club, newest first.
 // @Tags Reading
 // @Produce json
 // @Param id path int true "Club ID"
-// @Success 200 {array} models.ClubAssignmentResponse
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} pagination.Page[models.ClubAssignmentResponse]
 // @Failure 400 {object} models.ErrorResponse
 // @Router /clubs/{id}/reading [get]
 // @Security Bearer
 func (h *ReadingHandler) ListClubAssignments(c *gin.Context) {
 	clubID, _ := strconv.ParseUint(c.Param("id"), 10, 64)
-	resp, err := h.readingService.ListClubAssignments(uint(clubID))
+	req, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+	resp, err := h.readingService.ListClubAssignments(uint(clubID), req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
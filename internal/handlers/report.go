@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+)
+
+type ReportHandler struct {
+	reportService *services.ReportService
+	validator     *validator.Validate
+}
+
+func NewReportHandler(reportService *services.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+		validator:     validator.New(),
+	}
+}
+
+// @Summary Report content or a user
+// @Description Report a post, comment, club rating or user to the moderators. Each target can be reported once per user; you are notified when a moderator decides the report.
+// @Tags Moderation
+// @Accept json
+// @Produce json
+// @Param report body models.CreateReportRequest true "What to report and why"
+// @Success 201 {object} models.Report
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/reports [post]
+func (h *ReportHandler) CreateReport(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.reportService.CreateReport(userID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrReportTargetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": req.TargetType + " not found"})
+			return
+		}
+		writeReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// @Summary Report a club rating
+// @Description Report an abusive club rating. Kept for older clients; it files the same report as POST /reports with reason "other".
+// @Tags Clubs
+// @Accept json
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param rating_id path int true "Rating ID"
+// @Param request body models.ReportClubRatingRequest true "Report reason"
+// @Success 201 {object} map[string]string "Rating reported"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Rating not found"
+// @Failure 409 {object} map[string]string "Already reported"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/clubs/{id}/ratings/{rating_id}/report [post]
+func (h *ReportHandler) ReportClubRating(c *gin.Context) {
+	clubID64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid club ID"})
+		return
+	}
+	ratingID64, err := strconv.ParseUint(c.Param("rating_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rating ID"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ReportClubRatingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.reportService.ReportClubRating(uint(clubID64), uint(ratingID64), userID, &req); err != nil {
+		if errors.Is(err, services.ErrRatingNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		writeReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "rating reported successfully"})
+}
+
+// @Summary List the moderation queue
+// @Description List the reports you moderate: those about your clubs' content for club moderators and owners, every report for platform moderators and support. Open reports come oldest first; settled ones newest first.
+// @Tags Moderation
+// @Produce json
+// @Param status query string false "Report status" Enums(open, resolved, dismissed) default(open)
+// @Param target_type query string false "Only reports about this kind of target" Enums(post, comment, rating, user)
+// @Param club_id query int false "Only reports about this club's content"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.Report]
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/moderation/reports [get]
+func (h *ReportHandler) ListReports(c *gin.Context) {
+	userID, role, ok := currentModerator(c)
+	if !ok {
+		return
+	}
+
+	var req models.ListReportsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reports, err := h.reportService.ListReports(userID, role, &req)
+	if err != nil {
+		writeReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// @Summary Get a report
+// @Tags Moderation
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} models.Report
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/moderation/reports/{id} [get]
+func (h *ReportHandler) GetReport(c *gin.Context) {
+	userID, role, ok := currentModerator(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	report, err := h.reportService.GetReport(userID, role, uint(id))
+	if err != nil {
+		writeReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary Decide a report
+// @Description Dismiss a report, hide or delete the reported content, or warn or suspend its author or the reported user. The decision settles every open report on the same target, is logged, and the reporters are notified of the outcome. Only platform moderators can suspend.
+// @Tags Moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "Report ID"
+// @Param decision body models.ResolveReportRequest true "Decision"
+// @Success 200 {object} models.Report
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/moderation/reports/{id}/resolve [post]
+func (h *ReportHandler) ResolveReport(c *gin.Context) {
+	userID, role, ok := currentModerator(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	var req models.ResolveReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.reportService.ResolveReport(userID, role, uint(id), &req)
+	if err != nil {
+		writeReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary List moderation decisions
+// @Description List the decisions taken on the reports you moderate, newest first
+// @Tags Moderation
+// @Produce json
+// @Param club_id query int false "Only decisions about this club's content"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.ModerationLog]
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/moderation/log [get]
+func (h *ReportHandler) ListModerationLog(c *gin.Context) {
+	userID, role, ok := currentModerator(c)
+	if !ok {
+		return
+	}
+
+	var req models.ListModerationLogRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	logs, err := h.reportService.ListModerationLog(userID, role, &req)
+	if err != nil {
+		writeReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, logs)
+}
+
+func writeReportError(c *gin.Context, err error) {
+	switch {
+	case invalidCursor(c, err):
+	case errors.Is(err, services.ErrReportNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadyReported), errors.Is(err, services.ErrReportAlreadySettled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotModerator), errors.Is(err, services.ErrSuspendNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCannotReportSelf), errors.Is(err, services.ErrInvalidModerationStep):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// currentModerator returns the caller's ID and platform role. On failure it
+// writes the error response itself and returns false.
+func currentModerator(c *gin.Context) (uint, string, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return 0, "", false
+	}
+	role, _ := c.Get("user_role")
+	roleStr, _ := role.(string)
+	return userID, roleStr, true
+}
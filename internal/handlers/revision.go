@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+)
+
+type RevisionHandler struct {
+	revisionService *services.RevisionService
+}
+
+func NewRevisionHandler(revisionService *services.RevisionService) *RevisionHandler {
+	return &RevisionHandler{
+		revisionService: revisionService,
+	}
+}
+
+// @Summary Get post edit history
+// @Description List every version of a post with a line diff against the previous version. Who can see it depends on the club's revision_visibility.
+// @Tags Posts
+// @Produce json
+// @Param id path int true "Post ID"
+// @Success 200 {object} models.RevisionHistoryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/posts/{id}/revisions [get]
+func (h *RevisionHandler) GetPostRevisions(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		return
+	}
+
+	viewerID, viewerRole := optionalViewer(c)
+	history, err := h.revisionService.GetPostRevisions(uint(postID), viewerID, viewerRole)
+	if err != nil {
+		writeRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// @Summary Get comment edit history
+// @Description List every version of a comment with a line diff against the previous version. Who can see it depends on the club's revision_visibility.
+// @Tags Comments
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Success 200 {object} models.RevisionHistoryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/comments/{id}/revisions [get]
+func (h *RevisionHandler) GetCommentRevisions(c *gin.Context) {
+	commentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	viewerID, viewerRole := optionalViewer(c)
+	history, err := h.revisionService.GetCommentRevisions(uint(commentID), viewerID, viewerRole)
+	if err != nil {
+		writeRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// optionalViewer reads the user set by OptionalAuthMiddleware, if any.
+func optionalViewer(c *gin.Context) (*uint, string) {
+	var viewerID *uint
+	if raw, ok := c.Get("user_id"); ok {
+		if id, ok := raw.(uint); ok {
+			viewerID = &id
+		}
+	}
+	role, _ := c.Get("user_role")
+	roleStr, _ := role.(string)
+	return viewerID, roleStr
+}
+
+func writeRevisionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRevisionsHidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrClubNotFound), err.Error() == "post not found", err.Error() == "comment not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
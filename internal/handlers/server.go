@@ -10,6 +10,7 @@ import (
 	"github.com/nevzattalhaozcan/forgotten/pkg/cache"
 	searchCache "github.com/nevzattalhaozcan/forgotten/internal/cache"
 	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"github.com/nevzattalhaozcan/forgotten/pkg/storage"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	swaggerFiles "github.com/swaggo/files"
@@ -22,6 +23,7 @@ import (
 	"github.com/nevzattalhaozcan/forgotten/internal/middleware"
 	"github.com/nevzattalhaozcan/forgotten/internal/repository"
 	"github.com/nevzattalhaozcan/forgotten/internal/services"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -29,6 +31,11 @@ type Server struct {
 	db     *gorm.DB
 	config *config.Config
 	router *gin.Engine
+
+	postScheduler *services.PostScheduler
+	viewFlusher   *services.ViewFlusher
+	hotRefresher  *services.HotScoreRefresher
+	attachmentCleaner *services.AttachmentCleaner
 }
 
 func NewServer(db *gorm.DB, config *config.Config) *Server {
@@ -68,6 +75,24 @@ func (s *Server) setupRoutes() {
 	var readingRepo repository.ReadingRepository = repository.NewReadingRepository(s.db)
 	var clubReadingRepo repository.ClubReadingRepository = repository.NewClubReadingRepository(s.db)
 	var clubRatingRepo repository.ClubRatingRepository = repository.NewClubRatingRepository(s.db)
+	var analyticsRepo repository.AnalyticsRepository = repository.NewAnalyticsRepository(s.db)
+	var reactionRepo repository.ReactionRepository = repository.NewReactionRepository(s.db)
+	var revisionRepo repository.RevisionRepository = repository.NewRevisionRepository(s.db)
+	var tagRepo repository.TagRepository = repository.NewTagRepository(s.db)
+	var locationRepo repository.LocationRepository = repository.NewLocationRepository(s.db)
+	var contentRepo repository.ContentRepository = repository.NewContentRepository(s.db)
+	var mentionRepo repository.MentionRepository = repository.NewMentionRepository(s.db)
+	var blockRepo repository.BlockRepository = repository.NewBlockRepository(s.db)
+	var hashtagRepo repository.HashtagRepository = repository.NewHashtagRepository(s.db)
+	var viewRepo repository.ViewRepository = repository.NewViewRepository(s.db)
+	var followRepo repository.FollowRepository = repository.NewFollowRepository(s.db)
+	var feedRepo repository.FeedRepository = repository.NewFeedRepository(s.db)
+	var bookmarkRepo repository.BookmarkRepository = repository.NewBookmarkRepository(s.db)
+	var reportRepo repository.ReportRepository = repository.NewReportRepository(s.db)
+	var notificationRepo repository.NotificationRepository = repository.NewNotificationRepository(s.db)
+	var contentFilterRepo repository.ContentFilterRepository = repository.NewContentFilterRepository(s.db)
+	var attachmentRepo repository.AttachmentRepository = repository.NewAttachmentRepository(s.db)
+	var reshareRepo repository.ReshareRepository = repository.NewReshareRepository(s.db)
 
 	var rdbAvailable bool
 	var ttl time.Duration
@@ -89,38 +114,126 @@ func (s *Server) setupRoutes() {
 		logger.Info("User repository caching enabled")
 	}
 
-	userService := services.NewUserService(userRepo, s.config)
+	locationService, err := services.NewLocationService()
+    if err != nil {
+        log.Fatal("Failed to initialize location service:", err)
+    }
+
+	userService := services.NewUserService(userRepo, locationService, s.config)
 	userHandler := NewUserHandler(userService)
 
-	clubService := services.NewClubService(clubRepo, clubRatingRepo, s.config)
+	clubService := services.NewClubService(clubRepo, clubRatingRepo, tagRepo, locationService, s.config)
 	clubHandler := NewClubHandler(clubService)
 
-	eventService := services.NewEventService(eventRepo, clubRepo, s.config)
+	eventService := services.NewEventService(eventRepo, clubRepo, locationService, s.config)
 	eventHandler := NewEventHandler(eventService)
 
-	bookService := services.NewBookService(bookRepo, bookClient, s.config)
+	bookService := services.NewBookService(bookRepo, readingRepo, bookClient, s.config)
 	bookHandler := NewBookHandler(bookService)
 
-	postService := services.NewPostService(postRepo, userRepo, clubRepo, bookRepo, s.db, s.config)
-	postHandler := NewPostHandler(postService)
+	mentionService := services.NewMentionService(mentionRepo, blockRepo, userRepo, clubRepo)
+	mentionHandler := NewMentionHandler(mentionService)
+
+	spoilerService := services.NewSpoilerService(readingRepo, userRepo)
+	reshareService := services.NewReshareService(reshareRepo, spoilerService)
+
+	viewWindow := time.Duration(s.config.Views.DedupWindowMinutes) * time.Minute
+	var viewCounter services.ViewCounter = services.NewMemoryViewCounter(viewWindow)
+	if rdbAvailable {
+		viewCounter = services.NewRedisViewCounter(rdb, viewWindow)
+	}
+	viewService := services.NewViewService(viewCounter, viewRepo, postRepo, clubRepo)
+	hotRankService := services.NewHotRankService(postRepo, services.NewHotScorer(s.config.Hot), s.config)
+	viewService.AddEngagementListener(hotRankService)
+	s.hotRefresher = services.NewHotScoreRefresher(hotRankService, time.Duration(s.config.Hot.RefreshIntervalSeconds)*time.Second)
+	viewHandler := NewViewHandler(viewService)
+	s.viewFlusher = services.NewViewFlusher(viewService, time.Duration(s.config.Views.FlushIntervalSeconds)*time.Second)
+
+	hashtagService := services.NewHashtagService(hashtagRepo, spoilerService, reshareService, s.config)
+	hashtagHandler := NewHashtagHandler(hashtagService, viewService)
 
-	commentService := services.NewCommentService(commentRepo, postRepo, userRepo, s.config)
+	blockService := services.NewBlockService(blockRepo, userRepo)
+	blockHandler := NewBlockHandler(blockService)
+
+	followService := services.NewFollowService(followRepo, blockRepo, userRepo)
+	followHandler := NewFollowHandler(followService)
+
+	var feedStore services.FeedStore = services.NewMemoryFeedStore()
+	if rdbAvailable {
+		feedStore = services.NewRedisFeedStore(rdb, time.Duration(s.config.Feed.WindowHours)*time.Hour)
+	}
+	feedService := services.NewFeedService(feedRepo, followRepo, feedStore, services.NewWeightedFeedScorer(s.config.Feed), spoilerService, reshareService, s.config)
+	if !rdbAvailable {
+		feedService.DisableFanout()
+	}
+	feedHandler := NewFeedHandler(feedService, viewService)
+
+	bookmarkService := services.NewBookmarkService(bookmarkRepo, spoilerService, reshareService)
+	bookmarkHandler := NewBookmarkHandler(bookmarkService)
+
+	contentFilter := services.NewContentFilter(contentFilterRepo, reportRepo, userRepo, s.config)
+	contentFilterHandler := NewContentFilterHandler(contentFilter)
+
+	fileStorage, err := storage.New(&s.config.Attachments)
+	if err != nil {
+		log.Fatal("Failed to initialize attachment storage:", err)
+	}
+	if local, ok := fileStorage.(*storage.Local); ok && local.ServedAt() != "" {
+		s.router.Static(local.ServedAt(), local.Dir())
+	}
+	attachmentService := services.NewAttachmentService(attachmentRepo, fileStorage, s.config)
+	attachmentHandler := NewAttachmentHandler(attachmentService)
+	s.attachmentCleaner = services.NewAttachmentCleaner(attachmentService, time.Duration(s.config.Attachments.CleanupIntervalSeconds)*time.Second)
+
+	postService := services.NewPostService(postRepo, userRepo, clubRepo, bookRepo, mentionService, hashtagService, spoilerService, contentFilter, attachmentService, reshareService, s.db, s.config)
+	postService.AddPublishListener(feedService)
+	postService.AddEngagementListener(hotRankService)
+	postHandler := NewPostHandler(postService, viewService)
+	s.postScheduler = services.NewPostScheduler(postService, time.Duration(s.config.Posts.SchedulerIntervalSeconds)*time.Second)
+
+	commentService := services.NewCommentService(commentRepo, postRepo, userRepo, mentionService, contentFilter, attachmentService, s.config)
+	commentService.AddEngagementListener(hotRankService)
 	commentHandler := NewCommentHandler(commentService)
 
+	reactionService := services.NewReactionService(reactionRepo, postRepo, commentRepo, s.config)
+	reactionService.AddEngagementListener(hotRankService)
+	reactionHandler := NewReactionHandler(reactionService)
+
+	notificationService := services.NewNotificationService(notificationRepo)
+	postService.AddPublishListener(services.NewScheduledPostNotifier(notificationService, feedRepo))
+	notificationHandler := NewNotificationHandler(notificationService)
+
+	reportService := services.NewReportService(reportRepo, postRepo, commentRepo, clubRatingRepo, userRepo, postService, commentService, clubService, notificationService)
+	reportHandler := NewReportHandler(reportService)
+
+	revisionService := services.NewRevisionService(revisionRepo, postRepo, commentRepo, clubRepo, s.config)
+	revisionHandler := NewRevisionHandler(revisionService)
+
 	readingService := services.NewReadingService(s.config, userRepo, bookRepo, clubRepo, readingRepo, clubReadingRepo)
 	readingHandler := NewReadingHandler(readingService)
 
-	locationService, err := services.NewLocationService()
-    if err != nil {
-        log.Fatal("Failed to initialize location service:", err)
-    }
+	analyticsService := services.NewAnalyticsService(analyticsRepo, clubRepo, rdb, s.config)
+	analyticsHandler := NewAnalyticsHandler(analyticsService)
+
+	exportService := services.NewExportService(clubRepo, eventRepo, clubReadingRepo, s.config)
+	exportHandler := NewExportHandler(exportService)
+
+	contentRenderService := services.NewContentRenderService(contentRepo)
+	contentHandler := NewContentHandler(contentRenderService)
+
+	tagService := services.NewTagService(tagRepo, s.config)
+	if err := tagService.SeedDefaults("data/tags.json"); err != nil {
+		logger.Warn("failed to seed default tags", zap.Error(err))
+	}
+	tagHandler := NewTagHandler(tagService)
 
 	var locationCache *searchCache.LocationCache
 	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
 		locationCache = searchCache.NewLocationCache(redisURL, locationService)
 	}
 
-	locationHandler := NewLocationHandler(locationService, locationCache)
+	locationBackfillService := services.NewLocationBackfillService(locationRepo, locationService)
+	locationHandler := NewLocationHandler(locationService, locationBackfillService, locationCache)
 
 	if s.config.Server.Environment != "production" {
 		s.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -136,35 +249,62 @@ func (s *Server) setupRoutes() {
 		api.GET("/clubs/:id", clubHandler.GetClub)
 		api.GET("/clubs/:id/members", clubHandler.ListClubMembers)
 		api.GET("/clubs/:id/ratings", clubHandler.ListClubRatings)
+		api.GET("/clubs/:id/ratings/histogram", clubHandler.GetRatingBreakdown)
+
+		api.GET("/tags", tagHandler.SearchTags)
 
 		api.GET("/posts/public", postHandler.ListPublicPosts)
 		api.GET("/posts/popular", postHandler.ListPopularPublicPosts)
 
 		api.GET("/books", bookHandler.Search)
-		api.GET("/books/:id", bookHandler.GetBookByID)
+		api.GET("/books/:id", middleware.OptionalAuthMiddleware(s.config), bookHandler.GetBookByID)
+
+		api.GET("/hashtags/trending", hashtagHandler.ListTrendingHashtags)
+		api.GET("/hashtags/:tag/posts", middleware.OptionalAuthMiddleware(s.config), hashtagHandler.ListPostsByHashtag)
 
 		api.GET("/posts/:id/likes", postHandler.ListLikesByPostID)
 		api.GET("/posts", postHandler.ListAllPosts)
 		api.GET("/clubs/:id/posts/summaries", middleware.OptionalAuthMiddleware(s.config), postHandler.ListPostSummaries)
-		api.GET("/posts/:id", postHandler.GetPostByID)
+		api.GET("/posts/:id", middleware.OptionalAuthMiddleware(s.config), postHandler.GetPostByID)
 
 		api.GET("/posts/:id/comments", commentHandler.ListCommentsByPostID)
 		api.GET("/users/:id/comments", commentHandler.ListCommentsByUserID)
 		api.GET("/comments/:id", commentHandler.GetCommentByID)
+		api.GET("/comments/:id/replies", commentHandler.ListReplies)
+		api.GET("/comments/:id/thread", commentHandler.GetCommentThread)
 		api.GET("/comments/:id/likes", commentHandler.ListLikesByCommentID)
 
+		api.GET("/reactions", reactionHandler.ListAvailableReactions)
+		api.GET("/posts/:id/reactions", reactionHandler.ListPostReactions)
+		api.GET("/comments/:id/reactions", reactionHandler.ListCommentReactions)
+
+		api.GET("/posts/:id/revisions", middleware.OptionalAuthMiddleware(s.config), revisionHandler.GetPostRevisions)
+		api.GET("/comments/:id/revisions", middleware.OptionalAuthMiddleware(s.config), revisionHandler.GetCommentRevisions)
+
 		api.GET("/events/public", eventHandler.GetPublicEvents)
 		api.GET("/locations/search", locationHandler.SearchLocations)
 	}
 
 	protected := api.Group("/")
 	protected.Use(middleware.AuthMiddleware(s.config))
+	protected.Use(middleware.RejectSuspended(userRepo))
 	{
 		protected.GET("/profile", userHandler.GetProfile)
 		protected.GET("/users/:id", middleware.AuthorizeSelf(), userHandler.GetUser)
 		protected.GET("/users", middleware.RestrictToRoles("admin", "superuser"), userHandler.GetAllUsers)
+
+		protected.POST("/locations/backfill", middleware.RestrictToRoles("admin", "superuser"), locationHandler.BackfillLocations)
+		protected.POST("/content/rerender", middleware.RestrictToRoles("admin", "superuser"), contentHandler.RerenderContent)
+
+		protected.POST("/tags", middleware.RestrictToRoles("admin", "superuser"), tagHandler.CreateTag)
+		protected.POST("/tags/merge", middleware.RestrictToRoles("admin", "superuser"), tagHandler.MergeTags)
+		protected.POST("/tags/:id/aliases", middleware.RestrictToRoles("admin", "superuser"), tagHandler.AddAlias)
 		protected.GET("/users/search", userHandler.SearchUsers)
 		protected.GET("/users/:id/profile", userHandler.GetPublicProfile)
+		protected.POST("/users/:id/block", blockHandler.BlockUser)
+		protected.DELETE("/users/:id/block", blockHandler.UnblockUser)
+		protected.POST("/users/:id/follow", followHandler.FollowUser)
+		protected.DELETE("/users/:id/follow", followHandler.UnfollowUser)
 		protected.PUT("/users/:id", middleware.AuthorizeSelf(), userHandler.UpdateUser)
 		protected.DELETE("/users/:id", middleware.AuthorizeSelf(), userHandler.DeleteUser)
 		protected.PATCH("/users/:id/password", userHandler.PatchPassword)
@@ -181,6 +321,14 @@ func (s *Server) setupRoutes() {
 		protected.POST("/clubs/:id/join", clubHandler.JoinClub)
 		protected.POST("/clubs/:id/leave", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), clubHandler.LeaveClub)
 		protected.POST("/clubs/:id/ratings", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), clubHandler.RateClub)
+		protected.PUT("/clubs/:id/ratings/:rating_id/reply", middleware.RequireClubMembershipWithRoles(clubRepo, eventRepo, "club_admin"), clubHandler.ReplyToRating)
+		protected.GET("/clubs/:id/content-filter", middleware.RequireClubMembershipWithRoles(clubRepo, eventRepo, "club_admin", "moderator"), contentFilterHandler.GetClubContentFilter)
+		protected.PUT("/clubs/:id/content-filter", middleware.RequireClubMembershipWithRoles(clubRepo, eventRepo, "club_admin"), contentFilterHandler.UpdateClubContentFilter)
+		protected.POST("/clubs/:id/attachments", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), attachmentHandler.UploadAttachment)
+		protected.GET("/clubs/:id/attachment-settings", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), attachmentHandler.GetClubAttachmentSettings)
+		protected.PUT("/clubs/:id/attachment-settings", middleware.RequireClubMembershipWithRoles(clubRepo, eventRepo, "club_admin"), attachmentHandler.UpdateClubAttachmentSettings)
+		protected.DELETE("/attachments/:id", attachmentHandler.DeleteAttachment)
+		protected.POST("/clubs/:id/ratings/:rating_id/report", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), reportHandler.ReportClubRating)
 		protected.GET("/my-clubs", clubHandler.GetMyClubs)
 
 		protected.PUT("/clubs/:id/members/:user_id", middleware.RequireClubMembershipWithRoles(clubRepo, eventRepo, "club_admin", "moderator"), clubHandler.UpdateClubMember)
@@ -194,6 +342,10 @@ func (s *Server) setupRoutes() {
 
 		protected.POST("/events/:id/rsvp", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), eventHandler.RSVPToEvent)
 		protected.GET("/events/:id/attendees", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), eventHandler.GetEventAttendees)
+		protected.POST("/events/:id/attendance", middleware.RequireClubMembershipWithRoles(clubRepo, eventRepo, "club_admin", "moderator"), eventHandler.MarkAttendance)
+
+		protected.GET("/clubs/:id/analytics", middleware.RequireClubMembershipWithRoles(clubRepo, eventRepo, "club_admin"), analyticsHandler.GetClubAnalytics)
+		protected.GET("/clubs/:id/export", middleware.RequireClubMembershipWithRoles(clubRepo, eventRepo, "club_admin"), exportHandler.ExportClub)
 
 		protected.POST("/books", middleware.RestrictToRoles("admin", "superuser"), bookHandler.CreateBook)
 		protected.PUT("/books/:id", middleware.RestrictToRoles("admin", "superuser"), bookHandler.UpdateBook)
@@ -203,11 +355,37 @@ func (s *Server) setupRoutes() {
 		protected.PUT("/posts/:id", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), postHandler.UpdatePost)
 		protected.DELETE("/posts/:id", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), postHandler.DeletePost)
 		protected.GET("/posts/reviews", postHandler.GetReviewsByBook)
+		protected.GET("/me/posts/drafts", postHandler.ListMyDrafts)
+		protected.GET("/me/posts/scheduled", postHandler.ListMyScheduledPosts)
+		protected.GET("/me/mentions", mentionHandler.ListMyMentions)
+		protected.GET("/me/feed", feedHandler.GetHomeFeed)
+		protected.POST("/me/bookmarks", bookmarkHandler.CreateBookmark)
+		protected.GET("/me/bookmarks", bookmarkHandler.ListBookmarks)
+		protected.PUT("/me/bookmarks/:id", bookmarkHandler.MoveBookmark)
+		protected.DELETE("/me/bookmarks/:id", bookmarkHandler.DeleteBookmark)
+		protected.GET("/me/collections", bookmarkHandler.ListCollections)
+		protected.POST("/me/collections", bookmarkHandler.CreateCollection)
+		protected.PUT("/me/collections/order", bookmarkHandler.ReorderCollections)
+		protected.PUT("/me/collections/:id", bookmarkHandler.RenameCollection)
+		protected.DELETE("/me/collections/:id", bookmarkHandler.DeleteCollection)
+		protected.PUT("/me/collections/:id/order", bookmarkHandler.ReorderBookmarks)
+		protected.GET("/me/notifications", notificationHandler.ListNotifications)
+		protected.GET("/me/notifications/unread-count", notificationHandler.CountUnread)
+		protected.POST("/me/notifications/read", notificationHandler.MarkRead)
+
+		protected.POST("/reports", reportHandler.CreateReport)
+		protected.GET("/moderation/reports", reportHandler.ListReports)
+		protected.GET("/moderation/reports/:id", reportHandler.GetReport)
+		protected.POST("/moderation/reports/:id/resolve", reportHandler.ResolveReport)
+		protected.GET("/moderation/log", reportHandler.ListModerationLog)
+		protected.GET("/posts/:id/views", viewHandler.GetPostViews)
 		protected.GET("/posts/filter", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), postHandler.GetPostsByType)
 
 		protected.POST("/posts/:id/vote", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), postHandler.VoteOnPoll)
 		protected.POST("/posts/:id/unvote", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), postHandler.RemoveVoteFromPoll)
 		protected.GET("/posts/:id/poll/votes", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), postHandler.GetUserPollVotes)
+		protected.GET("/posts/:id/poll/voters", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), postHandler.ListPollVoters)
+		protected.POST("/posts/:id/poll/close", postHandler.ClosePoll)
 
 		protected.POST("/posts/:id/like", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), postHandler.LikePost)
 		protected.POST("/posts/:id/unlike", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), postHandler.UnlikePost)
@@ -219,6 +397,11 @@ func (s *Server) setupRoutes() {
 		protected.POST("/comments/:id/like", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), commentHandler.LikeComment)
 		protected.POST("/comments/:id/unlike", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), commentHandler.UnlikeComment)
 
+		protected.POST("/posts/:id/reactions", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), reactionHandler.ReactToPost)
+		protected.DELETE("/posts/:id/reactions", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), reactionHandler.UnreactToPost)
+		protected.POST("/comments/:id/reactions", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), reactionHandler.ReactToComment)
+		protected.DELETE("/comments/:id/reactions", middleware.RequireClubMembership(clubRepo, postRepo, commentRepo, eventRepo), reactionHandler.UnreactToComment)
+
 		protected.POST("/users/:id/reading/sync", middleware.AuthorizeSelf(), readingHandler.SyncUserStats)
 		protected.POST("/users/:id/reading/start", middleware.AuthorizeSelf(), readingHandler.StartReading)
 		protected.PATCH("/users/:id/reading/:bookID/progress", middleware.AuthorizeSelf(), readingHandler.UpdateProgress)
@@ -234,5 +417,14 @@ func (s *Server) setupRoutes() {
 }
 
 func (s *Server) Start(addr string) error {
+	s.postScheduler.Start()
+	defer s.postScheduler.Stop()
+	s.viewFlusher.Start()
+	defer s.viewFlusher.Stop()
+	s.hotRefresher.Start()
+	defer s.hotRefresher.Stop()
+	s.attachmentCleaner.Start()
+	defer s.attachmentCleaner.Stop()
+
 	return s.router.Run(addr)
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -142,20 +143,31 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 }
 
 // @Summary Get all users
-// @Description Retrieve a list of all users
+// @Description Retrieve a page of users in sign-up order
 // @Tags Users
 // @Produce json
-// @Success 200 {object} map[string]interface{} "Users retrieved successfully"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} pagination.Page[models.UserResponse] "Users retrieved successfully"
+// @Failure 400 {object} map[string]string "Bad request"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/v1/users [get]
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
-	users, err := h.userService.GetAllUsers()
+	page, ok := bindPage(c, h.validator)
+	if !ok {
+		return
+	}
+
+	users, err := h.userService.GetAllUsers(page)
 	if err != nil {
+		if invalidCursor(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"users": users})
+	c.JSON(http.StatusOK, users)
 }
 
 // @Summary Update user
@@ -191,6 +203,10 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 
 	user, err := h.userService.UpdateUser(userID.(uint), &req)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidLocation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
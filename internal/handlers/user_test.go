@@ -35,7 +35,7 @@ func (suite *UserHandlerTestSuite) SetupTest() {
 
 	cfg := configtest.New()
 	userRepo := repository.NewUserRepository(suite.db)
-	userService := services.NewUserService(userRepo, cfg)
+	userService := services.NewUserService(userRepo, nil, cfg)
 	suite.userHandler = NewUserHandler(userService)
 
 	suite.router = gin.New()
@@ -110,7 +110,7 @@ func (suite *UserHandlerTestSuite) TestLoginHandler() {
 
 	userRepo := repository.NewUserRepository(suite.db)
 	cfg := configtest.New()
-	userService := services.NewUserService(userRepo, cfg)
+	userService := services.NewUserService(userRepo, nil, cfg)
 
 	registerReq, err := test_helpers.CreateRegisterRequest()
 	suite.Require().NoError(err)
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/services"
+)
+
+type ViewHandler struct {
+	viewService *services.ViewService
+	validator   *validator.Validate
+}
+
+func NewViewHandler(viewService *services.ViewService) *ViewHandler {
+	return &ViewHandler{
+		viewService: viewService,
+		validator:   validator.New(),
+	}
+}
+
+// @Summary Get post views over time
+// @Description Daily deduplicated views and feed impressions of a post. Only the author, club admins and platform admins can see them. Counts are written in batches, so the last minute or so may be missing.
+// @Tags Posts
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param from query string false "First day (YYYY-MM-DD), defaults to 30 days before to"
+// @Param to query string false "Last day (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} models.PostViewsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/v1/posts/{id}/views [get]
+func (h *ViewHandler) GetPostViews(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		return
+	}
+
+	var req models.PostViewsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	viewerID, viewerRole := optionalViewer(c)
+	if viewerID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	views, err := h.viewService.GetPostViews(uint(postID), *viewerID, viewerRole, &req)
+	if err != nil {
+		switch {
+		case err.Error() == "post not found", errors.Is(err, services.ErrClubNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrPostViewsForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidAnalyticsRange):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, views)
+}
+
+// viewerKey identifies the caller for view deduplication.
+func viewerKey(c *gin.Context, viewerID *uint) string {
+	return services.ViewerKey(viewerID, c.ClientIP(), c.Request.UserAgent())
+}
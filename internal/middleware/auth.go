@@ -3,10 +3,13 @@ package middleware
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nevzattalhaozcan/forgotten/internal/config"
@@ -39,6 +42,38 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	}
 }
 
+// RejectSuspended turns away users a moderator suspended. Suspension is
+// checked at login too, but tokens issued before it stay valid until they
+// expire, so it has to be checked on every request to take effect at once.
+func RejectSuspended(userRepo repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := c.Get("user_id")
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+			c.Abort()
+			return
+		}
+
+		user, err := userRepo.GetByID(userID.(uint))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user"})
+			}
+			c.Abort()
+			return
+		}
+		if user.SuspendedUntil != nil && time.Now().Before(*user.SuspendedUntil) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("account suspended until %s", user.SuspendedUntil.Format(time.RFC3339))})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 /** Ensure that the user can only access their own resources
  * If the user ID in the path parameter does not match the user ID in the token, return 403 Forbidden
  * If there is no user ID in the path parameter, allow access (for routes that do not require a specific user ID)
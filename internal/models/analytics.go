@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+type ClubAnalyticsRequest struct {
+	From        string `form:"from" validate:"omitempty,datetime=2006-01-02"`
+	To          string `form:"to" validate:"omitempty,datetime=2006-01-02"`
+	Granularity string `form:"granularity" validate:"omitempty,oneof=day week month"`
+	TopLimit    int    `form:"top" validate:"omitempty,gte=1,lte=50"`
+}
+
+type AnalyticsPoint struct {
+	Bucket time.Time `json:"bucket" gorm:"column:bucket"`
+	Value  int       `json:"value" gorm:"column:value"`
+}
+
+type EventParticipationStats struct {
+	EventID        uint      `json:"event_id" gorm:"column:event_id"`
+	Title          string    `json:"title" gorm:"column:title"`
+	EventDate      time.Time `json:"event_date" gorm:"column:event_date"`
+	Going          int       `json:"going" gorm:"column:going"`
+	Maybe          int       `json:"maybe" gorm:"column:maybe"`
+	NotGoing       int       `json:"not_going" gorm:"column:not_going"`
+	Attended       int       `json:"attended" gorm:"column:attended"`
+	RSVPRate       float64   `json:"rsvp_rate" gorm:"-"`
+	AttendanceRate float64   `json:"attendance_rate" gorm:"-"`
+}
+
+type AssignmentCompletionStats struct {
+	AssignmentID   uint    `json:"assignment_id" gorm:"column:assignment_id"`
+	BookID         uint    `json:"book_id" gorm:"column:book_id"`
+	BookTitle      string  `json:"book_title" gorm:"column:book_title"`
+	Status         string  `json:"status" gorm:"column:status"`
+	Members        int     `json:"members" gorm:"column:members"`
+	InProgress     int     `json:"in_progress" gorm:"column:in_progress"`
+	Finished       int     `json:"finished" gorm:"column:finished"`
+	CompletionRate float64 `json:"completion_rate" gorm:"-"`
+}
+
+type MemberActivity struct {
+	UserID    uint    `json:"user_id" gorm:"column:user_id"`
+	Username  string  `json:"username" gorm:"column:username"`
+	AvatarURL *string `json:"avatar_url,omitempty" gorm:"column:avatar_url"`
+	Posts     int     `json:"posts" gorm:"column:posts"`
+	Comments  int     `json:"comments" gorm:"column:comments"`
+	Score     int     `json:"score" gorm:"column:score"`
+}
+
+type ClubAnalyticsResponse struct {
+	ClubID      uint      `json:"club_id"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Granularity string    `json:"granularity"`
+
+	NewMembers  []AnalyticsPoint `json:"new_members"`
+	LeftMembers []AnalyticsPoint `json:"left_members"`
+	Posts       []AnalyticsPoint `json:"posts"`
+	Comments    []AnalyticsPoint `json:"comments"`
+	Likes       []AnalyticsPoint `json:"likes"`
+
+	Events         []EventParticipationStats `json:"events"`
+	RSVPRate       float64                   `json:"rsvp_rate"`
+	AttendanceRate float64                   `json:"attendance_rate"`
+
+	Assignments              []AssignmentCompletionStats `json:"assignments"`
+	AssignmentCompletionRate float64                     `json:"assignment_completion_rate"`
+
+	TopMembers []MemberActivity `json:"top_members"`
+
+	GeneratedAt time.Time `json:"generated_at"`
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	AttachmentKindImage = "image"
+	AttachmentKindFile  = "file"
+)
+
+// Attachment is a file uploaded to a club. It is uploaded before the post or
+// comment it belongs to is saved, so PostID and CommentID stay nil until it
+// is attached; uploads never attached are deleted after a while. Position
+// orders the attachments of one post or comment.
+type Attachment struct {
+	ID            uint    `json:"id" gorm:"primaryKey"`
+	ClubID        uint    `json:"club_id" gorm:"not null;index"`
+	UploaderID    uint    `json:"uploader_id" gorm:"not null;index"`
+	PostID        *uint   `json:"post_id,omitempty" gorm:"index"`
+	CommentID     *uint   `json:"comment_id,omitempty" gorm:"index"`
+	Position      int     `json:"position" gorm:"not null;default:0"`
+	Kind          string  `json:"kind" gorm:"size:10;not null"`
+	FileName      string  `json:"file_name" gorm:"size:255;not null"`
+	ContentType   string  `json:"content_type" gorm:"size:100;not null"`
+	Size          int64   `json:"size" gorm:"not null"`
+	StorageKey    string  `json:"-" gorm:"size:255;not null"`
+	URL           string  `json:"url" gorm:"size:1024;not null"`
+	ThumbnailKey  *string `json:"-" gorm:"size:255"`
+	ThumbnailURL  *string `json:"thumbnail_url,omitempty" gorm:"size:1024"`
+	ThumbnailSize int64   `json:"-" gorm:"not null;default:0"`
+	Width         int     `json:"width,omitempty"`
+	Height        int     `json:"height,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// StoredSize is what the attachment counts against its club's quota.
+func (a *Attachment) StoredSize() int64 {
+	return a.Size + a.ThumbnailSize
+}
+
+type AttachmentResponse struct {
+	ID           uint    `json:"id"`
+	Kind         string  `json:"kind"`
+	FileName     string  `json:"file_name"`
+	ContentType  string  `json:"content_type"`
+	Size         int64   `json:"size"`
+	URL          string  `json:"url"`
+	ThumbnailURL *string `json:"thumbnail_url,omitempty"`
+	Width        int     `json:"width,omitempty"`
+	Height       int     `json:"height,omitempty"`
+}
+
+func (a *Attachment) ToResponse() AttachmentResponse {
+	return AttachmentResponse{
+		ID:           a.ID,
+		Kind:         a.Kind,
+		FileName:     a.FileName,
+		ContentType:  a.ContentType,
+		Size:         a.Size,
+		URL:          a.URL,
+		ThumbnailURL: a.ThumbnailURL,
+		Width:        a.Width,
+		Height:       a.Height,
+	}
+}
+
+func attachmentResponses(attachments []Attachment) []AttachmentResponse {
+	if len(attachments) == 0 {
+		return nil
+	}
+	responses := make([]AttachmentResponse, len(attachments))
+	for i := range attachments {
+		responses[i] = attachments[i].ToResponse()
+	}
+	return responses
+}
+
+// ClubAttachmentSettings overrides the platform attachment settings for one
+// club. AllowedTypes narrows the platform list; nil keeps all of it. Only
+// platform admins set QuotaMB; nil keeps the platform quota.
+type ClubAttachmentSettings struct {
+	ClubID       uint           `json:"club_id" gorm:"primaryKey"`
+	AllowedTypes pq.StringArray `json:"allowed_types" gorm:"type:text[]" swaggertype:"array,string"`
+	QuotaMB      *int           `json:"quota_mb,omitempty"`
+	UpdatedBy    uint           `json:"updated_by"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UpdateClubAttachmentSettingsRequest replaces a club's overrides. QuotaMB is
+// ignored unless a platform admin sends it.
+type UpdateClubAttachmentSettingsRequest struct {
+	AllowedTypes []string `json:"allowed_types" validate:"max=50,dive,min=3,max=100"`
+	QuotaMB      *int     `json:"quota_mb" validate:"omitempty,gte=0"`
+}
+
+// ClubAttachmentSettingsResponse is the settings that apply to a club, with
+// the platform defaults filled in, and how much of its quota it uses.
+type ClubAttachmentSettingsResponse struct {
+	ClubID       uint     `json:"club_id"`
+	AllowedTypes []string `json:"allowed_types"`
+	MaxFileBytes int64    `json:"max_file_bytes"`
+	QuotaBytes   int64    `json:"quota_bytes"`
+	UsedBytes    int64    `json:"used_bytes"`
+	MaxPerPost   int      `json:"max_per_post"`
+}
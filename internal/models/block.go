@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// UserBlock means BlockerID does not want to be drawn into BlockedID's
+// content. For now it only suppresses mentions.
+type UserBlock struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BlockerID uint      `json:"blocker_id" gorm:"not null;uniqueIndex:idx_user_block"`
+	BlockedID uint      `json:"blocked_id" gorm:"not null;uniqueIndex:idx_user_block;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
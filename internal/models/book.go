@@ -40,6 +40,18 @@ type Book struct {
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// BookRating is the rating a user gave a book in their review post. A user
+// has at most one review of a book that was not deleted; the book's local
+// rating averages the ratings of its published reviews.
+type BookRating struct {
+	PostID    uint      `json:"post_id" gorm:"primaryKey;autoIncrement:false"`
+	BookID    uint      `json:"book_id" gorm:"not null;index;uniqueIndex:idx_book_ratings_book_user"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_book_ratings_book_user"`
+	Rating    float32   `json:"rating" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type ExternalBook struct {
 	ExternalID    string   `json:"external_id"`
 	Source        string   `json:"source"`
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+)
+
+const (
+	BookmarkTargetPost    = "post"
+	BookmarkTargetComment = "comment"
+)
+
+// BookmarkCollection is a named group of a user's bookmarks. Collections are
+// only ever visible to their owner.
+type BookmarkCollection struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	UserID   uint   `json:"user_id" gorm:"not null;uniqueIndex:idx_bookmark_collection_name"`
+	Name     string `json:"name" gorm:"size:100;not null;uniqueIndex:idx_bookmark_collection_name"`
+	Position int    `json:"position" gorm:"not null;default:0"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Bookmark saves a post or comment for later. A target is bookmarked at most
+// once per user; CollectionID is nil for bookmarks outside any collection.
+// Position orders bookmarks within their collection.
+type Bookmark struct {
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	UserID       uint   `json:"user_id" gorm:"not null;uniqueIndex:idx_bookmark_unique"`
+	TargetType   string `json:"target_type" gorm:"size:20;not null;uniqueIndex:idx_bookmark_unique"`
+	TargetID     uint   `json:"target_id" gorm:"not null;uniqueIndex:idx_bookmark_unique"`
+	CollectionID *uint  `json:"collection_id" gorm:"index"`
+	Position     int    `json:"position" gorm:"not null;default:0"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateBookmarkRequest struct {
+	TargetType   string `json:"target_type" validate:"required,oneof=post comment"`
+	TargetID     uint   `json:"target_id" validate:"required,gt=0"`
+	CollectionID *uint  `json:"collection_id,omitempty" validate:"omitempty,gt=0"`
+}
+
+// MoveBookmarkRequest moves a bookmark to the end of another collection, or
+// out of any collection when CollectionID is null.
+type MoveBookmarkRequest struct {
+	CollectionID *uint `json:"collection_id" validate:"omitempty,gt=0"`
+}
+
+// ListBookmarksRequest lists one collection in its saved order when
+// CollectionID is set, otherwise every bookmark, newest first.
+type ListBookmarksRequest struct {
+	pagination.Request
+	CollectionID *uint `form:"collection_id" validate:"omitempty,gt=0"`
+}
+
+type BookmarkCollectionRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+type ReorderRequest struct {
+	IDs []uint `json:"ids" validate:"required,min=1,dive,gt=0"`
+}
+
+type BookmarkCollectionResponse struct {
+	ID             uint      `json:"id"`
+	Name           string    `json:"name"`
+	Position       int       `json:"position"`
+	BookmarksCount int       `json:"bookmarks_count"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// BookmarkResponse carries the bookmarked post or comment. When the target
+// was deleted or the user can no longer read its club, the bookmark is a
+// tombstone: Unavailable is set and neither Post nor Comment is.
+type BookmarkResponse struct {
+	ID           uint             `json:"id"`
+	TargetType   string           `json:"target_type"`
+	TargetID     uint             `json:"target_id"`
+	CollectionID *uint            `json:"collection_id"`
+	Position     int              `json:"position"`
+	Unavailable  bool             `json:"unavailable"`
+	Post         *PostResponse    `json:"post,omitempty"`
+	Comment      *CommentResponse `json:"comment,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+}
+
+func (b *Bookmark) ToResponse() BookmarkResponse {
+	return BookmarkResponse{
+		ID:           b.ID,
+		TargetType:   b.TargetType,
+		TargetID:     b.TargetID,
+		CollectionID: b.CollectionID,
+		Position:     b.Position,
+		CreatedAt:    b.CreatedAt,
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/lib/pq"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
 )
 
@@ -33,11 +34,34 @@ type ClubMembership struct {
 	Club       Club      `json:"-" gorm:"foreignKey:ClubID;constraint:OnDelete:CASCADE"`
 }
 
+type ClubMembershipLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ClubID    uint      `json:"club_id" gorm:"index:idx_club_membership_logs_club_created;not null"`
+	UserID    uint      `json:"user_id" gorm:"index;not null"`
+	Action    string    `json:"action" gorm:"size:20;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"index:idx_club_membership_logs_club_created;autoCreateTime"`
+}
+
+const (
+	MembershipActionJoined = "joined"
+	MembershipActionLeft   = "left"
+)
+
+// Who can read the full edit history of posts and comments in a club. Edit
+// counts are always visible.
+const (
+	RevisionVisibilityPublic     = "public"
+	RevisionVisibilityMembers    = "members"
+	RevisionVisibilityModerators = "moderators"
+)
+
 type Club struct {
 	ID            uint             `json:"id" gorm:"primaryKey"`
 	Name          string           `json:"name" gorm:"size:100;not null;unique"`
 	Description   string           `json:"description" gorm:"type:text"`
 	Location      *string          `json:"location" gorm:"size:255"`
+	CityID        *string          `json:"city_id" gorm:"size:10;index"`
+	DistrictID    *string          `json:"district_id" gorm:"size:10;index"`
 	MeetingType   *string          `json:"meeting_type" gorm:"size:50" default:"online"`
 	Genre         *string          `json:"genre" gorm:"size:100"`
 	CoverImageURL *string          `json:"cover_image_url" gorm:"type:text"`
@@ -46,8 +70,10 @@ type Club struct {
 	MembersCount  int              `json:"members_count" gorm:"default:0"`
 	Rating        float32          `json:"rating" gorm:"default:0"`
 	RatingsCount  int              `json:"ratings_count" gorm:"default:0"`
+	RatingHistogram json.RawMessage `json:"rating_histogram" gorm:"type:jsonb" swaggerignore:"true"`
 	Tags          pq.StringArray   `json:"tags" gorm:"type:text[]" swaggertype:"array,string"`
 	OwnerID       *uint            `json:"owner_id"`
+	RevisionVisibility string      `json:"revision_visibility" gorm:"size:20;default:'members'"`
 
 	CurrentBook   json.RawMessage  `json:"current_book" gorm:"type:jsonb" swaggerignore:"true"`
 	NextMeeting   json.RawMessage  `json:"next_meeting" gorm:"type:jsonb" swaggerignore:"true"`
@@ -63,13 +89,37 @@ type Club struct {
 }
 
 type ClubRating struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	ClubID    uint      `json:"club_id" gorm:"index;not null"`
-	UserID    uint      `json:"user_id" gorm:"index;not null"`
-	Rating    float32   `json:"rating" gorm:"not null"`
-	Comment   *string   `json:"comment" gorm:"type:text"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	ClubID       uint       `json:"club_id" gorm:"index;not null"`
+	UserID       uint       `json:"user_id" gorm:"index;not null"`
+	Rating       float32    `json:"rating" gorm:"not null"`
+	Comment      *string    `json:"comment" gorm:"type:text"`
+	Reply        *string    `json:"reply,omitempty" gorm:"type:text"`
+	RepliedBy    *uint      `json:"replied_by,omitempty"`
+	RepliedAt    *time.Time `json:"replied_at,omitempty"`
+	MemberLeftAt *time.Time `json:"member_left_at,omitempty"`
+	ReportsCount int        `json:"reports_count" gorm:"default:0"`
+	IsHidden     bool       `json:"-" gorm:"default:false"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type ClubRatingReport struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	RatingID   uint      `json:"rating_id" gorm:"uniqueIndex:idx_club_rating_reports_rating_reporter;not null"`
+	ReporterID uint      `json:"reporter_id" gorm:"uniqueIndex:idx_club_rating_reports_rating_reporter;not null"`
+	Reason     string    `json:"reason" gorm:"size:500"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	Rating ClubRating `json:"-" gorm:"foreignKey:RatingID;constraint:OnDelete:CASCADE"`
+}
+
+// RatingHistogram maps each star value (1-5) to the number of ratings that
+// round to it.
+type RatingHistogram map[int]int
+
+func NewRatingHistogram() RatingHistogram {
+	return RatingHistogram{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}
 }
 
 type RateClubRequest struct {
@@ -77,22 +127,42 @@ type RateClubRequest struct {
 	Comment *string `json:"comment" validate:"omitempty,max=1000"`
 }
 
+type ReplyClubRatingRequest struct {
+	Reply string `json:"reply" validate:"required,max=1000"`
+}
+
+type ReportClubRatingRequest struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500"`
+}
+
+type ClubRatingBreakdownResponse struct {
+	ClubID       uint            `json:"club_id"`
+	Rating       float32         `json:"rating"`
+	RatingsCount int             `json:"ratings_count"`
+	Histogram    RatingHistogram `json:"histogram"`
+}
+
 type CreateClubRequest struct {
 	Name          string         `json:"name" validate:"required,min=3,max=100"`
 	Description   string         `json:"description" validate:"max=1000"`
 	Location      *string        `json:"location" validate:"omitempty,max=255"`
+	CityID        *string        `json:"city_id" validate:"omitempty,max=10"`
+	DistrictID    *string        `json:"district_id" validate:"omitempty,max=10"`
 	MeetingType   *string        `json:"meeting_type" validate:"omitempty,oneof=online in-person hybrid"`
 	Genre         *string        `json:"genre" validate:"omitempty,max=100"`
 	CoverImageURL *string        `json:"cover_image_url" validate:"omitempty,url"`
 	IsPrivate     bool           `json:"is_private"`
 	MaxMembers    int            `json:"max_members" validate:"gte=1,lte=1000"`
 	Tags          pq.StringArray `json:"tags" validate:"dive,max=50" swaggertype:"array,string"`
+	RevisionVisibility string    `json:"revision_visibility" validate:"omitempty,oneof=public members moderators"`
 }
 
 type UpdateClubRequest struct {
 	Name          *string         `json:"name" validate:"omitempty,min=3,max=100"`
 	Description   *string         `json:"description" validate:"omitempty,max=1000"`
 	Location      *string         `json:"location" validate:"omitempty,max=255"`
+	CityID        *string         `json:"city_id" validate:"omitempty,max=10"`
+	DistrictID    *string         `json:"district_id" validate:"omitempty,max=10"`
 	MeetingType   *string         `json:"meeting_type" validate:"omitempty,oneof=online in-person hybrid"`
 	Genre         *string         `json:"genre" validate:"omitempty,max=100"`
 	CoverImageURL *string         `json:"cover_image_url" validate:"omitempty,url"`
@@ -101,6 +171,7 @@ type UpdateClubRequest struct {
 	Tags          *pq.StringArray `json:"tags" validate:"omitempty,dive,max=50" swaggertype:"array,string"`
 	CurrentBook   *CurrentBook    `json:"current_book"`
 	NextMeeting   *NextMeeting    `json:"next_meeting"`
+	RevisionVisibility *string    `json:"revision_visibility" validate:"omitempty,oneof=public members moderators"`
 }
 
 type UpdateClubMembershipRequest struct {
@@ -120,12 +191,13 @@ type OwnerLeaveRequest struct {
 
 type ClubFilterRequest struct {
     Location      string `form:"location" validate:"omitempty,max=255"`
+    CityID        string `form:"city_id" validate:"omitempty,max=10"`
+    DistrictID    string `form:"district_id" validate:"omitempty,max=10"`
     Genre         string `form:"genre" validate:"omitempty,max=100"`
     MeetingType   string `form:"meeting_type" validate:"omitempty,oneof=online in-person hybrid"`
     MinMembers    int    `form:"min_members" validate:"omitempty,gte=0"`
     MaxMembers    int    `form:"max_members" validate:"omitempty,gte=0"`
-    Limit         int    `form:"limit" validate:"omitempty,gte=1,lte=100"`
-    Offset        int    `form:"offset" validate:"omitempty,gte=0"`
+    pagination.Request
 }
 
 type ClubMembershipResponse struct {
@@ -143,6 +215,8 @@ type ClubResponse struct {
 	Name          string           `json:"name"`
 	Description   string           `json:"description"`
 	Location      *string          `json:"location,omitempty"`
+	CityID        *string          `json:"city_id,omitempty"`
+	DistrictID    *string          `json:"district_id,omitempty"`
 	MeetingType   *string          `json:"meeting_type,omitempty"`
 	Genre         *string          `json:"genre,omitempty"`
 	CoverImageURL *string          `json:"cover_image_url,omitempty"`
@@ -151,8 +225,10 @@ type ClubResponse struct {
 	MembersCount  int              `json:"members_count"`
 	Rating        float32          `json:"rating"`
 	RatingsCount  int              `json:"ratings_count"`
+	RatingHistogram RatingHistogram `json:"rating_histogram"`
 	Tags          pq.StringArray   `json:"tags"`
 	OwnerID       uint             `json:"owner_id"`
+	RevisionVisibility string      `json:"revision_visibility"`
 	Owner         UserResponse     `json:"owner"`
 	CurrentBook   *CurrentBook     `json:"current_book,omitempty"`
 	NextMeeting   *NextMeeting     `json:"next_meeting,omitempty"`
@@ -178,6 +254,16 @@ func (c *Club) ToResponse() ClubResponse {
 		}
 	}
 
+	histogram := NewRatingHistogram()
+	if len(c.RatingHistogram) > 0 {
+		var h RatingHistogram
+		if err := json.Unmarshal(c.RatingHistogram, &h); err == nil {
+			for star, count := range h {
+				histogram[star] = count
+			}
+		}
+	}
+
 	members := make([]ClubMembership, len(c.Members))
 	copy(members, c.Members)
 
@@ -186,6 +272,8 @@ func (c *Club) ToResponse() ClubResponse {
 		Name:          c.Name,
 		Description:   c.Description,
 		Location:      c.Location,
+		CityID:        c.CityID,
+		DistrictID:    c.DistrictID,
 		MeetingType:   c.MeetingType,
 		Genre:         c.Genre,
 		CoverImageURL: c.CoverImageURL,
@@ -194,6 +282,7 @@ func (c *Club) ToResponse() ClubResponse {
 		MembersCount:  c.MembersCount,
 		Rating:        c.Rating,
 		RatingsCount:  c.RatingsCount,
+		RatingHistogram: histogram,
 		Tags:          c.Tags,
 		OwnerID: func() uint {
 			if c.OwnerID != nil {
@@ -201,6 +290,7 @@ func (c *Club) ToResponse() ClubResponse {
 			}
 			return 0
 		}(),
+		RevisionVisibility: c.RevisionVisibility,
 		Owner:       c.Owner.ToResponse(),
 		CurrentBook: currentBook,
 		NextMeeting: nextMeeting,
@@ -3,36 +3,65 @@ package models
 import (
 	"time"
 
+	"github.com/nevzattalhaozcan/forgotten/pkg/markdown"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
 )
 
-type CommentLike struct {
-	ID        uint    `json:"id" gorm:"primaryKey"`
-	UserID    uint    `json:"user_id"`
-	CommentID uint    `json:"comment_id"`
-	User      User    `json:"user" gorm:"foreignKey:UserID" swaggerignore:"true"`
-	Comment   Comment `json:"comment" gorm:"foreignKey:CommentID" swaggerignore:"true"`
-
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
-
 type Comment struct {
-	ID         uint          `json:"id" gorm:"primaryKey"`
-	PostID     uint          `json:"post_id" gorm:"not null; foreignKey:PostID"`
-	UserID     uint          `json:"user_id" gorm:"not null;foreignKey:UserID"`
-	Content    string        `json:"content" gorm:"type:text;not null"`
-	LikesCount int           `json:"likes_count" gorm:"default:0"`
-	User       User          `json:"user" gorm:"foreignKey:UserID" swaggerignore:"true"`
-	Likes      []CommentLike `json:"likes,omitempty" gorm:"foreignKey:CommentID" swaggerignore:"true"`
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	PostID         uint           `json:"post_id" gorm:"not null; foreignKey:PostID"`
+	UserID         uint           `json:"user_id" gorm:"not null;foreignKey:UserID"`
+	ParentID       *uint          `json:"parent_id,omitempty" gorm:"index"`
+	Depth          int            `json:"depth" gorm:"default:0"`
+	RepliesCount   int            `json:"replies_count" gorm:"default:0"`
+	IsDeleted      bool           `json:"is_deleted" gorm:"default:false"`
+	IsHidden       bool           `json:"is_hidden" gorm:"default:false"`
+	IsHeld         bool           `json:"is_held" gorm:"default:false"`
+	Content        string         `json:"content" gorm:"type:text;not null"`
+	ContentHTML    string         `json:"-" gorm:"type:text"`
+	RenderVersion  int            `json:"-" gorm:"default:0"`
+	LikesCount     int            `json:"likes_count" gorm:"default:0"`
+	ReactionCounts ReactionCounts `json:"reaction_counts,omitempty" gorm:"type:jsonb" swaggertype:"object"`
+	EditCount      int            `json:"edit_count" gorm:"default:0"`
+	EditedAt       *time.Time     `json:"edited_at,omitempty"`
+	User           User           `json:"user" gorm:"foreignKey:UserID" swaggerignore:"true"`
+	Replies        []Comment      `json:"replies,omitempty" gorm:"foreignKey:ParentID" swaggerignore:"true"`
+	Mentions       []Mention      `json:"-" gorm:"polymorphic:Target;polymorphicValue:comment" swaggerignore:"true"`
+	Attachments    []Attachment   `json:"attachments,omitempty" gorm:"foreignKey:CommentID" swaggerignore:"true"`
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// DeletedCommentContent replaces the content of a deleted comment that still
+// has replies, so the thread below it stays readable.
+const DeletedCommentContent = "[deleted]"
+
+// HiddenCommentContent is shown in place of a comment hidden by a moderator.
+const HiddenCommentContent = "[hidden by a moderator]"
+
+// HeldCommentContent is shown in place of a comment the content filter held
+// until a moderator approves it.
+const HeldCommentContent = "[awaiting moderator review]"
+
+// RenderContent refreshes the sanitized HTML stored next to the Markdown
+// source. Call it whenever Content changes.
+func (c *Comment) RenderContent() {
+	c.ContentHTML = markdown.Render(c.Content).HTML
+	c.RenderVersion = markdown.RenderVersion
+}
+
 type CreateCommentRequest struct {
-	Content string `json:"content" validate:"required,min=1"`
+	Content       string `json:"content" validate:"required,min=1"`
+	ParentID      *uint  `json:"parent_id,omitempty" validate:"omitempty,gt=0"`
+	AttachmentIDs []uint `json:"attachment_ids,omitempty" validate:"omitempty,dive,gt=0"`
+}
+
+type CommentThreadRequest struct {
+	pagination.Request
+	RepliesLimit int `form:"replies_limit" validate:"omitempty,gte=0,lte=50"`
 }
 
 type UpdateCommentRequest struct {
@@ -40,13 +69,26 @@ type UpdateCommentRequest struct {
 }
 
 type CommentResponse struct {
-	ID         uint          `json:"id"`
-	PostID     uint          `json:"post_id"`
-	UserID     uint          `json:"user_id"`
-	Content    string        `json:"content"`
-	LikesCount int           `json:"likes_count"`
-	User       User          `json:"user" swaggerignore:"true"`
-	Likes      []CommentLike `json:"likes,omitempty" swaggerignore:"true"`
+	ID           uint                 `json:"id"`
+	PostID       uint                 `json:"post_id"`
+	UserID       uint                 `json:"user_id"`
+	ParentID     *uint                `json:"parent_id,omitempty"`
+	Depth        int                  `json:"depth"`
+	RepliesCount int                  `json:"replies_count"`
+	IsDeleted    bool                 `json:"is_deleted"`
+	IsHidden     bool                 `json:"is_hidden"`
+	IsHeld       bool                 `json:"is_held"`
+	Content      string               `json:"content"`
+	ContentHTML  string               `json:"content_html"`
+	LikesCount   int                  `json:"likes_count"`
+	Reactions    []ReactionSummary    `json:"reactions,omitempty"`
+	Edited       bool                 `json:"edited"`
+	EditCount    int                  `json:"edit_count"`
+	EditedAt     *time.Time           `json:"edited_at,omitempty"`
+	User         User                 `json:"user" swaggerignore:"true"`
+	Mentions     []MentionEntity      `json:"mentions,omitempty"`
+	Attachments  []AttachmentResponse `json:"attachments,omitempty"`
+	Replies      []CommentResponse    `json:"replies,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -58,24 +100,63 @@ type CommentLikeResponse struct {
 	CreatedAt time.Time    `json:"created_at"`
 }
 
-func (cl CommentLike) ToResponse() CommentLikeResponse {
+// ToCommentLikeResponse keeps the legacy likes listing working on top of ❤️ reactions.
+func (r Reaction) ToCommentLikeResponse() CommentLikeResponse {
 	return CommentLikeResponse{
-		ID:        cl.ID,
-		User:      cl.User.ToResponse(),
-		CreatedAt: cl.CreatedAt,
+		ID:        r.ID,
+		User:      r.User.ToResponse(),
+		CreatedAt: r.CreatedAt,
 	}
 }
 
 func (c *Comment) ToResponse() CommentResponse {
-	return CommentResponse{
-		ID:         c.ID,
-		PostID:     c.PostID,
-		UserID:     c.UserID,
-		Content:    c.Content,
-		LikesCount: c.LikesCount,
-		User:       c.User,
-		Likes:      c.Likes,
-		CreatedAt:  c.CreatedAt,
-		UpdatedAt:  c.UpdatedAt,
+	response := CommentResponse{
+		ID:           c.ID,
+		PostID:       c.PostID,
+		UserID:       c.UserID,
+		ParentID:     c.ParentID,
+		Depth:        c.Depth,
+		RepliesCount: c.RepliesCount,
+		IsDeleted:    c.IsDeleted,
+		IsHidden:     c.IsHidden,
+		IsHeld:       c.IsHeld,
+		Content:      c.Content,
+		ContentHTML:  c.ContentHTML,
+		LikesCount:   c.LikesCount,
+		Reactions:    c.ReactionCounts.Summaries(nil),
+		Edited:       c.EditCount > 0,
+		EditCount:    c.EditCount,
+		EditedAt:     c.EditedAt,
+		User:         c.User,
+		Mentions:     mentionEntities(c.Content, c.Mentions),
+		Attachments:  attachmentResponses(c.Attachments),
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.UpdatedAt,
+	}
+
+	if c.RenderVersion < markdown.RenderVersion {
+		response.ContentHTML = markdown.Render(c.Content).HTML
+	}
+
+	if c.IsHidden {
+		response.Content = HiddenCommentContent
+		response.ContentHTML = markdown.Render(HiddenCommentContent).HTML
+	} else if c.IsHeld {
+		response.Content = HeldCommentContent
+		response.ContentHTML = markdown.Render(HeldCommentContent).HTML
+	}
+
+	// placeholders keep their position in the thread but not their author
+	if c.IsDeleted || c.IsHidden || c.IsHeld {
+		response.UserID = 0
+		response.User = User{}
+		response.Reactions = nil
+		response.Mentions = nil
+		response.Attachments = nil
+	}
+
+	for i := range c.Replies {
+		response.Replies = append(response.Replies, c.Replies[i].ToResponse())
 	}
+	return response
 }
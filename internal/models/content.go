@@ -0,0 +1,7 @@
+package models
+
+type ContentRerenderResponse struct {
+	RenderVersion int `json:"render_version"`
+	Posts         int `json:"posts"`
+	Comments      int `json:"comments"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// What the content filter does with matching posts and comments. Flagged
+// content is published and queued for review; held content waits in the
+// queue unpublished until a moderator approves it by dismissing the report;
+// blocked content is rejected. FilterActionOff only appears in settings and
+// turns a rule off.
+const (
+	FilterActionFlag  = "flag"
+	FilterActionHold  = "hold"
+	FilterActionBlock = "block"
+	FilterActionOff   = "off"
+)
+
+// ClubContentFilter overrides the platform content filter for one club's posts
+// and comments. Its word lists add to the platform lists; AllowWords lifts
+// platform hold and flag words, but never platform block words. Nil fields
+// keep the platform setting.
+type ClubContentFilter struct {
+	ClubID               uint           `json:"club_id" gorm:"primaryKey"`
+	BlockWords           pq.StringArray `json:"block_words" gorm:"type:text[]" swaggertype:"array,string"`
+	HoldWords            pq.StringArray `json:"hold_words" gorm:"type:text[]" swaggertype:"array,string"`
+	FlagWords            pq.StringArray `json:"flag_words" gorm:"type:text[]" swaggertype:"array,string"`
+	AllowWords           pq.StringArray `json:"allow_words" gorm:"type:text[]" swaggertype:"array,string"`
+	MaxLinks             *int           `json:"max_links,omitempty"`
+	LinkAction           *string        `json:"link_action,omitempty" gorm:"size:10"`
+	RepeatAction         *string        `json:"repeat_action,omitempty" gorm:"size:10"`
+	NewAccountRateAction *string        `json:"new_account_rate_action,omitempty" gorm:"size:10"`
+	NewAccountLinkAction *string        `json:"new_account_link_action,omitempty" gorm:"size:10"`
+	UpdatedBy            uint           `json:"updated_by"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UpdateClubContentFilterRequest replaces a club's overrides.
+type UpdateClubContentFilterRequest struct {
+	BlockWords           []string `json:"block_words" validate:"max=200,dive,min=2,max=50"`
+	HoldWords            []string `json:"hold_words" validate:"max=200,dive,min=2,max=50"`
+	FlagWords            []string `json:"flag_words" validate:"max=200,dive,min=2,max=50"`
+	AllowWords           []string `json:"allow_words" validate:"max=200,dive,min=2,max=50"`
+	MaxLinks             *int     `json:"max_links" validate:"omitempty,gte=0,lte=50"`
+	LinkAction           *string  `json:"link_action" validate:"omitempty,oneof=flag hold block off"`
+	RepeatAction         *string  `json:"repeat_action" validate:"omitempty,oneof=flag hold block off"`
+	NewAccountRateAction *string  `json:"new_account_rate_action" validate:"omitempty,oneof=flag hold block off"`
+	NewAccountLinkAction *string  `json:"new_account_link_action" validate:"omitempty,oneof=flag hold block off"`
+}
+
+// RecentContent is a post or comment an author wrote recently, as the content
+// filter compares new content against it.
+type RecentContent struct {
+	Kind      string
+	ID        uint
+	Text      string
+	CreatedAt time.Time
+}
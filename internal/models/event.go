@@ -70,6 +70,8 @@ type Event struct {
 	EventDate    time.Time   `json:"event_date" gorm:"type:date;not null"`
 	EventTime    DBTime      `json:"event_time" gorm:"type:time;not null"`
 	Location     string      `json:"location,omitempty"`
+	CityID       *string     `json:"city_id,omitempty" gorm:"size:10;index"`
+	DistrictID   *string     `json:"district_id,omitempty" gorm:"size:10;index"`
 	OnlineLink   string      `json:"online_link,omitempty"`
 	MaxAttendees *int        `json:"max_attendees,omitempty"`
 	IsPublic     bool        `json:"is_public" gorm:"default:false"`
@@ -90,6 +92,7 @@ type EventRSVP struct {
 	UserID    uint       `json:"user_id" gorm:"not null"`
 	EventID   uint       `json:"event_id" gorm:"not null"`
 	Status    RSVPStatus `json:"status" gorm:"type:varchar(20);not null"`
+	Attended  bool       `json:"attended" gorm:"default:false"`
 	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	User      User       `json:"user" gorm:"foreignKey:UserID"`
 	Event     Event      `json:"event" gorm:"foreignKey:EventID"`
@@ -110,6 +113,8 @@ type CreateEventRequest struct {
 	EventDate    DateYMD   `json:"event_date" binding:"required"`
 	EventTime    TimeHM    `json:"event_time" binding:"required"`
 	Location     string    `json:"location,omitempty"`
+	CityID       *string   `json:"city_id,omitempty"`
+	DistrictID   *string   `json:"district_id,omitempty"`
 	OnlineLink   string    `json:"online_link,omitempty"`
 	MaxAttendees *int      `json:"max_attendees,omitempty"`
 	IsPublic     bool      `json:"is_public" gorm:"default:false"`
@@ -122,6 +127,8 @@ type UpdateEventRequest struct {
 	EventDate    *DateYMD   `json:"event_date,omitempty" binding:"omitempty"`
 	EventTime    *TimeHM    `json:"event_time,omitempty" binding:"omitempty"`
 	Location     *string    `json:"location,omitempty"`
+	CityID       *string    `json:"city_id,omitempty"`
+	DistrictID   *string    `json:"district_id,omitempty"`
 	OnlineLink   *string    `json:"online_link,omitempty"`
 	MaxAttendees *int       `json:"max_attendees,omitempty"`
 	IsPublic     *bool      `json:"is_public,omitempty"`
@@ -131,6 +138,11 @@ type RSVPRequest struct {
 	Status RSVPStatus `json:"status" binding:"required,oneof=going maybe not_going"`
 }
 
+type MarkAttendanceRequest struct {
+	UserIDs  []uint `json:"user_ids" binding:"required,min=1"`
+	Attended bool   `json:"attended"`
+}
+
 type EventResponse struct {
 	ID           uint        `json:"id"`
 	Title        string      `json:"title"`
@@ -140,6 +152,8 @@ type EventResponse struct {
 	EventDate    string      `json:"event_date"`
 	EventTime    string      `json:"event_time"`
 	Location     string      `json:"location,omitempty"`
+	CityID       *string     `json:"city_id,omitempty"`
+	DistrictID   *string     `json:"district_id,omitempty"`
 	OnlineLink   string      `json:"online_link,omitempty"`
 	MaxAttendees *int        `json:"max_attendees,omitempty"`
 	CreatedAt    time.Time   `json:"created_at"`
@@ -157,6 +171,8 @@ func (e *Event) ToResponse() EventResponse {
 		EventDate:    e.EventDate.Format("2006-01-02"),
 		EventTime:    e.EventTime.Format("15:04"),
 		Location:     e.Location,
+		CityID:       e.CityID,
+		DistrictID:   e.DistrictID,
 		OnlineLink:   e.OnlineLink,
 		MaxAttendees: e.MaxAttendees,
 		CreatedAt:    e.CreatedAt,
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+const (
+	ExportTypeMembers    = "members"
+	ExportTypeAttendance = "attendance"
+	ExportTypeReading    = "reading"
+	ExportTypePosts      = "posts"
+
+	ExportFormatCSV   = "csv"
+	ExportFormatExcel = "excel"
+)
+
+type ClubExportRequest struct {
+	Type   string `form:"type" validate:"required,oneof=members attendance reading posts"`
+	Format string `form:"format" validate:"omitempty,oneof=csv excel"`
+}
+
+// ReadingExportRow is one member's progress on one of the club's assigned
+// books. The progress fields are empty for members who have not started it.
+type ReadingExportRow struct {
+	AssignmentID     uint
+	BookID           uint
+	BookTitle        string
+	AssignmentStatus string
+	DueDate          *time.Time
+	UserID           uint
+	Username         string
+	ReadingStatus    *string
+	CurrentPage      *int
+	Percent          *float32
+	StartedAt        *time.Time
+	FinishedAt       *time.Time
+}
+
+// MemberPostActivity counts a member's published posts in a club and their
+// comments on them.
+type MemberPostActivity struct {
+	UserID   uint
+	Username string
+	Role     string
+	Posts    int
+	Comments int
+}
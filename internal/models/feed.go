@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+)
+
+// Why a post is in someone's home feed. A post that qualifies for several
+// gets the first that applies, in this order.
+const (
+	FeedReasonClub      = "club"
+	FeedReasonFollowing = "following"
+	FeedReasonTrending  = "trending"
+)
+
+// FeedPost is what the feed ranks a post on, without its content.
+type FeedPost struct {
+	PostID        uint
+	ClubID        uint
+	AuthorID      uint
+	PublishedAt   time.Time
+	LikesCount    int
+	CommentsCount int
+}
+
+// FeedClub is a club the reader belongs to.
+type FeedClub struct {
+	ClubID       uint
+	MembersCount int
+}
+
+type HomeFeedRequest struct {
+	pagination.Request
+	Refresh        bool `form:"refresh"`
+	RevealSpoilers bool `form:"reveal_spoilers"`
+}
+
+type FeedItem struct {
+	Post   PostResponse `json:"post"`
+	Reason string       `json:"reason"`
+}
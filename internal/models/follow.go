@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// UserFollow means FollowerID wants FolloweeID's posts in their home feed.
+type UserFollow struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	FollowerID uint      `json:"follower_id" gorm:"not null;uniqueIndex:idx_user_follow"`
+	FolloweeID uint      `json:"followee_id" gorm:"not null;uniqueIndex:idx_user_follow;index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
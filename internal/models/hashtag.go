@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+)
+
+// PostHashtag indexes one normalized #tag used in a post's content.
+type PostHashtag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	PostID    uint      `json:"post_id" gorm:"not null;uniqueIndex:idx_post_hashtag"`
+	Tag       string    `json:"tag" gorm:"size:50;not null;uniqueIndex:idx_post_hashtag;index"`
+	ClubID    uint      `json:"club_id" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Post Post `json:"-" gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE" swaggerignore:"true"`
+}
+
+// HashtagUse is one published post using a tag, for trending scores.
+type HashtagUse struct {
+	Tag         string
+	PublishedAt time.Time
+}
+
+type ListHashtagPostsRequest struct {
+	pagination.Request
+	RevealSpoilers bool `form:"reveal_spoilers"`
+}
+
+type HashtagPostsResponse struct {
+	Tag string `json:"tag"`
+	pagination.Page[PostResponse]
+}
+
+type TrendingHashtagsRequest struct {
+	Limit int `form:"limit" validate:"omitempty,gte=1,lte=50"`
+}
+
+type TrendingHashtag struct {
+	Tag       string  `json:"tag"`
+	Score     float64 `json:"score"`
+	PostCount int     `json:"post_count"`
+}
+
+type TrendingHashtagsResponse struct {
+	Hashtags    []TrendingHashtag `json:"hashtags"`
+	WindowHours int               `json:"window_hours"`
+}
@@ -25,3 +25,19 @@ type LocationSearchResponse struct {
 	CityName string `json:"city_name,omitempty"`
 	CityID   string `json:"city_id,omitempty"`
 }
+
+type LocationRecord struct {
+	ID       uint
+	Location string
+}
+
+type LocationBackfillStats struct {
+	Scanned  int `json:"scanned"`
+	Resolved int `json:"resolved"`
+}
+
+type LocationBackfillResponse struct {
+	Users  LocationBackfillStats `json:"users"`
+	Clubs  LocationBackfillStats `json:"clubs"`
+	Events LocationBackfillStats `json:"events"`
+}
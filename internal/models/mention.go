@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"github.com/nevzattalhaozcan/forgotten/pkg/utils"
+)
+
+const (
+	MentionTargetPost    = "post"
+	MentionTargetComment = "comment"
+)
+
+// Mention records that a post or comment mentions a user. PostID is the post
+// itself or the post the comment belongs to.
+type Mention struct {
+	ID              uint   `json:"id" gorm:"primaryKey"`
+	TargetType      string `json:"target_type" gorm:"size:20;not null;uniqueIndex:idx_mention_unique"`
+	TargetID        uint   `json:"target_id" gorm:"not null;uniqueIndex:idx_mention_unique"`
+	MentionedUserID uint   `json:"mentioned_user_id" gorm:"not null;uniqueIndex:idx_mention_unique;index"`
+	PostID          uint   `json:"post_id" gorm:"not null;index"`
+	ClubID          uint   `json:"club_id" gorm:"not null"`
+	AuthorID        uint   `json:"author_id" gorm:"not null"`
+
+	MentionedUser User `json:"-" gorm:"foreignKey:MentionedUserID" swaggerignore:"true"`
+	Author        User `json:"-" gorm:"foreignKey:AuthorID" swaggerignore:"true"`
+	Post          Post `json:"-" gorm:"foreignKey:PostID" swaggerignore:"true"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MentionEntity is one resolved @username in a post or comment. Start and End
+// are rune offsets into content.
+type MentionEntity struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+}
+
+// mentionEntities locates the stored mentions in content. Usernames that were
+// not resolved, or that changed since, are left out.
+func mentionEntities(content string, mentions []Mention) []MentionEntity {
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	users := make(map[string]uint, len(mentions))
+	for _, m := range mentions {
+		if m.MentionedUser.Username != "" {
+			users[m.MentionedUser.Username] = m.MentionedUserID
+		}
+	}
+
+	var entities []MentionEntity
+	for _, match := range utils.ParseMentions(content) {
+		if userID, ok := users[match.Username]; ok {
+			entities = append(entities, MentionEntity{
+				UserID:   userID,
+				Username: match.Username,
+				Start:    match.Start,
+				End:      match.End,
+			})
+		}
+	}
+	return entities
+}
+
+type ListMentionsRequest struct {
+	pagination.Request
+}
+
+type MentionResponse struct {
+	ID         uint        `json:"id"`
+	TargetType string      `json:"target_type"`
+	PostID     uint        `json:"post_id"`
+	PostTitle  string      `json:"post_title"`
+	CommentID  *uint       `json:"comment_id,omitempty"`
+	ClubID     uint        `json:"club_id"`
+	Author     UserSummary `json:"author"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+func (m *Mention) ToResponse() MentionResponse {
+	resp := MentionResponse{
+		ID:         m.ID,
+		TargetType: m.TargetType,
+		PostID:     m.PostID,
+		PostTitle:  m.Post.Title,
+		ClubID:     m.ClubID,
+		Author: UserSummary{
+			ID:        m.Author.ID,
+			Username:  m.Author.Username,
+			AvatarURL: m.Author.AvatarURL,
+		},
+		CreatedAt: m.CreatedAt,
+	}
+	if m.TargetType == MentionTargetComment {
+		commentID := m.TargetID
+		resp.CommentID = &commentID
+	}
+	return resp
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+)
+
+const (
+	NotificationReportResolved   = "report_resolved"
+	NotificationModerationWarn   = "moderation_warning"
+	NotificationAccountSuspended = "account_suspended"
+	NotificationContentApproved  = "content_approved"
+	NotificationPostScheduled    = "scheduled_post_published"
+	NotificationClubPost         = "club_post_published"
+)
+
+// Notification is a message to a user about something that happened to them
+// or their content. TargetType and TargetID point at what it is about.
+type Notification struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"not null;index:idx_notifications_user"`
+	Type       string     `json:"type" gorm:"size:50;not null"`
+	Message    string     `json:"message" gorm:"type:text;not null"`
+	TargetType string     `json:"target_type,omitempty" gorm:"size:20"`
+	TargetID   uint       `json:"target_id,omitempty"`
+	ReadAt     *time.Time `json:"read_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ListNotificationsRequest struct {
+	pagination.Request
+	Unread bool `form:"unread"`
+}
+
+// MarkNotificationsReadRequest marks the listed notifications as read, or
+// all of them when IDs is empty.
+type MarkNotificationsReadRequest struct {
+	IDs []uint `json:"ids" validate:"omitempty,dive,gt=0"`
+}
@@ -6,32 +6,117 @@ import (
 	"errors"
 	"time"
 
+	"github.com/nevzattalhaozcan/forgotten/pkg/markdown"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
 )
 
 type Post struct {
-	ID            uint         `json:"id" gorm:"primaryKey"`
-	Title         string       `json:"title" gorm:"size:255;not null"`
-	Content       string       `json:"content" gorm:"type:text;not null"`
-	Type          string       `json:"type" gorm:"not null" validate:"required,oneof=discussion announcement post poll review annotation" default:"discussion"`
-	TypeData      PostTypeData `json:"type_data,omitempty" gorm:"type:jsonb" swaggertype:"object"`
-	IsPinned      bool         `json:"is_pinned" gorm:"default:false"`
-	LikesCount    int          `json:"likes_count" gorm:"default:0"`
-	CommentsCount int          `json:"comments_count" gorm:"default:0"`
-	ViewsCount    int          `json:"views_count" gorm:"default:0"`
-	UserID        uint         `json:"user_id"`
-	ClubID        uint         `json:"club_id"`
-
-	Club     Club       `json:"club" gorm:"foreignKey:ClubID" swaggerignore:"true"`
-	User     User       `json:"user" gorm:"foreignKey:UserID" swaggerignore:"true"`
-	Comments []Comment  `json:"comments,omitempty" gorm:"foreignKey:PostID" swaggerignore:"true"`
-	Likes    []PostLike `json:"likes,omitempty" gorm:"foreignKey:PostID" swaggerignore:"true"`
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	Title          string         `json:"title" gorm:"size:255;not null"`
+	Content        string         `json:"content" gorm:"type:text;not null"`
+	ContentHTML    string         `json:"-" gorm:"type:text"`
+	Excerpt        string         `json:"-" gorm:"size:320"`
+	RenderVersion  int            `json:"-" gorm:"default:0"`
+	Type           string         `json:"type" gorm:"not null" validate:"required,oneof=discussion announcement post poll review annotation" default:"discussion"`
+	TypeData       PostTypeData   `json:"type_data,omitempty" gorm:"type:jsonb" swaggertype:"object"`
+	IsPinned       bool           `json:"is_pinned" gorm:"default:false"`
+	LikesCount     int            `json:"likes_count" gorm:"default:0"`
+	ReactionCounts ReactionCounts `json:"reaction_counts,omitempty" gorm:"type:jsonb" swaggertype:"object"`
+	CommentsCount  int            `json:"comments_count" gorm:"default:0"`
+	ViewsCount     int            `json:"views_count" gorm:"default:0"`
+	ShareCount     int            `json:"share_count" gorm:"default:0"`
+	HotScore       float64        `json:"-" gorm:"default:0"`
+	EditCount      int            `json:"edit_count" gorm:"default:0"`
+	EditedAt       *time.Time     `json:"edited_at,omitempty"`
+	Status         string         `json:"status" gorm:"size:20;not null;default:'published';index"`
+	PublishAt      *time.Time     `json:"publish_at,omitempty" gorm:"index"`
+	PublishedAt    *time.Time     `json:"published_at,omitempty"`
+	UserID         uint           `json:"user_id"`
+	ClubID         uint           `json:"club_id"`
+	// SharedPostID is the post a reshare, a post of type post, points at.
+	// It is kept when the original is deleted so the reshare can say so.
+	SharedPostID *uint `json:"shared_post_id,omitempty" gorm:"index"`
+
+	Club        Club         `json:"club" gorm:"foreignKey:ClubID" swaggerignore:"true"`
+	User        User         `json:"user" gorm:"foreignKey:UserID" swaggerignore:"true"`
+	Comments    []Comment    `json:"comments,omitempty" gorm:"foreignKey:PostID" swaggerignore:"true"`
+	Mentions    []Mention    `json:"-" gorm:"polymorphic:Target;polymorphicValue:post" swaggerignore:"true"`
+	Attachments []Attachment `json:"attachments,omitempty" gorm:"foreignKey:PostID" swaggerignore:"true"`
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+	// PostStatusHidden is set by moderators. Hidden posts drop out of every
+	// listing, like drafts, but are kept for review.
+	PostStatusHidden = "hidden"
+	// PostStatusHeld is set by the content filter. Held posts are unlisted
+	// until a moderator approves them, which publishes them.
+	PostStatusHeld = "held"
+)
+
+// How public and club feeds are sorted. Hot decays engagement with age, top
+// is the most liked and commented within a time window, new is newest first.
+const (
+	PostSortHot = "hot"
+	PostSortTop = "top"
+	PostSortNew = "new"
+)
+
+// Time windows for PostSortTop.
+const (
+	TopWindowDay   = "day"
+	TopWindowWeek  = "week"
+	TopWindowMonth = "month"
+	TopWindowAll   = "all"
+)
+
+type PostListRequest struct {
+	pagination.Request
+	Sort   string `form:"sort" validate:"omitempty,oneof=hot top new"`
+	Window string `form:"window" validate:"omitempty,oneof=day week month all"`
+}
+
+// PostListOptions is a PostListRequest resolved against the current time.
+type PostListOptions struct {
+	Sort  string
+	Since *time.Time // only posts published since, for top
+}
+
+// PostEngagement is what a post's hot score is computed from.
+type PostEngagement struct {
+	PostID         uint
+	PublishedAt    time.Time
+	LikesCount     int
+	CommentsCount  int
+	ViewsCount     int
+	ReactionsCount int // reactions other than ❤️, which LikesCount already counts
+}
+
+// PostExcerptLength is the length, in runes, of the plain-text excerpt shown
+// in post summaries.
+const PostExcerptLength = 280
+
+// RenderContent refreshes the sanitized HTML and excerpt stored next to the
+// Markdown source. Call it whenever Content changes.
+func (p *Post) RenderContent() {
+	out := markdown.Render(p.Content)
+	p.ContentHTML = out.HTML
+	p.Excerpt = markdown.Excerpt(out.Text, PostExcerptLength)
+	p.RenderVersion = markdown.RenderVersion
+}
+
+// IsPublished reports whether the post is visible to anyone but its author.
+func (p *Post) IsPublished() bool {
+	return p.Status == "" || p.Status == PostStatusPublished
+}
+
 type PostTypeData json.RawMessage
 
 func (ptd *PostTypeData) Scan(value interface{}) error {
@@ -54,25 +139,35 @@ func (ptd PostTypeData) Value() (driver.Value, error) {
 	return []byte(ptd), nil
 }
 
-type PostLike struct {
-	ID     uint `json:"id" gorm:"primaryKey"`
-	UserID uint `json:"user_id" gorm:"uniqueIndex:idx_user_post_like"`
-	PostID uint `json:"post_id" gorm:"uniqueIndex:idx_user_post_like"`
-	User   User `json:"user" gorm:"foreignKey:UserID" swaggerignore:"true"`
-	Post   Post `json:"post" gorm:"foreignKey:PostID" swaggerignore:"true"`
+// PollVote is one option a user chose. On ranked-choice polls a ballot is a
+// vote per ranked option, Rank 1 being the first preference; plurality votes
+// have no rank.
+type PollVote struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	PostID   uint   `json:"post_id" gorm:"index;uniqueIndex:idx_poll_votes_voter_option"`
+	UserID   uint   `json:"user_id" gorm:"index;uniqueIndex:idx_poll_votes_voter_option"`
+	OptionID string `json:"option_id" gorm:"uniqueIndex:idx_poll_votes_voter_option"`
+	Rank     int    `json:"rank,omitempty" gorm:"default:0"`
+
+	User User `json:"-" gorm:"foreignKey:UserID" swaggerignore:"true"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-type PollVote struct {
-	ID       uint   `json:"id" gorm:"primaryKey"`
-	PostID   uint   `json:"post_id" gorm:"index"`
-	UserID   uint   `json:"user_id" gorm:"index"`
-	OptionID string `json:"option_id"`
+// Counted reports whether the vote moves its option's counter: every
+// plurality vote does, and on ranked-choice polls only first preferences.
+func (v PollVote) Counted() bool {
+	return v.Rank <= 1
+}
 
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+// PollOptionCount is the running number of counted votes for one poll
+// option. It changes in the same transaction as the votes themselves, so
+// counts can be read without going through every vote.
+type PollOptionCount struct {
+	PostID   uint   `json:"post_id" gorm:"primaryKey;autoIncrement:false"`
+	OptionID string `json:"option_id" gorm:"primaryKey;size:255"`
+	Votes    int    `json:"votes" gorm:"not null;default:0"`
 }
 
 type ReviewData struct {
@@ -82,11 +177,48 @@ type ReviewData struct {
 	BookAuthor string  `json:"book_author,omitempty"`
 }
 
+// How a poll is decided. Plurality polls count one vote per chosen option;
+// ranked-choice polls take ranked ballots and are decided by instant runoff.
+const (
+	PollMethodPlurality    = "plurality"
+	PollMethodRankedChoice = "ranked_choice"
+)
+
+// Who can see who voted for what. Public polls list their voters; anonymous
+// ones never show them, not even to the author.
+const (
+	PollVotesPublic    = "public"
+	PollVotesAnonymous = "anonymous"
+)
+
+// When a poll's results are shown: always, to those who voted (and to
+// everyone once the poll closes), or only once it closes.
+const (
+	PollResultsAlways     = "always"
+	PollResultsAfterVote  = "after_vote"
+	PollResultsAfterClose = "after_close"
+)
+
+// PollData is the type data of a poll post. Vote counts are not stored in it:
+// Options[].Votes is filled in from the option counters when a poll is read,
+// and only where the reader may see results. ClosedAt, ClosedBy and
+// FinalResults are set when the poll closes, by hand or on expiry.
 type PollData struct {
-	Question      string       `json:"question" validate:"required,min=1,max=500"`
-	Options       []PollOption `json:"options" validate:"required,min=2,max=10,dive"`
-	AllowMultiple bool         `json:"allow_multiple"`
-	ExpiresAt     *time.Time   `json:"expires_at,omitempty"`
+	Question          string       `json:"question" validate:"required,min=1,max=500"`
+	Options           []PollOption `json:"options" validate:"required,min=2,max=10,dive"`
+	AllowMultiple     bool         `json:"allow_multiple"`
+	ExpiresAt         *time.Time   `json:"expires_at,omitempty"`
+	Method            string       `json:"method" validate:"omitempty,oneof=plurality ranked_choice"`
+	VoteVisibility    string       `json:"vote_visibility" validate:"omitempty,oneof=public anonymous"`
+	ResultsVisibility string       `json:"results_visibility" validate:"omitempty,oneof=always after_vote after_close"`
+	ClosedAt          *time.Time   `json:"closed_at,omitempty"`
+	ClosedBy          *uint        `json:"closed_by,omitempty"`
+	FinalResults      *PollResults `json:"final_results,omitempty"`
+}
+
+// IsClosed reports whether the poll was closed or has expired.
+func (d *PollData) IsClosed(now time.Time) bool {
+	return d.ClosedAt != nil || (d.ExpiresAt != nil && !d.ExpiresAt.After(now))
 }
 
 type PollOption struct {
@@ -95,6 +227,68 @@ type PollOption struct {
 	Votes int    `json:"votes"`
 }
 
+// PollResults is a poll's tally. On ranked-choice polls option votes are
+// first preferences and Rounds shows the instant runoff; several winners mean
+// a tie.
+type PollResults struct {
+	TotalVoters int                `json:"total_voters"`
+	Options     []PollOptionResult `json:"options"`
+	Rounds      []PollRound        `json:"rounds,omitempty"`
+	WinnerIDs   []string           `json:"winner_ids"`
+}
+
+type PollOptionResult struct {
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+	Votes int    `json:"votes"`
+}
+
+// PollRound is one round of an instant runoff: the ballots counted for each
+// option still running, the options eliminated after it, and the ballots
+// with no option left running.
+type PollRound struct {
+	Counts     map[string]int `json:"counts"`
+	Eliminated []string       `json:"eliminated,omitempty"`
+	Exhausted  int            `json:"exhausted"`
+}
+
+// PollView is a poll as one viewer sees it. Results is nil while they are
+// hidden from the viewer.
+type PollView struct {
+	Method            string       `json:"method"`
+	VoteVisibility    string       `json:"vote_visibility"`
+	ResultsVisibility string       `json:"results_visibility"`
+	Closed            bool         `json:"closed"`
+	ClosedAt          *time.Time   `json:"closed_at,omitempty"`
+	ResultsVisible    bool         `json:"results_visible"`
+	Results           *PollResults `json:"results,omitempty"`
+	UserVotes         []string     `json:"user_votes,omitempty"`
+}
+
+// PollVoter is a user who chose an option of a public poll.
+type PollVoter struct {
+	User    UserSummary `json:"user"`
+	Rank    int         `json:"rank,omitempty"`
+	VotedAt time.Time   `json:"voted_at"`
+}
+
+func (v PollVote) ToPollVoter() PollVoter {
+	return PollVoter{
+		User: UserSummary{
+			ID:        v.User.ID,
+			Username:  v.User.Username,
+			AvatarURL: v.User.AvatarURL,
+		},
+		Rank:    v.Rank,
+		VotedAt: v.CreatedAt,
+	}
+}
+
+type ListPollVotersRequest struct {
+	pagination.Request
+	OptionID string `form:"option_id" validate:"required"`
+}
+
 type AnnotationData struct {
 	BookID     uint   `json:"book_id" validate:"required"`
 	Page       *int   `json:"page,omitempty" validate:"omitempty,gte=1"`
@@ -104,10 +298,30 @@ type AnnotationData struct {
 	BookAuthor string `json:"book_author,omitempty"`
 }
 
+// PostData is the type data reshares were created with before they pointed
+// at the original through SharedPostID. Only PostID is still read.
 type PostData struct {
-	PostID      uint   `json:"post_id,omitempty"`
-	PostTitle   string `json:"post_title,omitempty"`
-	PostContent string `json:"post_content,omitempty"`
+	PostID uint `json:"post_id,omitempty"`
+}
+
+// SharedPost is the live preview of the post a reshare points at, as the
+// viewer may see it. An original that was deleted or taken down is Removed,
+// and one in a club the viewer cannot read is Unavailable; neither shows any
+// of its content. Collapsed is set, and the excerpt left out, when the
+// original is a spoiler for the viewer.
+type SharedPost struct {
+	ID          uint         `json:"id"`
+	Removed     bool         `json:"removed,omitempty"`
+	Unavailable bool         `json:"unavailable,omitempty"`
+	Title       string       `json:"title,omitempty"`
+	Excerpt     string       `json:"excerpt,omitempty"`
+	Type        string       `json:"type,omitempty"`
+	ShareCount  int          `json:"share_count,omitempty"`
+	User        *UserSummary `json:"user,omitempty"`
+	Club        *ClubSummary `json:"club,omitempty"`
+	Spoiler     *SpoilerInfo `json:"spoiler,omitempty"`
+	Collapsed   bool         `json:"collapsed,omitempty"`
+	CreatedAt   *time.Time   `json:"created_at,omitempty"`
 }
 
 type UserSummary struct {
@@ -132,24 +346,36 @@ type CommentSummary struct {
 }
 
 type PostSummary struct {
-	ID            uint             `json:"id" gorm:"column:id"`
-	Title         string           `json:"title" gorm:"column:title"`
-	Content       string           `json:"content" gorm:"column:content"`
-	Type          string           `json:"type" gorm:"column:type"`
-	TypeData      interface{}      `json:"type_data,omitempty"`
-	IsPinned      bool             `json:"is_pinned" gorm:"column:is_pinned"`
-	LikesCount    int              `json:"likes_count" gorm:"column:likes_count"`
-	CommentsCount int              `json:"comments_count" gorm:"column:comments_count"`
-	ViewsCount    int              `json:"views_count" gorm:"column:views_count"`
-	HasUserLiked  bool             `json:"has_user_liked,omitempty" gorm:"-"`
-	UserID        uint             `json:"user_id" gorm:"column:post_user_id"`
-	ClubID        *uint            `json:"club_id" gorm:"column:post_club_id"`
-	User          UserSummary      `json:"user"`
-	Club          *ClubSummary     `json:"club,omitempty"`
-	Likes         []LikeSummary    `json:"likes,omitempty"`
-	Comments      []CommentSummary `json:"comments,omitempty"`
-	CreatedAt     time.Time        `json:"created_at" gorm:"column:created_at"`
-	UpdatedAt     time.Time        `json:"updated_at" gorm:"column:updated_at"`
+	ID            uint                 `json:"id" gorm:"column:id"`
+	Title         string               `json:"title" gorm:"column:title"`
+	Content       string               `json:"content" gorm:"column:content"`
+	ContentHTML   string               `json:"content_html" gorm:"column:content_html"`
+	Excerpt       string               `json:"excerpt" gorm:"column:excerpt"`
+	Type          string               `json:"type" gorm:"column:type"`
+	TypeData      interface{}          `json:"type_data,omitempty"`
+	IsPinned      bool                 `json:"is_pinned" gorm:"column:is_pinned"`
+	LikesCount    int                  `json:"likes_count" gorm:"column:likes_count"`
+	CommentsCount int                  `json:"comments_count" gorm:"column:comments_count"`
+	ViewsCount    int                  `json:"views_count" gorm:"column:views_count"`
+	ShareCount    int                  `json:"share_count" gorm:"column:share_count"`
+	Edited        bool                 `json:"edited" gorm:"-"`
+	EditCount     int                  `json:"edit_count" gorm:"column:edit_count"`
+	HasUserLiked  bool                 `json:"has_user_liked,omitempty" gorm:"-"`
+	Bookmarked    bool                 `json:"bookmarked" gorm:"-"`
+	Reactions     []ReactionSummary    `json:"reactions,omitempty" gorm:"-"`
+	Spoiler       *SpoilerInfo         `json:"spoiler,omitempty" gorm:"-"`
+	Collapsed     bool                 `json:"collapsed,omitempty" gorm:"-"`
+	Attachments   []AttachmentResponse `json:"attachments,omitempty" gorm:"-"`
+	SharedPostID  *uint                `json:"shared_post_id,omitempty" gorm:"column:shared_post_id"`
+	SharedPost    *SharedPost          `json:"shared_post,omitempty" gorm:"-"`
+	UserID        uint                 `json:"user_id" gorm:"column:post_user_id"`
+	ClubID        *uint                `json:"club_id" gorm:"column:post_club_id"`
+	User          UserSummary          `json:"user"`
+	Club          *ClubSummary         `json:"club,omitempty"`
+	Likes         []LikeSummary        `json:"likes,omitempty"`
+	Comments      []CommentSummary     `json:"comments,omitempty"`
+	CreatedAt     time.Time            `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt     time.Time            `json:"updated_at" gorm:"column:updated_at"`
 }
 
 func (p *Post) GetReviewData() (*ReviewData, error) {
@@ -194,6 +420,14 @@ type CreatePostRequest struct {
 	Type     string      `json:"type" validate:"required,oneof=discussion announcement post poll review annotation"`
 	ClubID   uint        `json:"club_id" validate:"required"`
 	TypeData interface{} `json:"type_data,omitempty"`
+	// Status defaults to published. Scheduled posts need a future PublishAt.
+	Status    string     `json:"status,omitempty" validate:"omitempty,oneof=draft scheduled published"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// AttachmentIDs are files the author uploaded to the club, in the order
+	// they are shown.
+	AttachmentIDs []uint `json:"attachment_ids,omitempty" validate:"omitempty,dive,gt=0"`
+	// SharedPostID is the post to reshare; posts of type post need one.
+	SharedPostID *uint `json:"shared_post_id,omitempty" validate:"omitempty,gt=0"`
 }
 
 type UpdatePostRequest struct {
@@ -203,31 +437,54 @@ type UpdatePostRequest struct {
 	ClubID   *uint       `json:"club_id,omitempty" validate:"omitempty"`
 	IsPinned *bool       `json:"is_pinned,omitempty"`
 	TypeData interface{} `json:"type_data,omitempty"`
+	// Status and PublishAt can only change while the post is unpublished.
+	Status    *string    `json:"status,omitempty" validate:"omitempty,oneof=draft scheduled published"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// AttachmentIDs replaces the post's attachments; an empty list removes
+	// them all.
+	AttachmentIDs *[]uint `json:"attachment_ids,omitempty" validate:"omitempty,dive,gt=0"`
 }
 
 type PostResponse struct {
-	ID            uint        `json:"id"`
-	Title         string      `json:"title"`
-	Content       string      `json:"content"`
-	Type          string      `json:"type"`
-	TypeData      interface{} `json:"type_data,omitempty"`
-	IsPinned      bool        `json:"is_pinned"`
-	LikesCount    int         `json:"likes_count"`
-	CommentsCount int         `json:"comments_count"`
-	ViewsCount    int         `json:"views_count"`
-	UserID        uint        `json:"user_id"`
-	ClubID        uint        `json:"club_id"`
-	User          User        `json:"user" swaggerignore:"true"`
-	Comments      []Comment   `json:"comments,omitempty" swaggerignore:"true"`
-	Likes         []PostLike  `json:"likes,omitempty" swaggerignore:"true"`
-
-	UserVoted bool     `json:"user_voted,omitempty"`
-	UserVotes []string `json:"user_votes,omitempty"`
+	ID            uint                 `json:"id"`
+	Title         string               `json:"title"`
+	Content       string               `json:"content"`
+	ContentHTML   string               `json:"content_html"`
+	Type          string               `json:"type"`
+	TypeData      interface{}          `json:"type_data,omitempty"`
+	IsPinned      bool                 `json:"is_pinned"`
+	LikesCount    int                  `json:"likes_count"`
+	Reactions     []ReactionSummary    `json:"reactions,omitempty"`
+	CommentsCount int                  `json:"comments_count"`
+	ViewsCount    int                  `json:"views_count"`
+	ShareCount    int                  `json:"share_count"`
+	Edited        bool                 `json:"edited"`
+	EditCount     int                  `json:"edit_count"`
+	EditedAt      *time.Time           `json:"edited_at,omitempty"`
+	Status        string               `json:"status"`
+	PublishAt     *time.Time           `json:"publish_at,omitempty"`
+	PublishedAt   *time.Time           `json:"published_at,omitempty"`
+	UserID        uint                 `json:"user_id"`
+	ClubID        uint                 `json:"club_id"`
+	User          User                 `json:"user" swaggerignore:"true"`
+	Comments      []Comment            `json:"comments,omitempty" swaggerignore:"true"`
+	Mentions      []MentionEntity      `json:"mentions,omitempty"`
+	Spoiler       *SpoilerInfo         `json:"spoiler,omitempty"`
+	Collapsed     bool                 `json:"collapsed,omitempty"`
+	Attachments   []AttachmentResponse `json:"attachments,omitempty"`
+	SharedPostID  *uint                `json:"shared_post_id,omitempty"`
+	SharedPost    *SharedPost          `json:"shared_post,omitempty"`
+
+	UserVoted bool      `json:"user_voted,omitempty"`
+	UserVotes []string  `json:"user_votes,omitempty"`
+	Poll      *PollView `json:"poll,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// PollVoteRequest casts a vote. On ranked-choice polls OptionIDs is the
+// ballot in order of preference and replaces any earlier ballot.
 type PollVoteRequest struct {
 	OptionIDs []string `json:"option_ids" validate:"required,min=1"`
 }
@@ -238,11 +495,12 @@ type PostLikeResponse struct {
 	CreatedAt time.Time    `json:"created_at"`
 }
 
-func (pl PostLike) ToResponse() PostLikeResponse {
+// ToPostLikeResponse keeps the legacy likes listing working on top of ❤️ reactions.
+func (r Reaction) ToPostLikeResponse() PostLikeResponse {
 	return PostLikeResponse{
-		ID:        pl.ID,
-		User:      pl.User.ToResponse(),
-		CreatedAt: pl.CreatedAt,
+		ID:        r.ID,
+		User:      r.User.ToResponse(),
+		CreatedAt: r.CreatedAt,
 	}
 }
 
@@ -251,16 +509,27 @@ func (p *Post) ToResponse() PostResponse {
 		ID:            p.ID,
 		Title:         p.Title,
 		Content:       p.Content,
+		ContentHTML:   p.ContentHTML,
 		Type:          p.Type,
 		IsPinned:      p.IsPinned,
 		LikesCount:    p.LikesCount,
+		Reactions:     p.ReactionCounts.Summaries(nil),
 		CommentsCount: p.CommentsCount,
 		ViewsCount:    p.ViewsCount,
+		ShareCount:    p.ShareCount,
+		Edited:        p.EditCount > 0,
+		EditCount:     p.EditCount,
+		EditedAt:      p.EditedAt,
+		Status:        p.Status,
+		PublishAt:     p.PublishAt,
+		PublishedAt:   p.PublishedAt,
 		UserID:        p.UserID,
 		ClubID:        p.ClubID,
 		User:          p.User,
 		Comments:      p.Comments,
-		Likes:         p.Likes,
+		Mentions:      mentionEntities(p.Content, p.Mentions),
+		Attachments:   attachmentResponses(p.Attachments),
+		SharedPostID:  p.SharedPostID,
 		CreatedAt:     p.CreatedAt,
 		UpdatedAt:     p.UpdatedAt,
 	}
@@ -273,5 +542,11 @@ func (p *Post) ToResponse() PostResponse {
 			response.TypeData = nil
 		}
 	}
+
+	// rows rendered under an older policy are re-rendered until the backfill
+	// catches up
+	if p.RenderVersion < markdown.RenderVersion {
+		response.ContentHTML = markdown.Render(p.Content).HTML
+	}
 	return response
 }
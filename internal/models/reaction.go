@@ -0,0 +1,121 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+)
+
+const (
+	ReactionTargetPost    = "post"
+	ReactionTargetComment = "comment"
+
+	// LikeReaction is what the legacy like endpoints add and remove.
+	LikeReaction = "❤️"
+)
+
+type Reaction struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	TargetType string `json:"target_type" gorm:"size:20;not null;uniqueIndex:idx_reaction_unique;index:idx_reaction_target"`
+	TargetID   uint   `json:"target_id" gorm:"not null;uniqueIndex:idx_reaction_unique;index:idx_reaction_target"`
+	UserID     uint   `json:"user_id" gorm:"not null;uniqueIndex:idx_reaction_unique;index"`
+	Emoji      string `json:"emoji" gorm:"size:32;not null;uniqueIndex:idx_reaction_unique"`
+	User       User   `json:"user" gorm:"foreignKey:UserID" swaggerignore:"true"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReactionCounts is the per-emoji count denormalized onto posts and comments.
+type ReactionCounts map[string]int
+
+func (rc *ReactionCounts) Scan(value interface{}) error {
+	if value == nil {
+		*rc = nil
+		return nil
+	}
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("cannot scan into ReactionCounts")
+	}
+	if len(bytes) == 0 {
+		*rc = nil
+		return nil
+	}
+	return json.Unmarshal(bytes, rc)
+}
+
+func (rc ReactionCounts) Value() (driver.Value, error) {
+	if len(rc) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(rc)
+}
+
+// Summaries lists the reactions with the most used first. userReactions marks
+// the ones the current user has added.
+func (rc ReactionCounts) Summaries(userReactions []string) []ReactionSummary {
+	reacted := make(map[string]bool, len(userReactions))
+	for _, emoji := range userReactions {
+		reacted[emoji] = true
+	}
+
+	out := make([]ReactionSummary, 0, len(rc))
+	for emoji, count := range rc {
+		if count <= 0 {
+			continue
+		}
+		out = append(out, ReactionSummary{Emoji: emoji, Count: count, Reacted: reacted[emoji]})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Emoji < out[j].Emoji
+	})
+	return out
+}
+
+type ReactionSummary struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	Reacted bool   `json:"reacted,omitempty"`
+}
+
+type ReactionRequest struct {
+	Emoji string `json:"emoji" form:"emoji" validate:"required,max=32"`
+}
+
+type ListReactionsRequest struct {
+	pagination.Request
+	Emoji string `form:"emoji" validate:"omitempty,max=32"`
+}
+
+type ReactionResponse struct {
+	ID        uint        `json:"id"`
+	Emoji     string      `json:"emoji"`
+	User      UserSummary `json:"user"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+func (r Reaction) ToResponse() ReactionResponse {
+	return ReactionResponse{
+		ID:    r.ID,
+		Emoji: r.Emoji,
+		User: UserSummary{
+			ID:        r.User.ID,
+			Username:  r.User.Username,
+			AvatarURL: r.User.AvatarURL,
+		},
+		CreatedAt: r.CreatedAt,
+	}
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+)
+
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+	ReportTargetRating  = "rating"
+	ReportTargetUser    = "user"
+)
+
+const (
+	ReportReasonSpam          = "spam"
+	ReportReasonHarassment    = "harassment"
+	ReportReasonHate          = "hate"
+	ReportReasonSpoiler       = "spoiler"
+	ReportReasonInappropriate = "inappropriate"
+	ReportReasonOther         = "other"
+	// ReportReasonContentFilter marks reports filed by the content filter,
+	// which have no reporter.
+	ReportReasonContentFilter = "content_filter"
+)
+
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+const (
+	ModerationDismiss = "dismiss"
+	ModerationHide    = "hide"
+	ModerationDelete  = "delete"
+	ModerationWarn    = "warn"
+	ModerationSuspend = "suspend"
+)
+
+// PlatformModeratorRoles are the user roles that moderate every report, not
+// only those of clubs they moderate.
+var PlatformModeratorRoles = []string{"moderator", "support", "admin", "superuser"}
+
+// Report flags a post, comment, club rating or user for moderation. A user
+// reports a target at most once; ReporterID is nil for reports filed by the
+// content filter. TargetUserID is the author of the reported
+// content, or the reported user; ClubID is the club the content belongs to
+// and nil for user reports, which only platform moderators see. Snapshot
+// keeps the content as it was when reported, since it may be edited or
+// deleted later.
+type Report struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	ReporterID   *uint      `json:"reporter_id" gorm:"uniqueIndex:idx_report_unique"`
+	TargetType   string     `json:"target_type" gorm:"size:20;not null;uniqueIndex:idx_report_unique;index:idx_report_target"`
+	TargetID     uint       `json:"target_id" gorm:"not null;uniqueIndex:idx_report_unique;index:idx_report_target"`
+	TargetUserID uint       `json:"target_user_id" gorm:"not null;index"`
+	ClubID       *uint      `json:"club_id,omitempty" gorm:"index"`
+	Reason       string     `json:"reason" gorm:"size:20;not null"`
+	Details      string     `json:"details,omitempty" gorm:"size:1000"`
+	Snapshot     string     `json:"snapshot" gorm:"type:text"`
+	Status       string     `json:"status" gorm:"size:20;not null;default:'open';index"`
+	Action       string     `json:"action,omitempty" gorm:"size:20"`
+	ResolvedBy   *uint      `json:"resolved_by,omitempty"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ModerationLog records a moderation decision. A decision settles every open
+// report on its target; ReportsCount says how many.
+type ModerationLog struct {
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	ModeratorID  uint   `json:"moderator_id" gorm:"not null;index"`
+	ReportID     uint   `json:"report_id" gorm:"not null;index"`
+	TargetType   string `json:"target_type" gorm:"size:20;not null"`
+	TargetID     uint   `json:"target_id" gorm:"not null"`
+	TargetUserID uint   `json:"target_user_id" gorm:"not null;index"`
+	ClubID       *uint  `json:"club_id,omitempty" gorm:"index"`
+	Action       string `json:"action" gorm:"size:20;not null"`
+	Note         string `json:"note,omitempty" gorm:"size:1000"`
+	ReportsCount int    `json:"reports_count"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateReportRequest struct {
+	TargetType string `json:"target_type" validate:"required,oneof=post comment rating user"`
+	TargetID   uint   `json:"target_id" validate:"required,gt=0"`
+	Reason     string `json:"reason" validate:"required,oneof=spam harassment hate spoiler inappropriate other"`
+	Details    string `json:"details" validate:"max=1000"`
+}
+
+// ListReportsRequest filters the moderation queue. Status defaults to open.
+type ListReportsRequest struct {
+	pagination.Request
+	Status     string `form:"status" validate:"omitempty,oneof=open resolved dismissed"`
+	TargetType string `form:"target_type" validate:"omitempty,oneof=post comment rating user"`
+	ClubID     *uint  `form:"club_id" validate:"omitempty,gt=0"`
+}
+
+// ResolveReportRequest decides a report. Hide and delete apply to the
+// reported content; warn and suspend to its author or the reported user.
+// SuspendDays is required to suspend.
+type ResolveReportRequest struct {
+	Action      string `json:"action" validate:"required,oneof=dismiss hide delete warn suspend"`
+	Note        string `json:"note" validate:"max=1000"`
+	SuspendDays int    `json:"suspend_days" validate:"required_if=Action suspend,gte=0,lte=365"`
+}
+
+type ListModerationLogRequest struct {
+	pagination.Request
+	ClubID *uint `form:"club_id" validate:"omitempty,gt=0"`
+}
+
+// ReportFilter narrows a report listing. A nil ClubIDs means reports of any
+// club and user reports; otherwise only reports of those clubs.
+type ReportFilter struct {
+	Status     string
+	TargetType string
+	ClubIDs    []uint
+}
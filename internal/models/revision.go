@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/pkg/utils"
+)
+
+const (
+	RevisionTargetPost    = "post"
+	RevisionTargetComment = "comment"
+)
+
+// Revision is one version of a post or comment. Version 1 is what was first
+// published; it is stored together with the first edit.
+type Revision struct {
+	ID         uint         `json:"id" gorm:"primaryKey"`
+	TargetType string       `json:"target_type" gorm:"size:20;not null;uniqueIndex:idx_revision_version"`
+	TargetID   uint         `json:"target_id" gorm:"not null;uniqueIndex:idx_revision_version"`
+	Version    int          `json:"version" gorm:"not null;uniqueIndex:idx_revision_version"`
+	Title      string       `json:"title,omitempty" gorm:"size:255"`
+	Content    string       `json:"content" gorm:"type:text;not null"`
+	TypeData   PostTypeData `json:"type_data,omitempty" gorm:"type:jsonb" swaggertype:"object"`
+	EditorID   uint         `json:"editor_id" gorm:"not null"`
+	Editor     User         `json:"editor" gorm:"foreignKey:EditorID" swaggerignore:"true"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RevisionResponse struct {
+	Version         int              `json:"version"`
+	Editor          UserSummary      `json:"editor"`
+	Title           string           `json:"title,omitempty"`
+	Content         string           `json:"content"`
+	TypeData        interface{}      `json:"type_data,omitempty"`
+	TitleChanged    bool             `json:"title_changed,omitempty"`
+	TypeDataChanged bool             `json:"type_data_changed,omitempty"`
+	Diff            []utils.DiffLine `json:"diff,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+}
+
+type RevisionHistoryResponse struct {
+	TargetType string             `json:"target_type"`
+	TargetID   uint               `json:"target_id"`
+	EditCount  int                `json:"edit_count"`
+	Revisions  []RevisionResponse `json:"revisions"`
+}
@@ -0,0 +1,96 @@
+package models
+
+import "encoding/json"
+
+const (
+	SpoilerReasonPage    = "page"    // the annotation is past the viewer's current page
+	SpoilerReasonChapter = "chapter" // the annotation names a chapter and the viewer has not finished the book
+	SpoilerReasonReview  = "review"  // the review covers a book the viewer has not finished
+)
+
+// SpoilerRef is the part of a book a post discusses. Page and Chapter are nil
+// when the post covers the whole book.
+type SpoilerRef struct {
+	BookID    uint
+	BookTitle string
+	Page      *int
+	Chapter   *int
+}
+
+// SpoilerInfo tells clients why a post is treated as a spoiler.
+type SpoilerInfo struct {
+	BookID    uint   `json:"book_id"`
+	BookTitle string `json:"book_title,omitempty"`
+	Page      *int   `json:"page,omitempty"`
+	Chapter   *int   `json:"chapter,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// SpoilerRefFromTypeData finds the book position a review or annotation
+// refers to. Annotations without a page or chapter are not spoilers.
+func SpoilerRefFromTypeData(postType string, typeData interface{}) *SpoilerRef {
+	if typeData == nil || (postType != "review" && postType != "annotation") {
+		return nil
+	}
+	raw, err := json.Marshal(typeData)
+	if err != nil {
+		return nil
+	}
+
+	switch postType {
+	case "review":
+		var data ReviewData
+		if err := json.Unmarshal(raw, &data); err != nil || data.BookID == 0 {
+			return nil
+		}
+		return &SpoilerRef{BookID: data.BookID, BookTitle: data.BookTitle}
+	default:
+		var data AnnotationData
+		if err := json.Unmarshal(raw, &data); err != nil || data.BookID == 0 {
+			return nil
+		}
+		if data.Page == nil && data.Chapter == nil {
+			return nil
+		}
+		return &SpoilerRef{BookID: data.BookID, BookTitle: data.BookTitle, Page: data.Page, Chapter: data.Chapter}
+	}
+}
+
+func (r *SpoilerRef) Info(reason string) *SpoilerInfo {
+	return &SpoilerInfo{
+		BookID:    r.BookID,
+		BookTitle: r.BookTitle,
+		Page:      r.Page,
+		Chapter:   r.Chapter,
+		Reason:    reason,
+	}
+}
+
+func (r *PostResponse) SpoilerRef() *SpoilerRef {
+	return SpoilerRefFromTypeData(r.Type, r.TypeData)
+}
+
+// Collapse hides everything in the post that could give the plot away. The
+// title and counters stay so the post can still be listed.
+func (r *PostResponse) Collapse() {
+	r.Collapsed = true
+	r.Content = ""
+	r.ContentHTML = ""
+	r.TypeData = nil
+	r.Mentions = nil
+	r.Comments = nil
+	r.Attachments = nil
+}
+
+func (s *PostSummary) SpoilerRef() *SpoilerRef {
+	return SpoilerRefFromTypeData(s.Type, s.TypeData)
+}
+
+func (s *PostSummary) Collapse() {
+	s.Collapsed = true
+	s.Content = ""
+	s.ContentHTML = ""
+	s.Excerpt = ""
+	s.TypeData = nil
+	s.Attachments = nil
+}
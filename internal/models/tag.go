@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Tag is a canonical club tag. Key is the normalized form used for matching
+// (lowercase, Turkish characters folded, separators removed).
+type Tag struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Name      string     `json:"name" gorm:"size:50;not null"`
+	Key       string     `json:"key" gorm:"size:50;not null;uniqueIndex"`
+	Aliases   []TagAlias `json:"aliases,omitempty" gorm:"foreignKey:TagID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TagAlias is an alternative spelling or a Turkish/English synonym of a tag.
+type TagAlias struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TagID     uint      `json:"tag_id" gorm:"index;not null"`
+	Alias     string    `json:"alias" gorm:"size:50;not null"`
+	Key       string    `json:"key" gorm:"size:50;not null;uniqueIndex"`
+	Language  string    `json:"language,omitempty" gorm:"size:5"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TagSearchRequest struct {
+	Query string `form:"q" validate:"omitempty,max=50"`
+	Limit int    `form:"limit" validate:"omitempty,gte=1,lte=50"`
+}
+
+type TagAliasRequest struct {
+	Alias    string `json:"alias" validate:"required,min=1,max=50"`
+	Language string `json:"language" validate:"omitempty,oneof=tr en"`
+}
+
+type CreateTagRequest struct {
+	Name    string            `json:"name" validate:"required,min=1,max=50"`
+	Aliases []TagAliasRequest `json:"aliases" validate:"omitempty,dive"`
+}
+
+type MergeTagsRequest struct {
+	TargetID  uint   `json:"target_id" validate:"required"`
+	SourceIDs []uint `json:"source_ids" validate:"required,min=1,dive,required"`
+}
+
+type TagResponse struct {
+	ID         uint     `json:"id"`
+	Name       string   `json:"name"`
+	Aliases    []string `json:"aliases"`
+	UsageCount int      `json:"usage_count"`
+}
+
+type MergeTagsResponse struct {
+	Tag          TagResponse `json:"tag"`
+	ClubsUpdated int64       `json:"clubs_updated"`
+}
+
+func (t *Tag) ToResponse(usageCount int) TagResponse {
+	aliases := make([]string, 0, len(t.Aliases))
+	for _, a := range t.Aliases {
+		aliases = append(aliases, a.Alias)
+	}
+	return TagResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Aliases:    aliases,
+		UsageCount: usageCount,
+	}
+}
@@ -54,6 +54,11 @@ const (
 	PREF_NOTIFICATIONS       = "notifications.enabled"
 	PREF_EMAIL_NOTIFICATIONS = "notifications.email"
 	PREF_PUSH_NOTIFICATIONS  = "notifications.push"
+
+	// spoiler protection collapses posts past the viewer's reading progress;
+	// protect_unstarted extends it to books the viewer has not started
+	PREF_SPOILER_PROTECTION        = "spoilers.protect"
+	PREF_SPOILER_PROTECT_UNSTARTED = "spoilers.protect_unstarted"
 )
 
 func DefaultUserPreferences() UserPreferences {
@@ -67,6 +72,9 @@ func DefaultUserPreferences() UserPreferences {
 		PREF_NOTIFICATIONS:       true,
 		PREF_EMAIL_NOTIFICATIONS: false,
 		PREF_PUSH_NOTIFICATIONS:  true,
+
+		PREF_SPOILER_PROTECTION:        true,
+		PREF_SPOILER_PROTECT_UNSTARTED: false,
 	}
 }
 
@@ -102,8 +110,13 @@ type User struct {
 	IsActive     bool   `json:"is_active" gorm:"default:true"`
 	Role         string `json:"role" validate:"required,oneof=admin user moderator support superuser" gorm:"default:'user'"`
 
+	// SuspendedUntil is set by moderators; the user cannot log in before it.
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+
 	AvatarURL      *string        `json:"avatar_url" gorm:"type:text"`
 	Location       *string        `json:"location" gorm:"size:255"`
+	CityID         *string        `json:"city_id" gorm:"size:10;index"`
+	DistrictID     *string        `json:"district_id" gorm:"size:10;index"`
 	FavoriteGenres pq.StringArray `json:"favorite_genres" gorm:"type:text[]" swaggertype:"array,string"`
 	Bio            *string        `json:"bio" gorm:"type:text"`
 	ReadingGoal    int            `json:"reading_goal" gorm:"default:0"`
@@ -118,8 +131,7 @@ type User struct {
 	ClubMemberships []ClubMembership `json:"club_memberships,omitempty" gorm:"foreignKey:UserID" swaggerignore:"true"`
 	Posts           []Post           `json:"posts,omitempty" gorm:"foreignKey:UserID" swaggerignore:"true"`
 	Comments        []Comment        `json:"comments,omitempty" gorm:"foreignKey:UserID" swaggerignore:"true"`
-	PostLikes       []PostLike       `json:"post_likes,omitempty" gorm:"foreignKey:UserID" swaggerignore:"true"`
-	CommentLikes    []CommentLike    `json:"comment_likes,omitempty" gorm:"foreignKey:UserID" swaggerignore:"true"`
+	Reactions       []Reaction       `json:"reactions,omitempty" gorm:"foreignKey:UserID" swaggerignore:"true"`
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -133,6 +145,8 @@ type PublicUserProfile struct {
 	LastName       string         `json:"last_name"`
 	AvatarURL      *string        `json:"avatar_url"`
 	Location       *string        `json:"location"`
+	CityID         *string        `json:"city_id,omitempty"`
+	DistrictID     *string        `json:"district_id,omitempty"`
 	FavoriteGenres pq.StringArray `json:"favorite_genres"`
 	Bio            *string        `json:"bio"`
 	BooksRead      int            `json:"books_read"`
@@ -156,8 +170,12 @@ type UserResponse struct {
 	IsActive  bool   `json:"is_active"`
 	Role      string `json:"role"`
 
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+
 	AvatarURL      *string        `json:"avatar_url"`
 	Location       *string        `json:"location"`
+	CityID         *string        `json:"city_id"`
+	DistrictID     *string        `json:"district_id"`
 	FavoriteGenres pq.StringArray `json:"favorite_genres" swaggertype:"array,string"`
 	Bio            *string        `json:"bio"`
 	ReadingGoal    *int           `json:"reading_goal"`
@@ -172,8 +190,7 @@ type UserResponse struct {
 	ClubMemberships []ClubMembership `json:"club_memberships,omitempty" swaggerignore:"true"`
 	Posts           []Post           `json:"posts,omitempty" swaggerignore:"true"`
 	Comments        []Comment        `json:"comments,omitempty" swaggerignore:"true"`
-	PostLikes       []PostLike       `json:"post_likes,omitempty" swaggerignore:"true"`
-	CommentLikes    []CommentLike    `json:"comment_likes,omitempty" swaggerignore:"true"`
+	Reactions       []Reaction       `json:"reactions,omitempty" swaggerignore:"true"`
 
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -193,6 +210,8 @@ type RegisterRequest struct {
 
 	AvatarURL      string   `json:"avatar_url"`
 	Location       string   `json:"location"`
+	CityID         string   `json:"city_id" validate:"omitempty,max=10"`
+	DistrictID     string   `json:"district_id" validate:"omitempty,max=10"`
 	FavoriteGenres []string `json:"favorite_genres"`
 	Bio            string   `json:"bio"`
 	ReadingGoal    int      `json:"reading_goal"`
@@ -209,6 +228,8 @@ type UpdateUserRequest struct {
 
 	AvatarURL      *string   `json:"avatar_url" validate:"omitempty,url"`
 	Location       *string   `json:"location" validate:"omitempty,max=255"`
+	CityID         *string   `json:"city_id" validate:"omitempty,max=10"`
+	DistrictID     *string   `json:"district_id" validate:"omitempty,max=10"`
 	FavoriteGenres *[]string `json:"favorite_genres"`
 	Bio            *string   `json:"bio" validate:"omitempty"`
 	ReadingGoal    *int      `json:"reading_goal" validate:"omitempty,gte=0"`
@@ -222,6 +243,8 @@ type UpdatePasswordRequest struct {
 type UpdateProfileRequest struct {
 	Bio            *string   `json:"bio,omitempty" validate:"omitempty"`
 	Location       *string   `json:"location,omitempty" validate:"omitempty,max=255"`
+	CityID         *string   `json:"city_id,omitempty" validate:"omitempty,max=10"`
+	DistrictID     *string   `json:"district_id,omitempty" validate:"omitempty,max=10"`
 	FavoriteGenres *[]string `json:"favorite_genres,omitempty"`
 	ReadingGoal    *int      `json:"reading_goal,omitempty" validate:"omitempty,gte=0"`
 }
@@ -254,8 +277,11 @@ func (u *User) ToResponse() UserResponse {
 		LastName:        u.LastName,
 		IsActive:        u.IsActive,
 		Role:            u.Role,
+		SuspendedUntil:  u.SuspendedUntil,
 		AvatarURL:       u.AvatarURL,
 		Location:        u.Location,
+		CityID:          u.CityID,
+		DistrictID:      u.DistrictID,
 		FavoriteGenres:  u.FavoriteGenres,
 		Bio:             u.Bio,
 		ReadingGoal:     &u.ReadingGoal,
@@ -268,8 +294,7 @@ func (u *User) ToResponse() UserResponse {
 		ClubMemberships: u.ClubMemberships,
 		Posts:           u.Posts,
 		Comments:        u.Comments,
-		PostLikes:       u.PostLikes,
-		CommentLikes:    u.CommentLikes,
+		Reactions:       u.Reactions,
 		CreatedAt:       u.CreatedAt,
 	}
 }
@@ -303,6 +328,8 @@ func (u *User) ToPublicProfile() PublicUserProfile {
 
 	if prefs.GetBool(PREF_SHOW_LOCATION, true) {
 		profile.Location = u.Location
+		profile.CityID = u.CityID
+		profile.DistrictID = u.DistrictID
 	}
 
 	if prefs.GetBool(PREF_SHOW_LAST_SEEN, true) && u.LastSeen != nil && u.IsOnline {
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+const (
+	ViewKindView       = "view"       // the post itself was opened
+	ViewKindImpression = "impression" // the post was shown in a feed
+)
+
+// PostViewStat holds a post's deduplicated views and feed impressions for one
+// UTC day.
+type PostViewStat struct {
+	PostID      uint      `json:"post_id" gorm:"primaryKey;autoIncrement:false"`
+	Day         time.Time `json:"day" gorm:"primaryKey"`
+	Views       int       `json:"views" gorm:"not null;default:0"`
+	Impressions int       `json:"impressions" gorm:"not null;default:0"`
+
+	Post Post `json:"-" gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE" swaggerignore:"true"`
+}
+
+// PostViewDelta is a batch of counts waiting to be added to a PostViewStat.
+type PostViewDelta struct {
+	PostID      uint
+	Day         time.Time
+	Views       int
+	Impressions int
+}
+
+type PostViewsRequest struct {
+	From string `form:"from" validate:"omitempty,datetime=2006-01-02"`
+	To   string `form:"to" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type PostViewPoint struct {
+	Day         time.Time `json:"day"`
+	Views       int       `json:"views"`
+	Impressions int       `json:"impressions"`
+}
+
+type PostViewsResponse struct {
+	PostID           uint            `json:"post_id"`
+	ViewsCount       int             `json:"views_count"`
+	From             time.Time       `json:"from"`
+	To               time.Time       `json:"to"`
+	TotalViews       int             `json:"total_views"`
+	TotalImpressions int             `json:"total_impressions"`
+	Points           []PostViewPoint `json:"points"`
+}
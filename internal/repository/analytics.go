@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"gorm.io/gorm"
+)
+
+type analyticsRepository struct {
+	db *gorm.DB
+}
+
+func NewAnalyticsRepository(db *gorm.DB) *analyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+// series buckets the rows of a sub-select exposing a created_at column.
+// Buckets are truncated in UTC so they line up with the service-side fill.
+func (r *analyticsRepository) series(granularity, source string, args ...interface{}) ([]models.AnalyticsPoint, error) {
+	query := fmt.Sprintf(`SELECT date_trunc(?, s.created_at AT TIME ZONE 'UTC') AS bucket, COUNT(*) AS value
+		FROM (%s) s
+		GROUP BY bucket
+		ORDER BY bucket`, source)
+
+	var points []models.AnalyticsPoint
+	err := r.db.Raw(query, append([]interface{}{granularity}, args...)...).Scan(&points).Error
+	return points, err
+}
+
+func (r *analyticsRepository) MembershipSeries(clubID uint, action string, from, to time.Time, granularity string) ([]models.AnalyticsPoint, error) {
+	return r.series(granularity, `
+		SELECT created_at FROM club_membership_logs
+		WHERE club_id = ? AND action = ? AND created_at >= ? AND created_at < ?`,
+		clubID, action, from, to)
+}
+
+func (r *analyticsRepository) PostSeries(clubID uint, from, to time.Time, granularity string) ([]models.AnalyticsPoint, error) {
+	return r.series(granularity, `
+		SELECT created_at FROM posts
+		WHERE club_id = ? AND status = 'published' AND deleted_at IS NULL AND created_at >= ? AND created_at < ?`,
+		clubID, from, to)
+}
+
+func (r *analyticsRepository) CommentSeries(clubID uint, from, to time.Time, granularity string) ([]models.AnalyticsPoint, error) {
+	return r.series(granularity, `
+		SELECT c.created_at FROM comments c
+		JOIN posts p ON p.id = c.post_id
+		WHERE p.club_id = ? AND c.deleted_at IS NULL AND c.created_at >= ? AND c.created_at < ?`,
+		clubID, from, to)
+}
+
+// LikeSeries counts ❤️ reactions, which replaced post and comment likes.
+func (r *analyticsRepository) LikeSeries(clubID uint, from, to time.Time, granularity string) ([]models.AnalyticsPoint, error) {
+	return r.series(granularity, `
+		SELECT re.created_at FROM reactions re
+		JOIN posts p ON re.target_type = 'post' AND p.id = re.target_id
+		WHERE p.club_id = ? AND re.emoji = ? AND re.created_at >= ? AND re.created_at < ?
+		UNION ALL
+		SELECT re.created_at FROM reactions re
+		JOIN comments c ON re.target_type = 'comment' AND c.id = re.target_id
+		JOIN posts p ON p.id = c.post_id
+		WHERE p.club_id = ? AND re.emoji = ? AND re.created_at >= ? AND re.created_at < ?`,
+		clubID, models.LikeReaction, from, to, clubID, models.LikeReaction, from, to)
+}
+
+func (r *analyticsRepository) EventParticipation(clubID uint, from, to time.Time) ([]models.EventParticipationStats, error) {
+	var out []models.EventParticipationStats
+	err := r.db.Raw(`
+		SELECT e.id AS event_id, e.title, e.event_date,
+			COUNT(rs.id) FILTER (WHERE rs.status = 'going') AS going,
+			COUNT(rs.id) FILTER (WHERE rs.status = 'maybe') AS maybe,
+			COUNT(rs.id) FILTER (WHERE rs.status = 'not_going') AS not_going,
+			COUNT(rs.id) FILTER (WHERE rs.attended) AS attended
+		FROM events e
+		LEFT JOIN event_rsvps rs ON rs.event_id = e.id
+		WHERE e.club_id = ? AND e.event_date >= ? AND e.event_date < ?
+		GROUP BY e.id, e.title, e.event_date
+		ORDER BY e.event_date`,
+		clubID, from, to).Scan(&out).Error
+	return out, err
+}
+
+func (r *analyticsRepository) AssignmentCompletion(clubID uint, from, to time.Time) ([]models.AssignmentCompletionStats, error) {
+	var out []models.AssignmentCompletionStats
+	err := r.db.Raw(`
+		SELECT a.id AS assignment_id, a.book_id, b.title AS book_title, a.status,
+			COUNT(DISTINCT m.user_id) AS members,
+			COUNT(DISTINCT ubp.user_id) FILTER (WHERE ubp.status IN ('reading', 'paused')) AS in_progress,
+			COUNT(DISTINCT ubp.user_id) FILTER (WHERE ubp.status = 'finished') AS finished
+		FROM club_book_assignments a
+		JOIN books b ON b.id = a.book_id
+		JOIN club_memberships m ON m.club_id = a.club_id AND m.is_approved = true
+		LEFT JOIN user_book_progresses ubp ON ubp.book_id = a.book_id AND ubp.user_id = m.user_id
+		WHERE a.club_id = ? AND a.created_at < ? AND (a.completed_at IS NULL OR a.completed_at >= ?)
+		GROUP BY a.id, a.book_id, b.title, a.status, a.created_at
+		ORDER BY a.created_at`,
+		clubID, to, from).Scan(&out).Error
+	return out, err
+}
+
+func (r *analyticsRepository) TopMembers(clubID uint, from, to time.Time, limit int) ([]models.MemberActivity, error) {
+	var out []models.MemberActivity
+	err := r.db.Raw(`
+		SELECT act.user_id, u.username, u.avatar_url,
+			SUM(act.posts) AS posts, SUM(act.comments) AS comments,
+			SUM(act.posts) + SUM(act.comments) AS score
+		FROM (
+			SELECT user_id, 1 AS posts, 0 AS comments FROM posts
+			WHERE club_id = ? AND status = 'published' AND deleted_at IS NULL AND created_at >= ? AND created_at < ?
+			UNION ALL
+			SELECT c.user_id, 0 AS posts, 1 AS comments FROM comments c
+			JOIN posts p ON p.id = c.post_id
+			WHERE p.club_id = ? AND c.deleted_at IS NULL AND c.created_at >= ? AND c.created_at < ?
+		) act
+		JOIN users u ON u.id = act.user_id
+		GROUP BY act.user_id, u.username, u.avatar_url
+		ORDER BY score DESC, act.user_id
+		LIMIT ?`,
+		clubID, from, to, clubID, from, to, limit).Scan(&out).Error
+	return out, err
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type AnalyticsRepositoryTestSuite struct {
+	suite.Suite
+	db            *gorm.DB
+	analyticsRepo AnalyticsRepository
+	members       []*models.User
+}
+
+func (suite *AnalyticsRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.analyticsRepo = NewAnalyticsRepository(suite.db)
+
+	suite.members = nil
+	for _, name := range []string{"ada", "ben", "cem", "dan"} {
+		u := &models.User{Username: name, Email: name + "@example.com", PasswordHash: "x"}
+		suite.Require().NoError(suite.db.Create(u).Error)
+		suite.members = append(suite.members, u)
+	}
+	for i, u := range suite.members {
+		m := models.ClubMembership{ClubID: 1, UserID: u.ID, Role: "member", IsApproved: i < 3}
+		suite.Require().NoError(suite.db.Omit("User", "Club").Create(&m).Error)
+	}
+}
+
+func (suite *AnalyticsRepositoryTestSuite) progress(user *models.User, bookID uint, status models.ReadingStatus) {
+	suite.Require().NoError(suite.db.Create(&models.UserBookProgress{UserID: user.ID, BookID: bookID, Status: status}).Error)
+}
+
+func (suite *AnalyticsRepositoryTestSuite) TestAssignmentCompletion() {
+	dune, emma := &models.Book{Title: "Dune"}, &models.Book{Title: "Emma"}
+	suite.Require().NoError(suite.db.Create(dune).Error)
+	suite.Require().NoError(suite.db.Create(emma).Error)
+	suite.Require().NoError(suite.db.Create(&models.ClubBookAssignment{ClubID: 1, BookID: dune.ID, CreatedAt: time.Now().Add(-time.Hour)}).Error)
+	suite.Require().NoError(suite.db.Create(&models.ClubBookAssignment{ClubID: 2, BookID: emma.ID}).Error)
+
+	suite.progress(suite.members[0], dune.ID, models.ReadingFinished)
+	suite.progress(suite.members[1], dune.ID, models.ReadingPaused)
+	suite.progress(suite.members[2], emma.ID, models.ReadingFinished)
+	suite.progress(suite.members[3], dune.ID, models.ReadingFinished)
+
+	stats, err := suite.analyticsRepo.AssignmentCompletion(1, time.Now().Add(-24*time.Hour), time.Now().Add(time.Hour))
+	suite.Require().NoError(err)
+
+	suite.Require().Len(stats, 1, "only the club's own assignments")
+	assert.Equal(suite.T(), "Dune", stats[0].BookTitle)
+	assert.Equal(suite.T(), 3, stats[0].Members, "pending members are not counted")
+	assert.Equal(suite.T(), 1, stats[0].InProgress)
+	assert.Equal(suite.T(), 1, stats[0].Finished, "a pending member's progress is not counted")
+}
+
+func (suite *AnalyticsRepositoryTestSuite) TestAssignmentCompletionSkipsAssignmentsOutsideTheWindow() {
+	dune := &models.Book{Title: "Dune"}
+	suite.Require().NoError(suite.db.Create(dune).Error)
+	done := time.Now().Add(-48 * time.Hour)
+	suite.Require().NoError(suite.db.Create(&models.ClubBookAssignment{
+		ClubID: 1, BookID: dune.ID, Status: models.ClubAssignmentCompleted,
+		CompletedAt: &done, CreatedAt: time.Now().Add(-72 * time.Hour),
+	}).Error)
+
+	stats, err := suite.analyticsRepo.AssignmentCompletion(1, time.Now().Add(-24*time.Hour), time.Now().Add(time.Hour))
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), stats)
+}
+
+func TestAnalyticsRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(AnalyticsRepositoryTestSuite))
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) *attachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+// attachmentsInOrder preloads the attachments of posts and comments in the
+// order their author gave them.
+func attachmentsInOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("attachments.position ASC, attachments.id ASC")
+}
+
+// listAttachments returns the attachments of the posts or comments with the
+// given ids, keyed by id; column is post_id or comment_id.
+func listAttachments(db *gorm.DB, column string, ids []uint) (map[uint][]models.AttachmentResponse, error) {
+	out := map[uint][]models.AttachmentResponse{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var attachments []models.Attachment
+	if err := attachmentsInOrder(db).Where(column+" IN ?", ids).Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	for i := range attachments {
+		a := &attachments[i]
+		owner := a.PostID
+		if column == "comment_id" {
+			owner = a.CommentID
+		}
+		out[*owner] = append(out[*owner], a.ToResponse())
+	}
+	return out, nil
+}
+
+func (r *attachmentRepository) Create(attachment *models.Attachment) error {
+	return r.db.Create(attachment).Error
+}
+
+func (r *attachmentRepository) GetByIDs(ids []uint) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	if len(ids) == 0 {
+		return attachments, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&attachments).Error
+	return attachments, err
+}
+
+// ClubUsage is the total size of the files a club keeps, thumbnails included.
+func (r *attachmentRepository) ClubUsage(clubID uint) (int64, error) {
+	var used int64
+	err := r.db.Model(&models.Attachment{}).
+		Where("club_id = ?", clubID).
+		Select("COALESCE(SUM(size + thumbnail_size), 0)").
+		Scan(&used).Error
+	return used, err
+}
+
+// SetPostAttachments makes ids, in order, the attachments of the post. The
+// attachments it had before and no longer has are detached and left for the
+// orphan cleanup. It reports false, changing nothing, when one of ids went to
+// another post or comment in the meantime.
+func (r *attachmentRepository) SetPostAttachments(postID uint, ids []uint) (bool, error) {
+	return r.setAttachments("post_id", postID, ids)
+}
+
+// SetCommentAttachments is SetPostAttachments for a comment.
+func (r *attachmentRepository) SetCommentAttachments(commentID uint, ids []uint) (bool, error) {
+	return r.setAttachments("comment_id", commentID, ids)
+}
+
+func (r *attachmentRepository) setAttachments(column string, ownerID uint, ids []uint) (bool, error) {
+	// returned to roll the transaction back when an attachment was taken
+	errTaken := errors.New("attachment taken")
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		detach := tx.Model(&models.Attachment{}).Where(column+" = ?", ownerID)
+		if len(ids) > 0 {
+			detach = detach.Where("id NOT IN ?", ids)
+		}
+		if err := detach.Updates(map[string]interface{}{column: nil, "position": 0}).Error; err != nil {
+			return err
+		}
+
+		for i, id := range ids {
+			res := tx.Model(&models.Attachment{}).
+				Where("id = ? AND post_id IS NULL AND comment_id IS NULL", id).
+				Or("id = ? AND "+column+" = ?", id, ownerID).
+				Updates(map[string]interface{}{column: ownerID, "position": i})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return errTaken
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errTaken) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// DeleteByPost removes the attachments of a post and returns them, so their
+// files can be deleted.
+func (r *attachmentRepository) DeleteByPost(postID uint) ([]models.Attachment, error) {
+	var deleted []models.Attachment
+	err := r.db.Clauses(clause.Returning{}).Where("post_id = ?", postID).Delete(&deleted).Error
+	return deleted, err
+}
+
+// DeleteByComment removes the attachments of a comment and returns them.
+func (r *attachmentRepository) DeleteByComment(commentID uint) ([]models.Attachment, error) {
+	var deleted []models.Attachment
+	err := r.db.Clauses(clause.Returning{}).Where("comment_id = ?", commentID).Delete(&deleted).Error
+	return deleted, err
+}
+
+// DeleteUpload removes an attachment its uploader no longer wants, attached
+// or not. It returns gorm.ErrRecordNotFound for someone else's attachment.
+func (r *attachmentRepository) DeleteUpload(id, uploaderID uint) (*models.Attachment, error) {
+	var deleted []models.Attachment
+	if err := r.db.Clauses(clause.Returning{}).Where("id = ? AND uploader_id = ?", id, uploaderID).Delete(&deleted).Error; err != nil {
+		return nil, err
+	}
+	if len(deleted) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &deleted[0], nil
+}
+
+// DeleteOrphans removes up to limit attachments uploaded before the given
+// time and never attached, or detached since, and returns them. One attached
+// while this runs is kept.
+func (r *attachmentRepository) DeleteOrphans(before time.Time, limit int) ([]models.Attachment, error) {
+	var ids []uint
+	if err := r.db.Model(&models.Attachment{}).
+		Where("post_id IS NULL AND comment_id IS NULL AND created_at < ?", before).
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var deleted []models.Attachment
+	err := r.db.Clauses(clause.Returning{}).
+		Where("id IN ? AND post_id IS NULL AND comment_id IS NULL", ids).
+		Delete(&deleted).Error
+	return deleted, err
+}
+
+func (r *attachmentRepository) GetClubSettings(clubID uint) (*models.ClubAttachmentSettings, error) {
+	var settings models.ClubAttachmentSettings
+	if err := r.db.Where("club_id = ?", clubID).First(&settings).Error; err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// SaveClubSettings stores a club's overrides, replacing any earlier ones.
+func (r *attachmentRepository) SaveClubSettings(settings *models.ClubAttachmentSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "club_id"}},
+		UpdateAll: true,
+	}).Create(settings).Error
+}
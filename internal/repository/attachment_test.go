@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepositoryTestSuite struct {
+	suite.Suite
+	db             *gorm.DB
+	attachmentRepo AttachmentRepository
+	postRepo       PostRepository
+	post           *models.Post
+	other          *models.Post
+}
+
+func (suite *AttachmentRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.attachmentRepo = NewAttachmentRepository(suite.db)
+	suite.postRepo = NewPostRepository(suite.db)
+
+	suite.post = &models.Post{Title: "Dune", Content: "text", Type: "discussion", UserID: 1, ClubID: 1}
+	suite.other = &models.Post{Title: "Emma", Content: "text", Type: "discussion", UserID: 1, ClubID: 1}
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(suite.post).Error)
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(suite.other).Error)
+}
+
+func (suite *AttachmentRepositoryTestSuite) upload(clubID uint, size int64) *models.Attachment {
+	a := &models.Attachment{
+		ClubID: clubID, UploaderID: 1, Kind: models.AttachmentKindFile,
+		FileName: "notes.pdf", ContentType: "application/pdf", Size: size,
+		StorageKey: "clubs/1/key.pdf", URL: "/uploads/clubs/1/key.pdf",
+	}
+	suite.Require().NoError(suite.attachmentRepo.Create(a))
+	return a
+}
+
+func (suite *AttachmentRepositoryTestSuite) TestSetPostAttachmentsKeepsOrder() {
+	first, second, third := suite.upload(1, 10), suite.upload(1, 10), suite.upload(1, 10)
+
+	ok, err := suite.attachmentRepo.SetPostAttachments(suite.post.ID, []uint{second.ID, first.ID})
+	suite.Require().NoError(err)
+	suite.Require().True(ok)
+
+	post, err := suite.postRepo.GetByID(suite.post.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(post.Attachments, 2)
+	assert.Equal(suite.T(), second.ID, post.Attachments[0].ID)
+	assert.Equal(suite.T(), first.ID, post.Attachments[1].ID)
+
+	ok, err = suite.attachmentRepo.SetPostAttachments(suite.post.ID, []uint{third.ID, second.ID})
+	suite.Require().NoError(err)
+	suite.Require().True(ok)
+
+	post, err = suite.postRepo.GetByID(suite.post.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(post.Attachments, 2)
+	assert.Equal(suite.T(), third.ID, post.Attachments[0].ID)
+	assert.Equal(suite.T(), second.ID, post.Attachments[1].ID)
+
+	var detached models.Attachment
+	suite.Require().NoError(suite.db.First(&detached, first.ID).Error)
+	assert.Nil(suite.T(), detached.PostID, "removed attachments are left for the orphan cleanup")
+}
+
+func (suite *AttachmentRepositoryTestSuite) TestSetPostAttachmentsRefusesTakenAttachment() {
+	mine, taken := suite.upload(1, 10), suite.upload(1, 10)
+	ok, err := suite.attachmentRepo.SetPostAttachments(suite.other.ID, []uint{taken.ID})
+	suite.Require().NoError(err)
+	suite.Require().True(ok)
+
+	ok, err = suite.attachmentRepo.SetPostAttachments(suite.post.ID, []uint{mine.ID, taken.ID})
+	suite.Require().NoError(err)
+	assert.False(suite.T(), ok)
+
+	var stored, stillTaken models.Attachment
+	suite.Require().NoError(suite.db.First(&stored, mine.ID).Error)
+	assert.Nil(suite.T(), stored.PostID, "nothing changes when one attachment is taken")
+	suite.Require().NoError(suite.db.First(&stillTaken, taken.ID).Error)
+	assert.Equal(suite.T(), suite.other.ID, *stillTaken.PostID)
+}
+
+func (suite *AttachmentRepositoryTestSuite) TestSummariesIncludeAttachments() {
+	a := suite.upload(1, 10)
+	ok, err := suite.attachmentRepo.SetPostAttachments(suite.post.ID, []uint{a.ID})
+	suite.Require().NoError(err)
+	suite.Require().True(ok)
+
+	page, err := suite.postRepo.ListPostSummaries(1, nil, models.PostListOptions{}, pagination.First(10))
+	suite.Require().NoError(err)
+	for _, summary := range page.Items {
+		if summary.ID == suite.post.ID {
+			suite.Require().Len(summary.Attachments, 1)
+			assert.Equal(suite.T(), a.ID, summary.Attachments[0].ID)
+		} else {
+			assert.Empty(suite.T(), summary.Attachments)
+		}
+	}
+}
+
+func (suite *AttachmentRepositoryTestSuite) TestClubUsage() {
+	a := suite.upload(1, 100)
+	a.ThumbnailSize = 20
+	suite.Require().NoError(suite.db.Save(a).Error)
+	suite.upload(1, 50)
+	suite.upload(2, 1000)
+
+	used, err := suite.attachmentRepo.ClubUsage(1)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(170), used)
+
+	used, err = suite.attachmentRepo.ClubUsage(3)
+	suite.Require().NoError(err)
+	assert.Zero(suite.T(), used)
+}
+
+func (suite *AttachmentRepositoryTestSuite) TestDeleteByPostReturnsDeleted() {
+	a, b := suite.upload(1, 10), suite.upload(1, 10)
+	ok, err := suite.attachmentRepo.SetPostAttachments(suite.post.ID, []uint{a.ID})
+	suite.Require().NoError(err)
+	suite.Require().True(ok)
+
+	deleted, err := suite.attachmentRepo.DeleteByPost(suite.post.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(deleted, 1)
+	assert.Equal(suite.T(), a.ID, deleted[0].ID)
+	assert.Equal(suite.T(), a.StorageKey, deleted[0].StorageKey)
+
+	var count int64
+	suite.Require().NoError(suite.db.Model(&models.Attachment{}).Count(&count).Error)
+	assert.Equal(suite.T(), int64(1), count)
+	suite.Require().NoError(suite.db.First(&models.Attachment{}, b.ID).Error)
+}
+
+func (suite *AttachmentRepositoryTestSuite) TestDeleteUploadChecksUploader() {
+	a := suite.upload(1, 10)
+
+	_, err := suite.attachmentRepo.DeleteUpload(a.ID, 2)
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+
+	deleted, err := suite.attachmentRepo.DeleteUpload(a.ID, 1)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), a.ID, deleted.ID)
+}
+
+func (suite *AttachmentRepositoryTestSuite) TestDeleteOrphans() {
+	old, attached, recent := suite.upload(1, 10), suite.upload(1, 10), suite.upload(1, 10)
+	ok, err := suite.attachmentRepo.SetPostAttachments(suite.post.ID, []uint{attached.ID})
+	suite.Require().NoError(err)
+	suite.Require().True(ok)
+	dayAgo := time.Now().Add(-24 * time.Hour)
+	suite.Require().NoError(suite.db.Model(&models.Attachment{}).Where("id IN ?", []uint{old.ID, attached.ID}).Update("created_at", dayAgo).Error)
+
+	deleted, err := suite.attachmentRepo.DeleteOrphans(time.Now().Add(-time.Hour), 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(deleted, 1)
+	assert.Equal(suite.T(), old.ID, deleted[0].ID)
+
+	suite.Require().NoError(suite.db.First(&models.Attachment{}, attached.ID).Error)
+	suite.Require().NoError(suite.db.First(&models.Attachment{}, recent.ID).Error)
+}
+
+func (suite *AttachmentRepositoryTestSuite) TestSaveClubSettingsReplaces() {
+	_, err := suite.attachmentRepo.GetClubSettings(1)
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+
+	quota := 50
+	suite.Require().NoError(suite.attachmentRepo.SaveClubSettings(&models.ClubAttachmentSettings{
+		ClubID: 1, AllowedTypes: pq.StringArray{"image/png"}, QuotaMB: &quota, UpdatedBy: 1,
+	}))
+	suite.Require().NoError(suite.attachmentRepo.SaveClubSettings(&models.ClubAttachmentSettings{
+		ClubID: 1, AllowedTypes: pq.StringArray{"application/pdf", "image/jpeg"}, UpdatedBy: 2,
+	}))
+
+	settings, err := suite.attachmentRepo.GetClubSettings(1)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), pq.StringArray{"application/pdf", "image/jpeg"}, settings.AllowedTypes)
+	assert.Nil(suite.T(), settings.QuotaMB)
+	assert.Equal(suite.T(), uint(2), settings.UpdatedBy)
+}
+
+func TestAttachmentRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(AttachmentRepositoryTestSuite))
+}
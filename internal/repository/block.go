@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type blockRepository struct {
+	db *gorm.DB
+}
+
+func NewBlockRepository(db *gorm.DB) *blockRepository {
+	return &blockRepository{db: db}
+}
+
+// Block is idempotent; blocking someone twice is not an error.
+func (r *blockRepository) Block(blockerID, blockedID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.UserBlock{BlockerID: blockerID, BlockedID: blockedID}).Error
+}
+
+func (r *blockRepository) Unblock(blockerID, blockedID uint) (bool, error) {
+	res := r.db.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&models.UserBlock{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// ListBlockersOf returns which of candidateIDs have blocked blockedID.
+func (r *blockRepository) ListBlockersOf(blockedID uint, candidateIDs []uint) ([]uint, error) {
+	if len(candidateIDs) == 0 {
+		return nil, nil
+	}
+	var ids []uint
+	err := r.db.Model(&models.UserBlock{}).
+		Where("blocked_id = ? AND blocker_id IN ?", blockedID, candidateIDs).
+		Pluck("blocker_id", &ids).Error
+	return ids, err
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
 )
 
@@ -36,13 +37,15 @@ func (r *bookRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Book{}, id).Error
 }
 
-func (r *bookRepository) List(limit, offset int) ([]*models.Book, error) {
-	var books []*models.Book
-	err := r.db.Limit(limit).Offset(offset).Find(&books).Error
-	if err != nil {
-		return nil, err
+// List returns books ordered by title.
+func (r *bookRepository) List(page pagination.Params) (pagination.Page[*models.Book], error) {
+	byTitle := pagination.Keyset{
+		Columns: []pagination.Column{{Expr: "books.title", Kind: pagination.String}},
+		ID:      "books.id",
 	}
-	return books, nil
+	return pagination.Find(r.db, byTitle, page, func(b *models.Book) pagination.Cursor {
+		return pagination.CursorFor(b.ID, b.Title)
+	})
 }
 
 func (r *bookRepository) GetByExternalID(source, externalID string) (*models.Book, error) {
@@ -98,4 +101,14 @@ func (r *bookRepository) SearchLocal(query string, limit int) ([]*models.Book, e
 		return nil, err
 	}
 	return books, nil
-}
\ No newline at end of file
+}
+
+// GetUserRating returns the rating userID gave the book in their review,
+// published or not.
+func (r *bookRepository) GetUserRating(bookID, userID uint) (*models.BookRating, error) {
+	var rating models.BookRating
+	if err := r.db.Where("book_id = ? AND user_id = ?", bookID, userID).First(&rating).Error; err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"errors"
+	"math"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// addBookRating records the rating of a new review post and recomputes its
+// book's local rating. A second review of a book by the same user fails on
+// idx_book_ratings_book_user. Call it in the transaction that creates the
+// post.
+func addBookRating(tx *gorm.DB, post *models.Post) error {
+	review, err := post.GetReviewData()
+	if err != nil || review == nil || review.BookID == 0 {
+		return nil
+	}
+	rating := models.BookRating{PostID: post.ID, BookID: review.BookID, UserID: post.UserID, Rating: review.Rating}
+	if err := tx.Create(&rating).Error; err != nil {
+		return err
+	}
+	return refreshBookRating(tx, review.BookID)
+}
+
+// syncBookRating brings the rating taken from a review post in line with the
+// post and recomputes the local rating of the books involved. Deleted posts,
+// and posts that are not reviews of a book, have no rating; neither have
+// reviews written before ratings were kept that repeat a book another of the
+// user's reviews rates. Call it in the transaction that changes the post.
+func syncBookRating(tx *gorm.DB, post *models.Post, deleted bool) error {
+	var previous models.BookRating
+	hadRating := true
+	if err := tx.Where("post_id = ?", post.ID).First(&previous).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		hadRating = false
+	}
+
+	var books []uint
+	if hadRating {
+		books = append(books, previous.BookID)
+	}
+	review, err := post.GetReviewData()
+	if deleted || err != nil || review == nil || review.BookID == 0 {
+		if hadRating {
+			if err := tx.Where("post_id = ?", post.ID).Delete(&models.BookRating{}).Error; err != nil {
+				return err
+			}
+		}
+	} else {
+		if !hadRating {
+			var rated int64
+			if err := tx.Model(&models.BookRating{}).
+				Where("book_id = ? AND user_id = ? AND post_id <> ?", review.BookID, post.UserID, post.ID).
+				Count(&rated).Error; err != nil {
+				return err
+			}
+			if rated > 0 {
+				return nil
+			}
+		}
+		rating := models.BookRating{PostID: post.ID, BookID: review.BookID, UserID: post.UserID, Rating: review.Rating}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "post_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"book_id", "user_id", "rating", "updated_at"}),
+		}).Create(&rating).Error; err != nil {
+			return err
+		}
+		if !hadRating || previous.BookID != review.BookID {
+			books = append(books, review.BookID)
+		}
+	}
+
+	for _, bookID := range books {
+		if err := refreshBookRating(tx, bookID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshPostBookRating recomputes the local rating of the book a review
+// rates, after the review was published or taken down.
+func refreshPostBookRating(tx *gorm.DB, postID uint) error {
+	var bookIDs []uint
+	if err := tx.Model(&models.BookRating{}).Where("post_id = ?", postID).Pluck("book_id", &bookIDs).Error; err != nil {
+		return err
+	}
+	for _, bookID := range bookIDs {
+		if err := refreshBookRating(tx, bookID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshBookRating sets a book's local rating to the average, to one
+// decimal, of the ratings in its published reviews.
+func refreshBookRating(tx *gorm.DB, bookID uint) error {
+	var aggregate struct {
+		Average *float64
+		Count   int
+	}
+	if err := tx.Table("book_ratings").
+		Select("AVG(book_ratings.rating) AS average, COUNT(*) AS count").
+		Joins("JOIN posts ON posts.id = book_ratings.post_id").
+		Where("book_ratings.book_id = ? AND posts.status = ? AND posts.deleted_at IS NULL", bookID, models.PostStatusPublished).
+		Scan(&aggregate).Error; err != nil {
+		return err
+	}
+
+	var local *float32
+	if aggregate.Count > 0 && aggregate.Average != nil {
+		rounded := float32(math.Round(*aggregate.Average*10) / 10)
+		local = &rounded
+	}
+	return tx.Model(&models.Book{}).Where("id = ?", bookID).UpdateColumns(map[string]interface{}{
+		"local_rating": local,
+		"rating_count": aggregate.Count,
+	}).Error
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type BookRatingRepositoryTestSuite struct {
+	suite.Suite
+	db       *gorm.DB
+	postRepo PostRepository
+	bookRepo BookRepository
+	book     *models.Book
+	other    *models.Book
+}
+
+func (suite *BookRatingRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.postRepo = NewPostRepository(suite.db)
+	suite.bookRepo = NewBookRepository(suite.db)
+
+	suite.book = &models.Book{Title: "Dune"}
+	suite.other = &models.Book{Title: "Emma"}
+	suite.Require().NoError(suite.db.Create(suite.book).Error)
+	suite.Require().NoError(suite.db.Create(suite.other).Error)
+}
+
+func (suite *BookRatingRepositoryTestSuite) review(userID, bookID uint, rating float32, status string) *models.Post {
+	data, err := json.Marshal(models.ReviewData{BookID: bookID, Rating: rating})
+	suite.Require().NoError(err)
+	post := &models.Post{Title: "Review", Content: "text", Type: "review", TypeData: data, UserID: userID, ClubID: 1, Status: status}
+	suite.Require().NoError(suite.postRepo.Create(post))
+	return post
+}
+
+func (suite *BookRatingRepositoryTestSuite) rating(bookID uint) (*float32, int) {
+	var book models.Book
+	suite.Require().NoError(suite.db.First(&book, bookID).Error)
+	return book.LocalRating, book.RatingCount
+}
+
+func (suite *BookRatingRepositoryTestSuite) TestPublishedReviewsRateTheBook() {
+	suite.review(1, suite.book.ID, 4, models.PostStatusPublished)
+	suite.review(2, suite.book.ID, 5, models.PostStatusPublished)
+	suite.review(3, suite.book.ID, 2, models.PostStatusDraft)
+
+	local, count := suite.rating(suite.book.ID)
+	suite.Require().NotNil(local)
+	assert.InDelta(suite.T(), 4.5, *local, 0.01)
+	assert.Equal(suite.T(), 2, count, "drafts do not count")
+
+	local, count = suite.rating(suite.other.ID)
+	assert.Nil(suite.T(), local)
+	assert.Zero(suite.T(), count)
+}
+
+func (suite *BookRatingRepositoryTestSuite) TestEditingAReviewMovesItsRating() {
+	post := suite.review(1, suite.book.ID, 4, models.PostStatusPublished)
+	suite.review(2, suite.book.ID, 3, models.PostStatusPublished)
+
+	data, err := json.Marshal(models.ReviewData{BookID: suite.book.ID, Rating: 5})
+	suite.Require().NoError(err)
+	post.TypeData = data
+	suite.Require().NoError(suite.postRepo.Update(post))
+	local, _ := suite.rating(suite.book.ID)
+	suite.Require().NotNil(local)
+	assert.InDelta(suite.T(), 4, *local, 0.01)
+
+	data, err = json.Marshal(models.ReviewData{BookID: suite.other.ID, Rating: 5})
+	suite.Require().NoError(err)
+	post.TypeData = data
+	suite.Require().NoError(suite.postRepo.Update(post))
+	local, count := suite.rating(suite.book.ID)
+	suite.Require().NotNil(local)
+	assert.InDelta(suite.T(), 3, *local, 0.01)
+	assert.Equal(suite.T(), 1, count)
+	local, count = suite.rating(suite.other.ID)
+	suite.Require().NotNil(local)
+	assert.InDelta(suite.T(), 5, *local, 0.01)
+	assert.Equal(suite.T(), 1, count)
+}
+
+func (suite *BookRatingRepositoryTestSuite) TestStatusChangesAndDeletesRecompute() {
+	post := suite.review(1, suite.book.ID, 4, models.PostStatusDraft)
+	_, count := suite.rating(suite.book.ID)
+	assert.Zero(suite.T(), count)
+
+	ok, err := suite.postRepo.Publish(post.ID, post.CreatedAt)
+	suite.Require().NoError(err)
+	suite.Require().True(ok)
+	_, count = suite.rating(suite.book.ID)
+	assert.Equal(suite.T(), 1, count)
+
+	suite.Require().NoError(suite.postRepo.SetStatus(post.ID, models.PostStatusHidden))
+	local, count := suite.rating(suite.book.ID)
+	assert.Nil(suite.T(), local, "hidden reviews do not count")
+	assert.Zero(suite.T(), count)
+
+	suite.Require().NoError(suite.postRepo.SetStatus(post.ID, models.PostStatusPublished))
+	_, count = suite.rating(suite.book.ID)
+	assert.Equal(suite.T(), 1, count)
+
+	suite.Require().NoError(suite.postRepo.Delete(post.ID))
+	_, count = suite.rating(suite.book.ID)
+	assert.Zero(suite.T(), count)
+	_, err = suite.bookRepo.GetUserRating(suite.book.ID, 1)
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound, "a deleted review frees the user to review the book again")
+}
+
+func (suite *BookRatingRepositoryTestSuite) TestGetUserRating() {
+	post := suite.review(1, suite.book.ID, 3.5, models.PostStatusDraft)
+
+	rating, err := suite.bookRepo.GetUserRating(suite.book.ID, 1)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), post.ID, rating.PostID)
+	assert.Equal(suite.T(), float32(3.5), rating.Rating)
+
+	_, err = suite.bookRepo.GetUserRating(suite.book.ID, 2)
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+
+	data, err := json.Marshal(models.ReviewData{BookID: suite.book.ID, Rating: 2})
+	suite.Require().NoError(err)
+	duplicate := &models.Post{Title: "Again", Content: "text", Type: "review", TypeData: data, UserID: 1, ClubID: 1}
+	assert.Error(suite.T(), suite.postRepo.Create(duplicate), "one review per user and book")
+}
+
+func (suite *BookRatingRepositoryTestSuite) TestLegacyDuplicateReviewsStayUnrated() {
+	// two reviews of the same book from before ratings were kept; the
+	// backfill rated only the latest
+	data, err := json.Marshal(models.ReviewData{BookID: suite.book.ID, Rating: 2})
+	suite.Require().NoError(err)
+	older := &models.Post{Title: "First", Content: "text", Type: "review", TypeData: data, UserID: 1, ClubID: 1}
+	suite.Require().NoError(suite.db.Create(older).Error)
+	latest := suite.review(1, suite.book.ID, 4, models.PostStatusPublished)
+
+	data, err = json.Marshal(models.ReviewData{BookID: suite.book.ID, Rating: 1})
+	suite.Require().NoError(err)
+	older.TypeData = data
+	suite.Require().NoError(suite.postRepo.Update(older), "the older review can still be edited")
+
+	rating, err := suite.bookRepo.GetUserRating(suite.book.ID, 1)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), latest.ID, rating.PostID)
+	local, count := suite.rating(suite.book.ID)
+	suite.Require().NotNil(local)
+	assert.InDelta(suite.T(), 4, *local, 0.01, "the older review does not rate the book")
+	assert.Equal(suite.T(), 1, count)
+
+	suite.Require().NoError(suite.postRepo.Delete(latest.ID))
+	suite.Require().NoError(suite.postRepo.Update(older))
+	rating, err = suite.bookRepo.GetUserRating(suite.book.ID, 1)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), older.ID, rating.PostID, "once the rated review is gone the older one takes its place")
+}
+
+func TestBookRatingRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(BookRatingRepositoryTestSuite))
+}
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type bookmarkRepository struct {
+	db *gorm.DB
+}
+
+func NewBookmarkRepository(db *gorm.DB) *bookmarkRepository {
+	return &bookmarkRepository{db: db}
+}
+
+// bookmarksInOrder and collectionsInOrder follow the order users arranged
+// them in; new entries are appended at the end.
+var bookmarksInOrder = pagination.Keyset{
+	Columns: []pagination.Column{{Expr: "position", Kind: pagination.Int}},
+	ID:      "id",
+}
+
+var collectionsInOrder = pagination.Keyset{
+	Columns: []pagination.Column{{Expr: "bookmark_collections.position", Kind: pagination.Int}},
+	ID:      "bookmark_collections.id",
+}
+
+func (r *bookmarkRepository) CreateCollection(collection *models.BookmarkCollection) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var last int
+		if err := tx.Model(&models.BookmarkCollection{}).
+			Where("user_id = ?", collection.UserID).
+			Select("COALESCE(MAX(position), -1)").
+			Scan(&last).Error; err != nil {
+			return err
+		}
+		collection.Position = last + 1
+		return tx.Create(collection).Error
+	})
+}
+
+// GetCollection returns the user's collection, or gorm.ErrRecordNotFound if
+// it belongs to someone else.
+func (r *bookmarkRepository) GetCollection(userID, id uint) (*models.BookmarkCollection, error) {
+	var collection models.BookmarkCollection
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&collection).Error; err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+func (r *bookmarkRepository) GetCollectionByName(userID uint, name string) (*models.BookmarkCollection, error) {
+	var collection models.BookmarkCollection
+	if err := r.db.Where("user_id = ? AND name = ?", userID, name).First(&collection).Error; err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+func (r *bookmarkRepository) RenameCollection(collection *models.BookmarkCollection) error {
+	return r.db.Model(collection).Update("name", collection.Name).Error
+}
+
+// DeleteCollection deletes the user's collection along with the bookmarks in
+// it.
+func (r *bookmarkRepository) DeleteCollection(userID, id uint) (bool, error) {
+	deleted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.BookmarkCollection{})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		deleted = true
+		return tx.Where("collection_id = ? AND user_id = ?", id, userID).Delete(&models.Bookmark{}).Error
+	})
+	return deleted, err
+}
+
+func (r *bookmarkRepository) ListCollections(userID uint, page pagination.Params) (pagination.Page[models.BookmarkCollectionResponse], error) {
+	query := r.db.Table("bookmark_collections").
+		Select("bookmark_collections.*, (SELECT COUNT(*) FROM bookmarks b WHERE b.collection_id = bookmark_collections.id) AS bookmarks_count").
+		Where("bookmark_collections.user_id = ?", userID)
+	return pagination.Find(query, collectionsInOrder, page, func(c models.BookmarkCollectionResponse) pagination.Cursor {
+		return pagination.CursorFor(c.ID, c.Position)
+	})
+}
+
+func (r *bookmarkRepository) ListCollectionIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.BookmarkCollection{}).
+		Where("user_id = ?", userID).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// SetCollectionPositions orders the user's collections as listed in ids.
+func (r *bookmarkRepository) SetCollectionPositions(userID uint, ids []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&models.BookmarkCollection{}).
+				Where("id = ? AND user_id = ?", id, userID).
+				UpdateColumn("position", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func inBookmarkCollection(query *gorm.DB, collectionID *uint) *gorm.DB {
+	if collectionID == nil {
+		return query.Where("collection_id IS NULL")
+	}
+	return query.Where("collection_id = ?", *collectionID)
+}
+
+// nextBookmarkPosition is the position that puts a bookmark at the end of
+// its collection.
+func nextBookmarkPosition(tx *gorm.DB, userID uint, collectionID *uint) (int, error) {
+	var last int
+	err := inBookmarkCollection(tx.Model(&models.Bookmark{}).Where("user_id = ?", userID), collectionID).
+		Select("COALESCE(MAX(position), -1)").
+		Scan(&last).Error
+	return last + 1, err
+}
+
+// Create appends a bookmark to its collection. It reports false when the
+// user already bookmarked the target.
+func (r *bookmarkRepository) Create(bookmark *models.Bookmark) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		position, err := nextBookmarkPosition(tx, bookmark.UserID, bookmark.CollectionID)
+		if err != nil {
+			return err
+		}
+		bookmark.Position = position
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(bookmark)
+		created = res.RowsAffected > 0
+		return res.Error
+	})
+	return created, err
+}
+
+// Get returns the user's bookmark, or gorm.ErrRecordNotFound if it belongs to
+// someone else.
+func (r *bookmarkRepository) Get(userID, id uint) (*models.Bookmark, error) {
+	var bookmark models.Bookmark
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&bookmark).Error; err != nil {
+		return nil, err
+	}
+	return &bookmark, nil
+}
+
+// Move puts a bookmark at the end of another collection.
+func (r *bookmarkRepository) Move(bookmark *models.Bookmark, collectionID *uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		position, err := nextBookmarkPosition(tx, bookmark.UserID, collectionID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(bookmark).Updates(map[string]interface{}{
+			"collection_id": collectionID,
+			"position":      position,
+		}).Error; err != nil {
+			return err
+		}
+		bookmark.CollectionID = collectionID
+		bookmark.Position = position
+		return nil
+	})
+}
+
+func (r *bookmarkRepository) Delete(userID, id uint) (bool, error) {
+	res := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Bookmark{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// List returns the bookmarks in a collection in their saved order, or all of
+// the user's bookmarks newest first when collectionID is nil.
+func (r *bookmarkRepository) List(userID uint, collectionID *uint, page pagination.Params) (pagination.Page[models.Bookmark], error) {
+	query := r.db.Where("user_id = ?", userID)
+	if collectionID == nil {
+		return pagination.Find(query, pagination.ByID("id", true), page, func(b models.Bookmark) pagination.Cursor {
+			return pagination.CursorFor(b.ID)
+		})
+	}
+	return pagination.Find(query.Where("collection_id = ?", *collectionID), bookmarksInOrder, page, func(b models.Bookmark) pagination.Cursor {
+		return pagination.CursorFor(b.ID, b.Position)
+	})
+}
+
+func (r *bookmarkRepository) ListIDsInCollection(userID, collectionID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.Bookmark{}).
+		Where("user_id = ? AND collection_id = ?", userID, collectionID).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// SetPositions orders the bookmarks of a collection as listed in ids.
+func (r *bookmarkRepository) SetPositions(userID, collectionID uint, ids []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&models.Bookmark{}).
+				Where("id = ? AND user_id = ? AND collection_id = ?", id, userID, collectionID).
+				UpdateColumn("position", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListReadableClubIDs returns the clubs among clubIDs whose posts userID may
+// read: public clubs, and private ones they own or are an approved member of.
+// Deleted clubs are never readable.
+func (r *bookmarkRepository) ListReadableClubIDs(userID uint, clubIDs []uint) (map[uint]bool, error) {
+	return listReadableClubIDs(r.db, userID, clubIDs)
+}
+
+func listReadableClubIDs(db *gorm.DB, userID uint, clubIDs []uint) (map[uint]bool, error) {
+	readable := map[uint]bool{}
+	if len(clubIDs) == 0 {
+		return readable, nil
+	}
+	var ids []uint
+	if err := db.Model(&models.Club{}).
+		Where("id IN ?", clubIDs).
+		Where("is_private = ? OR owner_id = ? OR EXISTS (SELECT 1 FROM club_memberships m WHERE m.club_id = clubs.id AND m.user_id = ? AND m.is_approved = ?)",
+			false, userID, userID, true).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		readable[id] = true
+	}
+	return readable, nil
+}
+
+// GetPosts loads the published posts among ids for display, in no particular
+// order.
+func (r *bookmarkRepository) GetPosts(ids []uint) ([]models.Post, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var posts []models.Post
+	err := r.db.
+		Preload("User").
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
+		Preload("Club").
+		Where("id IN ? AND status = ?", ids, models.PostStatusPublished).
+		Find(&posts).Error
+	return posts, err
+}
+
+// GetComments loads the comments among ids for display, in no particular
+// order.
+func (r *bookmarkRepository) GetComments(ids []uint) ([]models.Comment, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var comments []models.Comment
+	err := r.db.
+		Preload("User").
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
+		Where("id IN ?", ids).
+		Find(&comments).Error
+	return comments, err
+}
+
+// listUserBookmarks reports which of targetIDs the user bookmarked.
+func listUserBookmarks(db *gorm.DB, targetType string, userID uint, targetIDs []uint) (map[uint]bool, error) {
+	out := make(map[uint]bool)
+	if len(targetIDs) == 0 {
+		return out, nil
+	}
+
+	var ids []uint
+	if err := db.Model(&models.Bookmark{}).
+		Where("target_type = ? AND user_id = ? AND target_id IN ?", targetType, userID, targetIDs).
+		Pluck("target_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		out[id] = true
+	}
+	return out, nil
+}
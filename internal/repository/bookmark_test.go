@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type BookmarkRepositoryTestSuite struct {
+	suite.Suite
+	db           *gorm.DB
+	bookmarkRepo BookmarkRepository
+	user         *models.User
+	other        *models.User
+}
+
+func (suite *BookmarkRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.bookmarkRepo = NewBookmarkRepository(suite.db)
+
+	suite.user = &models.User{Username: "reader", Email: "reader@example.com", PasswordHash: "x"}
+	suite.other = &models.User{Username: "other", Email: "other@example.com", PasswordHash: "x"}
+	suite.Require().NoError(suite.db.Create(suite.user).Error)
+	suite.Require().NoError(suite.db.Create(suite.other).Error)
+}
+
+func (suite *BookmarkRepositoryTestSuite) createCollection(userID uint, name string) *models.BookmarkCollection {
+	collection := &models.BookmarkCollection{UserID: userID, Name: name}
+	suite.Require().NoError(suite.bookmarkRepo.CreateCollection(collection))
+	return collection
+}
+
+func (suite *BookmarkRepositoryTestSuite) bookmark(targetID uint, collectionID *uint) *models.Bookmark {
+	b := &models.Bookmark{UserID: suite.user.ID, TargetType: models.BookmarkTargetPost, TargetID: targetID, CollectionID: collectionID}
+	created, err := suite.bookmarkRepo.Create(b)
+	suite.Require().NoError(err)
+	suite.Require().True(created)
+	return b
+}
+
+func (suite *BookmarkRepositoryTestSuite) listIDs(collectionID *uint) []uint {
+	page, err := suite.bookmarkRepo.List(suite.user.ID, collectionID, pagination.First(10))
+	suite.Require().NoError(err)
+	ids := make([]uint, 0, len(page.Items))
+	for _, b := range page.Items {
+		ids = append(ids, b.ID)
+	}
+	return ids
+}
+
+func (suite *BookmarkRepositoryTestSuite) TestCollectionsKeepTheirOrder() {
+	first := suite.createCollection(suite.user.ID, "To read")
+	second := suite.createCollection(suite.user.ID, "Favorites")
+	suite.createCollection(suite.other.ID, "Theirs")
+	assert.Equal(suite.T(), 0, first.Position)
+	assert.Equal(suite.T(), 1, second.Position)
+
+	suite.bookmark(1, &second.ID)
+	suite.bookmark(2, &second.ID)
+
+	suite.Require().NoError(suite.bookmarkRepo.SetCollectionPositions(suite.user.ID, []uint{second.ID, first.ID}))
+	page, err := suite.bookmarkRepo.ListCollections(suite.user.ID, pagination.First(10))
+	suite.Require().NoError(err)
+	suite.Require().Len(page.Items, 2, "other users' collections are not listed")
+	assert.Equal(suite.T(), second.ID, page.Items[0].ID)
+	assert.Equal(suite.T(), 2, page.Items[0].BookmarksCount)
+	assert.Equal(suite.T(), first.ID, page.Items[1].ID)
+	assert.Zero(suite.T(), page.Items[1].BookmarksCount)
+
+	_, err = suite.bookmarkRepo.GetCollection(suite.other.ID, first.ID)
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+}
+
+func (suite *BookmarkRepositoryTestSuite) TestBookmarksKeepTheirOrder() {
+	collection := suite.createCollection(suite.user.ID, "Favorites")
+	a := suite.bookmark(1, &collection.ID)
+	b := suite.bookmark(2, &collection.ID)
+	c := suite.bookmark(3, &collection.ID)
+	loose := suite.bookmark(4, nil)
+	assert.Equal(suite.T(), []int{0, 1, 2}, []int{a.Position, b.Position, c.Position})
+	assert.Zero(suite.T(), loose.Position, "positions count per collection")
+
+	assert.Equal(suite.T(), []uint{a.ID, b.ID, c.ID}, suite.listIDs(&collection.ID))
+	assert.Equal(suite.T(), []uint{loose.ID, c.ID, b.ID, a.ID}, suite.listIDs(nil), "all bookmarks list newest first")
+
+	suite.Require().NoError(suite.bookmarkRepo.SetPositions(suite.user.ID, collection.ID, []uint{c.ID, a.ID, b.ID}))
+	assert.Equal(suite.T(), []uint{c.ID, a.ID, b.ID}, suite.listIDs(&collection.ID))
+
+	suite.Require().NoError(suite.bookmarkRepo.Move(loose, &collection.ID))
+	assert.Equal(suite.T(), 3, loose.Position)
+	assert.Equal(suite.T(), []uint{c.ID, a.ID, b.ID, loose.ID}, suite.listIDs(&collection.ID))
+
+	ids, err := suite.bookmarkRepo.ListIDsInCollection(suite.user.ID, collection.ID)
+	suite.Require().NoError(err)
+	assert.ElementsMatch(suite.T(), []uint{a.ID, b.ID, c.ID, loose.ID}, ids)
+}
+
+func (suite *BookmarkRepositoryTestSuite) TestTargetsAreBookmarkedOnce() {
+	suite.bookmark(1, nil)
+
+	created, err := suite.bookmarkRepo.Create(&models.Bookmark{UserID: suite.user.ID, TargetType: models.BookmarkTargetPost, TargetID: 1})
+	suite.Require().NoError(err)
+	assert.False(suite.T(), created)
+
+	created, err = suite.bookmarkRepo.Create(&models.Bookmark{UserID: suite.user.ID, TargetType: models.BookmarkTargetComment, TargetID: 1})
+	suite.Require().NoError(err)
+	assert.True(suite.T(), created, "a comment with the same ID is a different target")
+}
+
+func (suite *BookmarkRepositoryTestSuite) TestDeleteCollectionDeletesItsBookmarks() {
+	collection := suite.createCollection(suite.user.ID, "Favorites")
+	suite.bookmark(1, &collection.ID)
+	kept := suite.bookmark(2, nil)
+
+	deleted, err := suite.bookmarkRepo.DeleteCollection(suite.other.ID, collection.ID)
+	suite.Require().NoError(err)
+	assert.False(suite.T(), deleted, "only the owner can delete a collection")
+
+	deleted, err = suite.bookmarkRepo.DeleteCollection(suite.user.ID, collection.ID)
+	suite.Require().NoError(err)
+	assert.True(suite.T(), deleted)
+	assert.Equal(suite.T(), []uint{kept.ID}, suite.listIDs(nil))
+}
+
+func (suite *BookmarkRepositoryTestSuite) TestListReadableClubIDs() {
+	ownerID := suite.user.ID
+	public := &models.Club{Name: "Public"}
+	member := &models.Club{Name: "Member", IsPrivate: true}
+	pending := &models.Club{Name: "Pending", IsPrivate: true}
+	owned := &models.Club{Name: "Owned", IsPrivate: true, OwnerID: &ownerID}
+	gone := &models.Club{Name: "Gone"}
+	for _, club := range []*models.Club{public, member, pending, owned, gone} {
+		suite.Require().NoError(suite.db.Omit("Owner", "Members", "Tags").Create(club).Error)
+	}
+	suite.Require().NoError(suite.db.Omit("User", "Club").Create(&models.ClubMembership{UserID: suite.user.ID, ClubID: member.ID, IsApproved: true}).Error)
+	suite.Require().NoError(suite.db.Omit("User", "Club").Create(&models.ClubMembership{UserID: suite.user.ID, ClubID: pending.ID}).Error)
+	suite.Require().NoError(suite.db.Delete(gone).Error)
+
+	readable, err := suite.bookmarkRepo.ListReadableClubIDs(suite.user.ID, []uint{public.ID, member.ID, pending.ID, owned.ID, gone.ID})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), map[uint]bool{public.ID: true, member.ID: true, owned.ID: true}, readable)
+}
+
+func (suite *BookmarkRepositoryTestSuite) TestSummariesFlagBookmarkedPosts() {
+	club := &models.Club{Name: "Public"}
+	suite.Require().NoError(suite.db.Omit("Owner", "Members", "Tags").Create(club).Error)
+	now := time.Now()
+	var posts []*models.Post
+	for i := 0; i < 2; i++ {
+		post := &models.Post{Title: "Post", Content: "text", Type: "discussion", UserID: suite.other.ID, ClubID: club.ID, Status: models.PostStatusPublished, PublishedAt: &now}
+		suite.Require().NoError(suite.db.Omit("Club", "User").Create(post).Error)
+		posts = append(posts, post)
+	}
+	suite.bookmark(posts[0].ID, nil)
+
+	page, err := NewPostRepository(suite.db).ListPostSummaries(club.ID, &suite.user.ID, models.PostListOptions{}, pagination.First(10))
+	suite.Require().NoError(err)
+	suite.Require().Len(page.Items, 2)
+	bookmarked := map[uint]bool{}
+	for _, s := range page.Items {
+		bookmarked[s.ID] = s.Bookmarked
+	}
+	assert.Equal(suite.T(), map[uint]bool{posts[0].ID: true, posts[1].ID: false}, bookmarked)
+}
+
+func TestBookmarkRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(BookmarkRepositoryTestSuite))
+}
@@ -1,7 +1,11 @@
 package repository
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -54,12 +58,17 @@ func (r *clubRepository) Update(club *models.Club) error {
 	return r.db.Omit(clause.Associations).Save(club).Error
 }
 
-func (r *clubRepository) UpdateRatingAggregate(clubID uint, avg float32, count int) error {
+func (r *clubRepository) UpdateRatingAggregate(clubID uint, avg float32, count int, histogram models.RatingHistogram) error {
+	histogramJSON, err := json.Marshal(histogram)
+	if err != nil {
+		return err
+	}
 	return r.db.Model(&models.Club{}).
 		Where("id = ?", clubID).
 		Updates(map[string]interface{}{
-			"rating":        avg,
-			"ratings_count": count,
+			"rating":           avg,
+			"ratings_count":    count,
+			"rating_histogram": histogramJSON,
 		}).Error
 }
 
@@ -67,30 +76,36 @@ func (r *clubRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Club{}, id).Error
 }
 
-func (r *clubRepository) List(limit, offset int) ([]*models.Club, error) {
-	var clubs []*models.Club
-	err := r.db.
-		Preload("Owner").
-		Limit(limit).
-		Offset(offset).
-		Find(&clubs).Error
-	if err != nil {
-		return nil, err
-	}
-	if len(clubs) == 0 {
-		return []*models.Club{}, nil
-	}
-	return clubs, nil
+// clubsNewestFirst lists the most recently created clubs first.
+var clubsNewestFirst = pagination.Keyset{
+	Columns: []pagination.Column{{Expr: "clubs.created_at", Kind: pagination.Time, Desc: true}},
+	ID:      "clubs.id",
+	Desc:    true,
 }
 
-func (r *clubRepository) ListWithFilters(location, genre, meetingType string, minMembers, maxMembers, limit, offset int) ([]*models.Club, error) {
-    var clubs []*models.Club
+func clubNewestCursor(c *models.Club) pagination.Cursor {
+	return pagination.CursorFor(c.ID, c.CreatedAt)
+}
+
+func (r *clubRepository) List(page pagination.Params) (pagination.Page[*models.Club], error) {
+	return pagination.Find(r.db.Preload("Owner"), clubsNewestFirst, page, clubNewestCursor)
+}
+
+func (r *clubRepository) ListWithFilters(location, cityID, districtID, genre, meetingType string, minMembers, maxMembers int, page pagination.Params) (pagination.Page[*models.Club], error) {
     query := r.db.Model(&models.Club{}).Preload("Owner")
 
     if location != "" {
         query = query.Where("LOWER(location) LIKE LOWER(?)", "%"+location+"%")
     }
 
+    if cityID != "" {
+        query = query.Where("city_id = ?", cityID)
+    }
+
+    if districtID != "" {
+        query = query.Where("district_id = ?", districtID)
+    }
+
     if genre != "" {
         query = query.Where("LOWER(genre) LIKE LOWER(?)", "%"+genre+"%")
     }
@@ -107,36 +122,50 @@ func (r *clubRepository) ListWithFilters(location, genre, meetingType string, mi
         query = query.Where("members_count <= ?", maxMembers)
     }
 
-    if limit <= 0 {
-        limit = 20
-    }
-    query = query.Limit(limit).Offset(offset)
-
-    err := query.Order("created_at DESC").Find(&clubs).Error
-    if err != nil {
-        return nil, err
-    }
-
-    if len(clubs) == 0 {
-        return []*models.Club{}, nil
-    }
-
-    return clubs, nil
+    return pagination.Find(query, clubsNewestFirst, page, clubNewestCursor)
 }
 
 func (r *clubRepository) JoinClub(membership *models.ClubMembership) error {
-	return r.db.Create(membership).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(membership).Error; err != nil {
+			return err
+		}
+
+		if !membership.IsApproved {
+			return nil
+		}
+
+		return tx.Create(&models.ClubMembershipLog{
+			ClubID: membership.ClubID,
+			UserID: membership.UserID,
+			Action: models.MembershipActionJoined,
+		}).Error
+	})
 }
 
+// LeaveClub removes a membership. Only approved members are logged as
+// having left; a pending request that is withdrawn never counted as a join.
 func (r *clubRepository) LeaveClub(clubID, userID uint) error {
-	result := r.db.Where("club_id = ? AND user_id = ?", clubID, userID).Delete(&models.ClubMembership{})
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
-	return nil
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var membership models.ClubMembership
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("club_id = ? AND user_id = ?", clubID, userID).
+			First(&membership).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&membership).Error; err != nil {
+			return err
+		}
+
+		if !membership.IsApproved {
+			return nil
+		}
+		return tx.Create(&models.ClubMembershipLog{
+			ClubID: clubID,
+			UserID: userID,
+			Action: models.MembershipActionLeft,
+		}).Error
+	})
 }
 
 func (r *clubRepository) CountApprovedMembers(clubID uint) (int64, error) {
@@ -149,26 +178,53 @@ func (r *clubRepository) CountApprovedMembers(clubID uint) (int64, error) {
 	return count, nil
 }
 
-func (r *clubRepository) ListClubMembers(clubID uint) ([]*models.ClubMembership, error) {
-	var members []*models.ClubMembership
-	err := r.db.
-		Where("club_id = ? AND is_approved = true", clubID).
-		Preload("User").
-		Find(&members).Error
-	if err != nil {
-		return nil, err
+// ListClubMembers returns the approved members in the order they joined.
+func (r *clubRepository) ListClubMembers(clubID uint, page pagination.Params) (pagination.Page[*models.ClubMembership], error) {
+	byJoinDate := pagination.Keyset{
+		Columns: []pagination.Column{{Expr: "club_memberships.joined_at", Kind: pagination.Time}},
+		ID:      "club_memberships.id",
 	}
-	return members, nil
+	query := r.db.
+		Where("club_id = ? AND is_approved = true", clubID).
+		Preload("User")
+	return pagination.Find(query, byJoinDate, page, func(m *models.ClubMembership) pagination.Cursor {
+		return pagination.CursorFor(m.ID, m.JoinedAt)
+	})
 }
 
+// UpdateClubMember changes a member's role and approval. Approving a member
+// logs them as joined and revoking the approval logs them as left.
 func (r *clubRepository) UpdateClubMember(membership *models.ClubMembership) error {
-	return r.db.
-		Model(&models.ClubMembership{}).
-		Where("club_id = ? AND user_id = ?", membership.ClubID, membership.UserID).
-		Updates(map[string]interface{}{
-			"role":        membership.Role,
-			"is_approved": membership.IsApproved,
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.ClubMembership
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "is_approved").
+			Where("club_id = ? AND user_id = ?", membership.ClubID, membership.UserID).
+			First(&current).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ClubMembership{}).
+			Where("id = ?", current.ID).
+			Updates(map[string]interface{}{
+				"role":        membership.Role,
+				"is_approved": membership.IsApproved,
+			}).Error; err != nil {
+			return err
+		}
+
+		if current.IsApproved == membership.IsApproved {
+			return nil
+		}
+		action := models.MembershipActionJoined
+		if !membership.IsApproved {
+			action = models.MembershipActionLeft
+		}
+		return tx.Create(&models.ClubMembershipLog{
+			ClubID: membership.ClubID,
+			UserID: membership.UserID,
+			Action: action,
 		}).Error
+	})
 }
 
 func (r *clubRepository) GetClubMemberByUserID(clubID, userID uint) (*models.ClubMembership, error) {
@@ -195,49 +251,187 @@ func (r *clubRatingRepository) UpsertRating(cr *models.ClubRating) error {
 
 	existing.Rating = cr.Rating
 	existing.Comment = cr.Comment
-	return r.db.Save(&existing).Error
+	existing.MemberLeftAt = nil
+	if err := r.db.Save(&existing).Error; err != nil {
+		return err
+	}
+	*cr = existing
+	return nil
 }
 
-func (r *clubRatingRepository) ListByClub(clubID uint, limit, offset int) ([]models.ClubRating, error) {
-	var out []models.ClubRating
-	err := r.db.Where("club_id = ?", clubID).
-		Order("updated_at desc").
-		Limit(limit).Offset(offset).
-		Find(&out).Error
-
-	return out, err
+// ListByClub returns the club's ratings, most recently updated first.
+func (r *clubRatingRepository) ListByClub(clubID uint, page pagination.Params) (pagination.Page[models.ClubRating], error) {
+	recentFirst := pagination.Keyset{
+		Columns: []pagination.Column{{Expr: "club_ratings.updated_at", Kind: pagination.Time, Desc: true}},
+		ID:      "club_ratings.id",
+		Desc:    true,
+	}
+	return pagination.Find(r.db.Where("club_id = ? AND is_hidden = ?", clubID, false), recentFirst, page, func(cr models.ClubRating) pagination.Cursor {
+		return pagination.CursorFor(cr.ID, cr.UpdatedAt)
+	})
 }
 
-func (r *clubRatingRepository) GetAggregateForClub(clubID uint) (float32, int, error) {
+// GetAggregateForClub averages the club's ratings. Ratings of users who have
+// left the club count with formerMemberWeight; a weight of 0 excludes them.
+func (r *clubRatingRepository) GetAggregateForClub(clubID uint, formerMemberWeight float64) (float32, int, error) {
 	type agg struct {
 		Avg   float64
 		Count int64
 	}
 	var a agg
-	err := r.db.Model(&models.ClubRating{}).
-		Select("COALESCE(AVG(rating), 0) AS avg, COUNT(*) as count").
-		Where("club_id = ?", clubID).
-		Scan(&a).Error
+	q := r.db.Model(&models.ClubRating{}).
+		Select(`COALESCE(SUM(rating * CASE WHEN member_left_at IS NULL THEN 1 ELSE ? END) /
+			NULLIF(SUM(CASE WHEN member_left_at IS NULL THEN 1 ELSE ? END), 0), 0) AS avg,
+			COUNT(*) AS count`, formerMemberWeight, formerMemberWeight).
+		Where("club_id = ? AND is_hidden = ?", clubID, false)
+	if formerMemberWeight <= 0 {
+		q = q.Where("member_left_at IS NULL")
+	}
+	err := q.Scan(&a).Error
 
 	return float32(a.Avg), int(a.Count), err
 }
 
+func (r *clubRatingRepository) GetHistogramForClub(clubID uint, includeFormerMembers bool) (models.RatingHistogram, error) {
+	var rows []struct {
+		Star  int
+		Count int
+	}
+	q := r.db.Model(&models.ClubRating{}).
+		Select("CAST(ROUND(rating) AS INTEGER) AS star, COUNT(*) AS count").
+		Where("club_id = ? AND is_hidden = ?", clubID, false)
+	if !includeFormerMembers {
+		q = q.Where("member_left_at IS NULL")
+	}
+	if err := q.Group("star").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	histogram := models.NewRatingHistogram()
+	for _, row := range rows {
+		if _, ok := histogram[row.Star]; ok {
+			histogram[row.Star] = row.Count
+		}
+	}
+	return histogram, nil
+}
+
+func (r *clubRatingRepository) GetByID(id uint) (*models.ClubRating, error) {
+	var rating models.ClubRating
+	if err := r.db.First(&rating, id).Error; err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+func (r *clubRatingRepository) Update(cr *models.ClubRating) error {
+	return r.db.Save(cr).Error
+}
+
+// SetMemberLeft flags (or, with a nil leftAt, clears) the user's rating as
+// coming from someone who is no longer a member.
+func (r *clubRatingRepository) SetMemberLeft(clubID, userID uint, leftAt *time.Time) (int64, error) {
+	res := r.db.Model(&models.ClubRating{}).
+		Where("club_id = ? AND user_id = ?", clubID, userID).
+		UpdateColumn("member_left_at", leftAt)
+	return res.RowsAffected, res.Error
+}
+
+// Report records a report and bumps the rating's report counter. It returns
+// false when the reporter has already reported this rating.
+func (r *clubRatingRepository) Report(report *models.ClubRatingReport) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(report)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		created = true
+		return tx.Model(&models.ClubRating{}).
+			Where("id = ?", report.RatingID).
+			UpdateColumn("reports_count", gorm.Expr("reports_count + 1")).Error
+	})
+	return created, err
+}
+
+// Hide takes a rating out of the club's listing and aggregate.
+func (r *clubRatingRepository) Hide(id uint) error {
+	return r.db.Model(&models.ClubRating{}).Where("id = ?", id).UpdateColumn("is_hidden", true).Error
+}
+
+func (r *clubRatingRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ClubRating{}, id).Error
+}
+
 func (r *clubRepository) UpdateMembership(m *models.ClubMembership) error {
 	return r.db.Save(m).Error
 }
 
-func (r *clubRepository) ListUserClubs(userID uint) ([]*models.Club, error) {
-	var clubs []*models.Club
-	err := r.db.Joins("JOIN club_memberships ON club_memberships.club_id = clubs.id").
+// ListUserClubs returns the clubs the user is an approved member of, by name.
+func (r *clubRepository) ListUserClubs(userID uint, page pagination.Params) (pagination.Page[*models.Club], error) {
+	byName := pagination.Keyset{
+		Columns: []pagination.Column{{Expr: "clubs.name", Kind: pagination.String}},
+		ID:      "clubs.id",
+	}
+	query := r.db.Joins("JOIN club_memberships ON club_memberships.club_id = clubs.id").
 		Where("club_memberships.user_id = ? AND club_memberships.is_approved = true", userID).
 		Preload("Owner").
 		Preload("Moderators").
 		Preload("Members").
 		Preload("Members.User").
-		Preload("Posts").
-		Find(&clubs).Error
+		Preload("Posts")
+	return pagination.Find(query, byName, page, func(c *models.Club) pagination.Cursor {
+		return pagination.CursorFor(c.ID, c.Name)
+	})
+}
+
+// EachMemberPostActivity calls fn with the post and comment counts of every
+// approved member, in the order they joined. Only published posts in the
+// club and the comments on them count.
+func (r *clubRepository) EachMemberPostActivity(clubID uint, fn func(row *models.MemberPostActivity) error) error {
+	return eachRow(r.db.Raw(`
+		SELECT m.user_id, COALESCE(u.username, '') AS username, m.role,
+			COALESCE(pc.posts, 0) AS posts, COALESCE(cc.comments, 0) AS comments
+		FROM club_memberships m
+		LEFT JOIN users u ON u.id = m.user_id AND u.deleted_at IS NULL
+		LEFT JOIN (
+			SELECT user_id, COUNT(id) AS posts FROM posts
+			WHERE club_id = ? AND status = ? AND deleted_at IS NULL
+			GROUP BY user_id
+		) pc ON pc.user_id = m.user_id
+		LEFT JOIN (
+			SELECT c.user_id, COUNT(c.id) AS comments FROM comments c
+			JOIN posts p ON p.id = c.post_id
+			WHERE p.club_id = ? AND p.status = ? AND p.deleted_at IS NULL
+				AND c.deleted_at IS NULL AND c.is_deleted = ?
+			GROUP BY c.user_id
+		) cc ON cc.user_id = m.user_id
+		WHERE m.club_id = ? AND m.is_approved = ?
+		ORDER BY m.joined_at, m.id`,
+		clubID, models.PostStatusPublished, clubID, models.PostStatusPublished, false, clubID, true), fn)
+}
+
+// eachRow scans the rows of query one at a time into a T and calls fn with
+// each, so exports of large clubs are read in one query without being held in
+// memory.
+func eachRow[T any](query *gorm.DB, fn func(row *T) error) error {
+	rows, err := query.Rows()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return clubs, nil
-}
\ No newline at end of file
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := query.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ClubRatingRepositoryTestSuite struct {
+	suite.Suite
+	db             *gorm.DB
+	clubRatingRepo ClubRatingRepository
+}
+
+func (suite *ClubRatingRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.clubRatingRepo = NewClubRatingRepository(suite.db)
+
+	leftAt := time.Now()
+	for _, r := range []*models.ClubRating{
+		{ClubID: 1, UserID: 1, Rating: 5},
+		{ClubID: 1, UserID: 2, Rating: 4},
+		{ClubID: 1, UserID: 3, Rating: 1, MemberLeftAt: &leftAt},
+		{ClubID: 2, UserID: 1, Rating: 2},
+	} {
+		suite.Require().NoError(suite.db.Create(r).Error)
+	}
+}
+
+func (suite *ClubRatingRepositoryTestSuite) TestGetAggregateForClub_ExcludesFormerMembers() {
+	avg, count, err := suite.clubRatingRepo.GetAggregateForClub(1, 0)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, count)
+	assert.InDelta(suite.T(), 4.5, avg, 0.001)
+}
+
+func (suite *ClubRatingRepositoryTestSuite) TestGetAggregateForClub_DownWeightsFormerMembers() {
+	avg, count, err := suite.clubRatingRepo.GetAggregateForClub(1, 0.5)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3, count)
+	assert.InDelta(suite.T(), 9.5/2.5, avg, 0.001)
+}
+
+func (suite *ClubRatingRepositoryTestSuite) TestGetHistogramForClub() {
+	histogram, err := suite.clubRatingRepo.GetHistogramForClub(1, false)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.RatingHistogram{1: 0, 2: 0, 3: 0, 4: 1, 5: 1}, histogram)
+
+	histogram, err = suite.clubRatingRepo.GetHistogramForClub(1, true)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, histogram[1])
+}
+
+func (suite *ClubRatingRepositoryTestSuite) TestReport_OncePerReporter() {
+	var rating models.ClubRating
+	suite.Require().NoError(suite.db.Where("club_id = ? AND user_id = ?", 1, 3).First(&rating).Error)
+
+	created, err := suite.clubRatingRepo.Report(&models.ClubRatingReport{RatingID: rating.ID, ReporterID: 1, Reason: "abusive"})
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), created)
+
+	created, err = suite.clubRatingRepo.Report(&models.ClubRatingReport{RatingID: rating.ID, ReporterID: 1, Reason: "abusive"})
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), created)
+
+	reloaded, err := suite.clubRatingRepo.GetByID(rating.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, reloaded.ReportsCount)
+}
+
+func (suite *ClubRatingRepositoryTestSuite) TestHide_DropsRatingFromListingAndAggregate() {
+	var rating models.ClubRating
+	suite.Require().NoError(suite.db.Where("club_id = ? AND user_id = ?", 1, 1).First(&rating).Error)
+	suite.Require().NoError(suite.clubRatingRepo.Hide(rating.ID))
+
+	avg, count, err := suite.clubRatingRepo.GetAggregateForClub(1, 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, count)
+	assert.InDelta(suite.T(), 4, avg, 0.001)
+
+	histogram, err := suite.clubRatingRepo.GetHistogramForClub(1, true)
+	assert.NoError(suite.T(), err)
+	assert.Zero(suite.T(), histogram[5])
+
+	page, err := suite.clubRatingRepo.ListByClub(1, pagination.First(10))
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), page.Items, 2)
+	for _, r := range page.Items {
+		assert.NotEqual(suite.T(), rating.ID, r.ID)
+	}
+}
+
+func TestClubRatingRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ClubRatingRepositoryTestSuite))
+}
+
+type ClubMembershipLogTestSuite struct {
+	suite.Suite
+	db       *gorm.DB
+	clubRepo ClubRepository
+}
+
+func (suite *ClubMembershipLogTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.clubRepo = NewClubRepository(suite.db)
+}
+
+func (suite *ClubMembershipLogTestSuite) actions(userID uint) []string {
+	var actions []string
+	suite.Require().NoError(suite.db.Model(&models.ClubMembershipLog{}).
+		Where("club_id = ? AND user_id = ?", 1, userID).
+		Order("id").
+		Pluck("action", &actions).Error)
+	return actions
+}
+
+func (suite *ClubMembershipLogTestSuite) TestApprovalLogsJoinAndLeave() {
+	suite.Require().NoError(suite.clubRepo.JoinClub(&models.ClubMembership{ClubID: 1, UserID: 1, Role: "member"}))
+	assert.Empty(suite.T(), suite.actions(1), "pending requests are not members yet")
+
+	suite.Require().NoError(suite.clubRepo.UpdateClubMember(&models.ClubMembership{ClubID: 1, UserID: 1, Role: "member", IsApproved: true}))
+	suite.Require().NoError(suite.clubRepo.UpdateClubMember(&models.ClubMembership{ClubID: 1, UserID: 1, Role: "moderator", IsApproved: true}))
+	assert.Equal(suite.T(), []string{models.MembershipActionJoined}, suite.actions(1), "role changes are not logged")
+
+	suite.Require().NoError(suite.clubRepo.UpdateClubMember(&models.ClubMembership{ClubID: 1, UserID: 1, Role: "member", IsApproved: false}))
+	assert.Equal(suite.T(), []string{models.MembershipActionJoined, models.MembershipActionLeft}, suite.actions(1))
+}
+
+func (suite *ClubMembershipLogTestSuite) TestLeaveLogsApprovedMembersOnly() {
+	suite.Require().NoError(suite.clubRepo.JoinClub(&models.ClubMembership{ClubID: 1, UserID: 1, Role: "member", IsApproved: true}))
+	suite.Require().NoError(suite.clubRepo.JoinClub(&models.ClubMembership{ClubID: 1, UserID: 2, Role: "member"}))
+
+	suite.Require().NoError(suite.clubRepo.LeaveClub(1, 1))
+	suite.Require().NoError(suite.clubRepo.LeaveClub(1, 2))
+	assert.Equal(suite.T(), []string{models.MembershipActionJoined, models.MembershipActionLeft}, suite.actions(1))
+	assert.Empty(suite.T(), suite.actions(2), "withdrawn requests are not churn")
+
+	assert.ErrorIs(suite.T(), suite.clubRepo.LeaveClub(1, 2), gorm.ErrRecordNotFound)
+}
+
+func TestClubMembershipLogTestSuite(t *testing.T) {
+	suite.Run(t, new(ClubMembershipLogTestSuite))
+}
+
+type ClubMemberActivityTestSuite struct {
+	suite.Suite
+	db       *gorm.DB
+	clubRepo ClubRepository
+	reader   *models.User
+	lurker   *models.User
+}
+
+func (suite *ClubMemberActivityTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.clubRepo = NewClubRepository(suite.db)
+
+	suite.reader = &models.User{Username: "reader", Email: "reader@example.com", PasswordHash: "x"}
+	suite.lurker = &models.User{Username: "lurker", Email: "lurker@example.com", PasswordHash: "x"}
+	pending := &models.User{Username: "pending", Email: "pending@example.com", PasswordHash: "x"}
+	for _, u := range []*models.User{suite.reader, suite.lurker, pending} {
+		suite.Require().NoError(suite.db.Create(u).Error)
+	}
+	for _, m := range []models.ClubMembership{
+		{ClubID: 1, UserID: suite.reader.ID, Role: "club_admin", IsApproved: true},
+		{ClubID: 1, UserID: suite.lurker.ID, Role: "member", IsApproved: true},
+		{ClubID: 1, UserID: pending.ID, Role: "member"},
+	} {
+		suite.Require().NoError(suite.db.Omit("User", "Club").Create(&m).Error)
+	}
+}
+
+func (suite *ClubMemberActivityTestSuite) TestEachMemberPostActivity() {
+	post := func(clubID uint, status string) *models.Post {
+		p := &models.Post{Title: "t", Content: "c", Type: "discussion", UserID: suite.reader.ID, ClubID: clubID, Status: status}
+		suite.Require().NoError(suite.db.Omit("User", "Club").Create(p).Error)
+		return p
+	}
+	published := post(1, models.PostStatusPublished)
+	post(1, models.PostStatusPublished)
+	draft := post(1, models.PostStatusDraft)
+	elsewhere := post(2, models.PostStatusPublished)
+	for _, c := range []models.Comment{
+		{PostID: published.ID, UserID: suite.lurker.ID, Content: "counts"},
+		{PostID: published.ID, UserID: suite.lurker.ID, Content: "gone", IsDeleted: true},
+		{PostID: draft.ID, UserID: suite.lurker.ID, Content: "draft"},
+		{PostID: elsewhere.ID, UserID: suite.lurker.ID, Content: "other club"},
+	} {
+		suite.Require().NoError(suite.db.Omit("User").Create(&c).Error)
+	}
+
+	var rows []models.MemberPostActivity
+	suite.Require().NoError(suite.clubRepo.EachMemberPostActivity(1, func(row *models.MemberPostActivity) error {
+		rows = append(rows, *row)
+		return nil
+	}))
+
+	assert.Equal(suite.T(), []models.MemberPostActivity{
+		{UserID: suite.reader.ID, Username: "reader", Role: "club_admin", Posts: 2, Comments: 0},
+		{UserID: suite.lurker.ID, Username: "lurker", Role: "member", Posts: 0, Comments: 1},
+	}, rows)
+}
+
+func TestClubMemberActivityTestSuite(t *testing.T) {
+	suite.Run(t, new(ClubMemberActivityTestSuite))
+}
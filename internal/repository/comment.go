@@ -1,7 +1,11 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/markdown"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -26,6 +30,14 @@ func (r *commentRepository) Create(comment *models.Comment) error {
 			return err
 		}
 
+		if comment.ParentID != nil {
+			if err := tx.Model(&models.Comment{}).
+				Where("id = ?", *comment.ParentID).
+				UpdateColumn("replies_count", gorm.Expr("replies_count + ?", 1)).Error; err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 }
@@ -34,8 +46,8 @@ func (r *commentRepository) GetByID(id uint) (*models.Comment, error) {
 	var comment models.Comment
 	if err := r.db.
 		Preload("User").
-		Preload("Likes").
-		Preload("Likes.User").
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
 		First(&comment, id).Error; err != nil {
 		return nil, err
 	}
@@ -46,6 +58,9 @@ func (r *commentRepository) Update(comment *models.Comment) error {
 	return r.db.Omit(clause.Associations).Save(comment).Error
 }
 
+// Delete removes a comment. A comment that still has replies is turned into a
+// "[deleted]" placeholder instead, and placeholders left without replies are
+// pruned on the way up the thread.
 func (r *commentRepository) Delete(id uint) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		var comment models.Comment
@@ -53,87 +68,240 @@ func (r *commentRepository) Delete(id uint) error {
 			return err
 		}
 
-		if err := tx.Delete(&models.Comment{}, id).Error; err != nil {
-			return err
+		if comment.IsDeleted {
+			return gorm.ErrRecordNotFound
 		}
 
-		if err := tx.Exec("UPDATE posts SET comments_count = GREATEST(comments_count - 1, 0) WHERE id = ?", comment.PostID).Error; err != nil {
+		if err := tx.Exec("UPDATE posts SET comments_count = CASE WHEN comments_count > 0 THEN comments_count - 1 ELSE 0 END WHERE id = ?", comment.PostID).Error; err != nil {
 			return err
 		}
 
-		return nil
+		if comment.RepliesCount > 0 {
+			return tx.Model(&models.Comment{}).
+				Where("id = ?", comment.ID).
+				Updates(map[string]interface{}{
+					"is_deleted":     true,
+					"content":        models.DeletedCommentContent,
+					"content_html":   markdown.Render(models.DeletedCommentContent).HTML,
+					"render_version": markdown.RenderVersion,
+				}).Error
+		}
+
+		for {
+			if err := tx.Delete(&models.Comment{}, comment.ID).Error; err != nil {
+				return err
+			}
+			if comment.ParentID == nil {
+				return nil
+			}
+
+			var parent models.Comment
+			if err := tx.First(&parent, *comment.ParentID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return nil
+				}
+				return err
+			}
+
+			if parent.RepliesCount > 0 {
+				parent.RepliesCount--
+			}
+			if err := tx.Model(&models.Comment{}).
+				Where("id = ?", parent.ID).
+				UpdateColumn("replies_count", parent.RepliesCount).Error; err != nil {
+				return err
+			}
+
+			if !parent.IsDeleted || parent.RepliesCount > 0 {
+				return nil
+			}
+			comment = parent
+		}
 	})
 }
 
-func (r *commentRepository) ListByPostID(postID uint) ([]models.Comment, error) {
-	var comments []models.Comment
+// commentsOldestFirst keeps threads in the order they were written.
+var commentsOldestFirst = pagination.Keyset{
+	Columns: []pagination.Column{{Expr: "comments.created_at", Kind: pagination.Time}},
+	ID:      "comments.id",
+}
+
+func commentCursor(c models.Comment) pagination.Cursor {
+	return pagination.CursorFor(c.ID, c.CreatedAt)
+}
+
+func (r *commentRepository) ListTopLevelByPostID(postID uint, page pagination.Params) (pagination.Page[models.Comment], error) {
+	query := r.db.
+		Preload("User").
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
+		Where("post_id = ? AND parent_id IS NULL", postID)
+	return pagination.Find(query, commentsOldestFirst, page, commentCursor)
+}
+
+// ListFirstReplies returns up to perParent direct replies of each given parent,
+// oldest first.
+func (r *commentRepository) ListFirstReplies(parentIDs []uint, perParent int) ([]models.Comment, error) {
+	if len(parentIDs) == 0 || perParent <= 0 {
+		return []models.Comment{}, nil
+	}
+
+	ranked := r.db.Model(&models.Comment{}).
+		Select("comments.*, ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY created_at ASC, id ASC) AS reply_rank").
+		Where("parent_id IN ?", parentIDs)
+
+	var replies []models.Comment
 	if err := r.db.
+		Table("(?) AS comments", ranked).
 		Preload("User").
-		Preload("Likes").
-		Where("post_id = ?", postID).
-		Find(&comments).Error; err != nil {
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
+		Where("reply_rank <= ?", perParent).
+		Order("created_at ASC, id ASC").
+		Find(&replies).Error; err != nil {
 		return nil, err
 	}
-	return comments, nil
+	return replies, nil
 }
 
-func (r *commentRepository) ListByUserID(userID uint) ([]models.Comment, error) {
-	var comments []models.Comment
-	if err := r.db.
+func (r *commentRepository) ListReplies(parentID uint, page pagination.Params) (pagination.Page[models.Comment], error) {
+	query := r.db.
 		Preload("User").
-		Preload("Likes").
-		Where("user_id = ?", userID).
-		Find(&comments).Error; err != nil {
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
+		Where("parent_id = ?", parentID)
+	return pagination.Find(query, commentsOldestFirst, page, commentCursor)
+}
+
+// GetThread loads a comment together with all of its replies. Threads are
+// bounded by the configured depth, so this walks one level per query.
+func (r *commentRepository) GetThread(rootID uint) (*models.Comment, error) {
+	root, err := r.GetByID(rootID)
+	if err != nil {
 		return nil, err
 	}
-	return comments, nil
-}
 
-func (r *commentRepository) LikeComment(like *models.CommentLike) error {
-	return r.db.Create(like).Error
+	byParent := make(map[uint][]models.Comment)
+	parentIDs := []uint{root.ID}
+	for len(parentIDs) > 0 {
+		var level []models.Comment
+		if err := r.db.
+			Preload("User").
+			Preload("Mentions.MentionedUser").
+			Preload("Attachments", attachmentsInOrder).
+			Where("parent_id IN ?", parentIDs).
+			Order("created_at ASC, id ASC").
+			Find(&level).Error; err != nil {
+			return nil, err
+		}
+
+		parentIDs = make([]uint, 0, len(level))
+		for _, c := range level {
+			byParent[*c.ParentID] = append(byParent[*c.ParentID], c)
+			parentIDs = append(parentIDs, c.ID)
+		}
+	}
+
+	attachReplies(root, byParent)
+	return root, nil
 }
 
-func (r *commentRepository) UnlikeComment(userID, commentID uint) error {
-	return r.db.Where("user_id = ? AND comment_id = ?", userID, commentID).Delete(&models.CommentLike{}).Error
+func attachReplies(comment *models.Comment, byParent map[uint][]models.Comment) {
+	comment.Replies = byParent[comment.ID]
+	for i := range comment.Replies {
+		attachReplies(&comment.Replies[i], byParent)
+	}
 }
 
-func (r *commentRepository) CountLikes(commentID uint) (int64, error) {
-	var count int64
-	if err := r.db.Model(&models.CommentLike{}).Where("comment_id = ?", commentID).Count(&count).Error; err != nil {
-		return 0, err
+// ListByUserID returns the user's comments, newest first.
+func (r *commentRepository) ListByUserID(userID uint, page pagination.Params) (pagination.Page[models.Comment], error) {
+	newestFirst := pagination.Keyset{
+		Columns: []pagination.Column{{Expr: "comments.created_at", Kind: pagination.Time, Desc: true}},
+		ID:      "comments.id",
+		Desc:    true,
 	}
-	return count, nil
+	query := r.db.
+		Preload("User").
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
+		Where("user_id = ? AND is_deleted = ?", userID, false)
+	return pagination.Find(query, newestFirst, page, commentCursor)
 }
 
-func (r *commentRepository) ListCommentLikes(commentID uint) ([]models.CommentLikeResponse, error) {
-	var likes []models.CommentLike
-	if err := r.db.
+func (r *commentRepository) LikeComment(userID, commentID uint) error {
+	_, err := addReaction(r.db, &models.Reaction{
+		TargetType: models.ReactionTargetComment,
+		TargetID:   commentID,
+		UserID:     userID,
+		Emoji:      models.LikeReaction,
+	})
+	return err
+}
+
+func (r *commentRepository) UnlikeComment(userID, commentID uint) error {
+	_, err := removeReaction(r.db, models.ReactionTargetComment, commentID, userID, models.LikeReaction)
+	return err
+}
+
+func (r *commentRepository) ListCommentLikes(commentID uint, page pagination.Params) (pagination.Page[models.CommentLikeResponse], error) {
+	query := r.db.
 		Preload("User").
-		Where("comment_id = ?", commentID).
-		Find(&likes).Error; err != nil {
-		return nil, err
+		Where("target_type = ? AND target_id = ? AND emoji = ?", models.ReactionTargetComment, commentID, models.LikeReaction)
+	likes, err := pagination.Find(query, reactionsOldestFirst, page, reactionCursor)
+	if err != nil {
+		return pagination.Page[models.CommentLikeResponse]{}, err
 	}
-
-	res := make([]models.CommentLikeResponse, 0, len(likes))
-    for _, l := range likes {
-        res = append(res, l.ToResponse())
-    }
-    return res, nil
+	return pagination.Map(likes, models.Reaction.ToCommentLikeResponse), nil
 }
 
 func (r *commentRepository) HasUserLiked(userID, commentID uint) (bool, error) {
-    var count int64
-    err := r.db.Model(&models.CommentLike{}).
-        Where("user_id = ? AND comment_id = ?", userID, commentID).
-        Count(&count).Error
-    if err != nil {
-        return false, err
-    }
-    return count > 0, nil
-}
-
-func (r *commentRepository) UpdateLikesCount(commentID uint, count int) error {
-    return r.db.Model(&models.Comment{}).
-        Where("id = ?", commentID).
-        Update("likes_count", count).Error
-}
\ No newline at end of file
+	return hasReacted(r.db, models.ReactionTargetComment, commentID, userID, models.LikeReaction)
+}
+
+// UpdateWithRevision saves the comment and, when its content changed, records
+// the edit made by editorID.
+func (r *commentRepository) UpdateWithRevision(comment *models.Comment, editorID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var stored models.Comment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stored, comment.ID).Error; err != nil {
+			return err
+		}
+
+		if stored.Content != comment.Content {
+			now := time.Now()
+			editCount, err := recordRevision(tx,
+				&models.Revision{
+					TargetType: models.RevisionTargetComment,
+					TargetID:   stored.ID,
+					Content:    stored.Content,
+					EditorID:   stored.UserID,
+					CreatedAt:  stored.CreatedAt,
+				},
+				&models.Revision{
+					TargetType: models.RevisionTargetComment,
+					TargetID:   comment.ID,
+					Content:    comment.Content,
+					EditorID:   editorID,
+					CreatedAt:  now,
+				})
+			if err != nil {
+				return err
+			}
+			comment.EditCount = editCount
+			comment.EditedAt = &now
+		}
+
+		return tx.Omit(clause.Associations).Save(comment).Error
+	})
+}
+
+// Hide replaces a comment with a placeholder while keeping it, and its
+// replies, in the thread.
+func (r *commentRepository) Hide(id uint) error {
+	return r.db.Model(&models.Comment{}).Where("id = ?", id).UpdateColumn("is_hidden", true).Error
+}
+
+// Release shows a comment the content filter held.
+func (r *commentRepository) Release(id uint) error {
+	return r.db.Model(&models.Comment{}).Where("id = ?", id).UpdateColumn("is_held", false).Error
+}
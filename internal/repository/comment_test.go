@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type CommentRepositoryTestSuite struct {
+	suite.Suite
+	db          *gorm.DB
+	commentRepo CommentRepository
+	post        *models.Post
+}
+
+func (suite *CommentRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.commentRepo = NewCommentRepository(suite.db)
+
+	suite.post = &models.Post{Title: "Dune", Content: "Chapter one", Type: "discussion", UserID: 1, ClubID: 1}
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(suite.post).Error)
+}
+
+func (suite *CommentRepositoryTestSuite) reply(parent *models.Comment, content string) *models.Comment {
+	comment := &models.Comment{PostID: suite.post.ID, UserID: 1, Content: content}
+	if parent != nil {
+		comment.ParentID = &parent.ID
+		comment.Depth = parent.Depth + 1
+	}
+	suite.Require().NoError(suite.commentRepo.Create(comment))
+	return comment
+}
+
+func (suite *CommentRepositoryTestSuite) commentsCount() int {
+	var post models.Post
+	suite.Require().NoError(suite.db.First(&post, suite.post.ID).Error)
+	return post.CommentsCount
+}
+
+func (suite *CommentRepositoryTestSuite) TestCreate_CountsReplies() {
+	root := suite.reply(nil, "root")
+	suite.reply(root, "first")
+	suite.reply(root, "second")
+
+	stored, err := suite.commentRepo.GetByID(root.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, stored.RepliesCount)
+	assert.Equal(suite.T(), 3, suite.commentsCount())
+}
+
+func (suite *CommentRepositoryTestSuite) TestListTopLevelAndFirstReplies() {
+	a := suite.reply(nil, "a")
+	b := suite.reply(nil, "b")
+	for _, content := range []string{"a1", "a2", "a3"} {
+		suite.reply(a, content)
+	}
+	suite.reply(b, "b1")
+
+	top, err := suite.commentRepo.ListTopLevelByPostID(suite.post.ID, pagination.First(10))
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), top.Items, 2)
+	assert.False(suite.T(), top.HasMore)
+
+	replies, err := suite.commentRepo.ListFirstReplies([]uint{a.ID, b.ID}, 2)
+	assert.NoError(suite.T(), err)
+	contents := make([]string, 0, len(replies))
+	for _, r := range replies {
+		contents = append(contents, r.Content)
+	}
+	assert.ElementsMatch(suite.T(), []string{"a1", "a2", "b1"}, contents)
+}
+
+func (suite *CommentRepositoryTestSuite) TestGetThread() {
+	root := suite.reply(nil, "root")
+	child := suite.reply(root, "child")
+	suite.reply(child, "grandchild")
+
+	thread, err := suite.commentRepo.GetThread(root.ID)
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(thread.Replies, 1)
+	suite.Require().Len(thread.Replies[0].Replies, 1)
+	assert.Equal(suite.T(), "grandchild", thread.Replies[0].Replies[0].Content)
+}
+
+func (suite *CommentRepositoryTestSuite) TestDelete_LeavesPlaceholderForReplies() {
+	root := suite.reply(nil, "root")
+	child := suite.reply(root, "child")
+
+	assert.NoError(suite.T(), suite.commentRepo.Delete(root.ID))
+
+	stored, err := suite.commentRepo.GetByID(root.ID)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), stored.IsDeleted)
+	assert.Equal(suite.T(), models.DeletedCommentContent, stored.Content)
+	assert.Equal(suite.T(), 1, suite.commentsCount())
+
+	// removing the last reply prunes the placeholder as well
+	assert.NoError(suite.T(), suite.commentRepo.Delete(child.ID))
+	_, err = suite.commentRepo.GetByID(root.ID)
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+	assert.Equal(suite.T(), 0, suite.commentsCount())
+}
+
+func TestCommentRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(CommentRepositoryTestSuite))
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"gorm.io/gorm"
+)
+
+type contentRepository struct {
+	db *gorm.DB
+}
+
+func NewContentRepository(db *gorm.DB) *contentRepository {
+	return &contentRepository{db: db}
+}
+
+// ListStalePosts returns posts rendered with an older version than version,
+// ordered by id and starting after afterID.
+func (r *contentRepository) ListStalePosts(version int, afterID uint, limit int) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.
+		Select("id, content").
+		Where("render_version < ? AND id > ?", version, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
+
+func (r *contentRepository) ListStaleComments(version int, afterID uint, limit int) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.db.
+		Select("id, content").
+		Where("render_version < ? AND id > ?", version, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&comments).Error
+	return comments, err
+}
+
+// SavePostRendering stores the rendered fields only, leaving updated_at alone.
+func (r *contentRepository) SavePostRendering(post *models.Post) error {
+	return r.db.Model(&models.Post{}).
+		Where("id = ?", post.ID).
+		UpdateColumns(map[string]interface{}{
+			"content_html":   post.ContentHTML,
+			"excerpt":        post.Excerpt,
+			"render_version": post.RenderVersion,
+		}).Error
+}
+
+func (r *contentRepository) SaveCommentRendering(comment *models.Comment) error {
+	return r.db.Model(&models.Comment{}).
+		Where("id = ?", comment.ID).
+		UpdateColumns(map[string]interface{}{
+			"content_html":   comment.ContentHTML,
+			"render_version": comment.RenderVersion,
+		}).Error
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type contentFilterRepository struct {
+	db *gorm.DB
+}
+
+func NewContentFilterRepository(db *gorm.DB) *contentFilterRepository {
+	return &contentFilterRepository{db: db}
+}
+
+func (r *contentFilterRepository) GetClubFilter(clubID uint) (*models.ClubContentFilter, error) {
+	var filter models.ClubContentFilter
+	if err := r.db.Where("club_id = ?", clubID).First(&filter).Error; err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
+
+// SaveClubFilter stores a club's overrides, replacing any earlier ones.
+func (r *contentFilterRepository) SaveClubFilter(filter *models.ClubContentFilter) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "club_id"}},
+		UpdateAll: true,
+	}).Create(filter).Error
+}
+
+// ListRecentContent returns the posts and comments userID wrote since the
+// given time, newest first and at most limit of them.
+func (r *contentFilterRepository) ListRecentContent(userID uint, since time.Time, limit int) ([]models.RecentContent, error) {
+	var posts []models.Post
+	if err := r.db.Select("id", "content", "created_at").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	var comments []models.Comment
+	if err := r.db.Select("id", "content", "created_at").
+		Where("user_id = ? AND created_at >= ? AND is_deleted = ?", userID, since, false).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	recent := make([]models.RecentContent, 0, len(posts)+len(comments))
+	for _, p := range posts {
+		recent = append(recent, models.RecentContent{Kind: models.ReportTargetPost, ID: p.ID, Text: p.Content, CreatedAt: p.CreatedAt})
+	}
+	for _, c := range comments {
+		recent = append(recent, models.RecentContent{Kind: models.ReportTargetComment, ID: c.ID, Text: c.Content, CreatedAt: c.CreatedAt})
+	}
+	sort.Slice(recent, func(i, j int) bool {
+		return recent[i].CreatedAt.After(recent[j].CreatedAt)
+	})
+	if len(recent) > limit {
+		recent = recent[:limit]
+	}
+	return recent, nil
+}
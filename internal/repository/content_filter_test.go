@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ContentFilterRepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	filterRepo ContentFilterRepository
+}
+
+func (suite *ContentFilterRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.filterRepo = NewContentFilterRepository(suite.db)
+}
+
+func (suite *ContentFilterRepositoryTestSuite) TestSaveClubFilter_Replaces() {
+	_, err := suite.filterRepo.GetClubFilter(1)
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+
+	action := models.FilterActionHold
+	suite.Require().NoError(suite.filterRepo.SaveClubFilter(&models.ClubContentFilter{ClubID: 1, HoldWords: pq.StringArray{"casino"}, LinkAction: &action, UpdatedBy: 5}))
+	suite.Require().NoError(suite.filterRepo.SaveClubFilter(&models.ClubContentFilter{ClubID: 1, FlagWords: pq.StringArray{"spoiler"}, UpdatedBy: 6}))
+
+	filter, err := suite.filterRepo.GetClubFilter(1)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), filter.HoldWords)
+	assert.Equal(suite.T(), pq.StringArray{"spoiler"}, filter.FlagWords)
+	assert.Nil(suite.T(), filter.LinkAction)
+	assert.Equal(suite.T(), uint(6), filter.UpdatedBy)
+}
+
+func (suite *ContentFilterRepositoryTestSuite) TestListRecentContent() {
+	now := time.Now()
+	post := &models.Post{Title: "t", Content: "post", Type: "discussion", UserID: 1, ClubID: 1, CreatedAt: now.Add(-2 * time.Hour)}
+	oldPost := &models.Post{Title: "t", Content: "old", Type: "discussion", UserID: 1, ClubID: 1, CreatedAt: now.Add(-48 * time.Hour)}
+	theirs := &models.Post{Title: "t", Content: "theirs", Type: "discussion", UserID: 2, ClubID: 1, CreatedAt: now}
+	for _, p := range []*models.Post{post, oldPost, theirs} {
+		suite.Require().NoError(suite.db.Omit("User", "Club").Create(p).Error)
+	}
+	comment := &models.Comment{PostID: post.ID, UserID: 1, Content: "comment", CreatedAt: now.Add(-time.Hour)}
+	deleted := &models.Comment{PostID: post.ID, UserID: 1, Content: "gone", IsDeleted: true, CreatedAt: now}
+	for _, c := range []*models.Comment{comment, deleted} {
+		suite.Require().NoError(suite.db.Omit("User").Create(c).Error)
+	}
+
+	recent, err := suite.filterRepo.ListRecentContent(1, now.Add(-24*time.Hour), 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(recent, 2)
+	assert.Equal(suite.T(), models.RecentContent{Kind: models.ReportTargetComment, ID: comment.ID, Text: "comment", CreatedAt: recent[0].CreatedAt}, recent[0], "newest first")
+	assert.Equal(suite.T(), post.ID, recent[1].ID)
+
+	recent, err = suite.filterRepo.ListRecentContent(1, now.Add(-24*time.Hour), 1)
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), recent, 1)
+}
+
+func TestContentFilterRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ContentFilterRepositoryTestSuite))
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/markdown"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ContentRepositoryTestSuite struct {
+	suite.Suite
+	db          *gorm.DB
+	contentRepo ContentRepository
+}
+
+func (suite *ContentRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.contentRepo = NewContentRepository(suite.db)
+}
+
+func (suite *ContentRepositoryTestSuite) TestStalePostsAreRerendered() {
+	stale := &models.Post{Title: "Old", Content: "**bold**", Type: "discussion", UserID: 1, ClubID: 1}
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(stale).Error)
+
+	fresh := &models.Post{Title: "New", Content: "plain", Type: "discussion", UserID: 1, ClubID: 1}
+	fresh.RenderContent()
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(fresh).Error)
+
+	posts, err := suite.contentRepo.ListStalePosts(markdown.RenderVersion, 0, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(posts, 1)
+	assert.Equal(suite.T(), stale.ID, posts[0].ID)
+
+	posts[0].RenderContent()
+	suite.Require().NoError(suite.contentRepo.SavePostRendering(&posts[0]))
+
+	var stored models.Post
+	suite.Require().NoError(suite.db.First(&stored, stale.ID).Error)
+	assert.Contains(suite.T(), stored.ContentHTML, "<strong>bold</strong>")
+	assert.Equal(suite.T(), "bold", stored.Excerpt)
+	assert.Equal(suite.T(), markdown.RenderVersion, stored.RenderVersion)
+
+	posts, err = suite.contentRepo.ListStalePosts(markdown.RenderVersion, 0, 10)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), posts)
+}
+
+func (suite *ContentRepositoryTestSuite) TestStaleCommentsAreRerendered() {
+	post := &models.Post{Title: "Post", Content: "text", Type: "discussion", UserID: 1, ClubID: 1}
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(post).Error)
+	comment := &models.Comment{PostID: post.ID, UserID: 1, Content: "see https://example.com"}
+	suite.Require().NoError(suite.db.Omit("User").Create(comment).Error)
+
+	comments, err := suite.contentRepo.ListStaleComments(markdown.RenderVersion, 0, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(comments, 1)
+
+	comments[0].RenderContent()
+	suite.Require().NoError(suite.contentRepo.SaveCommentRendering(&comments[0]))
+
+	var stored models.Comment
+	suite.Require().NoError(suite.db.First(&stored, comment.ID).Error)
+	assert.Contains(suite.T(), stored.ContentHTML, `rel="nofollow ugc"`)
+	assert.Equal(suite.T(), markdown.RenderVersion, stored.RenderVersion)
+}
+
+func TestContentRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ContentRepositoryTestSuite))
+}
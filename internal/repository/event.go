@@ -2,6 +2,7 @@ package repository
 
 import (
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
 )
 
@@ -17,17 +18,22 @@ func (r *eventRepository) Create(event *models.Event) error {
 	return r.db.Create(event).Error
 }
 
-func (r *eventRepository) GetClubEvents(clubID uint) ([]models.Event, error) {
-	var events []models.Event
-	err := r.db.
-		Where("club_id = ?", clubID).
-		Preload("RSVPs").
-		Find(&events).Error
-	if err != nil {
-		return nil, err
-	}
+// eventsLatestFirst orders events by their date, latest first.
+var eventsLatestFirst = pagination.Keyset{
+	Columns: []pagination.Column{{Expr: "events.event_date", Kind: pagination.Time, Desc: true}},
+	ID:      "events.id",
+	Desc:    true,
+}
 
-	return events, nil
+func eventCursor(e models.Event) pagination.Cursor {
+	return pagination.CursorFor(e.ID, e.EventDate)
+}
+
+func (r *eventRepository) GetClubEvents(clubID uint, page pagination.Params) (pagination.Page[models.Event], error) {
+	query := r.db.
+		Where("club_id = ?", clubID).
+		Preload("RSVPs")
+	return pagination.Find(query, eventsLatestFirst, page, eventCursor)
 }
 
 func (r *eventRepository) GetByID(id uint) (*models.Event, error) {
@@ -67,27 +73,49 @@ func (r *eventRepository) RSVP(eventID uint, rsvp *models.EventRSVP) error {
 	return r.db.Save(&existingRSVP).Error
 }
 
-func (r *eventRepository) GetEventAttendees(eventID uint) ([]models.EventRSVP, error) {
+func (r *eventRepository) MarkAttendance(eventID uint, userIDs []uint, attended bool) (int64, error) {
+	result := r.db.Model(&models.EventRSVP{}).
+		Where("event_id = ? AND user_id IN ?", eventID, userIDs).
+		Update("attended", attended)
+	return result.RowsAffected, result.Error
+}
+
+// GetEventAttendees returns the event's RSVPs in the order they came in.
+func (r *eventRepository) GetEventAttendees(eventID uint, page pagination.Params) (pagination.Page[models.EventRSVP], error) {
+	oldestFirst := pagination.Keyset{
+		Columns: []pagination.Column{{Expr: "event_rsvps.created_at", Kind: pagination.Time}},
+		ID:      "event_rsvps.id",
+	}
+	query := r.db.
+		Where("event_id = ?", eventID).
+		Preload("User")
+	return pagination.Find(query, oldestFirst, page, func(rsvp models.EventRSVP) pagination.Cursor {
+		return pagination.CursorFor(rsvp.ID, rsvp.CreatedAt)
+	})
+}
+
+// ListEventsAttendees returns the RSVPs of all the given events in one query,
+// grouped by event and oldest first within each.
+func (r *eventRepository) ListEventsAttendees(eventIDs []uint) ([]models.EventRSVP, error) {
 	var rsvps []models.EventRSVP
+	if len(eventIDs) == 0 {
+		return rsvps, nil
+	}
 	err := r.db.
-		Where("event_id = ?", eventID).
+		Where("event_id IN ?", eventIDs).
 		Preload("User").
+		Order("event_id, created_at, id").
 		Find(&rsvps).Error
-	if err != nil {
-		return nil, err
-	}
-	return rsvps, nil
+	return rsvps, err
 }
 
-func (r *eventRepository) GetPublicEvents() ([]models.Event, error) {
-	var events []models.Event
-	err := r.db.
-		Where("is_public = ?", true).
-		Preload("RSVPs").
-		Find(&events).Error
-	if err != nil {
-		return nil, err
+func (r *eventRepository) GetPublicEvents(cityID, districtID string, page pagination.Params) (pagination.Page[models.Event], error) {
+	query := r.db.Where("is_public = ?", true)
+	if cityID != "" {
+		query = query.Where("city_id = ?", cityID)
 	}
-
-	return events, nil
+	if districtID != "" {
+		query = query.Where("district_id = ?", districtID)
+	}
+	return pagination.Find(query.Preload("RSVPs"), eventsLatestFirst, page, eventCursor)
 }
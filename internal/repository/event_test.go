@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type EventRepositoryTestSuite struct {
+	suite.Suite
+	db        *gorm.DB
+	eventRepo EventRepository
+}
+
+func (suite *EventRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.eventRepo = NewEventRepository(suite.db)
+}
+
+func (suite *EventRepositoryTestSuite) TestListEventsAttendees() {
+	var users []*models.User
+	for _, name := range []string{"ada", "ben"} {
+		u := &models.User{Username: name, Email: name + "@example.com", PasswordHash: "x"}
+		suite.Require().NoError(suite.db.Create(u).Error)
+		users = append(users, u)
+	}
+	var events []*models.Event
+	for _, title := range []string{"first", "second", "third"} {
+		e := &models.Event{Title: title, ClubID: 1, EventDate: time.Now(), EventTime: models.DBTime{Time: time.Now()}}
+		suite.Require().NoError(suite.db.Omit("Club").Create(e).Error)
+		events = append(events, e)
+	}
+	for _, r := range []models.EventRSVP{
+		{EventID: events[1].ID, UserID: users[1].ID, Status: models.RSVPGoing, CreatedAt: time.Now().Add(-time.Hour)},
+		{EventID: events[0].ID, UserID: users[0].ID, Status: models.RSVPGoing, Attended: true},
+		{EventID: events[1].ID, UserID: users[0].ID, Status: models.RSVPGoing},
+		{EventID: events[2].ID, UserID: users[0].ID, Status: models.RSVPGoing},
+	} {
+		suite.Require().NoError(suite.db.Omit("User", "Event").Create(&r).Error)
+	}
+
+	rsvps, err := suite.eventRepo.ListEventsAttendees([]uint{events[0].ID, events[1].ID})
+	suite.Require().NoError(err)
+
+	suite.Require().Len(rsvps, 3, "only the given events")
+	assert.Equal(suite.T(), events[0].ID, rsvps[0].EventID)
+	assert.Equal(suite.T(), "ada", rsvps[0].User.Username)
+	assert.Equal(suite.T(), events[1].ID, rsvps[1].EventID)
+	assert.Equal(suite.T(), "ben", rsvps[1].User.Username, "oldest first within an event")
+	assert.Equal(suite.T(), "ada", rsvps[2].User.Username)
+
+	rsvps, err = suite.eventRepo.ListEventsAttendees(nil)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), rsvps)
+}
+
+func TestEventRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(EventRepositoryTestSuite))
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"gorm.io/gorm"
+)
+
+type feedRepository struct {
+	db *gorm.DB
+}
+
+func NewFeedRepository(db *gorm.DB) *feedRepository {
+	return &feedRepository{db: db}
+}
+
+type feedPostRow struct {
+	ID            uint
+	ClubID        uint
+	UserID        uint
+	PublishedAt   *time.Time
+	CreatedAt     time.Time
+	LikesCount    int
+	CommentsCount int
+}
+
+func (r *feedRepository) feedPosts() *gorm.DB {
+	return r.db.Model(&models.Post{}).
+		Select("posts.id, posts.club_id, posts.user_id, posts.published_at, posts.created_at, posts.likes_count, posts.comments_count").
+		Joins("JOIN clubs ON clubs.id = posts.club_id AND clubs.deleted_at IS NULL").
+		Where("posts.status = ?", models.PostStatusPublished)
+}
+
+func scanFeedPosts(query *gorm.DB) ([]models.FeedPost, error) {
+	var rows []feedPostRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]models.FeedPost, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, models.FeedPost{
+			PostID:        row.ID,
+			ClubID:        row.ClubID,
+			AuthorID:      row.UserID,
+			PublishedAt:   postSortTime(row.PublishedAt, row.CreatedAt),
+			LikesCount:    row.LikesCount,
+			CommentsCount: row.CommentsCount,
+		})
+	}
+	return out, nil
+}
+
+// ListMemberClubs returns the clubs userID owns or is an approved member of.
+// MembersCount counts approved members, like ListMemberIDs, so the feed
+// splits clubs into fan-out and fan-in the same way on both sides.
+func (r *feedRepository) ListMemberClubs(userID uint) ([]models.FeedClub, error) {
+	var clubs []models.FeedClub
+	err := r.db.Model(&models.Club{}).
+		Select("clubs.id AS club_id, (SELECT COUNT(*) FROM club_memberships a WHERE a.club_id = clubs.id AND a.is_approved = ?) AS members_count", true).
+		Where("clubs.owner_id = ? OR EXISTS (SELECT 1 FROM club_memberships m WHERE m.club_id = clubs.id AND m.user_id = ? AND m.is_approved = ?)",
+			userID, userID, true).
+		Scan(&clubs).Error
+	return clubs, err
+}
+
+// ListMemberIDs returns the approved members of a club.
+func (r *feedRepository) ListMemberIDs(clubID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.ClubMembership{}).
+		Where("club_id = ? AND is_approved = ?", clubID, true).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// GetFeedPosts returns the posts among ids that are still published, in no
+// particular order.
+func (r *feedRepository) GetFeedPosts(ids []uint) ([]models.FeedPost, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return scanFeedPosts(r.feedPosts().Where("posts.id IN ?", ids))
+}
+
+// ListClubPosts returns up to limit posts published in clubIDs since, newest
+// first.
+func (r *feedRepository) ListClubPosts(clubIDs []uint, since time.Time, limit int) ([]models.FeedPost, error) {
+	if len(clubIDs) == 0 {
+		return nil, nil
+	}
+	return scanFeedPosts(r.feedPosts().
+		Where("posts.club_id IN ? AND COALESCE(posts.published_at, posts.created_at) >= ?", clubIDs, since).
+		Order("COALESCE(posts.published_at, posts.created_at) DESC, posts.id DESC").
+		Limit(limit))
+}
+
+// ListAuthorPosts returns up to limit posts by authorIDs published since in
+// clubs viewerID can read, newest first.
+func (r *feedRepository) ListAuthorPosts(authorIDs []uint, viewerID uint, since time.Time, limit int) ([]models.FeedPost, error) {
+	if len(authorIDs) == 0 {
+		return nil, nil
+	}
+	return scanFeedPosts(r.feedPosts().
+		Where("posts.user_id IN ? AND COALESCE(posts.published_at, posts.created_at) >= ?", authorIDs, since).
+		Where("clubs.is_private = ? OR clubs.owner_id = ? OR EXISTS (SELECT 1 FROM club_memberships m WHERE m.club_id = clubs.id AND m.user_id = ? AND m.is_approved = ?)",
+			false, viewerID, viewerID, true).
+		Order("COALESCE(posts.published_at, posts.created_at) DESC, posts.id DESC").
+		Limit(limit))
+}
+
+// ListTrendingPosts returns the most engaged-with posts published in public
+// clubs since.
+func (r *feedRepository) ListTrendingPosts(since time.Time, limit int) ([]models.FeedPost, error) {
+	return scanFeedPosts(r.feedPosts().
+		Where("clubs.is_private = ? AND COALESCE(posts.published_at, posts.created_at) >= ?", false, since).
+		Order("posts.likes_count + posts.comments_count DESC, posts.id DESC").
+		Limit(limit))
+}
+
+// CountClubActivity counts the posts and comments userID wrote since, per
+// club.
+func (r *feedRepository) CountClubActivity(userID uint, since time.Time) (map[uint]int, error) {
+	type row struct {
+		ClubID uint
+		N      int
+	}
+
+	var posts, comments []row
+	if err := r.db.Model(&models.Post{}).
+		Select("club_id, COUNT(*) AS n").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Group("club_id").
+		Scan(&posts).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(&models.Comment{}).
+		Select("posts.club_id, COUNT(*) AS n").
+		Joins("JOIN posts ON posts.id = comments.post_id").
+		Where("comments.user_id = ? AND comments.created_at >= ?", userID, since).
+		Group("posts.club_id").
+		Scan(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int, len(posts))
+	for _, c := range append(posts, comments...) {
+		counts[c.ClubID] += c.N
+	}
+	return counts, nil
+}
+
+// GetPostsByIDs loads posts for display, in no particular order.
+func (r *feedRepository) GetPostsByIDs(ids []uint) ([]models.Post, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var posts []models.Post
+	err := r.db.
+		Preload("User").
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
+		Preload("Club").
+		Where("id IN ? AND status = ?", ids, models.PostStatusPublished).
+		Find(&posts).Error
+	return posts, err
+}
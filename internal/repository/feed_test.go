@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type FeedRepositoryTestSuite struct {
+	suite.Suite
+	db          *gorm.DB
+	feedRepo    FeedRepository
+	reader      *models.User
+	author      *models.User
+	publicClub  *models.Club
+	privateClub *models.Club
+}
+
+func (suite *FeedRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.feedRepo = NewFeedRepository(suite.db)
+
+	suite.reader = &models.User{Username: "reader", Email: "reader@example.com", PasswordHash: "x"}
+	suite.author = &models.User{Username: "author", Email: "author@example.com", PasswordHash: "x"}
+	suite.Require().NoError(suite.db.Create(suite.reader).Error)
+	suite.Require().NoError(suite.db.Create(suite.author).Error)
+
+	suite.publicClub = &models.Club{Name: "Public"}
+	suite.privateClub = &models.Club{Name: "Private", IsPrivate: true}
+	suite.Require().NoError(suite.db.Omit("Owner", "Members", "Tags").Create(suite.publicClub).Error)
+	suite.Require().NoError(suite.db.Omit("Owner", "Members", "Tags").Create(suite.privateClub).Error)
+}
+
+func (suite *FeedRepositoryTestSuite) join(userID, clubID uint, approved bool) {
+	suite.Require().NoError(suite.db.Omit("User", "Club").Create(&models.ClubMembership{
+		UserID: userID, ClubID: clubID, IsApproved: approved,
+	}).Error)
+}
+
+func (suite *FeedRepositoryTestSuite) createPost(clubID uint, status string, publishedAt time.Time, likes int) *models.Post {
+	post := &models.Post{Title: "Post", Content: "text", Type: "discussion", UserID: suite.author.ID, ClubID: clubID, Status: status, LikesCount: likes}
+	if status == models.PostStatusPublished {
+		post.PublishedAt = &publishedAt
+	}
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(post).Error)
+	return post
+}
+
+func (suite *FeedRepositoryTestSuite) TestListMemberClubsCountsApprovedMembers() {
+	suite.join(suite.reader.ID, suite.publicClub.ID, true)
+	suite.join(suite.author.ID, suite.publicClub.ID, false)
+	suite.join(suite.reader.ID, suite.privateClub.ID, false)
+
+	clubs, err := suite.feedRepo.ListMemberClubs(suite.reader.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(clubs, 1, "pending memberships do not count")
+	assert.Equal(suite.T(), suite.publicClub.ID, clubs[0].ClubID)
+	assert.Equal(suite.T(), 1, clubs[0].MembersCount)
+
+	ids, err := suite.feedRepo.ListMemberIDs(suite.publicClub.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), []uint{suite.reader.ID}, ids)
+}
+
+func (suite *FeedRepositoryTestSuite) TestFanInSources() {
+	now := time.Now()
+	old := suite.createPost(suite.publicClub.ID, models.PostStatusPublished, now.AddDate(0, 0, -30), 50)
+	public := suite.createPost(suite.publicClub.ID, models.PostStatusPublished, now.Add(-time.Hour), 1)
+	popular := suite.createPost(suite.publicClub.ID, models.PostStatusPublished, now.Add(-2*time.Hour), 9)
+	private := suite.createPost(suite.privateClub.ID, models.PostStatusPublished, now, 20)
+	suite.createPost(suite.publicClub.ID, models.PostStatusDraft, now, 0)
+	since := now.AddDate(0, 0, -7)
+
+	posts, err := suite.feedRepo.ListClubPosts([]uint{suite.publicClub.ID}, since, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(posts, 2)
+	assert.Equal(suite.T(), public.ID, posts[0].PostID, "newest first")
+	assert.Equal(suite.T(), suite.author.ID, posts[0].AuthorID)
+
+	posts, err = suite.feedRepo.ListAuthorPosts([]uint{suite.author.ID}, suite.reader.ID, since, 10)
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), posts, 2, "posts in private clubs stay hidden from non-members")
+
+	suite.join(suite.reader.ID, suite.privateClub.ID, true)
+	posts, err = suite.feedRepo.ListAuthorPosts([]uint{suite.author.ID}, suite.reader.ID, since, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(posts, 3)
+	assert.Equal(suite.T(), private.ID, posts[0].PostID)
+
+	posts, err = suite.feedRepo.ListTrendingPosts(since, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(posts, 2)
+	assert.Equal(suite.T(), popular.ID, posts[0].PostID)
+
+	posts, err = suite.feedRepo.GetFeedPosts([]uint{old.ID, public.ID, 999})
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), posts, 2)
+}
+
+func (suite *FeedRepositoryTestSuite) TestCountClubActivity() {
+	now := time.Now()
+	post := suite.createPost(suite.publicClub.ID, models.PostStatusPublished, now, 0)
+	mine := &models.Post{Title: "Mine", Content: "text", Type: "discussion", UserID: suite.reader.ID, ClubID: suite.privateClub.ID}
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(mine).Error)
+	for i := 0; i < 2; i++ {
+		suite.Require().NoError(suite.db.Omit("User", "Post", "Parent").Create(&models.Comment{PostID: post.ID, UserID: suite.reader.ID, Content: "hi"}).Error)
+	}
+
+	counts, err := suite.feedRepo.CountClubActivity(suite.reader.ID, now.Add(-time.Hour))
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), map[uint]int{suite.publicClub.ID: 2, suite.privateClub.ID: 1}, counts)
+}
+
+func TestFeedRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(FeedRepositoryTestSuite))
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type followRepository struct {
+	db *gorm.DB
+}
+
+func NewFollowRepository(db *gorm.DB) *followRepository {
+	return &followRepository{db: db}
+}
+
+// Follow is idempotent; following someone twice is not an error.
+func (r *followRepository) Follow(followerID, followeeID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.UserFollow{FollowerID: followerID, FolloweeID: followeeID}).Error
+}
+
+func (r *followRepository) Unfollow(followerID, followeeID uint) (bool, error) {
+	res := r.db.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&models.UserFollow{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *followRepository) ListFolloweeIDs(followerID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.UserFollow{}).
+		Where("follower_id = ?", followerID).
+		Pluck("followee_id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+type hashtagRepository struct {
+	db *gorm.DB
+}
+
+func NewHashtagRepository(db *gorm.DB) *hashtagRepository {
+	return &hashtagRepository{db: db}
+}
+
+// Replace swaps the stored hashtags of a post for tags.
+func (r *hashtagRepository) Replace(postID, clubID uint, tags []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", postID).Delete(&models.PostHashtag{}).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+
+		rows := make([]models.PostHashtag, 0, len(tags))
+		for _, tag := range tags {
+			rows = append(rows, models.PostHashtag{PostID: postID, ClubID: clubID, Tag: tag})
+		}
+		return tx.Omit("Post").Create(&rows).Error
+	})
+}
+
+// ListPostsByTag returns published posts using tag, newest first. Posts in
+// private clubs are only included for the owner and approved members;
+// viewerID is nil for anonymous requests.
+func (r *hashtagRepository) ListPostsByTag(tag string, viewerID *uint, page pagination.Params) (pagination.Page[models.Post], error) {
+	query := r.db.Model(&models.Post{}).
+		Joins("JOIN post_hashtags ON post_hashtags.post_id = posts.id").
+		Joins("JOIN clubs ON clubs.id = posts.club_id AND clubs.deleted_at IS NULL").
+		Where("post_hashtags.tag = ? AND posts.status = ?", tag, models.PostStatusPublished)
+
+	if viewerID == nil {
+		query = query.Where("clubs.is_private = ?", false)
+	} else {
+		query = query.Where(
+			"clubs.is_private = ? OR clubs.owner_id = ? OR EXISTS (SELECT 1 FROM club_memberships m WHERE m.club_id = clubs.id AND m.user_id = ? AND m.is_approved = ?)",
+			false, *viewerID, *viewerID, true)
+	}
+
+	query = query.
+		Preload("User").
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
+		Preload("Club")
+	return pagination.Find(query, postsNewestFirst, page, postNewestCursor)
+}
+
+// ListRecentUses returns the tags of published posts in public clubs that
+// were published at or after since.
+func (r *hashtagRepository) ListRecentUses(since time.Time) ([]models.HashtagUse, error) {
+	var uses []models.HashtagUse
+	if err := r.db.Model(&models.PostHashtag{}).
+		Select("post_hashtags.tag, posts.published_at").
+		Joins("JOIN posts ON posts.id = post_hashtags.post_id AND posts.deleted_at IS NULL").
+		Joins("JOIN clubs ON clubs.id = posts.club_id AND clubs.deleted_at IS NULL").
+		Where("posts.status = ? AND posts.published_at >= ? AND clubs.is_private = ?", models.PostStatusPublished, since, false).
+		Scan(&uses).Error; err != nil {
+		return nil, err
+	}
+	return uses, nil
+}
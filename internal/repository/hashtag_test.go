@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type HashtagRepositoryTestSuite struct {
+	suite.Suite
+	db          *gorm.DB
+	hashtagRepo HashtagRepository
+	publicClub  *models.Club
+	privateClub *models.Club
+	member      *models.User
+}
+
+func (suite *HashtagRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.hashtagRepo = NewHashtagRepository(suite.db)
+
+	suite.member = &models.User{Username: "member", Email: "member@example.com", PasswordHash: "x"}
+	suite.Require().NoError(suite.db.Create(suite.member).Error)
+
+	suite.publicClub = &models.Club{Name: "Public"}
+	suite.privateClub = &models.Club{Name: "Private", IsPrivate: true}
+	suite.Require().NoError(suite.db.Omit("Owner", "Members", "Tags").Create(suite.publicClub).Error)
+	suite.Require().NoError(suite.db.Omit("Owner", "Members", "Tags").Create(suite.privateClub).Error)
+	suite.Require().NoError(suite.db.Omit("User", "Club").Create(&models.ClubMembership{
+		UserID: suite.member.ID, ClubID: suite.privateClub.ID, IsApproved: true,
+	}).Error)
+}
+
+func (suite *HashtagRepositoryTestSuite) createPost(clubID uint, status string, publishedAt time.Time, tags ...string) *models.Post {
+	post := &models.Post{Title: "Post", Content: "text", Type: "discussion", UserID: suite.member.ID, ClubID: clubID, Status: status}
+	if status == models.PostStatusPublished {
+		post.PublishedAt = &publishedAt
+	}
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(post).Error)
+	suite.Require().NoError(suite.hashtagRepo.Replace(post.ID, clubID, tags))
+	return post
+}
+
+func (suite *HashtagRepositoryTestSuite) TestReplace() {
+	post := suite.createPost(suite.publicClub.ID, models.PostStatusPublished, time.Now(), "kitap", "roman")
+	suite.Require().NoError(suite.hashtagRepo.Replace(post.ID, suite.publicClub.ID, []string{"kitap"}))
+
+	var tags []string
+	suite.Require().NoError(suite.db.Model(&models.PostHashtag{}).Where("post_id = ?", post.ID).Pluck("tag", &tags).Error)
+	assert.Equal(suite.T(), []string{"kitap"}, tags)
+}
+
+func (suite *HashtagRepositoryTestSuite) TestListPostsByTag_Visibility() {
+	now := time.Now()
+	public := suite.createPost(suite.publicClub.ID, models.PostStatusPublished, now.Add(-time.Hour), "kitap")
+	private := suite.createPost(suite.privateClub.ID, models.PostStatusPublished, now, "kitap")
+	suite.createPost(suite.publicClub.ID, models.PostStatusDraft, now, "kitap")
+	suite.createPost(suite.publicClub.ID, models.PostStatusPublished, now, "roman")
+
+	posts, err := suite.hashtagRepo.ListPostsByTag("kitap", nil, pagination.First(10))
+	suite.Require().NoError(err)
+	suite.Require().Len(posts.Items, 1)
+	assert.Equal(suite.T(), public.ID, posts.Items[0].ID)
+
+	posts, err = suite.hashtagRepo.ListPostsByTag("kitap", &suite.member.ID, pagination.First(10))
+	suite.Require().NoError(err)
+	suite.Require().Len(posts.Items, 2)
+	assert.Equal(suite.T(), private.ID, posts.Items[0].ID, "newest first")
+
+	outsider := uint(999)
+	posts, err = suite.hashtagRepo.ListPostsByTag("kitap", &outsider, pagination.First(10))
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), posts.Items, 1)
+}
+
+func (suite *HashtagRepositoryTestSuite) TestListRecentUses() {
+	now := time.Now()
+	suite.createPost(suite.publicClub.ID, models.PostStatusPublished, now.Add(-time.Hour), "kitap", "roman")
+	suite.createPost(suite.publicClub.ID, models.PostStatusPublished, now.Add(-100*time.Hour), "eski")
+	suite.createPost(suite.privateClub.ID, models.PostStatusPublished, now, "gizli")
+	suite.createPost(suite.publicClub.ID, models.PostStatusDraft, now, "taslak")
+
+	uses, err := suite.hashtagRepo.ListRecentUses(now.Add(-72 * time.Hour))
+	suite.Require().NoError(err)
+
+	var tags []string
+	for _, use := range uses {
+		tags = append(tags, use.Tag)
+		assert.WithinDuration(suite.T(), now.Add(-time.Hour), use.PublishedAt, time.Second)
+	}
+	assert.ElementsMatch(suite.T(), []string{"kitap", "roman"}, tags)
+}
+
+func TestHashtagRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(HashtagRepositoryTestSuite))
+}
@@ -1,6 +1,12 @@
 package repository
 
-import "github.com/nevzattalhaozcan/forgotten/internal/models"
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+)
 
 type UserRepository interface {
 	Create(user *models.User) error
@@ -9,7 +15,7 @@ type UserRepository interface {
 	GetByUsername(username string) (*models.User, error)
 	Update(user *models.User) error
 	Delete(id uint) error
-	List(limit, offset int) ([]*models.User, error)
+	List(page pagination.Params) (pagination.Page[*models.User], error)
 	SearchByUsernameOrName(query string, limit int) ([]*models.User, error)
 	GetByEmailIncludingDeleted(email string) (*models.User, error)
 	GetByUsernameIncludingDeleted(username string) (*models.User, error)
@@ -20,35 +26,171 @@ type ClubRepository interface {
 	GetByID(id uint) (*models.Club, error)
 	Update(club *models.Club) error
 	Delete(id uint) error
-	List(limit, offset int) ([]*models.Club, error)
-	ListWithFilters(location, genre, meetingType string, minMembers, maxMembers, limit, offset int) ([]*models.Club, error)
+	List(page pagination.Params) (pagination.Page[*models.Club], error)
+	ListWithFilters(location, cityID, districtID, genre, meetingType string, minMembers, maxMembers int, page pagination.Params) (pagination.Page[*models.Club], error)
 	GetByName(name string) (*models.Club, error)
 	JoinClub(membership *models.ClubMembership) error
 	LeaveClub(clubID, userID uint) error
-	ListClubMembers(clubID uint) ([]*models.ClubMembership, error)
+	ListClubMembers(clubID uint, page pagination.Params) (pagination.Page[*models.ClubMembership], error)
 	UpdateClubMember(membership *models.ClubMembership) error
 	GetClubMemberByUserID(clubID, userID uint) (*models.ClubMembership, error)
-	UpdateRatingAggregate(clubID uint, avg float32, count int) error
+	UpdateRatingAggregate(clubID uint, avg float32, count int, histogram models.RatingHistogram) error
 	UpdateMembership(m *models.ClubMembership) error
 	CountApprovedMembers(clubID uint) (int64, error)
-	ListUserClubs(userID uint) ([]*models.Club, error)
+	ListUserClubs(userID uint, page pagination.Params) (pagination.Page[*models.Club], error)
+	EachMemberPostActivity(clubID uint, fn func(row *models.MemberPostActivity) error) error
 }
 
 type ClubRatingRepository interface {
     UpsertRating(r *models.ClubRating) error
-    ListByClub(clubID uint, limit, offset int) ([]models.ClubRating, error)
-    GetAggregateForClub(clubID uint) (avg float32, count int, err error)
+    ListByClub(clubID uint, page pagination.Params) (pagination.Page[models.ClubRating], error)
+    GetAggregateForClub(clubID uint, formerMemberWeight float64) (avg float32, count int, err error)
+    GetHistogramForClub(clubID uint, includeFormerMembers bool) (models.RatingHistogram, error)
+    GetByID(id uint) (*models.ClubRating, error)
+    Update(r *models.ClubRating) error
+    SetMemberLeft(clubID, userID uint, leftAt *time.Time) (int64, error)
+    Report(report *models.ClubRatingReport) (bool, error)
+    Hide(id uint) error
+    Delete(id uint) error
+}
+
+type TagRepository interface {
+	Create(tag *models.Tag) error
+	GetByID(id uint) (*models.Tag, error)
+	FindByKey(key string) (*models.Tag, error)
+	AddAlias(alias *models.TagAlias) error
+	Search(key string, limit int) ([]models.Tag, error)
+	UsageCounts(names []string) (map[string]int, error)
+	Merge(target *models.Tag, sources []models.Tag, rewrite func(tags pq.StringArray) (pq.StringArray, bool)) (int64, error)
+}
+
+type LocationRepository interface {
+	ListUnresolved(table string, afterID uint, limit int) ([]models.LocationRecord, error)
+	SetLocation(table string, id uint, cityID, districtID *string) error
+}
+
+type MentionRepository interface {
+	Replace(targetType string, targetID uint, mentions []models.Mention) error
+	ListByMentionedUser(userID uint, page pagination.Params) (pagination.Page[models.Mention], error)
+}
+
+type HashtagRepository interface {
+	Replace(postID, clubID uint, tags []string) error
+	ListPostsByTag(tag string, viewerID *uint, page pagination.Params) (pagination.Page[models.Post], error)
+	ListRecentUses(since time.Time) ([]models.HashtagUse, error)
+}
+
+type ViewRepository interface {
+	ApplyDeltas(deltas []models.PostViewDelta) error
+	ListDaily(postID uint, from, to time.Time) ([]models.PostViewStat, error)
+}
+
+type BlockRepository interface {
+	Block(blockerID, blockedID uint) error
+	Unblock(blockerID, blockedID uint) (bool, error)
+	ListBlockersOf(blockedID uint, candidateIDs []uint) ([]uint, error)
+}
+
+type FollowRepository interface {
+	Follow(followerID, followeeID uint) error
+	Unfollow(followerID, followeeID uint) (bool, error)
+	ListFolloweeIDs(followerID uint) ([]uint, error)
+}
+
+type BookmarkRepository interface {
+	CreateCollection(collection *models.BookmarkCollection) error
+	GetCollection(userID, id uint) (*models.BookmarkCollection, error)
+	GetCollectionByName(userID uint, name string) (*models.BookmarkCollection, error)
+	RenameCollection(collection *models.BookmarkCollection) error
+	DeleteCollection(userID, id uint) (bool, error)
+	ListCollections(userID uint, page pagination.Params) (pagination.Page[models.BookmarkCollectionResponse], error)
+	ListCollectionIDs(userID uint) ([]uint, error)
+	SetCollectionPositions(userID uint, ids []uint) error
+	Create(bookmark *models.Bookmark) (bool, error)
+	Get(userID, id uint) (*models.Bookmark, error)
+	Move(bookmark *models.Bookmark, collectionID *uint) error
+	Delete(userID, id uint) (bool, error)
+	List(userID uint, collectionID *uint, page pagination.Params) (pagination.Page[models.Bookmark], error)
+	ListIDsInCollection(userID, collectionID uint) ([]uint, error)
+	SetPositions(userID, collectionID uint, ids []uint) error
+	ListReadableClubIDs(userID uint, clubIDs []uint) (map[uint]bool, error)
+	GetPosts(ids []uint) ([]models.Post, error)
+	GetComments(ids []uint) ([]models.Comment, error)
+}
+
+type ReportRepository interface {
+	Create(report *models.Report) (bool, error)
+	GetByID(id uint) (*models.Report, error)
+	HasOpen(targetType string, targetID uint) (bool, error)
+	List(filter models.ReportFilter, page pagination.Params) (pagination.Page[models.Report], error)
+	Resolve(log *models.ModerationLog, status string, at time.Time) ([]models.Report, error)
+	Reopen(log *models.ModerationLog, reportIDs []uint) error
+	ListLogs(clubIDs []uint, page pagination.Params) (pagination.Page[models.ModerationLog], error)
+	ListModeratedClubIDs(userID uint) ([]uint, error)
+	ListReadableClubIDs(userID uint, clubIDs []uint) (map[uint]bool, error)
+}
+
+type ContentFilterRepository interface {
+	GetClubFilter(clubID uint) (*models.ClubContentFilter, error)
+	SaveClubFilter(filter *models.ClubContentFilter) error
+	ListRecentContent(userID uint, since time.Time, limit int) ([]models.RecentContent, error)
+}
+
+type AttachmentRepository interface {
+	Create(attachment *models.Attachment) error
+	GetByIDs(ids []uint) ([]models.Attachment, error)
+	ClubUsage(clubID uint) (int64, error)
+	SetPostAttachments(postID uint, ids []uint) (bool, error)
+	SetCommentAttachments(commentID uint, ids []uint) (bool, error)
+	DeleteByPost(postID uint) ([]models.Attachment, error)
+	DeleteByComment(commentID uint) ([]models.Attachment, error)
+	DeleteUpload(id, uploaderID uint) (*models.Attachment, error)
+	DeleteOrphans(before time.Time, limit int) ([]models.Attachment, error)
+	GetClubSettings(clubID uint) (*models.ClubAttachmentSettings, error)
+	SaveClubSettings(settings *models.ClubAttachmentSettings) error
+}
+
+type ReshareRepository interface {
+	GetOriginals(ids []uint) ([]models.Post, error)
+	ListReadableClubIDs(userID uint, clubIDs []uint) (map[uint]bool, error)
+}
+
+type NotificationRepository interface {
+	Create(notifications []models.Notification) error
+	List(userID uint, unreadOnly bool, page pagination.Params) (pagination.Page[models.Notification], error)
+	CountUnread(userID uint) (int64, error)
+	MarkRead(userID uint, ids []uint, at time.Time) (int64, error)
+}
+
+type FeedRepository interface {
+	ListMemberClubs(userID uint) ([]models.FeedClub, error)
+	ListMemberIDs(clubID uint) ([]uint, error)
+	GetFeedPosts(ids []uint) ([]models.FeedPost, error)
+	ListClubPosts(clubIDs []uint, since time.Time, limit int) ([]models.FeedPost, error)
+	ListAuthorPosts(authorIDs []uint, viewerID uint, since time.Time, limit int) ([]models.FeedPost, error)
+	ListTrendingPosts(since time.Time, limit int) ([]models.FeedPost, error)
+	CountClubActivity(userID uint, since time.Time) (map[uint]int, error)
+	GetPostsByIDs(ids []uint) ([]models.Post, error)
+}
+
+type ContentRepository interface {
+	ListStalePosts(version int, afterID uint, limit int) ([]models.Post, error)
+	ListStaleComments(version int, afterID uint, limit int) ([]models.Comment, error)
+	SavePostRendering(post *models.Post) error
+	SaveCommentRendering(comment *models.Comment) error
 }
 
 type EventRepository interface {
 	Create(event *models.Event) error
-    GetClubEvents(clubID uint) ([]models.Event, error)
+    GetClubEvents(clubID uint, page pagination.Params) (pagination.Page[models.Event], error)
     GetByID(id uint) (*models.Event, error)
     Update(event *models.Event) error
     Delete(id uint) error
     RSVP(eventID uint, rsvp *models.EventRSVP) error
-    GetEventAttendees(eventID uint) ([]models.EventRSVP, error)
-	GetPublicEvents() ([]models.Event, error)
+    GetEventAttendees(eventID uint, page pagination.Params) (pagination.Page[models.EventRSVP], error)
+    ListEventsAttendees(eventIDs []uint) ([]models.EventRSVP, error)
+	GetPublicEvents(cityID, districtID string, page pagination.Params) (pagination.Page[models.Event], error)
+	MarkAttendance(eventID uint, userIDs []uint, attended bool) (int64, error)
 }
 
 type BookRepository interface {
@@ -56,59 +198,88 @@ type BookRepository interface {
 	GetByID(id uint) (*models.Book, error)
 	Update(book *models.Book) error
 	Delete(id uint) error
-	List(limit, offset int) ([]*models.Book, error)
+	List(page pagination.Params) (pagination.Page[*models.Book], error)
 	GetByExternalID(source, externalID string) (*models.Book, error)
     GetByISBN(isbn string) (*models.Book, error)
     UpsertByExternalID(book *models.Book) error
     SearchLocal(query string, limit int) ([]*models.Book, error)
+    GetUserRating(bookID, userID uint) (*models.BookRating, error)
 }
 
 type PostRepository interface {
 	Create(post *models.Post) error
 	GetByID(id uint) (*models.Post, error)
 	Update(post *models.Post) error
+	UpdateWithRevision(post *models.Post, editorID uint) error
 	Delete(id uint) error
-	ListByUserID(userID uint) ([]models.Post, error)
-	ListByClubID(clubID uint) ([]models.Post, error)
-	ListAll() ([]models.Post, error)
-	ListPublicPosts() ([]models.Post, error)
+	ListByUserID(userID uint, page pagination.Params) (pagination.Page[models.Post], error)
+	ListByClubID(clubID uint, page pagination.Params) (pagination.Page[models.Post], error)
+	ListAll(page pagination.Params) (pagination.Page[models.Post], error)
+	ListPublicPosts(opts models.PostListOptions, page pagination.Params) (pagination.Page[models.Post], error)
 	ListPopularPublicPosts(limit int) ([]models.Post, error)
-	AddLike(like *models.PostLike) error
+	AddLike(userID, postID uint) error
 	RemoveLike(userID, postID uint) error
-	ListLikesByPostID(postID uint) ([]models.PostLikeResponse, error)
+	ListLikesByPostID(postID uint, page pagination.Params) (pagination.Page[models.PostLikeResponse], error)
 	HasUserLiked(userID, postID uint) (bool, error)
-	CountLikes(postID uint) (int64, error)
-	UpdateLikesCount(postID uint, count int) error
-    VoteOnPoll(vote *models.PollVote) error
+    AddPollVotes(postID, userID uint, votes []models.PollVote, exclusive bool) (bool, error)
+    ReplacePollVotes(postID, userID uint, votes []models.PollVote) error
     RemoveVoteFromPoll(postID, userID uint, optionID string) error
     GetUserPollVotes(postID, userID uint) ([]models.PollVote, error)
-    UpdatePollVoteCounts(postID uint) error
-    GetPostsByType(postType string, limit, offset int) ([]models.Post, error)
-    GetReviewPostsByBookID(bookID uint) ([]models.Post, error)
-    GetPollPostsByClubID(clubID uint, includeExpired bool) ([]models.Post, error)
-	ListPostSummaries(clubID uint, userID *uint, limit, offset int) ([]models.PostSummary, error)
+    ListPollVotes(postIDs []uint, userID *uint) ([]models.PollVote, error)
+    ListPollCounts(postIDs []uint) ([]models.PollOptionCount, error)
+    CountPollVoters(postIDs []uint) (map[uint]int, error)
+    UpdateTypeData(postID uint, typeData models.PostTypeData) error
+    ClosePoll(postID uint, close func(tx PostRepository) error) error
+    ListPollVoters(postID uint, optionID string, page pagination.Params) (pagination.Page[models.PollVoter], error)
+    GetPostsByType(postType string, page pagination.Params) (pagination.Page[models.Post], error)
+    GetReviewPostsByBookID(bookID uint, page pagination.Params) (pagination.Page[models.Post], error)
+    GetPollPostsByClubID(clubID uint, includeExpired bool, page pagination.Params) (pagination.Page[models.Post], error)
+	ListPostSummaries(clubID uint, userID *uint, opts models.PostListOptions, page pagination.Params) (pagination.Page[models.PostSummary], error)
+	ListByStatusForUser(userID uint, status string, page pagination.Params) (pagination.Page[models.Post], error)
+	ListDueScheduled(now time.Time, limit int) ([]models.Post, error)
+	Publish(postID uint, at time.Time) (bool, error)
+	GetEngagement(ids []uint) ([]models.PostEngagement, error)
+	ListEngagementSince(since time.Time, afterID uint, limit int) ([]models.PostEngagement, error)
+	UpdateHotScores(scores map[uint]float64) error
+	ClearHotScoresBefore(before time.Time) (int64, error)
+	SetStatus(postID uint, status string) error
 }
 
 type CommentRepository interface {
 	Create(comment *models.Comment) error
 	GetByID(id uint) (*models.Comment, error)
 	Update(comment *models.Comment) error
+	UpdateWithRevision(comment *models.Comment, editorID uint) error
 	Delete(id uint) error
-	ListByPostID(postID uint) ([]models.Comment, error)
-	ListByUserID(userID uint) ([]models.Comment, error)
-	LikeComment(like *models.CommentLike) error
+	ListTopLevelByPostID(postID uint, page pagination.Params) (pagination.Page[models.Comment], error)
+	ListFirstReplies(parentIDs []uint, perParent int) ([]models.Comment, error)
+	ListReplies(parentID uint, page pagination.Params) (pagination.Page[models.Comment], error)
+	GetThread(rootID uint) (*models.Comment, error)
+	ListByUserID(userID uint, page pagination.Params) (pagination.Page[models.Comment], error)
+	LikeComment(userID, commentID uint) error
 	UnlikeComment(userID, commentID uint) error
-	ListCommentLikes(commentID uint) ([]models.CommentLikeResponse, error)
+	ListCommentLikes(commentID uint, page pagination.Params) (pagination.Page[models.CommentLikeResponse], error)
 	HasUserLiked(userID, commentID uint) (bool, error)
-	CountLikes(commentID uint) (int64, error)
-	UpdateLikesCount(commentID uint, count int) error
+	Hide(id uint) error
+	Release(id uint) error
+}
+
+type RevisionRepository interface {
+	ListByTarget(targetType string, targetID uint) ([]models.Revision, error)
+}
+
+type ReactionRepository interface {
+	Add(reaction *models.Reaction) (bool, error)
+	Remove(targetType string, targetID, userID uint, emoji string) (bool, error)
+	List(targetType string, targetID uint, emoji string, page pagination.Params) (pagination.Page[models.Reaction], error)
+	ListUserReactions(targetType string, userID uint, targetIDs []uint) (map[uint][]string, error)
 }
 
 type ReadingRepository interface {
     UpsertUserProgress(progress *models.UserBookProgress) error
     GetUserBookProgress(userID, bookID uint) (*models.UserBookProgress, error)
-    ListUserProgress(userID uint) ([]*models.UserBookProgress, error)
-    ListUserFinished(userID uint) ([]*models.UserBookProgress, error)
+    ListUserProgress(userID uint, page pagination.Params) (pagination.Page[*models.UserBookProgress], error)
+    ListUserFinished(userID uint, page pagination.Params) (pagination.Page[*models.UserBookProgress], error)
     AppendLog(log *models.ReadingLog) error
     ListLogsByUserAndBook(userID, bookID uint) ([]models.ReadingLog, error)
 }
@@ -117,6 +288,17 @@ type ClubReadingRepository interface {
     CreateAssignment(a *models.ClubBookAssignment) error
     CompleteAssignment(assignmentID uint) error
     GetActiveAssignment(clubID uint) (*models.ClubBookAssignment, error)
-    ListAssignments(clubID uint) ([]models.ClubBookAssignment, error)
+    ListAssignments(clubID uint, page pagination.Params) (pagination.Page[models.ClubBookAssignment], error)
     UpdateAssignment(a *models.ClubBookAssignment) error
-}
\ No newline at end of file
+    EachAssignmentProgress(clubID uint, fn func(row *models.ReadingExportRow) error) error
+}
+
+type AnalyticsRepository interface {
+	MembershipSeries(clubID uint, action string, from, to time.Time, granularity string) ([]models.AnalyticsPoint, error)
+	PostSeries(clubID uint, from, to time.Time, granularity string) ([]models.AnalyticsPoint, error)
+	CommentSeries(clubID uint, from, to time.Time, granularity string) ([]models.AnalyticsPoint, error)
+	LikeSeries(clubID uint, from, to time.Time, granularity string) ([]models.AnalyticsPoint, error)
+	EventParticipation(clubID uint, from, to time.Time) ([]models.EventParticipationStats, error)
+	AssignmentCompletion(clubID uint, from, to time.Time) ([]models.AssignmentCompletionStats, error)
+	TopMembers(clubID uint, from, to time.Time, limit int) ([]models.MemberActivity, error)
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"gorm.io/gorm"
+)
+
+// tables that carry a free-text location alongside city_id/district_id
+var locationTables = map[string]bool{
+	"users":  true,
+	"clubs":  true,
+	"events": true,
+}
+
+type locationRepository struct {
+	db *gorm.DB
+}
+
+func NewLocationRepository(db *gorm.DB) *locationRepository {
+	return &locationRepository{db: db}
+}
+
+// ListUnresolved returns rows of table that have free-text location but no
+// city yet, ordered by id and starting after afterID.
+func (r *locationRepository) ListUnresolved(table string, afterID uint, limit int) ([]models.LocationRecord, error) {
+	if !locationTables[table] {
+		return nil, fmt.Errorf("unsupported location table %q", table)
+	}
+	var records []models.LocationRecord
+	err := r.db.Table(table).
+		Select("id, location").
+		Where("city_id IS NULL AND location IS NOT NULL AND location <> '' AND id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Scan(&records).Error
+	return records, err
+}
+
+func (r *locationRepository) SetLocation(table string, id uint, cityID, districtID *string) error {
+	if !locationTables[table] {
+		return fmt.Errorf("unsupported location table %q", table)
+	}
+	return r.db.Table(table).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"city_id":     cityID,
+			"district_id": districtID,
+		}).Error
+}
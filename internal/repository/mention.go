@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+type mentionRepository struct {
+	db *gorm.DB
+}
+
+func NewMentionRepository(db *gorm.DB) *mentionRepository {
+	return &mentionRepository{db: db}
+}
+
+// Replace swaps the stored mentions of a post or comment for mentions.
+func (r *mentionRepository) Replace(targetType string, targetID uint, mentions []models.Mention) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("target_type = ? AND target_id = ?", targetType, targetID).
+			Delete(&models.Mention{}).Error; err != nil {
+			return err
+		}
+		if len(mentions) == 0 {
+			return nil
+		}
+		return tx.Omit("MentionedUser", "Author", "Post").Create(&mentions).Error
+	})
+}
+
+// ListByMentionedUser returns the user's mentions, newest first. Mentions in
+// drafts, scheduled or deleted posts and in deleted comments are left out.
+func (r *mentionRepository) ListByMentionedUser(userID uint, page pagination.Params) (pagination.Page[models.Mention], error) {
+	query := r.db.Model(&models.Mention{}).
+		Where("mentions.mentioned_user_id = ?", userID).
+		Where("EXISTS (SELECT 1 FROM posts p WHERE p.id = mentions.post_id AND p.deleted_at IS NULL AND p.status = ?)", models.PostStatusPublished).
+		Where("mentions.target_type = ? OR EXISTS (SELECT 1 FROM comments c WHERE c.id = mentions.target_id AND c.deleted_at IS NULL AND c.is_deleted = ?)",
+			models.MentionTargetPost, false).
+		Preload("Author").
+		Preload("Post")
+
+	newestFirst := pagination.Keyset{
+		Columns: []pagination.Column{{Expr: "mentions.created_at", Kind: pagination.Time, Desc: true}},
+		ID:      "mentions.id",
+		Desc:    true,
+	}
+	return pagination.Find(query, newestFirst, page, func(m models.Mention) pagination.Cursor {
+		return pagination.CursorFor(m.ID, m.CreatedAt)
+	})
+}
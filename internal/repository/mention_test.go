@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type MentionRepositoryTestSuite struct {
+	suite.Suite
+	db          *gorm.DB
+	mentionRepo MentionRepository
+	blockRepo   BlockRepository
+	author      *models.User
+	reader      *models.User
+}
+
+func (suite *MentionRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.mentionRepo = NewMentionRepository(suite.db)
+	suite.blockRepo = NewBlockRepository(suite.db)
+
+	suite.author = &models.User{Username: "author", Email: "author@example.com", PasswordHash: "x"}
+	suite.reader = &models.User{Username: "reader", Email: "reader@example.com", PasswordHash: "x"}
+	suite.Require().NoError(suite.db.Create(suite.author).Error)
+	suite.Require().NoError(suite.db.Create(suite.reader).Error)
+}
+
+func (suite *MentionRepositoryTestSuite) createPost(status string) *models.Post {
+	post := &models.Post{Title: "Dune", Content: "@reader look", Type: "discussion", UserID: suite.author.ID, ClubID: 1, Status: status}
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(post).Error)
+	return post
+}
+
+func (suite *MentionRepositoryTestSuite) mention(targetType string, targetID, postID uint) models.Mention {
+	return models.Mention{
+		TargetType:      targetType,
+		TargetID:        targetID,
+		MentionedUserID: suite.reader.ID,
+		PostID:          postID,
+		ClubID:          1,
+		AuthorID:        suite.author.ID,
+	}
+}
+
+func (suite *MentionRepositoryTestSuite) TestReplace() {
+	post := suite.createPost(models.PostStatusPublished)
+
+	suite.Require().NoError(suite.mentionRepo.Replace(models.MentionTargetPost, post.ID, []models.Mention{
+		suite.mention(models.MentionTargetPost, post.ID, post.ID),
+	}))
+	suite.Require().NoError(suite.mentionRepo.Replace(models.MentionTargetPost, post.ID, []models.Mention{
+		suite.mention(models.MentionTargetPost, post.ID, post.ID),
+	}))
+
+	var count int64
+	suite.Require().NoError(suite.db.Model(&models.Mention{}).Count(&count).Error)
+	assert.Equal(suite.T(), int64(1), count)
+
+	suite.Require().NoError(suite.mentionRepo.Replace(models.MentionTargetPost, post.ID, nil))
+	suite.Require().NoError(suite.db.Model(&models.Mention{}).Count(&count).Error)
+	assert.Equal(suite.T(), int64(0), count)
+}
+
+func (suite *MentionRepositoryTestSuite) TestListByMentionedUser_HidesUnpublishedAndDeleted() {
+	published := suite.createPost(models.PostStatusPublished)
+	draft := suite.createPost(models.PostStatusDraft)
+
+	comment := &models.Comment{PostID: published.ID, UserID: suite.author.ID, Content: "@reader too"}
+	deleted := &models.Comment{PostID: published.ID, UserID: suite.author.ID, Content: "@reader gone", IsDeleted: true}
+	suite.Require().NoError(suite.db.Omit("User").Create(comment).Error)
+	suite.Require().NoError(suite.db.Omit("User").Create(deleted).Error)
+
+	for _, m := range []models.Mention{
+		suite.mention(models.MentionTargetPost, published.ID, published.ID),
+		suite.mention(models.MentionTargetPost, draft.ID, draft.ID),
+		suite.mention(models.MentionTargetComment, comment.ID, published.ID),
+		suite.mention(models.MentionTargetComment, deleted.ID, published.ID),
+	} {
+		suite.Require().NoError(suite.mentionRepo.Replace(m.TargetType, m.TargetID, []models.Mention{m}))
+	}
+
+	mentions, err := suite.mentionRepo.ListByMentionedUser(suite.reader.ID, pagination.First(10))
+	suite.Require().NoError(err)
+	suite.Require().Len(mentions.Items, 2)
+
+	targets := map[string]uint{}
+	for _, m := range mentions.Items {
+		targets[m.TargetType] = m.TargetID
+		assert.Equal(suite.T(), "author", m.Author.Username)
+		assert.Equal(suite.T(), "Dune", m.Post.Title)
+	}
+	assert.Equal(suite.T(), published.ID, targets[models.MentionTargetPost])
+	assert.Equal(suite.T(), comment.ID, targets[models.MentionTargetComment])
+}
+
+func (suite *MentionRepositoryTestSuite) TestPostPreloadsMentionEntities() {
+	post := suite.createPost(models.PostStatusPublished)
+	suite.Require().NoError(suite.mentionRepo.Replace(models.MentionTargetPost, post.ID, []models.Mention{
+		suite.mention(models.MentionTargetPost, post.ID, post.ID),
+	}))
+
+	stored, err := NewPostRepository(suite.db).GetByID(post.ID)
+	suite.Require().NoError(err)
+
+	resp := stored.ToResponse()
+	assert.Equal(suite.T(), []models.MentionEntity{
+		{UserID: suite.reader.ID, Username: "reader", Start: 0, End: 7},
+	}, resp.Mentions)
+}
+
+func (suite *MentionRepositoryTestSuite) TestBlocks() {
+	suite.Require().NoError(suite.blockRepo.Block(suite.reader.ID, suite.author.ID))
+	suite.Require().NoError(suite.blockRepo.Block(suite.reader.ID, suite.author.ID), "blocking twice is not an error")
+
+	blockers, err := suite.blockRepo.ListBlockersOf(suite.author.ID, []uint{suite.reader.ID, 99})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), []uint{suite.reader.ID}, blockers)
+
+	removed, err := suite.blockRepo.Unblock(suite.reader.ID, suite.author.ID)
+	suite.Require().NoError(err)
+	assert.True(suite.T(), removed)
+
+	blockers, err = suite.blockRepo.ListBlockersOf(suite.author.ID, []uint{suite.reader.ID})
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), blockers)
+}
+
+func TestMentionRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(MentionRepositoryTestSuite))
+}
@@ -9,6 +9,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/nevzattalhaozcan/forgotten/internal/models"
+	pagination "github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 )
 
 // MockUserRepository is a mock of UserRepository interface.
@@ -143,18 +144,18 @@ func (mr *MockUserRepositoryMockRecorder) GetByUsernameIncludingDeleted(username
 }
 
 // List mocks base method.
-func (m *MockUserRepository) List(limit, offset int) ([]*models.User, error) {
+func (m *MockUserRepository) List(page pagination.Params) (pagination.Page[*models.User], error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", limit, offset)
-	ret0, _ := ret[0].([]*models.User)
+	ret := m.ctrl.Call(m, "List", page)
+	ret0, _ := ret[0].(pagination.Page[*models.User])
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockUserRepositoryMockRecorder) List(limit, offset interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) List(page interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserRepository)(nil).List), limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserRepository)(nil).List), page)
 }
 
 // Update mocks base method.
@@ -296,33 +297,33 @@ func (mr *MockClubRepositoryMockRecorder) LeaveClub(clubID, userID interface{})
 }
 
 // List mocks base method.
-func (m *MockClubRepository) List(limit, offset int) ([]*models.Club, error) {
+func (m *MockClubRepository) List(page pagination.Params) (pagination.Page[*models.Club], error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", limit, offset)
-	ret0, _ := ret[0].([]*models.Club)
+	ret := m.ctrl.Call(m, "List", page)
+	ret0, _ := ret[0].(pagination.Page[*models.Club])
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockClubRepositoryMockRecorder) List(limit, offset interface{}) *gomock.Call {
+func (mr *MockClubRepositoryMockRecorder) List(page interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockClubRepository)(nil).List), limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockClubRepository)(nil).List), page)
 }
 
 // ListClubMembers mocks base method.
-func (m *MockClubRepository) ListClubMembers(clubID uint) ([]*models.ClubMembership, error) {
+func (m *MockClubRepository) ListClubMembers(clubID uint, page pagination.Params) (pagination.Page[*models.ClubMembership], error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListClubMembers", clubID)
-	ret0, _ := ret[0].([]*models.ClubMembership)
+	ret := m.ctrl.Call(m, "ListClubMembers", clubID, page)
+	ret0, _ := ret[0].(pagination.Page[*models.ClubMembership])
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListClubMembers indicates an expected call of ListClubMembers.
-func (mr *MockClubRepositoryMockRecorder) ListClubMembers(clubID interface{}) *gomock.Call {
+func (mr *MockClubRepositoryMockRecorder) ListClubMembers(clubID, page interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClubMembers", reflect.TypeOf((*MockClubRepository)(nil).ListClubMembers), clubID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClubMembers", reflect.TypeOf((*MockClubRepository)(nil).ListClubMembers), clubID, page)
 }
 
 // Update mocks base method.
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *notificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Create(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.Create(&notifications).Error
+}
+
+// List returns the user's notifications newest first.
+func (r *notificationRepository) List(userID uint, unreadOnly bool, page pagination.Params) (pagination.Page[models.Notification], error) {
+	query := r.db.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	return pagination.Find(query, pagination.ByID("id", true), page, func(n models.Notification) pagination.Cursor {
+		return pagination.CursorFor(n.ID)
+	})
+}
+
+func (r *notificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks the user's notifications among ids as read, or all of them
+// when ids is empty. It returns how many were unread.
+func (r *notificationRepository) MarkRead(userID uint, ids []uint, at time.Time) (int64, error) {
+	query := r.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	res := query.UpdateColumn("read_at", at)
+	return res.RowsAffected, res.Error
+}
@@ -1,14 +1,23 @@
 package repository
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"strconv"
 	"time"
 
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/markdown"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrPollClosed is returned for votes on a poll that was closed or has
+// expired.
+var ErrPollClosed = errors.New("poll is closed")
+
 type postRepository struct {
 	db *gorm.DB
 }
@@ -17,18 +26,54 @@ func NewPostRepository(db *gorm.DB) *postRepository {
 	return &postRepository{db: db}
 }
 
+// postsNewestFirst orders posts by when they went out; scheduled posts count
+// from their publish time, not from when they were written.
+var postsNewestFirst = pagination.Keyset{
+	Columns: []pagination.Column{{Expr: "COALESCE(posts.published_at, posts.created_at)", Kind: pagination.Time, Desc: true}},
+	ID:      "posts.id",
+	Desc:    true,
+}
+
+func postNewestCursor(p models.Post) pagination.Cursor {
+	return pagination.CursorFor(p.ID, postSortTime(p.PublishedAt, p.CreatedAt))
+}
+
+func postSortTime(publishedAt *time.Time, createdAt time.Time) time.Time {
+	if publishedAt != nil {
+		return *publishedAt
+	}
+	return createdAt
+}
+
+// Create stores a post. A reshare adds to its original's share count, and a
+// review rates its book.
 func (r *postRepository) Create(post *models.Post) error {
-	return r.db.Create(post).Error
+	if post.SharedPostID == nil && post.Type != "review" {
+		return r.db.Create(post).Error
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		if post.SharedPostID != nil {
+			if err := tx.Model(&models.Post{}).
+				Where("id = ?", *post.SharedPostID).
+				UpdateColumn("share_count", gorm.Expr("share_count + ?", 1)).Error; err != nil {
+				return err
+			}
+		}
+		return addBookRating(tx, post)
+	})
 }
 
 func (r *postRepository) GetByID(id uint) (*models.Post, error) {
 	var post models.Post
 	if err := r.db.
 		Preload("User").
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
 		Preload("Comments").
 		Preload("Comments.User").
-		Preload("Likes").
-		Preload("Likes.User").
 		First(&post, id).Error; err != nil {
 		return nil, err
 	}
@@ -36,67 +81,90 @@ func (r *postRepository) GetByID(id uint) (*models.Post, error) {
 }
 
 func (r *postRepository) Update(post *models.Post) error {
-	return r.db.Save(post).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(post).Error; err != nil {
+			return err
+		}
+		return syncBookRating(tx, post, false)
+	})
 }
 
+// Delete removes a post. A deleted reshare no longer counts towards its
+// original's share count; reshares of a deleted post are kept. A deleted
+// review no longer rates its book.
 func (r *postRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Post{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var post models.Post
+		if err := tx.Select("id", "shared_post_id").First(&post, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Post{}, id).Error; err != nil {
+			return err
+		}
+		if err := syncBookRating(tx, &post, true); err != nil {
+			return err
+		}
+		if post.SharedPostID == nil {
+			return nil
+		}
+		return tx.Exec("UPDATE posts SET share_count = CASE WHEN share_count > 0 THEN share_count - 1 ELSE 0 END WHERE id = ?", *post.SharedPostID).Error
+	})
 }
 
-func (r *postRepository) ListByUserID(userID uint) ([]models.Post, error) {
-	var posts []models.Post
-	if err := r.db.
+func (r *postRepository) ListByUserID(userID uint, page pagination.Params) (pagination.Page[models.Post], error) {
+	query := r.db.
 		Preload("User").
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
 		Preload("Comments").
-		Preload("Likes").
-		Where("user_id = ?", userID).
-		Find(&posts).Error; err != nil {
-		return nil, err
-	}
-	return posts, nil
+		Where("user_id = ? AND status = ?", userID, models.PostStatusPublished)
+	return pagination.Find(query, postsNewestFirst, page, postNewestCursor)
 }
 
-func (r *postRepository) ListByClubID(clubID uint) ([]models.Post, error) {
-	var posts []models.Post
-	if err := r.db.
+func (r *postRepository) ListByClubID(clubID uint, page pagination.Params) (pagination.Page[models.Post], error) {
+	query := r.db.
 		Preload("User").
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
 		Preload("Comments").
-		Preload("Likes").
-		Where("club_id = ?", clubID).
-		Find(&posts).Error; err != nil {
-		return nil, err
-	}
-	return posts, nil
+		Where("club_id = ? AND status = ?", clubID, models.PostStatusPublished)
+	return pagination.Find(query, postsNewestFirst, page, postNewestCursor)
 }
 
-func (r *postRepository) ListAll() ([]models.Post, error) {
-	var posts []models.Post
-	if err := r.db.
+func (r *postRepository) ListAll(page pagination.Params) (pagination.Page[models.Post], error) {
+	query := r.db.
 		Preload("User").
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
 		Preload("Comments").
-		Preload("Likes").
-		Preload("Likes.User").
-		Find(&posts).Error; err != nil {
-		return nil, err
-	}
-	return posts, nil
+		Where("status = ?", models.PostStatusPublished)
+	return pagination.Find(query, postsNewestFirst, page, postNewestCursor)
 }
 
-func (r *postRepository) ListPostSummaries(clubID uint, userID *uint, limit, offset int) ([]models.PostSummary, error) {
+func (r *postRepository) ListPostSummaries(clubID uint, userID *uint, opts models.PostListOptions, page pagination.Params) (pagination.Page[models.PostSummary], error) {
 	type row struct {
 		ID            uint      `gorm:"column:id"`
 		Title         string    `gorm:"column:title"`
 		Content       string    `gorm:"column:content"`
+		ContentHTML   string    `gorm:"column:content_html"`
+		Excerpt       string    `gorm:"column:excerpt"`
+		RenderVersion int       `gorm:"column:render_version"`
 		Type          string    `gorm:"column:type"`
 		TypeData      string    `gorm:"column:type_data"`
 		IsPinned      bool      `gorm:"column:is_pinned"`
 		LikesCount    int       `gorm:"column:likes_count"`
+		ReactionCounts models.ReactionCounts `gorm:"column:reaction_counts"`
 		CommentsCount int       `gorm:"column:comments_count"`
 		ViewsCount    int       `gorm:"column:views_count"`
+		ShareCount    int       `gorm:"column:share_count"`
+		HotScore      float64   `gorm:"column:hot_score"`
+		EditCount     int       `gorm:"column:edit_count"`
+		SharedPostID  *uint     `gorm:"column:shared_post_id"`
 		PostUserID    uint      `gorm:"column:post_user_id"`
 		PostClubID    *uint     `gorm:"column:post_club_id"`
 		CreatedAt     time.Time `gorm:"column:created_at"`
 		UpdatedAt     time.Time `gorm:"column:updated_at"`
+		PublishedAt   *time.Time `gorm:"column:published_at"`
 
 		UserID        *uint   `gorm:"column:user_id"`
 		UserUsername  *string `gorm:"column:user_username"`
@@ -106,22 +174,42 @@ func (r *postRepository) ListPostSummaries(clubID uint, userID *uint, limit, off
 		ClubName *string `gorm:"column:club_name"`
 	}
 
-	var rows []row
-
-	err := r.db.Table("posts").
-		Select(`posts.id, posts.title, posts.content, posts.type, posts.type_data, posts.is_pinned, posts.likes_count, posts.comments_count, posts.views_count,
-                posts.user_id as post_user_id, posts.club_id as post_club_id, posts.created_at, posts.updated_at,
+	query := r.db.Table("posts").
+		Select(`posts.id, posts.title, posts.content, posts.content_html, posts.excerpt, posts.render_version, posts.type, posts.type_data, posts.is_pinned, posts.likes_count, posts.reaction_counts, posts.comments_count, posts.views_count, posts.share_count, posts.hot_score, posts.edit_count, posts.shared_post_id,
+                posts.user_id as post_user_id, posts.club_id as post_club_id, posts.created_at, posts.updated_at, posts.published_at,
                 users.id as user_id, users.username as user_username, users.avatar_url as user_avatar_url,
                 clubs.id as club_id, clubs.name as club_name`).
 		Joins("LEFT JOIN users ON users.id = posts.user_id").
 		Joins("LEFT JOIN clubs ON clubs.id = posts.club_id").
-		Where("posts.club_id = ?", clubID).
-		Limit(limit).
-		Offset(offset).
-		Order("posts.created_at DESC").
-		Scan(&rows).Error
+		Where("posts.club_id = ? AND posts.status = ?", clubID, models.PostStatusPublished)
+
+	query, keyset := sortedPosts(query, opts)
+	rowsPage, err := pagination.Find(query, keyset, page, func(rrow row) pagination.Cursor {
+		return sortedPostCursor(opts.Sort, rrow.ID, rrow.HotScore, rrow.LikesCount, rrow.CommentsCount, rrow.PublishedAt, rrow.CreatedAt)
+	})
 	if err != nil {
-		return nil, err
+		return pagination.Page[models.PostSummary]{}, err
+	}
+	rows := rowsPage.Items
+
+	reacted := map[uint][]string{}
+	bookmarked := map[uint]bool{}
+	postIDs := make([]uint, 0, len(rows))
+	for _, rrow := range rows {
+		postIDs = append(postIDs, rrow.ID)
+	}
+	if userID != nil {
+		if reacted, err = listUserReactions(r.db, models.ReactionTargetPost, *userID, postIDs); err != nil {
+			return pagination.Page[models.PostSummary]{}, err
+		}
+		if bookmarked, err = listUserBookmarks(r.db, models.BookmarkTargetPost, *userID, postIDs); err != nil {
+			return pagination.Page[models.PostSummary]{}, err
+		}
+	}
+
+	attachments, err := listAttachments(r.db, "post_id", postIDs)
+	if err != nil {
+		return pagination.Page[models.PostSummary]{}, err
 	}
 
 	out := make([]models.PostSummary, 0, len(rows))
@@ -130,17 +218,31 @@ func (r *postRepository) ListPostSummaries(clubID uint, userID *uint, limit, off
 			ID:            rrow.ID,
 			Title:         rrow.Title,
 			Content:       rrow.Content,
+			ContentHTML:   rrow.ContentHTML,
+			Excerpt:       rrow.Excerpt,
 			Type:          rrow.Type,
 			IsPinned:      rrow.IsPinned,
 			LikesCount:    rrow.LikesCount,
 			CommentsCount: rrow.CommentsCount,
 			ViewsCount:    rrow.ViewsCount,
+			ShareCount:    rrow.ShareCount,
+			Edited:        rrow.EditCount > 0,
+			EditCount:     rrow.EditCount,
+			Bookmarked:    bookmarked[rrow.ID],
+			Attachments:   attachments[rrow.ID],
+			SharedPostID:  rrow.SharedPostID,
 			UserID:        rrow.PostUserID,
 			ClubID:        rrow.PostClubID,
 			CreatedAt:     rrow.CreatedAt,
 			UpdatedAt:     rrow.UpdatedAt,
 		}
 
+		if rrow.RenderVersion < markdown.RenderVersion {
+			out := markdown.Render(rrow.Content)
+			ps.ContentHTML = out.HTML
+			ps.Excerpt = markdown.Excerpt(out.Text, models.PostExcerptLength)
+		}
+
 		if rrow.TypeData != "" && rrow.TypeData != "null" {
             var typeDataInterface interface{}
             if err := json.Unmarshal([]byte(rrow.TypeData), &typeDataInterface); err == nil {
@@ -152,12 +254,16 @@ func (r *postRepository) ListPostSummaries(clubID uint, userID *uint, limit, off
             ps.TypeData = nil
         }
 
+		var userReactions []string
 		if userID != nil {
-			hasLiked, err := r.HasUserLiked(*userID, rrow.ID)
-			if err == nil {
-				ps.HasUserLiked = hasLiked
+			userReactions = reacted[rrow.ID]
+			for _, emoji := range userReactions {
+				if emoji == models.LikeReaction {
+					ps.HasUserLiked = true
+				}
 			}
 		}
+		ps.Reactions = rrow.ReactionCounts.Summaries(userReactions)
 
 		if rrow.UserID != nil {
 			ps.User = models.UserSummary{
@@ -186,7 +292,7 @@ func (r *postRepository) ListPostSummaries(clubID uint, userID *uint, limit, off
 		out = append(out, ps)
 	}
 
-	return out, nil
+	return pagination.Page[models.PostSummary]{Items: out, NextCursor: rowsPage.NextCursor, HasMore: rowsPage.HasMore}, nil
 }
 
 func safeString(s *string) string {
@@ -196,36 +302,77 @@ func safeString(s *string) string {
 	return *s
 }
 
-func (r *postRepository) ListPublicPosts() ([]models.Post, error) {
-	var posts []models.Post
-	if err := r.db.
+// postsHottest orders posts by their stored hot score, and postsTop by likes
+// and comments. Both move while a client pages through, so a post may rarely
+// show up twice or be skipped.
+var postsHottest = pagination.Keyset{
+	Columns: []pagination.Column{{Expr: "posts.hot_score", Kind: pagination.Float, Desc: true}},
+	ID:      "posts.id",
+	Desc:    true,
+}
+
+var postsTop = pagination.Keyset{
+	Columns: []pagination.Column{{Expr: "posts.likes_count + posts.comments_count", Kind: pagination.Int, Desc: true}},
+	ID:      "posts.id",
+	Desc:    true,
+}
+
+// sortedPosts narrows a query of published posts to opts and returns the
+// keyset to page it with.
+func sortedPosts(query *gorm.DB, opts models.PostListOptions) (*gorm.DB, pagination.Keyset) {
+	if opts.Since != nil {
+		query = query.Where("COALESCE(posts.published_at, posts.created_at) >= ?", *opts.Since)
+	}
+	switch opts.Sort {
+	case models.PostSortHot:
+		return query, postsHottest
+	case models.PostSortTop:
+		return query, postsTop
+	default:
+		return query, postsNewestFirst
+	}
+}
+
+// sortedPostCursor is the cursor of a post in a list sorted by sort.
+func sortedPostCursor(sort string, id uint, hotScore float64, likes, comments int, publishedAt *time.Time, createdAt time.Time) pagination.Cursor {
+	switch sort {
+	case models.PostSortHot:
+		return pagination.CursorFor(id, hotScore)
+	case models.PostSortTop:
+		return pagination.CursorFor(id, likes+comments)
+	default:
+		return pagination.CursorFor(id, postSortTime(publishedAt, createdAt))
+	}
+}
+
+func (r *postRepository) ListPublicPosts(opts models.PostListOptions, page pagination.Params) (pagination.Page[models.Post], error) {
+	query := r.db.
 		Preload("User").
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
 		Preload("Club"). // Add this to show which club the post belongs to
 		Preload("Comments").
-		Preload("Likes").
 		Joins("JOIN clubs ON posts.club_id = clubs.id").
-		Where("clubs.is_private = ?", false).                   // Fix: false for public clubs
-		Order("posts.likes_count DESC, posts.created_at DESC"). // Popular first
-		Limit(20).                                              // Limit for homepage
-		Find(&posts).Error; err != nil {
-		return nil, err
-	}
-	return posts, nil
+		Where("clubs.is_private = ? AND posts.status = ?", false, models.PostStatusPublished) // Fix: false for public clubs
+	query, keyset := sortedPosts(query, opts)
+	return pagination.Find(query, keyset, page, func(p models.Post) pagination.Cursor {
+		return sortedPostCursor(opts.Sort, p.ID, p.HotScore, p.LikesCount, p.CommentsCount, p.PublishedAt, p.CreatedAt)
+	})
 }
 
+// ListPopularPublicPosts returns the hottest posts in public clubs.
 func (r *postRepository) ListPopularPublicPosts(limit int) ([]models.Post, error) {
 	var posts []models.Post
 	if err := r.db.
 		Preload("User").
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
 		Preload("Club").
 		Preload("Comments").
 		Preload("Comments.User").
-		Preload("Likes").
-		Preload("Likes.User").
-		Preload("Likes.Post").
 		Joins("JOIN clubs ON posts.club_id = clubs.id").
-		Where("clubs.is_private = ? AND posts.created_at > ?", false, time.Now().AddDate(0, 0, -30)). // Last 30 days
-		Order("posts.likes_count DESC, posts.comments_count DESC, posts.created_at DESC").
+		Where("clubs.is_private = ? AND posts.status = ?", false, models.PostStatusPublished).
+		Order("posts.hot_score DESC, posts.id DESC").
 		Limit(limit).
 		Find(&posts).Error; err != nil {
 		return nil, err
@@ -233,109 +380,479 @@ func (r *postRepository) ListPopularPublicPosts(limit int) ([]models.Post, error
 	return posts, nil
 }
 
-func (r *postRepository) AddLike(like *models.PostLike) error {
-	return r.db.Create(like).Error
+// AddLike and the other like methods are kept for the legacy endpoints; a
+// like is a ❤️ reaction.
+func (r *postRepository) AddLike(userID, postID uint) error {
+	_, err := addReaction(r.db, &models.Reaction{
+		TargetType: models.ReactionTargetPost,
+		TargetID:   postID,
+		UserID:     userID,
+		Emoji:      models.LikeReaction,
+	})
+	return err
 }
 
 func (r *postRepository) RemoveLike(userID, postID uint) error {
-	result := r.db.Where("user_id = ? AND post_id = ?", userID, postID).Delete(&models.PostLike{})
-	if result.Error != nil {
-		return result.Error
+	removed, err := removeReaction(r.db, models.ReactionTargetPost, postID, userID, models.LikeReaction)
+	if err != nil {
+		return err
 	}
-	if result.RowsAffected == 0 {
+	if !removed {
 		return gorm.ErrRecordNotFound
 	}
 	return nil
 }
 
-func (r *postRepository) CountLikes(postID uint) (int64, error) {
-	var count int64
-	if err := r.db.Model(&models.PostLike{}).Where("post_id = ?", postID).Count(&count).Error; err != nil {
-		return 0, err
+func (r *postRepository) ListLikesByPostID(postID uint, page pagination.Params) (pagination.Page[models.PostLikeResponse], error) {
+	query := r.db.
+		Preload("User").
+		Where("target_type = ? AND target_id = ? AND emoji = ?", models.ReactionTargetPost, postID, models.LikeReaction)
+	likes, err := pagination.Find(query, reactionsOldestFirst, page, reactionCursor)
+	if err != nil {
+		return pagination.Page[models.PostLikeResponse]{}, err
 	}
-	return count, nil
+	return pagination.Map(likes, models.Reaction.ToPostLikeResponse), nil
 }
 
-func (r *postRepository) ListLikesByPostID(postID uint) ([]models.PostLikeResponse, error) {
-	var likes []models.PostLike
-	if err := r.db.
-		Preload("User").
-		Where("post_id = ?", postID).
-		Find(&likes).Error; err != nil {
-		return nil, err
-	}
+func (r *postRepository) HasUserLiked(userID, postID uint) (bool, error) {
+	return hasReacted(r.db, models.ReactionTargetPost, postID, userID, models.LikeReaction)
+}
 
-	res := make([]models.PostLikeResponse, 0, len(likes))
-	for _, l := range likes {
-		res = append(res, l.ToResponse())
-	}
-	return res, nil
+// AddPollVotes records userID's votes on a poll, skipping options they
+// already chose, and counts them. With exclusive set nothing is recorded and
+// false is returned when the voter has voted on the poll before. The poll's
+// row stays locked until the votes and counters are written, so two requests
+// from the same voter cannot both pass that check.
+func (r *postRepository) AddPollVotes(postID, userID uint, votes []models.PollVote, exclusive bool) (bool, error) {
+	added := true
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPoll(tx, postID); err != nil {
+			return err
+		}
+		if exclusive {
+			var count int64
+			if err := tx.Model(&models.PollVote{}).Where("post_id = ? AND user_id = ?", postID, userID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				added = false
+				return nil
+			}
+		}
+		return insertPollVotes(tx, votes)
+	})
+	return added, err
 }
 
-func (r *postRepository) HasUserLiked(userID, postID uint) (bool, error) {
-	var count int64
-	err := r.db.Model(&models.PostLike{}).
-		Where("user_id = ? AND post_id = ?", userID, postID).
-		Count(&count).Error
+// ReplacePollVotes swaps userID's votes on a poll for the given ones and
+// moves the counters with them.
+func (r *postRepository) ReplacePollVotes(postID, userID uint, votes []models.PollVote) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPoll(tx, postID); err != nil {
+			return err
+		}
+		if err := deletePollVotes(tx, tx.Where("post_id = ? AND user_id = ?", postID, userID)); err != nil {
+			return err
+		}
+		return insertPollVotes(tx, votes)
+	})
+}
+
+func (r *postRepository) RemoveVoteFromPoll(postID, userID uint, optionID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPoll(tx, postID); err != nil {
+			return err
+		}
+		return deletePollVotes(tx, tx.Where("post_id = ? AND user_id = ? AND option_id = ?", postID, userID, optionID))
+	})
+}
+
+// ClosePoll locks the poll and calls close with a repository bound to the
+// locking transaction. Votes wait on the lock, so the tally close reads is
+// final and no vote lands between it and the results close stores.
+func (r *postRepository) ClosePoll(postID uint, close func(tx PostRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPoll(tx, postID); err != nil {
+			return err
+		}
+		return close(&postRepository{db: tx})
+	})
+}
+
+// lockPoll locks the poll's row until the transaction ends and fails with
+// ErrPollClosed once the poll was closed or has expired.
+func lockPoll(tx *gorm.DB, postID uint) error {
+	var post models.Post
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "type", "type_data").First(&post, postID).Error; err != nil {
+		return err
+	}
+	data, err := post.GetPollData()
 	if err != nil {
-		return false, err
+		return err
 	}
-	return count > 0, nil
+	if data != nil && data.IsClosed(time.Now()) {
+		return ErrPollClosed
+	}
+	return nil
 }
 
-func (r *postRepository) UpdateLikesCount(postID uint, count int) error {
-	return r.db.Model(&models.Post{}).
-		Where("id = ?", postID).
-		Update("likes_count", count).Error
+// insertPollVotes adds the votes that are not stored yet and counts the ones
+// that were.
+func insertPollVotes(tx *gorm.DB, votes []models.PollVote) error {
+	for i := range votes {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("User").Create(&votes[i])
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 1 && votes[i].Counted() {
+			if err := movePollCount(tx, votes[i].PostID, votes[i].OptionID, 1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
-func (r *postRepository) VoteOnPoll(vote *models.PollVote) error {
-	return r.db.Create(vote).Error
+// deletePollVotes deletes the votes query matches and uncounts them. The
+// caller holds the poll's lock, so the votes cannot change in between.
+func deletePollVotes(tx *gorm.DB, query *gorm.DB) error {
+	var deleted []models.PollVote
+	if err := query.Find(&deleted).Error; err != nil {
+		return err
+	}
+	if len(deleted) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(deleted))
+	for i, v := range deleted {
+		ids[i] = v.ID
+	}
+	if err := tx.Delete(&models.PollVote{}, ids).Error; err != nil {
+		return err
+	}
+	for _, v := range deleted {
+		if v.Counted() {
+			if err := movePollCount(tx, v.PostID, v.OptionID, -1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
-func (r *postRepository) RemoveVoteFromPoll(postID, userID uint, optionID string) error {
-	return r.db.Where("post_id = ? AND user_id = ? AND option_id = ?", postID, userID, optionID).
-		Delete(&models.PollVote{}).Error
+func movePollCount(tx *gorm.DB, postID uint, optionID string, delta int) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "post_id"}, {Name: "option_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"votes": gorm.Expr("poll_option_counts.votes + excluded.votes")}),
+	}).Create(&models.PollOptionCount{PostID: postID, OptionID: optionID, Votes: delta}).Error
 }
 
 func (r *postRepository) GetUserPollVotes(postID, userID uint) ([]models.PollVote, error) {
 	var votes []models.PollVote
-	err := r.db.Where("post_id = ? AND user_id = ?", postID, userID).Find(&votes).Error
+	err := r.db.Where("post_id = ? AND user_id = ?", postID, userID).Order("rank, id").Find(&votes).Error
 	return votes, err
 }
 
-// TODO: Find a way to update poll vote counts
-func (r *postRepository) UpdatePollVoteCounts(postID uint) error {
-	return nil
+// ListPollVotes returns the votes on the given polls, grouped by poll and
+// voter with each ballot in rank order. When userID is set only that voter's
+// votes are returned.
+func (r *postRepository) ListPollVotes(postIDs []uint, userID *uint) ([]models.PollVote, error) {
+	var votes []models.PollVote
+	if len(postIDs) == 0 {
+		return votes, nil
+	}
+	query := r.db.Where("post_id IN ?", postIDs)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	err := query.Order("post_id, user_id, rank, id").Find(&votes).Error
+	return votes, err
 }
 
-func (r *postRepository) GetPostsByType(postType string, limit, offset int) ([]models.Post, error) {
-	var posts []models.Post
-	err := r.db.Where("type = ?", postType).
+// ListPollCounts returns the option counters of the given polls. Options
+// nobody voted for may have no counter.
+func (r *postRepository) ListPollCounts(postIDs []uint) ([]models.PollOptionCount, error) {
+	var counts []models.PollOptionCount
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+	err := r.db.Where("post_id IN ?", postIDs).Find(&counts).Error
+	return counts, err
+}
+
+// CountPollVoters returns how many users voted on each of the given polls.
+func (r *postRepository) CountPollVoters(postIDs []uint) (map[uint]int, error) {
+	voters := make(map[uint]int, len(postIDs))
+	if len(postIDs) == 0 {
+		return voters, nil
+	}
+	var rows []struct {
+		PostID uint
+		Voters int
+	}
+	if err := r.db.Model(&models.PollVote{}).
+		Select("post_id, COUNT(DISTINCT user_id) AS voters").
+		Where("post_id IN ?", postIDs).
+		Group("post_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		voters[row.PostID] = row.Voters
+	}
+	return voters, nil
+}
+
+// UpdateTypeData writes a post's type data alone, leaving its counters and
+// the rest of the row as they are.
+func (r *postRepository) UpdateTypeData(postID uint, typeData models.PostTypeData) error {
+	return r.db.Model(&models.Post{}).Where("id = ?", postID).
+		UpdateColumn("type_data", typeData).Error
+}
+
+// pollVotesOldestFirst lists votes in the order they were cast.
+var pollVotesOldestFirst = pagination.Keyset{
+	Columns: []pagination.Column{{Expr: "poll_votes.created_at", Kind: pagination.Time}},
+	ID:      "poll_votes.id",
+}
+
+func pollVoteCursor(v models.PollVote) pagination.Cursor {
+	return pagination.CursorFor(v.ID, v.CreatedAt)
+}
+
+// ListPollVoters returns the users who chose optionID, in the order they
+// voted.
+func (r *postRepository) ListPollVoters(postID uint, optionID string, page pagination.Params) (pagination.Page[models.PollVoter], error) {
+	query := r.db.Preload("User").
+		Where("post_id = ? AND option_id = ?", postID, optionID)
+	votes, err := pagination.Find(query, pollVotesOldestFirst, page, pollVoteCursor)
+	if err != nil {
+		return pagination.Page[models.PollVoter]{}, err
+	}
+	return pagination.Map(votes, models.PollVote.ToPollVoter), nil
+}
+
+func (r *postRepository) GetPostsByType(postType string, page pagination.Params) (pagination.Page[models.Post], error) {
+	query := r.db.Where("type = ? AND status = ?", postType, models.PostStatusPublished).
 		Preload("User").
-		Preload("Club").
-		Limit(limit).
-		Offset(offset).
-		Find(&posts).Error
-	return posts, err
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
+		Preload("Club")
+	return pagination.Find(query, postsNewestFirst, page, postNewestCursor)
 }
 
-func (r *postRepository) GetReviewPostsByBookID(bookID uint) ([]models.Post, error) {
-	var posts []models.Post
-	err := r.db.Where("type = ? AND type_data->>'book_id' = ?", "review", strconv.Itoa(int(bookID))).
+func (r *postRepository) GetReviewPostsByBookID(bookID uint, page pagination.Params) (pagination.Page[models.Post], error) {
+	query := r.db.Where("type = ? AND status = ? AND type_data->>'book_id' = ?", "review", models.PostStatusPublished, strconv.Itoa(int(bookID))).
 		Preload("User").
-		Find(&posts).Error
-	return posts, err
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder)
+	return pagination.Find(query, postsNewestFirst, page, postNewestCursor)
 }
 
-func (r *postRepository) GetPollPostsByClubID(clubID uint, includeExpired bool) ([]models.Post, error) {
-	query := r.db.Where("type = ? AND club_id = ?", "poll", clubID)
+func (r *postRepository) GetPollPostsByClubID(clubID uint, includeExpired bool, page pagination.Params) (pagination.Page[models.Post], error) {
+	query := r.db.Where("type = ? AND club_id = ? AND status = ?", "poll", clubID, models.PostStatusPublished)
 
 	if !includeExpired {
-		query = query.Where("(type_data->>'expires_at' IS NULL OR type_data->>'expires_at'::timestamp > NOW())")
+		query = query.Where("(type_data->>'expires_at' IS NULL OR type_data->>'expires_at'::timestamp > NOW()) AND type_data->>'closed_at' IS NULL")
+	}
+
+	return pagination.Find(query.Preload("User").Preload("Attachments", attachmentsInOrder), postsNewestFirst, page, postNewestCursor)
+}
+
+// UpdateWithRevision saves the post and, when its title, content or type data
+// changed, records the edit made by editorID.
+func (r *postRepository) UpdateWithRevision(post *models.Post, editorID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var stored models.Post
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stored, post.ID).Error; err != nil {
+			return err
+		}
+
+		if stored.Title != post.Title || stored.Content != post.Content || !bytes.Equal(stored.TypeData, post.TypeData) {
+			now := time.Now()
+			editCount, err := recordRevision(tx,
+				&models.Revision{
+					TargetType: models.RevisionTargetPost,
+					TargetID:   stored.ID,
+					Title:      stored.Title,
+					Content:    stored.Content,
+					TypeData:   stored.TypeData,
+					EditorID:   stored.UserID,
+					CreatedAt:  stored.CreatedAt,
+				},
+				&models.Revision{
+					TargetType: models.RevisionTargetPost,
+					TargetID:   post.ID,
+					Title:      post.Title,
+					Content:    post.Content,
+					TypeData:   post.TypeData,
+					EditorID:   editorID,
+					CreatedAt:  now,
+				})
+			if err != nil {
+				return err
+			}
+			post.EditCount = editCount
+			post.EditedAt = &now
+		}
+
+		if err := tx.Omit(clause.Associations).Save(post).Error; err != nil {
+			return err
+		}
+		return syncBookRating(tx, post, false)
+	})
+}
+
+// ListByStatusForUser returns the author's own drafts or scheduled posts.
+// Drafts come most recently edited first, scheduled posts soonest first.
+func (r *postRepository) ListByStatusForUser(userID uint, status string, page pagination.Params) (pagination.Page[models.Post], error) {
+	keyset := pagination.Keyset{
+		Columns: []pagination.Column{{Expr: "posts.updated_at", Kind: pagination.Time, Desc: true}},
+		ID:      "posts.id",
+		Desc:    true,
 	}
+	cursor := func(p models.Post) pagination.Cursor { return pagination.CursorFor(p.ID, p.UpdatedAt) }
+	if status == models.PostStatusScheduled {
+		keyset = pagination.Keyset{
+			Columns: []pagination.Column{{Expr: "posts.publish_at", Kind: pagination.Time}},
+			ID:      "posts.id",
+		}
+		cursor = func(p models.Post) pagination.Cursor { return pagination.CursorFor(p.ID, postSortTime(p.PublishAt, p.CreatedAt)) }
+	}
+
+	query := r.db.
+		Preload("User").
+		Preload("Mentions.MentionedUser").
+		Preload("Attachments", attachmentsInOrder).
+		Preload("Club").
+		Where("user_id = ? AND status = ?", userID, status)
+	return pagination.Find(query, keyset, page, cursor)
+}
 
+// ListDueScheduled returns scheduled posts whose publish time has passed,
+// oldest first.
+func (r *postRepository) ListDueScheduled(now time.Time, limit int) ([]models.Post, error) {
 	var posts []models.Post
-	err := query.Preload("User").Find(&posts).Error
-	return posts, err
+	if err := r.db.
+		Where("status = ? AND publish_at <= ?", models.PostStatusScheduled, now).
+		Order("publish_at ASC").
+		Limit(limit).
+		Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// Publish marks an unpublished post as published. It reports false when the
+// post was already published, e.g. by another server instance.
+func (r *postRepository) Publish(postID uint, at time.Time) (bool, error) {
+	published := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Post{}).
+			Where("id = ? AND status <> ?", postID, models.PostStatusPublished).
+			Updates(map[string]interface{}{
+				"status":       models.PostStatusPublished,
+				"published_at": at,
+				"publish_at":   nil,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		published = true
+		return refreshPostBookRating(tx, postID)
+	})
+	return published, err
+}
+
+type postEngagementRow struct {
+	ID             uint
+	PublishedAt    *time.Time
+	CreatedAt      time.Time
+	LikesCount     int
+	CommentsCount  int
+	ViewsCount     int
+	ReactionCounts models.ReactionCounts `gorm:"type:jsonb"`
+}
+
+func (r *postRepository) engagement() *gorm.DB {
+	return r.db.Model(&models.Post{}).
+		Select("id, published_at, created_at, likes_count, comments_count, views_count, reaction_counts").
+		Where("status = ?", models.PostStatusPublished)
+}
+
+func scanEngagement(query *gorm.DB) ([]models.PostEngagement, error) {
+	var rows []postEngagementRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]models.PostEngagement, 0, len(rows))
+	for _, row := range rows {
+		reactions := 0
+		for emoji, n := range row.ReactionCounts {
+			if emoji != models.LikeReaction {
+				reactions += n
+			}
+		}
+		out = append(out, models.PostEngagement{
+			PostID:         row.ID,
+			PublishedAt:    postSortTime(row.PublishedAt, row.CreatedAt),
+			LikesCount:     row.LikesCount,
+			CommentsCount:  row.CommentsCount,
+			ViewsCount:     row.ViewsCount,
+			ReactionsCount: reactions,
+		})
+	}
+	return out, nil
+}
+
+// GetEngagement returns the engagement of the published posts among ids.
+func (r *postRepository) GetEngagement(ids []uint) ([]models.PostEngagement, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return scanEngagement(r.engagement().Where("id IN ?", ids))
+}
+
+// ListEngagementSince pages through posts published since, by ID.
+func (r *postRepository) ListEngagementSince(since time.Time, afterID uint, limit int) ([]models.PostEngagement, error) {
+	return scanEngagement(r.engagement().
+		Where("COALESCE(published_at, created_at) >= ? AND id > ?", since, afterID).
+		Order("id ASC").
+		Limit(limit))
+}
+
+// UpdateHotScores stores hot scores by post ID. It leaves updated_at alone;
+// a score changing is not an edit.
+func (r *postRepository) UpdateHotScores(scores map[uint]float64) error {
+	if len(scores) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for id, score := range scores {
+			if err := tx.Model(&models.Post{}).Where("id = ?", id).
+				UpdateColumn("hot_score", score).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ClearHotScoresBefore zeroes the hot score of posts published before before,
+// which have dropped out of hot ranking, and returns how many it touched.
+func (r *postRepository) ClearHotScoresBefore(before time.Time) (int64, error) {
+	res := r.db.Model(&models.Post{}).
+		Where("hot_score <> 0 AND COALESCE(published_at, created_at) < ?", before).
+		UpdateColumn("hot_score", 0)
+	return res.RowsAffected, res.Error
+}
+
+// SetStatus moves a post to another status without touching anything else.
+func (r *postRepository) SetStatus(postID uint, status string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Post{}).Where("id = ?", postID).UpdateColumn("status", status).Error; err != nil {
+			return err
+		}
+		return refreshPostBookRating(tx, postID)
+	})
 }
@@ -0,0 +1,363 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type PostRepositoryTestSuite struct {
+	suite.Suite
+	db       *gorm.DB
+	postRepo *postRepository
+}
+
+func (suite *PostRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.postRepo = NewPostRepository(suite.db)
+}
+
+func (suite *PostRepositoryTestSuite) createPost(title, status string, publishAt *time.Time) *models.Post {
+	post := &models.Post{Title: title, Content: "text", Type: "announcement", UserID: 1, ClubID: 1, Status: status, PublishAt: publishAt}
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(post).Error)
+	return post
+}
+
+func (suite *PostRepositoryTestSuite) TestDefaultStatusIsPublished() {
+	post := suite.createPost("Welcome", "", nil)
+
+	var stored models.Post
+	suite.Require().NoError(suite.db.First(&stored, post.ID).Error)
+	assert.Equal(suite.T(), models.PostStatusPublished, stored.Status)
+}
+
+func (suite *PostRepositoryTestSuite) TestClubFeedsExcludeUnpublished() {
+	suite.createPost("Published", models.PostStatusPublished, nil)
+	suite.createPost("Draft", models.PostStatusDraft, nil)
+	later := time.Now().Add(time.Hour)
+	suite.createPost("Scheduled", models.PostStatusScheduled, &later)
+
+	posts, err := suite.postRepo.ListByClubID(1, pagination.First(10))
+	suite.Require().NoError(err)
+	suite.Require().Len(posts.Items, 1)
+	assert.Equal(suite.T(), "Published", posts.Items[0].Title)
+
+	summaries, err := suite.postRepo.ListPostSummaries(1, nil, models.PostListOptions{}, pagination.First(10))
+	suite.Require().NoError(err)
+	suite.Require().Len(summaries.Items, 1)
+	assert.Equal(suite.T(), "Published", summaries.Items[0].Title)
+}
+
+func (suite *PostRepositoryTestSuite) TestListByStatusForUser() {
+	suite.createPost("Draft", models.PostStatusDraft, nil)
+	suite.createPost("Published", models.PostStatusPublished, nil)
+	soon, later := time.Now().Add(time.Hour), time.Now().Add(2*time.Hour)
+	suite.createPost("Later", models.PostStatusScheduled, &later)
+	suite.createPost("Soon", models.PostStatusScheduled, &soon)
+
+	drafts, err := suite.postRepo.ListByStatusForUser(1, models.PostStatusDraft, pagination.First(10))
+	suite.Require().NoError(err)
+	suite.Require().Len(drafts.Items, 1)
+	assert.Equal(suite.T(), "Draft", drafts.Items[0].Title)
+
+	scheduled, err := suite.postRepo.ListByStatusForUser(1, models.PostStatusScheduled, pagination.First(10))
+	suite.Require().NoError(err)
+	suite.Require().Len(scheduled.Items, 2)
+	assert.Equal(suite.T(), "Soon", scheduled.Items[0].Title)
+
+	others, err := suite.postRepo.ListByStatusForUser(2, models.PostStatusDraft, pagination.First(10))
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), others.Items)
+}
+
+func (suite *PostRepositoryTestSuite) TestClubFeedPagesByCursor() {
+	for _, title := range []string{"a", "b", "c", "d", "e"} {
+		suite.createPost(title, models.PostStatusPublished, nil)
+	}
+
+	var titles []string
+	req := pagination.Request{Limit: 2}
+	for {
+		p, err := req.Params()
+		suite.Require().NoError(err)
+		page, err := suite.postRepo.ListByClubID(1, p)
+		suite.Require().NoError(err)
+		for _, post := range page.Items {
+			titles = append(titles, post.Title)
+		}
+		if !page.HasMore {
+			break
+		}
+		req.Cursor = page.NextCursor
+
+		// a post published while paging shows up on a fresh first page, not
+		// as a duplicate further down
+		if len(titles) == 2 {
+			suite.createPost("new", models.PostStatusPublished, nil)
+		}
+	}
+	assert.Equal(suite.T(), []string{"e", "d", "c", "b", "a"}, titles)
+}
+
+func (suite *PostRepositoryTestSuite) TestListDueScheduledAndPublish() {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+	due := suite.createPost("Due", models.PostStatusScheduled, &past)
+	suite.createPost("Future", models.PostStatusScheduled, &future)
+	suite.createPost("Draft", models.PostStatusDraft, nil)
+
+	posts, err := suite.postRepo.ListDueScheduled(now, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(posts, 1)
+	assert.Equal(suite.T(), due.ID, posts[0].ID)
+
+	ok, err := suite.postRepo.Publish(due.ID, now)
+	suite.Require().NoError(err)
+	assert.True(suite.T(), ok)
+
+	ok, err = suite.postRepo.Publish(due.ID, now)
+	suite.Require().NoError(err)
+	assert.False(suite.T(), ok, "a post is only published once")
+
+	var stored models.Post
+	suite.Require().NoError(suite.db.First(&stored, due.ID).Error)
+	assert.Equal(suite.T(), models.PostStatusPublished, stored.Status)
+	assert.Nil(suite.T(), stored.PublishAt)
+	assert.NotNil(suite.T(), stored.PublishedAt)
+
+	posts, err = suite.postRepo.ListDueScheduled(now, 10)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), posts)
+}
+
+func (suite *PostRepositoryTestSuite) TestClubFeedSorts() {
+	now := time.Now()
+	monthOld, dayOld := now.AddDate(0, 0, -30), now.Add(-24*time.Hour)
+	for _, p := range []struct {
+		title       string
+		publishedAt time.Time
+		likes       int
+		comments    int
+		hot         float64
+	}{
+		{"old favorite", monthOld, 20, 5, 0.001},
+		{"rising", now, 3, 1, 1.5},
+		{"yesterday", dayOld, 8, 0, 0.1},
+		{"quiet", now, 0, 0, 0},
+	} {
+		publishedAt := p.publishedAt
+		post := &models.Post{Title: p.title, Content: "text", Type: "discussion", UserID: 1, ClubID: 1, PublishedAt: &publishedAt,
+			LikesCount: p.likes, CommentsCount: p.comments, HotScore: p.hot}
+		suite.Require().NoError(suite.db.Omit("Club", "User").Create(post).Error)
+	}
+
+	titles := func(opts models.PostListOptions) []string {
+		var out []string
+		req := pagination.Request{Limit: 1}
+		for {
+			p, err := req.Params()
+			suite.Require().NoError(err)
+			page, err := suite.postRepo.ListPostSummaries(1, nil, opts, p)
+			suite.Require().NoError(err)
+			for _, post := range page.Items {
+				out = append(out, post.Title)
+			}
+			if !page.HasMore {
+				return out
+			}
+			req.Cursor = page.NextCursor
+		}
+	}
+
+	assert.Equal(suite.T(), []string{"quiet", "rising", "yesterday", "old favorite"}, titles(models.PostListOptions{Sort: models.PostSortNew}))
+	assert.Equal(suite.T(), []string{"rising", "yesterday", "old favorite", "quiet"}, titles(models.PostListOptions{Sort: models.PostSortHot}))
+	assert.Equal(suite.T(), []string{"old favorite", "yesterday", "rising", "quiet"}, titles(models.PostListOptions{Sort: models.PostSortTop}))
+
+	week := now.AddDate(0, 0, -7)
+	assert.Equal(suite.T(), []string{"yesterday", "rising", "quiet"}, titles(models.PostListOptions{Sort: models.PostSortTop, Since: &week}))
+}
+
+func (suite *PostRepositoryTestSuite) TestHotScoreBookkeeping() {
+	now := time.Now()
+	old := now.AddDate(0, 0, -60)
+	recent := suite.createPost("Recent", models.PostStatusPublished, nil)
+	stale := &models.Post{Title: "Stale", Content: "text", Type: "discussion", UserID: 1, ClubID: 1, PublishedAt: &old, HotScore: 2}
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(stale).Error)
+	draft := suite.createPost("Draft", models.PostStatusDraft, nil)
+	suite.Require().NoError(suite.db.Model(recent).UpdateColumns(map[string]interface{}{
+		"likes_count":     2,
+		"reaction_counts": models.ReactionCounts{models.LikeReaction: 2, "🔥": 3},
+	}).Error)
+
+	engagement, err := suite.postRepo.GetEngagement([]uint{recent.ID, stale.ID, draft.ID})
+	suite.Require().NoError(err)
+	suite.Require().Len(engagement, 2, "drafts have no engagement to rank")
+	for _, e := range engagement {
+		if e.PostID == recent.ID {
+			assert.Equal(suite.T(), 2, e.LikesCount)
+			assert.Equal(suite.T(), 3, e.ReactionsCount, "likes are not counted twice")
+		}
+	}
+
+	since, err := suite.postRepo.ListEngagementSince(now.AddDate(0, 0, -30), 0, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(since, 1)
+	assert.Equal(suite.T(), recent.ID, since[0].PostID)
+	since, err = suite.postRepo.ListEngagementSince(now.AddDate(0, 0, -30), recent.ID, 10)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), since)
+
+	var before models.Post
+	suite.Require().NoError(suite.db.First(&before, recent.ID).Error)
+	suite.Require().NoError(suite.postRepo.UpdateHotScores(map[uint]float64{recent.ID: 0.75}))
+	var after models.Post
+	suite.Require().NoError(suite.db.First(&after, recent.ID).Error)
+	assert.Equal(suite.T(), 0.75, after.HotScore)
+	assert.True(suite.T(), before.UpdatedAt.Equal(after.UpdatedAt), "rescoring is not an edit")
+
+	n, err := suite.postRepo.ClearHotScoresBefore(now.AddDate(0, 0, -30))
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(1), n)
+	var cleared models.Post
+	suite.Require().NoError(suite.db.First(&cleared, stale.ID).Error)
+	assert.Zero(suite.T(), cleared.HotScore)
+}
+
+func (suite *PostRepositoryTestSuite) TestPollVotes() {
+	poll := suite.createPost("Next book?", models.PostStatusPublished, nil)
+	ana := &models.User{Username: "ana", Email: "ana@example.com", PasswordHash: "x"}
+	ben := &models.User{Username: "ben", Email: "ben@example.com", PasswordHash: "x"}
+	suite.Require().NoError(suite.db.Create(ana).Error)
+	suite.Require().NoError(suite.db.Create(ben).Error)
+
+	ballot := func(userID uint, optionIDs ...string) []models.PollVote {
+		votes := make([]models.PollVote, len(optionIDs))
+		for i, id := range optionIDs {
+			votes[i] = models.PollVote{PostID: poll.ID, UserID: userID, OptionID: id, Rank: i + 1}
+		}
+		return votes
+	}
+	suite.Require().NoError(suite.postRepo.ReplacePollVotes(poll.ID, ben.ID, ballot(ben.ID, "opt_2", "opt_1")))
+	suite.Require().NoError(suite.postRepo.ReplacePollVotes(poll.ID, ana.ID, ballot(ana.ID, "opt_1", "opt_3")))
+	suite.Require().NoError(suite.postRepo.ReplacePollVotes(poll.ID, ana.ID, ballot(ana.ID, "opt_3", "opt_2", "opt_1")))
+
+	votes, err := suite.postRepo.ListPollVotes([]uint{poll.ID}, nil)
+	suite.Require().NoError(err)
+	var got []string
+	for _, v := range votes {
+		got = append(got, v.OptionID)
+	}
+	assert.Equal(suite.T(), []string{"opt_3", "opt_2", "opt_1", "opt_2", "opt_1"}, got, "the later ballot replaces the earlier one, grouped by voter in rank order")
+
+	assert.Equal(suite.T(), map[string]int{"opt_2": 1, "opt_3": 1}, suite.pollCounts(poll.ID), "only first preferences are counted")
+
+	own, err := suite.postRepo.ListPollVotes([]uint{poll.ID}, &ben.ID)
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), own, 2)
+
+	voters, err := suite.postRepo.ListPollVoters(poll.ID, "opt_1", pagination.First(1))
+	suite.Require().NoError(err)
+	suite.Require().Len(voters.Items, 1)
+	assert.True(suite.T(), voters.HasMore)
+	assert.Equal(suite.T(), "ben", voters.Items[0].User.Username)
+	assert.Equal(suite.T(), 2, voters.Items[0].Rank)
+
+	suite.Require().NoError(suite.postRepo.UpdateTypeData(poll.ID, models.PostTypeData(`{"question":"Next book?"}`)))
+	var stored models.Post
+	suite.Require().NoError(suite.db.First(&stored, poll.ID).Error)
+	assert.JSONEq(suite.T(), `{"question":"Next book?"}`, string(stored.TypeData))
+}
+
+func (suite *PostRepositoryTestSuite) TestPollCounters() {
+	poll := suite.createPost("Which day?", models.PostStatusPublished, nil)
+	vote := func(userID uint, optionIDs ...string) []models.PollVote {
+		votes := make([]models.PollVote, len(optionIDs))
+		for i, id := range optionIDs {
+			votes[i] = models.PollVote{PostID: poll.ID, UserID: userID, OptionID: id}
+		}
+		return votes
+	}
+
+	added, err := suite.postRepo.AddPollVotes(poll.ID, 1, vote(1, "mon"), true)
+	suite.Require().NoError(err)
+	assert.True(suite.T(), added)
+	added, err = suite.postRepo.AddPollVotes(poll.ID, 1, vote(1, "tue"), true)
+	suite.Require().NoError(err)
+	assert.False(suite.T(), added, "exclusive polls take one vote per voter")
+
+	_, err = suite.postRepo.AddPollVotes(poll.ID, 2, vote(2, "mon", "tue"), false)
+	suite.Require().NoError(err)
+	_, err = suite.postRepo.AddPollVotes(poll.ID, 2, vote(2, "tue", "wed"), false)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), map[string]int{"mon": 2, "tue": 1, "wed": 1}, suite.pollCounts(poll.ID), "a repeated option is neither stored nor counted twice")
+
+	suite.Require().NoError(suite.postRepo.RemoveVoteFromPoll(poll.ID, 2, "mon"))
+	suite.Require().NoError(suite.postRepo.RemoveVoteFromPoll(poll.ID, 2, "mon"))
+	assert.Equal(suite.T(), map[string]int{"mon": 1, "tue": 1, "wed": 1}, suite.pollCounts(poll.ID))
+
+	voters, err := suite.postRepo.CountPollVoters([]uint{poll.ID})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), map[uint]int{poll.ID: 2}, voters)
+}
+
+func (suite *PostRepositoryTestSuite) TestClosePoll() {
+	poll := &models.Post{Title: "Which day?", Content: "text", Type: "poll", UserID: 1, ClubID: 1,
+		TypeData: models.PostTypeData(`{"question":"Which day?","options":[{"id":"mon","text":"Mon"},{"id":"tue","text":"Tue"}]}`)}
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(poll).Error)
+	vote := func(userID uint, optionID string) []models.PollVote {
+		return []models.PollVote{{PostID: poll.ID, UserID: userID, OptionID: optionID}}
+	}
+	_, err := suite.postRepo.AddPollVotes(poll.ID, 1, vote(1, "mon"), true)
+	suite.Require().NoError(err)
+
+	err = suite.postRepo.ClosePoll(poll.ID, func(tx PostRepository) error {
+		counts, err := tx.ListPollCounts([]uint{poll.ID})
+		if err != nil {
+			return err
+		}
+		assert.Len(suite.T(), counts, 1, "the tally is read inside the lock")
+		return tx.UpdateTypeData(poll.ID, models.PostTypeData(`{"question":"Which day?","closed_at":"2026-01-01T00:00:00Z"}`))
+	})
+	suite.Require().NoError(err)
+
+	_, err = suite.postRepo.AddPollVotes(poll.ID, 2, vote(2, "tue"), true)
+	assert.ErrorIs(suite.T(), err, ErrPollClosed)
+	assert.ErrorIs(suite.T(), suite.postRepo.ReplacePollVotes(poll.ID, 1, vote(1, "tue")), ErrPollClosed)
+	assert.ErrorIs(suite.T(), suite.postRepo.RemoveVoteFromPoll(poll.ID, 1, "mon"), ErrPollClosed)
+	assert.Equal(suite.T(), map[string]int{"mon": 1}, suite.pollCounts(poll.ID))
+
+	called := false
+	err = suite.postRepo.ClosePoll(poll.ID, func(tx PostRepository) error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(suite.T(), err, ErrPollClosed, "a poll is closed once")
+	assert.False(suite.T(), called)
+}
+
+func (suite *PostRepositoryTestSuite) pollCounts(postID uint) map[string]int {
+	counts, err := suite.postRepo.ListPollCounts([]uint{postID})
+	suite.Require().NoError(err)
+	byOption := map[string]int{}
+	for _, c := range counts {
+		if c.Votes != 0 {
+			byOption[c.OptionID] = c.Votes
+		}
+	}
+	return byOption
+}
+
+func TestPostRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PostRepositoryTestSuite))
+}
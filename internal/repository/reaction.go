@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type reactionRepository struct {
+	db *gorm.DB
+}
+
+func NewReactionRepository(db *gorm.DB) *reactionRepository {
+	return &reactionRepository{db: db}
+}
+
+// reactionTargetTables maps reaction target types to the tables holding the
+// denormalized counts.
+var reactionTargetTables = map[string]string{
+	models.ReactionTargetPost:    "posts",
+	models.ReactionTargetComment: "comments",
+}
+
+func (r *reactionRepository) Add(reaction *models.Reaction) (bool, error) {
+	return addReaction(r.db, reaction)
+}
+
+func (r *reactionRepository) Remove(targetType string, targetID, userID uint, emoji string) (bool, error) {
+	return removeReaction(r.db, targetType, targetID, userID, emoji)
+}
+
+// reactionsOldestFirst lists reactions in the order they were added.
+var reactionsOldestFirst = pagination.Keyset{
+	Columns: []pagination.Column{{Expr: "reactions.created_at", Kind: pagination.Time}},
+	ID:      "reactions.id",
+}
+
+func reactionCursor(r models.Reaction) pagination.Cursor {
+	return pagination.CursorFor(r.ID, r.CreatedAt)
+}
+
+func (r *reactionRepository) List(targetType string, targetID uint, emoji string, page pagination.Params) (pagination.Page[models.Reaction], error) {
+	query := r.db.Model(&models.Reaction{}).
+		Preload("User").
+		Where("target_type = ? AND target_id = ?", targetType, targetID)
+	if emoji != "" {
+		query = query.Where("emoji = ?", emoji)
+	}
+	return pagination.Find(query, reactionsOldestFirst, page, reactionCursor)
+}
+
+// ListUserReactions returns the emojis the user added to each of the targets.
+func (r *reactionRepository) ListUserReactions(targetType string, userID uint, targetIDs []uint) (map[uint][]string, error) {
+	return listUserReactions(r.db, targetType, userID, targetIDs)
+}
+
+func addReaction(db *gorm.DB, reaction *models.Reaction) (bool, error) {
+	added := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := lockReactionTarget(tx, reaction.TargetType, reaction.TargetID); err != nil {
+			return err
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		added = true
+		return refreshReactionCounts(tx, reaction.TargetType, reaction.TargetID)
+	})
+	return added, err
+}
+
+func removeReaction(db *gorm.DB, targetType string, targetID, userID uint, emoji string) (bool, error) {
+	removed := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := lockReactionTarget(tx, targetType, targetID); err != nil {
+			return err
+		}
+		result := tx.Where("target_type = ? AND target_id = ? AND user_id = ? AND emoji = ?", targetType, targetID, userID, emoji).
+			Delete(&models.Reaction{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		removed = true
+		return refreshReactionCounts(tx, targetType, targetID)
+	})
+	return removed, err
+}
+
+// lockReactionTarget locks the post or comment a reaction is added to or
+// removed from, so concurrent reactions recount one after the other instead
+// of overwriting each other's counts.
+func lockReactionTarget(tx *gorm.DB, targetType string, targetID uint) error {
+	table, ok := reactionTargetTables[targetType]
+	if !ok {
+		return fmt.Errorf("unknown reaction target %q", targetType)
+	}
+	var ids []uint
+	return tx.Table(table).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", targetID).Pluck("id", &ids).Error
+}
+
+// refreshReactionCounts recomputes the per-emoji counts of a target from the
+// reactions table. likes_count mirrors the ❤️ count for older clients.
+func refreshReactionCounts(tx *gorm.DB, targetType string, targetID uint) error {
+	table, ok := reactionTargetTables[targetType]
+	if !ok {
+		return fmt.Errorf("unknown reaction target %q", targetType)
+	}
+
+	var rows []struct {
+		Emoji string
+		Count int
+	}
+	if err := tx.Model(&models.Reaction{}).
+		Select("emoji, COUNT(*) AS count").
+		Where("target_type = ? AND target_id = ?", targetType, targetID).
+		Group("emoji").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	counts := make(models.ReactionCounts, len(rows))
+	for _, row := range rows {
+		counts[row.Emoji] = row.Count
+	}
+
+	return tx.Table(table).
+		Where("id = ?", targetID).
+		UpdateColumns(map[string]interface{}{
+			"reaction_counts": counts,
+			"likes_count":     counts[models.LikeReaction],
+		}).Error
+}
+
+func listUserReactions(db *gorm.DB, targetType string, userID uint, targetIDs []uint) (map[uint][]string, error) {
+	out := make(map[uint][]string)
+	if len(targetIDs) == 0 {
+		return out, nil
+	}
+
+	var reactions []models.Reaction
+	if err := db.
+		Select("target_id, emoji").
+		Where("target_type = ? AND user_id = ? AND target_id IN ?", targetType, userID, targetIDs).
+		Find(&reactions).Error; err != nil {
+		return nil, err
+	}
+
+	for _, reaction := range reactions {
+		out[reaction.TargetID] = append(out[reaction.TargetID], reaction.Emoji)
+	}
+	return out, nil
+}
+
+func hasReacted(db *gorm.DB, targetType string, targetID, userID uint, emoji string) (bool, error) {
+	var count int64
+	err := db.Model(&models.Reaction{}).
+		Where("target_type = ? AND target_id = ? AND user_id = ? AND emoji = ?", targetType, targetID, userID, emoji).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ReactionRepositoryTestSuite struct {
+	suite.Suite
+	db           *gorm.DB
+	reactionRepo ReactionRepository
+	postRepo     *postRepository
+	post         *models.Post
+}
+
+func (suite *ReactionRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.reactionRepo = NewReactionRepository(suite.db)
+	suite.postRepo = NewPostRepository(suite.db)
+
+	suite.post = &models.Post{Title: "Dune", Content: "Chapter one", Type: "discussion", UserID: 1, ClubID: 1}
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(suite.post).Error)
+}
+
+func (suite *ReactionRepositoryTestSuite) react(userID uint, emoji string) bool {
+	added, err := suite.reactionRepo.Add(&models.Reaction{
+		TargetType: models.ReactionTargetPost,
+		TargetID:   suite.post.ID,
+		UserID:     userID,
+		Emoji:      emoji,
+	})
+	suite.Require().NoError(err)
+	return added
+}
+
+func (suite *ReactionRepositoryTestSuite) reload() models.Post {
+	var post models.Post
+	suite.Require().NoError(suite.db.First(&post, suite.post.ID).Error)
+	return post
+}
+
+func (suite *ReactionRepositoryTestSuite) TestAdd_DenormalizesCounts() {
+	assert.True(suite.T(), suite.react(1, models.LikeReaction))
+	assert.True(suite.T(), suite.react(1, "🔥"))
+	assert.True(suite.T(), suite.react(2, "🔥"))
+	assert.False(suite.T(), suite.react(2, "🔥"))
+
+	post := suite.reload()
+	assert.Equal(suite.T(), models.ReactionCounts{models.LikeReaction: 1, "🔥": 2}, post.ReactionCounts)
+	assert.Equal(suite.T(), 1, post.LikesCount)
+}
+
+func (suite *ReactionRepositoryTestSuite) TestLegacyLikesUseHeartReaction() {
+	assert.NoError(suite.T(), suite.postRepo.AddLike(3, suite.post.ID))
+
+	liked, err := suite.postRepo.HasUserLiked(3, suite.post.ID)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), liked)
+	assert.Equal(suite.T(), 1, suite.reload().LikesCount)
+
+	assert.NoError(suite.T(), suite.postRepo.RemoveLike(3, suite.post.ID))
+	assert.ErrorIs(suite.T(), suite.postRepo.RemoveLike(3, suite.post.ID), gorm.ErrRecordNotFound)
+
+	post := suite.reload()
+	assert.Equal(suite.T(), 0, post.LikesCount)
+	assert.Empty(suite.T(), post.ReactionCounts)
+}
+
+func (suite *ReactionRepositoryTestSuite) TestListAndUserReactions() {
+	suite.react(1, "📚")
+	suite.react(2, "📚")
+	suite.react(2, "😂")
+
+	first, err := suite.reactionRepo.List(models.ReactionTargetPost, suite.post.ID, "📚", pagination.First(1))
+	suite.Require().NoError(err)
+	suite.Require().Len(first.Items, 1)
+	assert.True(suite.T(), first.HasMore)
+
+	p, err := pagination.Request{Cursor: first.NextCursor, Limit: 1}.Params()
+	suite.Require().NoError(err)
+	second, err := suite.reactionRepo.List(models.ReactionTargetPost, suite.post.ID, "📚", p)
+	suite.Require().NoError(err)
+	suite.Require().Len(second.Items, 1)
+	assert.False(suite.T(), second.HasMore)
+	assert.NotEqual(suite.T(), first.Items[0].UserID, second.Items[0].UserID)
+
+	mine, err := suite.reactionRepo.ListUserReactions(models.ReactionTargetPost, 2, []uint{suite.post.ID})
+	assert.NoError(suite.T(), err)
+	assert.ElementsMatch(suite.T(), []string{"📚", "😂"}, mine[suite.post.ID])
+}
+
+func TestReactionRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ReactionRepositoryTestSuite))
+}
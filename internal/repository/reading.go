@@ -4,6 +4,7 @@ import (
     "errors"
 
     "github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
     "gorm.io/gorm"
 )
 
@@ -36,17 +37,33 @@ func (r *readingRepository) GetUserBookProgress(userID, bookID uint) (*models.Us
     return &p, nil
 }
 
-func (r *readingRepository) ListUserProgress(userID uint) ([]*models.UserBookProgress, error) {
-    var out []*models.UserBookProgress
-    err := r.db.Where("user_id = ?", userID).Order("updated_at DESC").Find(&out).Error
-    return out, err
+// ListUserProgress returns the user's books, most recently updated first.
+func (r *readingRepository) ListUserProgress(userID uint, page pagination.Params) (pagination.Page[*models.UserBookProgress], error) {
+    recentFirst := pagination.Keyset{
+        Columns: []pagination.Column{{Expr: "updated_at", Kind: pagination.Time, Desc: true}},
+        ID:      "id",
+        Desc:    true,
+    }
+    return pagination.Find(r.db.Where("user_id = ?", userID), recentFirst, page, func(p *models.UserBookProgress) pagination.Cursor {
+        return pagination.CursorFor(p.ID, p.UpdatedAt)
+    })
 }
 
-func (r *readingRepository) ListUserFinished(userID uint) ([]*models.UserBookProgress, error) {
-    var out []*models.UserBookProgress
-    err := r.db.Where("user_id = ? AND status = ?", userID, models.ReadingFinished).
-        Order("finished_at DESC").Find(&out).Error
-    return out, err
+// ListUserFinished returns the books the user finished, latest first. Rows
+// without a finish time fall back to their last update.
+func (r *readingRepository) ListUserFinished(userID uint, page pagination.Params) (pagination.Page[*models.UserBookProgress], error) {
+    latestFirst := pagination.Keyset{
+        Columns: []pagination.Column{{Expr: "COALESCE(finished_at, updated_at)", Kind: pagination.Time, Desc: true}},
+        ID:      "id",
+        Desc:    true,
+    }
+    query := r.db.Where("user_id = ? AND status = ?", userID, models.ReadingFinished)
+    return pagination.Find(query, latestFirst, page, func(p *models.UserBookProgress) pagination.Cursor {
+        if p.FinishedAt != nil {
+            return pagination.CursorFor(p.ID, *p.FinishedAt)
+        }
+        return pagination.CursorFor(p.ID, p.UpdatedAt)
+    })
 }
 
 func (r *readingRepository) AppendLog(l *models.ReadingLog) error {
@@ -81,12 +98,37 @@ func (r *clubReadingRepository) GetActiveAssignment(clubID uint) (*models.ClubBo
     return &a, nil
 }
 
-func (r *clubReadingRepository) ListAssignments(clubID uint) ([]models.ClubBookAssignment, error) {
-    var out []models.ClubBookAssignment
-    err := r.db.Where("club_id = ?", clubID).Order("created_at DESC").Find(&out).Error
-    return out, err
+// ListAssignments returns the club's assignments, newest first.
+func (r *clubReadingRepository) ListAssignments(clubID uint, page pagination.Params) (pagination.Page[models.ClubBookAssignment], error) {
+    newestFirst := pagination.Keyset{
+        Columns: []pagination.Column{{Expr: "club_book_assignments.created_at", Kind: pagination.Time, Desc: true}},
+        ID:      "club_book_assignments.id",
+        Desc:    true,
+    }
+    return pagination.Find(r.db.Where("club_id = ?", clubID), newestFirst, page, func(a models.ClubBookAssignment) pagination.Cursor {
+        return pagination.CursorFor(a.ID, a.CreatedAt)
+    })
 }
 
 func (r *clubReadingRepository) UpdateAssignment(a *models.ClubBookAssignment) error {
     return r.db.Save(a).Error
+}
+
+// EachAssignmentProgress calls fn with the progress of every approved member
+// on every book assigned to the club, newest assignment first and members in
+// the order they joined.
+func (r *clubReadingRepository) EachAssignmentProgress(clubID uint, fn func(row *models.ReadingExportRow) error) error {
+    return eachRow(r.db.Raw(`
+        SELECT a.id AS assignment_id, a.book_id, COALESCE(b.title, '') AS book_title,
+            a.status AS assignment_status, a.due_date,
+            m.user_id, COALESCE(u.username, '') AS username,
+            p.status AS reading_status, p.current_page, p.percent, p.started_at, p.finished_at
+        FROM club_book_assignments a
+        JOIN club_memberships m ON m.club_id = a.club_id AND m.is_approved = ?
+        LEFT JOIN users u ON u.id = m.user_id AND u.deleted_at IS NULL
+        LEFT JOIN books b ON b.id = a.book_id AND b.deleted_at IS NULL
+        LEFT JOIN user_book_progresses p ON p.user_id = m.user_id AND p.book_id = a.book_id
+        WHERE a.club_id = ?
+        ORDER BY a.created_at DESC, a.id DESC, m.joined_at, m.id`,
+        true, clubID), fn)
 }
\ No newline at end of file
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ClubReadingRepositoryTestSuite struct {
+	suite.Suite
+	db              *gorm.DB
+	clubReadingRepo ClubReadingRepository
+	reader          *models.User
+	lurker          *models.User
+}
+
+func (suite *ClubReadingRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.clubReadingRepo = NewClubReadingRepository(suite.db)
+
+	suite.reader = &models.User{Username: "reader", Email: "reader@example.com", PasswordHash: "x"}
+	suite.lurker = &models.User{Username: "lurker", Email: "lurker@example.com", PasswordHash: "x"}
+	pending := &models.User{Username: "pending", Email: "pending@example.com", PasswordHash: "x"}
+	for _, u := range []*models.User{suite.reader, suite.lurker, pending} {
+		suite.Require().NoError(suite.db.Create(u).Error)
+	}
+	for _, m := range []models.ClubMembership{
+		{ClubID: 1, UserID: suite.reader.ID, Role: "club_admin", IsApproved: true},
+		{ClubID: 1, UserID: suite.lurker.ID, Role: "member", IsApproved: true},
+		{ClubID: 1, UserID: pending.ID, Role: "member"},
+	} {
+		suite.Require().NoError(suite.db.Omit("User", "Club").Create(&m).Error)
+	}
+}
+
+func (suite *ClubReadingRepositoryTestSuite) TestEachAssignmentProgress() {
+	dune, emma := &models.Book{Title: "Dune"}, &models.Book{Title: "Emma"}
+	suite.Require().NoError(suite.db.Create(dune).Error)
+	suite.Require().NoError(suite.db.Create(emma).Error)
+	older := &models.ClubBookAssignment{ClubID: 1, BookID: dune.ID, CreatedAt: time.Now().Add(-time.Hour)}
+	newer := &models.ClubBookAssignment{ClubID: 1, BookID: emma.ID}
+	suite.Require().NoError(suite.db.Create(older).Error)
+	suite.Require().NoError(suite.db.Create(newer).Error)
+	page := 120
+	suite.Require().NoError(suite.db.Create(&models.UserBookProgress{UserID: suite.reader.ID, BookID: dune.ID, Status: models.ReadingActive, CurrentPage: &page}).Error)
+
+	var rows []models.ReadingExportRow
+	suite.Require().NoError(suite.clubReadingRepo.EachAssignmentProgress(1, func(row *models.ReadingExportRow) error {
+		rows = append(rows, *row)
+		return nil
+	}))
+
+	suite.Require().Len(rows, 4, "every approved member for every assignment")
+	assert.Equal(suite.T(), "Emma", rows[0].BookTitle, "newest assignment first")
+	assert.Nil(suite.T(), rows[0].ReadingStatus)
+	assert.Equal(suite.T(), "Dune", rows[2].BookTitle)
+	assert.Equal(suite.T(), "reader", rows[2].Username)
+	suite.Require().NotNil(rows[2].ReadingStatus)
+	assert.Equal(suite.T(), string(models.ReadingActive), *rows[2].ReadingStatus)
+	assert.Equal(suite.T(), 120, *rows[2].CurrentPage)
+	assert.Equal(suite.T(), "lurker", rows[3].Username)
+	assert.Nil(suite.T(), rows[3].ReadingStatus)
+}
+
+func TestClubReadingRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ClubReadingRepositoryTestSuite))
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) *reportRepository {
+	return &reportRepository{db: db}
+}
+
+// Create files a report. It returns false when the reporter already reported
+// the target.
+func (r *reportRepository) Create(report *models.Report) (bool, error) {
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(report)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *reportRepository) GetByID(id uint) (*models.Report, error) {
+	var report models.Report
+	if err := r.db.First(&report, id).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// HasOpen reports whether the target has an open report.
+func (r *reportRepository) HasOpen(targetType string, targetID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, models.ReportStatusOpen).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// List returns the reports matching filter. Open reports come oldest first,
+// as a queue; settled ones newest first.
+func (r *reportRepository) List(filter models.ReportFilter, page pagination.Params) (pagination.Page[models.Report], error) {
+	query := r.db.Model(&models.Report{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.ClubIDs != nil {
+		query = query.Where("club_id IN ?", filter.ClubIDs)
+	}
+	newestFirst := filter.Status != models.ReportStatusOpen
+	return pagination.Find(query, pagination.ByID("id", newestFirst), page, func(report models.Report) pagination.Cursor {
+		return pagination.CursorFor(report.ID)
+	})
+}
+
+// Resolve settles every open report on the target of log with status and
+// records the decision. It returns the reports it settled; when another
+// moderator settled them first it returns none and records nothing.
+func (r *reportRepository) Resolve(log *models.ModerationLog, status string, at time.Time) ([]models.Report, error) {
+	var settled []models.Report
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var open []models.Report
+		if err := tx.Where("target_type = ? AND target_id = ? AND status = ?", log.TargetType, log.TargetID, models.ReportStatusOpen).
+			Order("id").
+			Find(&open).Error; err != nil {
+			return err
+		}
+		if len(open) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(open))
+		for _, report := range open {
+			ids = append(ids, report.ID)
+		}
+		res := tx.Model(&models.Report{}).
+			Where("id IN ? AND status = ?", ids, models.ReportStatusOpen).
+			Updates(map[string]interface{}{
+				"status":      status,
+				"action":      log.Action,
+				"resolved_by": log.ModeratorID,
+				"resolved_at": at,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+
+		log.ReportsCount = int(res.RowsAffected)
+		if err := tx.Create(log).Error; err != nil {
+			return err
+		}
+		for i := range open {
+			open[i].Status = status
+			open[i].Action = log.Action
+			open[i].ResolvedBy = &log.ModeratorID
+			open[i].ResolvedAt = &at
+		}
+		settled = open
+		return nil
+	})
+	return settled, err
+}
+
+// Reopen undoes a Resolve whose action could not be applied: the reports it
+// settled are open again and its moderation log is removed.
+func (r *reportRepository) Reopen(log *models.ModerationLog, reportIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Report{}).
+			Where("id IN ? AND status <> ?", reportIDs, models.ReportStatusOpen).
+			Updates(map[string]interface{}{
+				"status":      models.ReportStatusOpen,
+				"action":      "",
+				"resolved_by": nil,
+				"resolved_at": nil,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ModerationLog{}, log.ID).Error
+	})
+}
+
+// ListLogs returns moderation decisions newest first. A nil clubIDs lists
+// every decision; otherwise only those about content of the given clubs.
+func (r *reportRepository) ListLogs(clubIDs []uint, page pagination.Params) (pagination.Page[models.ModerationLog], error) {
+	query := r.db.Model(&models.ModerationLog{})
+	if clubIDs != nil {
+		query = query.Where("club_id IN ?", clubIDs)
+	}
+	return pagination.Find(query, pagination.ByID("id", true), page, func(log models.ModerationLog) pagination.Cursor {
+		return pagination.CursorFor(log.ID)
+	})
+}
+
+// ListModeratedClubIDs returns the clubs userID owns or is an approved
+// moderator or club admin of.
+func (r *reportRepository) ListModeratedClubIDs(userID uint) ([]uint, error) {
+	ids := []uint{}
+	err := r.db.Model(&models.Club{}).
+		Where("owner_id = ? OR EXISTS (SELECT 1 FROM club_memberships m WHERE m.club_id = clubs.id AND m.user_id = ? AND m.is_approved = ? AND m.role IN ?)",
+			userID, userID, true, []string{"moderator", "club_admin"}).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *reportRepository) ListReadableClubIDs(userID uint, clubIDs []uint) (map[uint]bool, error) {
+	return listReadableClubIDs(r.db, userID, clubIDs)
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ReportRepositoryTestSuite struct {
+	suite.Suite
+	db               *gorm.DB
+	reportRepo       ReportRepository
+	notificationRepo NotificationRepository
+	club             *models.Club
+	other            *models.Club
+}
+
+func (suite *ReportRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.reportRepo = NewReportRepository(suite.db)
+	suite.notificationRepo = NewNotificationRepository(suite.db)
+
+	suite.club = &models.Club{Name: "Club"}
+	suite.other = &models.Club{Name: "Other"}
+	suite.Require().NoError(suite.db.Omit("Owner", "Members", "Tags").Create(suite.club).Error)
+	suite.Require().NoError(suite.db.Omit("Owner", "Members", "Tags").Create(suite.other).Error)
+}
+
+func (suite *ReportRepositoryTestSuite) report(reporterID uint, targetType string, targetID uint, clubID *uint) *models.Report {
+	report := &models.Report{
+		ReporterID:   &reporterID,
+		TargetType:   targetType,
+		TargetID:     targetID,
+		TargetUserID: 99,
+		ClubID:       clubID,
+		Reason:       models.ReportReasonSpam,
+		Status:       models.ReportStatusOpen,
+	}
+	created, err := suite.reportRepo.Create(report)
+	suite.Require().NoError(err)
+	suite.Require().True(created)
+	return report
+}
+
+func (suite *ReportRepositoryTestSuite) listIDs(filter models.ReportFilter) []uint {
+	page, err := suite.reportRepo.List(filter, pagination.First(10))
+	suite.Require().NoError(err)
+	ids := make([]uint, 0, len(page.Items))
+	for _, r := range page.Items {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func (suite *ReportRepositoryTestSuite) TestCreate_OncePerReporter() {
+	suite.report(1, models.ReportTargetPost, 10, &suite.club.ID)
+
+	reporterID := uint(1)
+	created, err := suite.reportRepo.Create(&models.Report{ReporterID: &reporterID, TargetType: models.ReportTargetPost, TargetID: 10, Reason: models.ReportReasonOther})
+	suite.Require().NoError(err)
+	assert.False(suite.T(), created)
+
+	suite.report(2, models.ReportTargetPost, 10, &suite.club.ID)
+	suite.report(1, models.ReportTargetComment, 10, &suite.club.ID)
+}
+
+func (suite *ReportRepositoryTestSuite) TestList_Filters() {
+	post := suite.report(1, models.ReportTargetPost, 10, &suite.club.ID)
+	comment := suite.report(1, models.ReportTargetComment, 20, &suite.other.ID)
+	user := suite.report(1, models.ReportTargetUser, 30, nil)
+
+	open := models.ReportFilter{Status: models.ReportStatusOpen}
+	assert.Equal(suite.T(), []uint{post.ID, comment.ID, user.ID}, suite.listIDs(open), "the queue is oldest first")
+
+	open.ClubIDs = []uint{suite.club.ID}
+	assert.Equal(suite.T(), []uint{post.ID}, suite.listIDs(open))
+
+	open.ClubIDs = []uint{}
+	assert.Empty(suite.T(), suite.listIDs(open), "an empty scope sees nothing")
+
+	assert.Equal(suite.T(), []uint{user.ID}, suite.listIDs(models.ReportFilter{TargetType: models.ReportTargetUser}))
+	assert.Empty(suite.T(), suite.listIDs(models.ReportFilter{Status: models.ReportStatusResolved}))
+}
+
+func (suite *ReportRepositoryTestSuite) TestResolve_SettlesEveryOpenReportOnTheTarget() {
+	first := suite.report(1, models.ReportTargetPost, 10, &suite.club.ID)
+	second := suite.report(2, models.ReportTargetPost, 10, &suite.club.ID)
+	unrelated := suite.report(1, models.ReportTargetPost, 11, &suite.club.ID)
+
+	now := time.Now()
+	log := &models.ModerationLog{ModeratorID: 5, ReportID: first.ID, TargetType: models.ReportTargetPost, TargetID: 10, TargetUserID: 99, ClubID: &suite.club.ID, Action: models.ModerationHide}
+	settled, err := suite.reportRepo.Resolve(log, models.ReportStatusResolved, now)
+	suite.Require().NoError(err)
+	suite.Require().Len(settled, 2)
+	assert.Equal(suite.T(), []uint{first.ID, second.ID}, []uint{settled[0].ID, settled[1].ID})
+	assert.Equal(suite.T(), models.ModerationHide, settled[0].Action)
+	assert.Equal(suite.T(), 2, log.ReportsCount)
+
+	reloaded, err := suite.reportRepo.GetByID(second.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), models.ReportStatusResolved, reloaded.Status)
+	assert.Equal(suite.T(), uint(5), *reloaded.ResolvedBy)
+	assert.Equal(suite.T(), []uint{unrelated.ID}, suite.listIDs(models.ReportFilter{Status: models.ReportStatusOpen}))
+
+	again := &models.ModerationLog{ModeratorID: 6, ReportID: first.ID, TargetType: models.ReportTargetPost, TargetID: 10, TargetUserID: 99, Action: models.ModerationDismiss}
+	settled, err = suite.reportRepo.Resolve(again, models.ReportStatusDismissed, now)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), settled, "reports are settled once")
+
+	logs, err := suite.reportRepo.ListLogs(nil, pagination.First(10))
+	suite.Require().NoError(err)
+	suite.Require().Len(logs.Items, 1, "settling nothing logs nothing")
+	assert.Equal(suite.T(), log.ID, logs.Items[0].ID)
+
+	logs, err = suite.reportRepo.ListLogs([]uint{suite.other.ID}, pagination.First(10))
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), logs.Items)
+}
+
+func (suite *ReportRepositoryTestSuite) TestReopen_UndoesAResolve() {
+	first := suite.report(1, models.ReportTargetPost, 10, &suite.club.ID)
+	second := suite.report(2, models.ReportTargetPost, 10, &suite.club.ID)
+
+	log := &models.ModerationLog{ModeratorID: 5, ReportID: first.ID, TargetType: models.ReportTargetPost, TargetID: 10, TargetUserID: 99, Action: models.ModerationHide}
+	settled, err := suite.reportRepo.Resolve(log, models.ReportStatusResolved, time.Now())
+	suite.Require().NoError(err)
+	suite.Require().Len(settled, 2)
+
+	suite.Require().NoError(suite.reportRepo.Reopen(log, []uint{first.ID, second.ID}))
+
+	assert.Equal(suite.T(), []uint{first.ID, second.ID}, suite.listIDs(models.ReportFilter{Status: models.ReportStatusOpen}))
+	reloaded, err := suite.reportRepo.GetByID(first.ID)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), reloaded.Action)
+	assert.Nil(suite.T(), reloaded.ResolvedBy)
+	assert.Nil(suite.T(), reloaded.ResolvedAt)
+	logs, err := suite.reportRepo.ListLogs(nil, pagination.First(10))
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), logs.Items)
+
+	again := &models.ModerationLog{ModeratorID: 6, ReportID: first.ID, TargetType: models.ReportTargetPost, TargetID: 10, TargetUserID: 99, Action: models.ModerationDismiss}
+	settled, err = suite.reportRepo.Resolve(again, models.ReportStatusDismissed, time.Now())
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), settled, 2, "reopened reports can be settled again")
+}
+
+func (suite *ReportRepositoryTestSuite) TestListModeratedClubIDs() {
+	ownerID := uint(7)
+	owned := &models.Club{Name: "Owned", OwnerID: &ownerID}
+	suite.Require().NoError(suite.db.Omit("Owner", "Members", "Tags").Create(owned).Error)
+	for _, m := range []*models.ClubMembership{
+		{UserID: 7, ClubID: suite.club.ID, Role: "moderator", IsApproved: true},
+		{UserID: 7, ClubID: suite.other.ID, Role: "member", IsApproved: true},
+		{UserID: 8, ClubID: suite.club.ID, Role: "club_admin", IsApproved: false},
+	} {
+		suite.Require().NoError(suite.db.Omit("User", "Club").Create(m).Error)
+	}
+
+	ids, err := suite.reportRepo.ListModeratedClubIDs(7)
+	suite.Require().NoError(err)
+	assert.ElementsMatch(suite.T(), []uint{suite.club.ID, owned.ID}, ids)
+
+	ids, err = suite.reportRepo.ListModeratedClubIDs(8)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), ids, "pending memberships do not moderate")
+}
+
+func (suite *ReportRepositoryTestSuite) TestNotifications() {
+	suite.Require().NoError(suite.notificationRepo.Create([]models.Notification{
+		{UserID: 1, Type: models.NotificationReportResolved, Message: "first"},
+		{UserID: 1, Type: models.NotificationReportResolved, Message: "second"},
+		{UserID: 2, Type: models.NotificationModerationWarn, Message: "theirs"},
+	}))
+
+	page, err := suite.notificationRepo.List(1, false, pagination.First(10))
+	suite.Require().NoError(err)
+	suite.Require().Len(page.Items, 2)
+	assert.Equal(suite.T(), "second", page.Items[0].Message, "newest first")
+
+	marked, err := suite.notificationRepo.MarkRead(1, []uint{page.Items[1].ID}, time.Now())
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(1), marked)
+
+	unread, err := suite.notificationRepo.CountUnread(1)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(1), unread)
+
+	page, err = suite.notificationRepo.List(1, true, pagination.First(10))
+	suite.Require().NoError(err)
+	suite.Require().Len(page.Items, 1)
+	assert.Equal(suite.T(), "second", page.Items[0].Message)
+
+	marked, err = suite.notificationRepo.MarkRead(1, nil, time.Now())
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(1), marked)
+
+	unread, err = suite.notificationRepo.CountUnread(2)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(1), unread, "other users' notifications are untouched")
+}
+
+func TestReportRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ReportRepositoryTestSuite))
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"gorm.io/gorm"
+)
+
+type reshareRepository struct {
+	db *gorm.DB
+}
+
+func NewReshareRepository(db *gorm.DB) *reshareRepository {
+	return &reshareRepository{db: db}
+}
+
+// GetOriginals loads the published posts among ids, with their author and
+// club, for reshare previews. Deleted, hidden and unpublished posts are left
+// out.
+func (r *reshareRepository) GetOriginals(ids []uint) ([]models.Post, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var posts []models.Post
+	err := r.db.
+		Preload("User").
+		Preload("Club").
+		Where("id IN ? AND status = ?", ids, models.PostStatusPublished).
+		Find(&posts).Error
+	return posts, err
+}
+
+// ListReadableClubIDs returns the clubs among clubIDs whose posts userID may
+// read; userID 0 reads public clubs only.
+func (r *reshareRepository) ListReadableClubIDs(userID uint, clubIDs []uint) (map[uint]bool, error) {
+	return listReadableClubIDs(r.db, userID, clubIDs)
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ReshareRepositoryTestSuite struct {
+	suite.Suite
+	db          *gorm.DB
+	reshareRepo ReshareRepository
+	postRepo    PostRepository
+	user        *models.User
+	club        *models.Club
+}
+
+func (suite *ReshareRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.reshareRepo = NewReshareRepository(suite.db)
+	suite.postRepo = NewPostRepository(suite.db)
+
+	suite.user = &models.User{Username: "reader", Email: "reader@example.com", PasswordHash: "x"}
+	suite.Require().NoError(suite.db.Create(suite.user).Error)
+	suite.club = &models.Club{Name: "Public"}
+	suite.Require().NoError(suite.db.Omit("Owner", "Members", "Tags").Create(suite.club).Error)
+}
+
+func (suite *ReshareRepositoryTestSuite) post(status string, sharedPostID *uint) *models.Post {
+	now := time.Now()
+	post := &models.Post{Title: "Dune", Content: "text", Type: "discussion", UserID: suite.user.ID, ClubID: suite.club.ID, Status: status, PublishedAt: &now, SharedPostID: sharedPostID}
+	if sharedPostID != nil {
+		post.Type = "post"
+	}
+	suite.Require().NoError(suite.postRepo.Create(post))
+	return post
+}
+
+func (suite *ReshareRepositoryTestSuite) shareCount(id uint) int {
+	var post models.Post
+	suite.Require().NoError(suite.db.Unscoped().First(&post, id).Error)
+	return post.ShareCount
+}
+
+func (suite *ReshareRepositoryTestSuite) TestReshareCountsOnOriginal() {
+	original := suite.post(models.PostStatusPublished, nil)
+	first := suite.post(models.PostStatusPublished, &original.ID)
+	second := suite.post(models.PostStatusPublished, &original.ID)
+	assert.Equal(suite.T(), 2, suite.shareCount(original.ID))
+
+	suite.Require().NoError(suite.postRepo.Delete(first.ID))
+	assert.Equal(suite.T(), 1, suite.shareCount(original.ID))
+
+	suite.Require().NoError(suite.postRepo.Delete(original.ID))
+	var kept models.Post
+	suite.Require().NoError(suite.db.First(&kept, second.ID).Error)
+	assert.Equal(suite.T(), original.ID, *kept.SharedPostID, "reshares keep pointing at a deleted original")
+}
+
+func (suite *ReshareRepositoryTestSuite) TestGetOriginalsSkipsUnpublished() {
+	published := suite.post(models.PostStatusPublished, nil)
+	hidden := suite.post(models.PostStatusHidden, nil)
+	draft := suite.post(models.PostStatusDraft, nil)
+	deleted := suite.post(models.PostStatusPublished, nil)
+	suite.Require().NoError(suite.postRepo.Delete(deleted.ID))
+
+	originals, err := suite.reshareRepo.GetOriginals([]uint{published.ID, hidden.ID, draft.ID, deleted.ID})
+	suite.Require().NoError(err)
+	suite.Require().Len(originals, 1)
+	assert.Equal(suite.T(), published.ID, originals[0].ID)
+	assert.Equal(suite.T(), suite.club.Name, originals[0].Club.Name)
+	assert.Equal(suite.T(), suite.user.Username, originals[0].User.Username)
+}
+
+func (suite *ReshareRepositoryTestSuite) TestAnonymousViewersReadPublicClubs() {
+	private := &models.Club{Name: "Private", IsPrivate: true}
+	suite.Require().NoError(suite.db.Omit("Owner", "Members", "Tags").Create(private).Error)
+	suite.Require().NoError(suite.db.Omit("User", "Club").Create(&models.ClubMembership{UserID: suite.user.ID, ClubID: private.ID, IsApproved: true}).Error)
+
+	readable, err := suite.reshareRepo.ListReadableClubIDs(0, []uint{suite.club.ID, private.ID})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), map[uint]bool{suite.club.ID: true}, readable)
+
+	readable, err = suite.reshareRepo.ListReadableClubIDs(suite.user.ID, []uint{suite.club.ID, private.ID})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), map[uint]bool{suite.club.ID: true, private.ID: true}, readable)
+}
+
+func TestReshareRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ReshareRepositoryTestSuite))
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"gorm.io/gorm"
+)
+
+type revisionRepository struct {
+	db *gorm.DB
+}
+
+func NewRevisionRepository(db *gorm.DB) *revisionRepository {
+	return &revisionRepository{db: db}
+}
+
+func (r *revisionRepository) ListByTarget(targetType string, targetID uint) ([]models.Revision, error) {
+	var revisions []models.Revision
+	if err := r.db.
+		Preload("Editor").
+		Where("target_type = ? AND target_id = ?", targetType, targetID).
+		Order("version ASC").
+		Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// recordRevision stores an edit and returns the new edit count. The first
+// edit also stores the original version so the history is complete.
+func recordRevision(tx *gorm.DB, original, edited *models.Revision) (int, error) {
+	var latest int
+	if err := tx.Model(&models.Revision{}).
+		Select("COALESCE(MAX(version), 0)").
+		Where("target_type = ? AND target_id = ?", edited.TargetType, edited.TargetID).
+		Scan(&latest).Error; err != nil {
+		return 0, err
+	}
+
+	if latest == 0 {
+		original.Version = 1
+		if err := tx.Create(original).Error; err != nil {
+			return 0, err
+		}
+		latest = 1
+	}
+
+	edited.Version = latest + 1
+	if err := tx.Create(edited).Error; err != nil {
+		return 0, err
+	}
+	return edited.Version - 1, nil
+}
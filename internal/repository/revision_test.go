@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type RevisionRepositoryTestSuite struct {
+	suite.Suite
+	db           *gorm.DB
+	revisionRepo RevisionRepository
+	postRepo     *postRepository
+	commentRepo  *commentRepository
+	post         *models.Post
+}
+
+func (suite *RevisionRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.revisionRepo = NewRevisionRepository(suite.db)
+	suite.postRepo = NewPostRepository(suite.db)
+	suite.commentRepo = NewCommentRepository(suite.db)
+
+	suite.post = &models.Post{Title: "Dune", Content: "first line", Type: "discussion", UserID: 1, ClubID: 1}
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(suite.post).Error)
+}
+
+func (suite *RevisionRepositoryTestSuite) TestPostUpdate_RecordsOriginalAndEdits() {
+	suite.post.Content = "second line"
+	suite.Require().NoError(suite.postRepo.UpdateWithRevision(suite.post, 2))
+	suite.post.Title = "Dune Messiah"
+	suite.Require().NoError(suite.postRepo.UpdateWithRevision(suite.post, 1))
+
+	revisions, err := suite.revisionRepo.ListByTarget(models.RevisionTargetPost, suite.post.ID)
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(revisions, 3)
+	assert.Equal(suite.T(), "first line", revisions[0].Content)
+	assert.Equal(suite.T(), uint(1), revisions[0].EditorID)
+	assert.Equal(suite.T(), uint(2), revisions[1].EditorID)
+	assert.Equal(suite.T(), "Dune Messiah", revisions[2].Title)
+
+	var stored models.Post
+	suite.Require().NoError(suite.db.First(&stored, suite.post.ID).Error)
+	assert.Equal(suite.T(), 2, stored.EditCount)
+	assert.NotNil(suite.T(), stored.EditedAt)
+}
+
+func (suite *RevisionRepositoryTestSuite) TestPostUpdate_SkipsUnchangedContent() {
+	suite.post.IsPinned = true
+	suite.Require().NoError(suite.postRepo.UpdateWithRevision(suite.post, 1))
+
+	revisions, err := suite.revisionRepo.ListByTarget(models.RevisionTargetPost, suite.post.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), revisions)
+	assert.Equal(suite.T(), 0, suite.post.EditCount)
+}
+
+func (suite *RevisionRepositoryTestSuite) TestCommentUpdate_RecordsRevision() {
+	comment := &models.Comment{PostID: suite.post.ID, UserID: 3, Content: "nice"}
+	suite.Require().NoError(suite.db.Omit("User").Create(comment).Error)
+
+	comment.Content = "nice, but the ending drags"
+	suite.Require().NoError(suite.commentRepo.UpdateWithRevision(comment, 3))
+
+	revisions, err := suite.revisionRepo.ListByTarget(models.RevisionTargetComment, comment.ID)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), revisions, 2)
+	assert.Equal(suite.T(), 1, comment.EditCount)
+}
+
+func TestRevisionRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(RevisionRepositoryTestSuite))
+}
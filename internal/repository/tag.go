@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"github.com/lib/pq"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"gorm.io/gorm"
+)
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *tagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) Create(tag *models.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+func (r *tagRepository) GetByID(id uint) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.db.Preload("Aliases").First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// FindByKey returns the tag whose own key or one of whose aliases matches key.
+func (r *tagRepository) FindByKey(key string) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.Preload("Aliases").
+		Where("key = ? OR id IN (SELECT tag_id FROM tag_aliases WHERE key = ?)", key, key).
+		First(&tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) AddAlias(alias *models.TagAlias) error {
+	return r.db.Create(alias).Error
+}
+
+// Search returns tags whose key or alias keys contain the normalized query.
+func (r *tagRepository) Search(key string, limit int) ([]models.Tag, error) {
+	var tags []models.Tag
+	q := r.db.Preload("Aliases").Order("name ASC").Limit(limit)
+	if key != "" {
+		like := "%" + key + "%"
+		q = q.Where("key LIKE ? OR id IN (SELECT tag_id FROM tag_aliases WHERE key LIKE ?)", like, like)
+	}
+	err := q.Find(&tags).Error
+	return tags, err
+}
+
+// UsageCounts returns how many clubs use each of the given tag names.
+func (r *tagRepository) UsageCounts(names []string) (map[string]int, error) {
+	counts := make(map[string]int, len(names))
+	if len(names) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		Tag   string
+		Count int
+	}
+	err := r.db.Raw(`
+		SELECT t.tag, COUNT(*) AS count
+		FROM clubs c, unnest(c.tags) AS t(tag)
+		WHERE c.deleted_at IS NULL AND t.tag = ANY(?)
+		GROUP BY t.tag`, pq.StringArray(names)).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.Tag] = row.Count
+	}
+	return counts, nil
+}
+
+// Merge folds the source tags into target: their aliases move over, their
+// names become aliases of target and the source tags are deleted. In the same
+// transaction it applies rewrite to the tags of every club that has any, and
+// returns the number of clubs updated.
+func (r *tagRepository) Merge(target *models.Tag, sources []models.Tag, rewrite func(tags pq.StringArray) (pq.StringArray, bool)) (int64, error) {
+	var updated int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := mergeTags(tx, target, sources); err != nil {
+			return err
+		}
+		var err error
+		updated, err = rewriteClubTags(tx, rewrite)
+		return err
+	})
+	return updated, err
+}
+
+func mergeTags(tx *gorm.DB, target *models.Tag, sources []models.Tag) error {
+	sourceIDs := make([]uint, 0, len(sources))
+	for _, s := range sources {
+		sourceIDs = append(sourceIDs, s.ID)
+	}
+
+	if err := tx.Model(&models.TagAlias{}).
+		Where("tag_id IN ?", sourceIDs).
+		Update("tag_id", target.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(&models.Tag{}, sourceIDs).Error; err != nil {
+		return err
+	}
+	for _, s := range sources {
+		alias := &models.TagAlias{TagID: target.ID, Alias: s.Name, Key: s.Key}
+		if err := tx.Create(alias).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteClubTags applies rewrite to the tags of every club that has any and
+// saves the ones it reports as changed. It returns the number of clubs updated.
+func rewriteClubTags(tx *gorm.DB, rewrite func(tags pq.StringArray) (pq.StringArray, bool)) (int64, error) {
+	var updated int64
+	var clubs []models.Club
+	err := tx.Model(&models.Club{}).
+		Select("id", "tags").
+		Where("tags IS NOT NULL").
+		FindInBatches(&clubs, 200, func(batchTx *gorm.DB, batch int) error {
+			for _, club := range clubs {
+				tags, changed := rewrite(club.Tags)
+				if !changed {
+					continue
+				}
+				if err := tx.Model(&models.Club{}).
+					Where("id = ?", club.ID).
+					UpdateColumn("tags", tags).Error; err != nil {
+					return err
+				}
+				updated++
+			}
+			return nil
+		}).Error
+	return updated, err
+}
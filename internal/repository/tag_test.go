@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TagRepositoryTestSuite struct {
+	suite.Suite
+	db      *gorm.DB
+	tagRepo TagRepository
+	target  *models.Tag
+	source  *models.Tag
+	club    *models.Club
+}
+
+func (suite *TagRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.tagRepo = NewTagRepository(suite.db)
+
+	suite.target = &models.Tag{Name: "Science Fiction", Key: "sciencefiction"}
+	suite.source = &models.Tag{Name: "Sci-Fi", Key: "scifi"}
+	suite.Require().NoError(suite.db.Create(suite.target).Error)
+	suite.Require().NoError(suite.db.Create(suite.source).Error)
+	suite.club = &models.Club{Name: "Readers", Tags: pq.StringArray{"Sci-Fi", "Classics"}}
+	suite.Require().NoError(suite.db.Omit("Owner", "Members").Create(suite.club).Error)
+}
+
+func (suite *TagRepositoryTestSuite) rename(tags pq.StringArray) (pq.StringArray, bool) {
+	out := make(pq.StringArray, len(tags))
+	for i, t := range tags {
+		out[i] = t
+		if t == suite.source.Name {
+			out[i] = suite.target.Name
+		}
+	}
+	return out, true
+}
+
+func (suite *TagRepositoryTestSuite) TestMergeRewritesClubTags() {
+	updated, err := suite.tagRepo.Merge(suite.target, []models.Tag{*suite.source}, suite.rename)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(1), updated)
+
+	var club models.Club
+	suite.Require().NoError(suite.db.Select("id", "tags").First(&club, suite.club.ID).Error)
+	assert.Equal(suite.T(), pq.StringArray{"Science Fiction", "Classics"}, club.Tags)
+
+	target, err := suite.tagRepo.GetByID(suite.target.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(target.Aliases, 1)
+	assert.Equal(suite.T(), "scifi", target.Aliases[0].Key)
+}
+
+func (suite *TagRepositoryTestSuite) TestFailedRewriteKeepsSourceTags() {
+	// the club update fails after the tags were merged
+	suite.Require().NoError(suite.db.Exec("CREATE TRIGGER reject_tags BEFORE UPDATE OF tags ON clubs BEGIN SELECT RAISE(ABORT, 'rejected'); END").Error)
+
+	_, err := suite.tagRepo.Merge(suite.target, []models.Tag{*suite.source}, suite.rename)
+	suite.Require().Error(err)
+
+	_, err = suite.tagRepo.GetByID(suite.source.ID)
+	assert.NoError(suite.T(), err, "the source tag survives a failed merge")
+	var aliases int64
+	suite.Require().NoError(suite.db.Model(&models.TagAlias{}).Count(&aliases).Error)
+	assert.Zero(suite.T(), aliases)
+}
+
+func TestTagRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(TagRepositoryTestSuite))
+}
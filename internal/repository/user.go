@@ -2,6 +2,7 @@ package repository
 
 import (
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
 )
 
@@ -70,13 +71,11 @@ func (r *userRepository) Delete(id uint) error {
 	return r.db.Delete(&models.User{}, id).Error
 }
 
-func (r *userRepository) List(limit, offset int) ([]*models.User, error) {
-	var users []*models.User
-	err := r.db.Limit(limit).Offset(offset).Find(&users).Error
-	if err != nil {
-		return nil, err
-	}
-	return users, nil
+// List returns users in sign-up order.
+func (r *userRepository) List(page pagination.Params) (pagination.Page[*models.User], error) {
+	return pagination.Find(r.db, pagination.ByID("users.id", false), page, func(u *models.User) pagination.Cursor {
+		return pagination.CursorFor(u.ID)
+	})
 }
 
 func (r *userRepository) SearchByUsernameOrName(query string, limit int) ([]*models.User, error) {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/lib/pq"
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -38,8 +39,11 @@ type userCacheEntry struct {
 	LastName       string         `json:"last_name"`
 	IsActive       bool           `json:"is_active"`
 	Role           string         `json:"role"`
+	SuspendedUntil *time.Time     `json:"suspended_until"`
 	AvatarURL      *string        `json:"avatar_url"`
 	Location       *string        `json:"location"`
+	CityID         *string        `json:"city_id"`
+	DistrictID     *string        `json:"district_id"`
 	FavoriteGenres pq.StringArray `json:"favorite_genres"`
 	Bio            *string        `json:"bio"`
 	ReadingGoal    *int           `json:"reading_goal"`
@@ -61,8 +65,11 @@ func toCache(user *models.User) *userCacheEntry {
 		LastName:       user.LastName,
 		IsActive:       user.IsActive,
 		Role:           user.Role,
+		SuspendedUntil: user.SuspendedUntil,
 		AvatarURL:      user.AvatarURL,
 		Location:       user.Location,
+		CityID:         user.CityID,
+		DistrictID:     user.DistrictID,
 		FavoriteGenres: user.FavoriteGenres,
 		Bio:            user.Bio,
 		ReadingGoal:    &user.ReadingGoal,
@@ -85,8 +92,11 @@ func toModel(entry *userCacheEntry) *models.User {
 		LastName:       entry.LastName,
 		IsActive:       entry.IsActive,
 		Role:           entry.Role,
+		SuspendedUntil: entry.SuspendedUntil,
 		AvatarURL:      entry.AvatarURL,
 		Location:       entry.Location,
+		CityID:         entry.CityID,
+		DistrictID:     entry.DistrictID,
 		FavoriteGenres: entry.FavoriteGenres,
 		Bio:            entry.Bio,
 		ReadingGoal:    func() int { if entry.ReadingGoal != nil { return *entry.ReadingGoal }; return 0 }(),
@@ -237,8 +247,8 @@ func (r *cachedUserRepository) Delete(id uint) error {
 	return nil
 }
 
-func (r *cachedUserRepository) List(limit, offset int) ([]*models.User, error) {
-	return r.base.List(limit, offset)
+func (r *cachedUserRepository) List(page pagination.Params) (pagination.Page[*models.User], error) {
+	return r.base.List(page)
 }
 
 func (r *cachedUserRepository) SearchByUsernameOrName(query string, limit int) ([]*models.User, error) {
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type viewRepository struct {
+	db *gorm.DB
+}
+
+func NewViewRepository(db *gorm.DB) *viewRepository {
+	return &viewRepository{db: db}
+}
+
+// ApplyDeltas adds buffered counts to the daily stats and to posts.views_count
+// in one transaction. Counts for posts that no longer exist are dropped.
+func (r *viewRepository) ApplyDeltas(deltas []models.PostViewDelta) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(deltas))
+	for _, d := range deltas {
+		ids = append(ids, d.PostID)
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing []uint
+		if err := tx.Unscoped().Model(&models.Post{}).Where("id IN ?", ids).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		known := make(map[uint]bool, len(existing))
+		for _, id := range existing {
+			known[id] = true
+		}
+
+		views := map[uint]int{}
+		for _, d := range deltas {
+			if !known[d.PostID] {
+				continue
+			}
+			stat := models.PostViewStat{PostID: d.PostID, Day: d.Day, Views: d.Views, Impressions: d.Impressions}
+			if err := tx.Omit("Post").Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "post_id"}, {Name: "day"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"views":       gorm.Expr("post_view_stats.views + ?", d.Views),
+					"impressions": gorm.Expr("post_view_stats.impressions + ?", d.Impressions),
+				}),
+			}).Create(&stat).Error; err != nil {
+				return err
+			}
+			views[d.PostID] += d.Views
+		}
+
+		for postID, n := range views {
+			if n == 0 {
+				continue
+			}
+			if err := tx.Unscoped().Model(&models.Post{}).Where("id = ?", postID).
+				UpdateColumn("views_count", gorm.Expr("views_count + ?", n)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListDaily returns the post's stats for days in [from, to), oldest first.
+// Days without views are missing.
+func (r *viewRepository) ListDaily(postID uint, from, to time.Time) ([]models.PostViewStat, error) {
+	var stats []models.PostViewStat
+	err := r.db.Where("post_id = ? AND day >= ? AND day < ?", postID, from, to).
+		Order("day").
+		Find(&stats).Error
+	return stats, err
+}
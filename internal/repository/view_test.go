@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ViewRepositoryTestSuite struct {
+	suite.Suite
+	db       *gorm.DB
+	viewRepo ViewRepository
+	post     *models.Post
+}
+
+func (suite *ViewRepositoryTestSuite) SetupTest() {
+	var err error
+
+	suite.db, err = test_helpers.SetupTestDB()
+	suite.Require().NoError(err)
+
+	suite.viewRepo = NewViewRepository(suite.db)
+
+	suite.post = &models.Post{Title: "Dune", Content: "text", Type: "discussion", UserID: 1, ClubID: 1}
+	suite.Require().NoError(suite.db.Omit("Club", "User").Create(suite.post).Error)
+}
+
+func (suite *ViewRepositoryTestSuite) TestApplyDeltasAccumulates() {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	next := day.AddDate(0, 0, 1)
+
+	suite.Require().NoError(suite.viewRepo.ApplyDeltas([]models.PostViewDelta{
+		{PostID: suite.post.ID, Day: day, Views: 3, Impressions: 10},
+		{PostID: 999, Day: day, Views: 5},
+	}))
+	suite.Require().NoError(suite.viewRepo.ApplyDeltas([]models.PostViewDelta{
+		{PostID: suite.post.ID, Day: day, Views: 2},
+		{PostID: suite.post.ID, Day: next, Impressions: 4},
+	}))
+
+	stats, err := suite.viewRepo.ListDaily(suite.post.ID, day, next.AddDate(0, 0, 1))
+	suite.Require().NoError(err)
+	suite.Require().Len(stats, 2)
+	assert.Equal(suite.T(), 5, stats[0].Views)
+	assert.Equal(suite.T(), 10, stats[0].Impressions)
+	assert.Equal(suite.T(), 0, stats[1].Views)
+	assert.Equal(suite.T(), 4, stats[1].Impressions)
+
+	var stored models.Post
+	suite.Require().NoError(suite.db.First(&stored, suite.post.ID).Error)
+	assert.Equal(suite.T(), 5, stored.ViewsCount, "only views, not impressions, count towards views_count")
+
+	var count int64
+	suite.Require().NoError(suite.db.Model(&models.PostViewStat{}).Where("post_id = ?", 999).Count(&count).Error)
+	assert.Zero(suite.T(), count, "counts for unknown posts are dropped")
+}
+
+func TestViewRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ViewRepositoryTestSuite))
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	analyticsDefaultRange = 30 * 24 * time.Hour
+	analyticsMaxRange     = 366 * 24 * time.Hour
+	analyticsTopMembers   = 10
+	analyticsPastCacheTTL = 24 * time.Hour
+)
+
+var ErrInvalidAnalyticsRange = errors.New("invalid date range")
+
+type AnalyticsService struct {
+	analyticsRepo repository.AnalyticsRepository
+	clubRepo      repository.ClubRepository
+	rdb           *redis.Client
+	config        *config.Config
+}
+
+// NewAnalyticsService wires the analytics service. rdb may be nil, in which
+// case every request is computed from the database.
+func NewAnalyticsService(analyticsRepo repository.AnalyticsRepository, clubRepo repository.ClubRepository, rdb *redis.Client, config *config.Config) *AnalyticsService {
+	return &AnalyticsService{
+		analyticsRepo: analyticsRepo,
+		clubRepo:      clubRepo,
+		rdb:           rdb,
+		config:        config,
+	}
+}
+
+func (s *AnalyticsService) GetClubAnalytics(clubID uint, req *models.ClubAnalyticsRequest) (*models.ClubAnalyticsResponse, error) {
+	club, err := s.clubRepo.GetByID(clubID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClubNotFound
+		}
+		return nil, err
+	}
+
+	from, to, err := analyticsRange(req.From, req.To, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	granularity := req.Granularity
+	if granularity == "" {
+		granularity = models.GranularityDay
+	}
+	topLimit := req.TopLimit
+	if topLimit <= 0 {
+		topLimit = analyticsTopMembers
+	}
+
+	// Periods that ended before today can no longer change, so they are safe to cache.
+	cacheable := s.rdb != nil && !to.After(startOfDay(time.Now().UTC()))
+	cacheKey := fmt.Sprintf("club_analytics:%d:%s:%s:%s:%d", clubID, from.Format("2006-01-02"), to.Format("2006-01-02"), granularity, topLimit)
+
+	if cacheable {
+		if cached, err := s.rdb.Get(context.Background(), cacheKey).Bytes(); err == nil {
+			var resp models.ClubAnalyticsResponse
+			if json.Unmarshal(cached, &resp) == nil {
+				return &resp, nil
+			}
+		}
+	}
+
+	resp, err := s.computeClubAnalytics(club, from, to, granularity, topLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheable {
+		if b, err := json.Marshal(resp); err == nil {
+			if err := s.rdb.Set(context.Background(), cacheKey, b, analyticsPastCacheTTL).Err(); err != nil {
+				logger.Warn("failed to cache club analytics", zap.Uint("club_id", clubID), zap.Error(err))
+			}
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *AnalyticsService) computeClubAnalytics(club *models.Club, from, to time.Time, granularity string, topLimit int) (*models.ClubAnalyticsResponse, error) {
+	resp := &models.ClubAnalyticsResponse{
+		ClubID:      club.ID,
+		From:        from,
+		To:          to.AddDate(0, 0, -1),
+		Granularity: granularity,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	joined, err := s.analyticsRepo.MembershipSeries(club.ID, models.MembershipActionJoined, from, to, granularity)
+	if err != nil {
+		return nil, err
+	}
+	left, err := s.analyticsRepo.MembershipSeries(club.ID, models.MembershipActionLeft, from, to, granularity)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.analyticsRepo.PostSeries(club.ID, from, to, granularity)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.analyticsRepo.CommentSeries(club.ID, from, to, granularity)
+	if err != nil {
+		return nil, err
+	}
+	likes, err := s.analyticsRepo.LikeSeries(club.ID, from, to, granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.NewMembers = fillSeries(joined, from, to, granularity)
+	resp.LeftMembers = fillSeries(left, from, to, granularity)
+	resp.Posts = fillSeries(posts, from, to, granularity)
+	resp.Comments = fillSeries(comments, from, to, granularity)
+	resp.Likes = fillSeries(likes, from, to, granularity)
+
+	events, err := s.analyticsRepo.EventParticipation(club.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var responded, going, attended int
+	for i := range events {
+		e := &events[i]
+		replies := e.Going + e.Maybe + e.NotGoing
+		e.RSVPRate = ratio(replies, club.MembersCount)
+		e.AttendanceRate = ratio(e.Attended, e.Going)
+		responded += replies
+		going += e.Going
+		attended += e.Attended
+	}
+	resp.Events = events
+	resp.RSVPRate = ratio(responded, club.MembersCount*len(events))
+	resp.AttendanceRate = ratio(attended, going)
+
+	assignments, err := s.analyticsRepo.AssignmentCompletion(club.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var members, finished int
+	for i := range assignments {
+		a := &assignments[i]
+		a.CompletionRate = ratio(a.Finished, a.Members)
+		members += a.Members
+		finished += a.Finished
+	}
+	resp.Assignments = assignments
+	resp.AssignmentCompletionRate = ratio(finished, members)
+
+	top, err := s.analyticsRepo.TopMembers(club.ID, from, to, topLimit)
+	if err != nil {
+		return nil, err
+	}
+	resp.TopMembers = top
+
+	return resp, nil
+}
+
+// analyticsRange parses the inclusive YYYY-MM-DD bounds of a request and
+// returns a half-open [from, to) range in UTC.
+func analyticsRange(fromStr, toStr string, now time.Time) (time.Time, time.Time, error) {
+	to := startOfDay(now).AddDate(0, 0, 1)
+	if toStr != "" {
+		t, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidAnalyticsRange
+		}
+		to = t.AddDate(0, 0, 1)
+	}
+
+	from := to.Add(-analyticsDefaultRange)
+	if fromStr != "" {
+		f, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidAnalyticsRange
+		}
+		from = f
+	}
+
+	if !from.Before(to) || to.Sub(from) > analyticsMaxRange {
+		return time.Time{}, time.Time{}, ErrInvalidAnalyticsRange
+	}
+	return from, to, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// truncateBucket mirrors Postgres date_trunc for the supported granularities;
+// weeks start on Monday.
+func truncateBucket(t time.Time, granularity string) time.Time {
+	d := startOfDay(t)
+	switch granularity {
+	case models.GranularityWeek:
+		offset := (int(d.Weekday()) + 6) % 7
+		return d.AddDate(0, 0, -offset)
+	case models.GranularityMonth:
+		return time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return d
+	}
+}
+
+func nextBucket(t time.Time, granularity string) time.Time {
+	switch granularity {
+	case models.GranularityWeek:
+		return t.AddDate(0, 0, 7)
+	case models.GranularityMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// fillSeries returns one point per bucket in [from, to), using zero for
+// buckets the database did not return.
+func fillSeries(points []models.AnalyticsPoint, from, to time.Time, granularity string) []models.AnalyticsPoint {
+	byBucket := make(map[time.Time]int, len(points))
+	for _, p := range points {
+		byBucket[truncateBucket(p.Bucket, granularity)] += p.Value
+	}
+
+	var out []models.AnalyticsPoint
+	for b := truncateBucket(from, granularity); b.Before(to); b = nextBucket(b, granularity) {
+		out = append(out, models.AnalyticsPoint{Bucket: b, Value: byBucket[b]})
+	}
+	return out
+}
+
+func ratio(part, whole int) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
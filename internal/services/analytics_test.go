@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFillSeries(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	t.Run("fills missing days with zero", func(t *testing.T) {
+		points := []models.AnalyticsPoint{
+			{Bucket: time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), Value: 5},
+		}
+
+		out := fillSeries(points, from, to, models.GranularityDay)
+
+		assert.Len(t, out, 3)
+		assert.Equal(t, 0, out[0].Value)
+		assert.Equal(t, 5, out[1].Value)
+		assert.Equal(t, 0, out[2].Value)
+	})
+
+	t.Run("weeks start on monday", func(t *testing.T) {
+		// 2025-03-01 is a Saturday
+		out := fillSeries(nil, from, to, models.GranularityWeek)
+
+		assert.Len(t, out, 2)
+		assert.Equal(t, time.Monday, out[0].Bucket.Weekday())
+		assert.Equal(t, time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC), out[0].Bucket)
+	})
+}
+
+func TestAnalyticsRange(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	t.Run("defaults to the last 30 days", func(t *testing.T) {
+		from, to, err := analyticsRange("", "", now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC), to)
+		assert.Equal(t, 30*24*time.Hour, to.Sub(from))
+	})
+
+	t.Run("to is inclusive", func(t *testing.T) {
+		_, to, err := analyticsRange("2025-01-01", "2025-01-31", now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), to)
+	})
+
+	t.Run("rejects inverted and oversized ranges", func(t *testing.T) {
+		_, _, err := analyticsRange("2025-02-01", "2025-01-01", now)
+		assert.ErrorIs(t, err, ErrInvalidAnalyticsRange)
+
+		_, _, err = analyticsRange("2023-01-01", "2025-01-01", now)
+		assert.ErrorIs(t, err, ErrInvalidAnalyticsRange)
+	})
+}
+
+func TestTruncateBucket(t *testing.T) {
+	// a Wednesday evening
+	at := time.Date(2025, 4, 30, 20, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC), truncateBucket(at, models.GranularityDay))
+	assert.Equal(t, time.Date(2025, 4, 28, 0, 0, 0, 0, time.UTC), truncateBucket(at, models.GranularityWeek))
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), truncateBucket(at, models.GranularityMonth))
+
+	sunday := time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC), truncateBucket(sunday, models.GranularityWeek),
+		"Sundays belong to the week that started the Monday before")
+}
+
+func TestFillSeriesMonths(t *testing.T) {
+	from := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	points := []models.AnalyticsPoint{
+		{Bucket: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), Value: 3},
+		{Bucket: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Value: 2},
+	}
+
+	out := fillSeries(points, from, to, models.GranularityMonth)
+
+	assert.Len(t, out, 3)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), out[0].Bucket)
+	assert.Equal(t, []int{0, 3, 2}, []int{out[0].Value, out[1].Value, out[2].Value})
+}
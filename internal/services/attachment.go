@@ -0,0 +1,481 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"github.com/nevzattalhaozcan/forgotten/pkg/storage"
+	"github.com/nevzattalhaozcan/forgotten/pkg/thumbnail"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAttachmentTooLarge       = errors.New("file is too large")
+	ErrAttachmentTypeNotAllowed = errors.New("file type is not allowed in this club")
+	ErrAttachmentQuotaExceeded  = errors.New("club has no storage left for this file")
+	ErrTooManyAttachments       = errors.New("too many attachments")
+	ErrInvalidAttachment        = errors.New("attachments must be your own uploads to this club, not used elsewhere")
+	ErrAttachmentNotFound       = errors.New("attachment not found")
+	ErrUnknownAttachmentType    = errors.New("allowed types must come from the platform's allowed types")
+	ErrAttachmentQuotaAdminOnly = errors.New("only platform admins can change a club's quota")
+)
+
+// orphanBatchSize caps how many orphaned uploads one CleanupOrphans call
+// deletes; the rest are picked up on the next run.
+const orphanBatchSize = 100
+
+const megabyte = 1 << 20
+
+// extensions are the file name extensions stored files get, so they are
+// served with the type they were checked as.
+var extensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// AttachmentService stores files uploaded to clubs and attaches them to posts
+// and comments. Files are uploaded first and attached when the post or
+// comment is saved; uploads that never are get deleted by CleanupOrphans.
+type AttachmentService struct {
+	attachmentRepo repository.AttachmentRepository
+	storage        storage.Storage
+	config         *config.Config
+}
+
+func NewAttachmentService(attachmentRepo repository.AttachmentRepository, storage storage.Storage, config *config.Config) *AttachmentService {
+	return &AttachmentService{
+		attachmentRepo: attachmentRepo,
+		storage:        storage,
+		config:         config,
+	}
+}
+
+// MaxFileBytes is the largest file Upload accepts.
+func (s *AttachmentService) MaxFileBytes() int64 {
+	return int64(s.config.Attachments.MaxFileMB) * megabyte
+}
+
+// Upload stores a file for the uploader's next post or comment in the club.
+// The type is taken from the file contents; images get a thumbnail when the
+// server can decode them.
+func (s *AttachmentService) Upload(ctx context.Context, uploaderID, clubID uint, fileName string, body io.Reader) (*models.AttachmentResponse, error) {
+	maxBytes := s.MaxFileBytes()
+	data, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	settings, err := s.clubSettings(clubID)
+	if err != nil {
+		return nil, err
+	}
+	contentType := sniffContentType(data)
+	if !slices.Contains(settings.AllowedTypes, contentType) {
+		return nil, ErrAttachmentTypeNotAllowed
+	}
+
+	key, err := newStorageKey(clubID)
+	if err != nil {
+		return nil, err
+	}
+	attachment := &models.Attachment{
+		ClubID:      clubID,
+		UploaderID:  uploaderID,
+		Kind:        models.AttachmentKindFile,
+		FileName:    cleanFileName(fileName),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		StorageKey:  key + extensions[contentType],
+	}
+
+	var thumb *thumbnail.Thumbnail
+	if strings.HasPrefix(contentType, "image/") {
+		attachment.Kind = models.AttachmentKindImage
+		if thumb, err = thumbnail.Make(data, s.config.Attachments.ThumbnailSize); err != nil {
+			logger.Info("uploaded image has no thumbnail", zap.Uint("club_id", clubID), zap.String("content_type", contentType), zap.Error(err))
+			thumb = nil
+		} else {
+			attachment.Width, attachment.Height = thumb.Width, thumb.Height
+			attachment.ThumbnailSize = int64(len(thumb.Data))
+		}
+	}
+
+	used, err := s.attachmentRepo.ClubUsage(clubID)
+	if err != nil {
+		return nil, err
+	}
+	if used+attachment.StoredSize() > settings.QuotaBytes {
+		return nil, ErrAttachmentQuotaExceeded
+	}
+
+	if err := s.storage.Put(ctx, attachment.StorageKey, bytes.NewReader(data), attachment.Size, contentType); err != nil {
+		return nil, err
+	}
+	attachment.URL = s.storage.URL(attachment.StorageKey)
+	if thumb != nil {
+		if thumb.Data == nil {
+			attachment.ThumbnailURL = &attachment.URL
+		} else {
+			thumbKey := key + "_thumb" + extensions[thumb.ContentType]
+			if err := s.storage.Put(ctx, thumbKey, bytes.NewReader(thumb.Data), attachment.ThumbnailSize, thumb.ContentType); err != nil {
+				s.deleteFiles([]models.Attachment{*attachment})
+				return nil, err
+			}
+			thumbURL := s.storage.URL(thumbKey)
+			attachment.ThumbnailKey = &thumbKey
+			attachment.ThumbnailURL = &thumbURL
+		}
+	}
+
+	if err := s.attachmentRepo.Create(attachment); err != nil {
+		s.deleteFiles([]models.Attachment{*attachment})
+		return nil, err
+	}
+	response := attachment.ToResponse()
+	return &response, nil
+}
+
+// DeleteUpload deletes one of the uploader's attachments, taking it off the
+// post or comment it was on.
+func (s *AttachmentService) DeleteUpload(id, uploaderID uint) error {
+	attachment, err := s.attachmentRepo.DeleteUpload(id, uploaderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAttachmentNotFound
+		}
+		return err
+	}
+	s.deleteFiles([]models.Attachment{*attachment})
+	return nil
+}
+
+// CheckForPost reports whether the author can attach ids to a post in the
+// club. postID is 0 for a post not saved yet.
+func (s *AttachmentService) CheckForPost(authorID, clubID, postID uint, ids []uint) error {
+	return s.check(authorID, clubID, ids, func(a *models.Attachment) bool {
+		return a.CommentID == nil && (a.PostID == nil || (postID != 0 && *a.PostID == postID))
+	})
+}
+
+// CheckForComment reports whether the author can attach ids to a new comment
+// on a post in the club.
+func (s *AttachmentService) CheckForComment(authorID, clubID uint, ids []uint) error {
+	return s.check(authorID, clubID, ids, func(a *models.Attachment) bool {
+		return a.PostID == nil && a.CommentID == nil
+	})
+}
+
+func (s *AttachmentService) check(authorID, clubID uint, ids []uint, free func(*models.Attachment) bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if len(ids) > s.config.Attachments.MaxPerPost {
+		return ErrTooManyAttachments
+	}
+	attachments, err := s.attachmentRepo.GetByIDs(ids)
+	if err != nil {
+		return err
+	}
+	return checkAttachable(attachments, ids, authorID, clubID, free)
+}
+
+// checkAttachable makes sure every id is an attachment the author uploaded
+// to the club that free accepts, and that none is given twice.
+func checkAttachable(attachments []models.Attachment, ids []uint, authorID, clubID uint, free func(*models.Attachment) bool) error {
+	byID := make(map[uint]*models.Attachment, len(attachments))
+	for i := range attachments {
+		byID[attachments[i].ID] = &attachments[i]
+	}
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		a, ok := byID[id]
+		if !ok || seen[id] || a.UploaderID != authorID || a.ClubID != clubID || !free(a) {
+			return ErrInvalidAttachment
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// AttachToPost makes ids, in order, the post's attachments. Ones the post no
+// longer has are left for CleanupOrphans. Check the ids with CheckForPost
+// first.
+func (s *AttachmentService) AttachToPost(postID uint, ids []uint) error {
+	ok, err := s.attachmentRepo.SetPostAttachments(postID, ids)
+	if err == nil && !ok {
+		return ErrInvalidAttachment
+	}
+	return err
+}
+
+// AttachToComment is AttachToPost for a comment.
+func (s *AttachmentService) AttachToComment(commentID uint, ids []uint) error {
+	ok, err := s.attachmentRepo.SetCommentAttachments(commentID, ids)
+	if err == nil && !ok {
+		return ErrInvalidAttachment
+	}
+	return err
+}
+
+// DeleteForPost deletes the attachments of a deleted post. Failures are
+// logged; files left behind do not count against the club.
+func (s *AttachmentService) DeleteForPost(postID uint) {
+	attachments, err := s.attachmentRepo.DeleteByPost(postID)
+	if err != nil {
+		logger.Warn("failed to delete post attachments", zap.Uint("post_id", postID), zap.Error(err))
+		return
+	}
+	s.deleteFiles(attachments)
+}
+
+// DeleteForComment deletes the attachments of a deleted comment, logging
+// failures like DeleteForPost.
+func (s *AttachmentService) DeleteForComment(commentID uint) {
+	attachments, err := s.attachmentRepo.DeleteByComment(commentID)
+	if err != nil {
+		logger.Warn("failed to delete comment attachments", zap.Uint("comment_id", commentID), zap.Error(err))
+		return
+	}
+	s.deleteFiles(attachments)
+}
+
+// CleanupOrphans deletes uploads that were never attached, or were detached,
+// and are older than the configured grace period, such as the files of an
+// abandoned draft. It returns how many it deleted.
+func (s *AttachmentService) CleanupOrphans(now time.Time) (int, error) {
+	before := now.Add(-time.Duration(s.config.Attachments.OrphanAfterHours) * time.Hour)
+	orphans, err := s.attachmentRepo.DeleteOrphans(before, orphanBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	s.deleteFiles(orphans)
+	return len(orphans), nil
+}
+
+func (s *AttachmentService) deleteFiles(attachments []models.Attachment) {
+	ctx := context.Background()
+	for _, a := range attachments {
+		keys := []string{a.StorageKey}
+		if a.ThumbnailKey != nil {
+			keys = append(keys, *a.ThumbnailKey)
+		}
+		for _, key := range keys {
+			if err := s.storage.Delete(ctx, key); err != nil {
+				logger.Warn("failed to delete attachment file", zap.Uint("attachment_id", a.ID), zap.String("key", key), zap.Error(err))
+			}
+		}
+	}
+}
+
+// GetClubSettings returns the attachment settings that apply to the club and
+// how much of its quota it uses.
+func (s *AttachmentService) GetClubSettings(clubID uint) (*models.ClubAttachmentSettingsResponse, error) {
+	settings, err := s.clubSettings(clubID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.UsedBytes, err = s.attachmentRepo.ClubUsage(clubID); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// UpdateClubSettings replaces the club's overrides. Only platform admins may
+// change the quota; anyone else keeps the club's current one.
+func (s *AttachmentService) UpdateClubSettings(clubID, userID uint, role string, req *models.UpdateClubAttachmentSettingsRequest) (*models.ClubAttachmentSettingsResponse, error) {
+	platformAdmin := role == "admin" || role == "superuser"
+	if req.QuotaMB != nil && !platformAdmin {
+		return nil, ErrAttachmentQuotaAdminOnly
+	}
+
+	var allowed []string
+	for _, t := range req.AllowedTypes {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if !slices.Contains(s.config.Attachments.AllowedTypes, t) {
+			return nil, ErrUnknownAttachmentType
+		}
+		if !slices.Contains(allowed, t) {
+			allowed = append(allowed, t)
+		}
+	}
+
+	settings := &models.ClubAttachmentSettings{
+		ClubID:       clubID,
+		AllowedTypes: allowed,
+		QuotaMB:      req.QuotaMB,
+		UpdatedBy:    userID,
+	}
+	if !platformAdmin {
+		current, err := s.attachmentRepo.GetClubSettings(clubID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if current != nil {
+			settings.QuotaMB = current.QuotaMB
+		}
+	}
+	if err := s.attachmentRepo.SaveClubSettings(settings); err != nil {
+		return nil, err
+	}
+	return s.GetClubSettings(clubID)
+}
+
+func (s *AttachmentService) clubSettings(clubID uint) (*models.ClubAttachmentSettingsResponse, error) {
+	club, err := s.attachmentRepo.GetClubSettings(clubID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		club = nil
+	}
+	settings := buildAttachmentSettings(s.config.Attachments, clubID, club)
+	return &settings, nil
+}
+
+// buildAttachmentSettings applies a club's overrides, which may be nil, to
+// the platform settings.
+func buildAttachmentSettings(cfg config.AttachmentsConfig, clubID uint, club *models.ClubAttachmentSettings) models.ClubAttachmentSettingsResponse {
+	settings := models.ClubAttachmentSettingsResponse{
+		ClubID:       clubID,
+		AllowedTypes: cfg.AllowedTypes,
+		MaxFileBytes: int64(cfg.MaxFileMB) * megabyte,
+		QuotaBytes:   int64(cfg.ClubQuotaMB) * megabyte,
+		MaxPerPost:   cfg.MaxPerPost,
+	}
+	if club == nil {
+		return settings
+	}
+	if len(club.AllowedTypes) > 0 {
+		// the platform list may have shrunk since the club chose its types
+		allowed := []string{}
+		for _, t := range club.AllowedTypes {
+			if slices.Contains(cfg.AllowedTypes, t) {
+				allowed = append(allowed, t)
+			}
+		}
+		settings.AllowedTypes = allowed
+	}
+	if club.QuotaMB != nil {
+		settings.QuotaBytes = int64(*club.QuotaMB) * megabyte
+	}
+	return settings
+}
+
+// sniffContentType is the media type of data, without parameters such as
+// the charset of text.
+func sniffContentType(data []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// newStorageKey returns a key, without extension, no one can guess.
+func newStorageKey(clubID uint) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("clubs/%d/%s", clubID, hex.EncodeToString(buf)), nil
+}
+
+// cleanFileName keeps the base name of an uploaded file for display, without
+// control characters and at most 255 bytes long.
+func cleanFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	return name
+}
+
+// AttachmentCleaner periodically deletes orphaned uploads. Deleting is
+// conditional in the database, so several server instances can run a
+// cleaner at the same time.
+type AttachmentCleaner struct {
+	attachmentService *AttachmentService
+	interval          time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+func NewAttachmentCleaner(attachmentService *AttachmentService, interval time.Duration) *AttachmentCleaner {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &AttachmentCleaner{
+		attachmentService: attachmentService,
+		interval:          interval,
+		stop:              make(chan struct{}),
+	}
+}
+
+// Start runs the cleaner in the background until Stop is called.
+func (c *AttachmentCleaner) Start() {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		c.RunOnce(time.Now())
+		for {
+			select {
+			case <-c.stop:
+				return
+			case now := <-ticker.C:
+				c.RunOnce(now)
+			}
+		}
+	}()
+}
+
+func (c *AttachmentCleaner) Stop() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// RunOnce deletes the uploads that are orphaned at now.
+func (c *AttachmentCleaner) RunOnce(now time.Time) {
+	deleted, err := c.attachmentService.CleanupOrphans(now)
+	if err != nil {
+		logger.Error("failed to delete orphaned attachments", zap.Error(err))
+	}
+	if deleted > 0 {
+		logger.Info("deleted orphaned attachments", zap.Int("count", deleted))
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAttachmentsConfig() config.AttachmentsConfig {
+	return config.AttachmentsConfig{
+		MaxFileMB:    10,
+		AllowedTypes: []string{"image/jpeg", "image/png", "application/pdf"},
+		ClubQuotaMB:  100,
+		MaxPerPost:   4,
+	}
+}
+
+func TestBuildAttachmentSettings(t *testing.T) {
+	settings := buildAttachmentSettings(testAttachmentsConfig(), 7, nil)
+	assert.Equal(t, models.ClubAttachmentSettingsResponse{
+		ClubID:       7,
+		AllowedTypes: []string{"image/jpeg", "image/png", "application/pdf"},
+		MaxFileBytes: 10 << 20,
+		QuotaBytes:   100 << 20,
+		MaxPerPost:   4,
+	}, settings)
+
+	quota := 5
+	settings = buildAttachmentSettings(testAttachmentsConfig(), 7, &models.ClubAttachmentSettings{
+		ClubID:       7,
+		AllowedTypes: pq.StringArray{"application/pdf", "image/gif"},
+		QuotaMB:      &quota,
+	})
+	assert.Equal(t, []string{"application/pdf"}, settings.AllowedTypes, "types the platform no longer allows are dropped")
+	assert.Equal(t, int64(5<<20), settings.QuotaBytes)
+
+	settings = buildAttachmentSettings(testAttachmentsConfig(), 7, &models.ClubAttachmentSettings{ClubID: 7})
+	assert.Len(t, settings.AllowedTypes, 3, "no club types keep the platform list")
+}
+
+func TestCheckAttachable(t *testing.T) {
+	postID, otherPost := uint(10), uint(11)
+	attachments := []models.Attachment{
+		{ID: 1, UploaderID: 5, ClubID: 2},
+		{ID: 2, UploaderID: 5, ClubID: 2, PostID: &postID},
+		{ID: 3, UploaderID: 5, ClubID: 2, PostID: &otherPost},
+		{ID: 4, UploaderID: 6, ClubID: 2},
+		{ID: 5, UploaderID: 5, ClubID: 3},
+	}
+	forPost := func(a *models.Attachment) bool {
+		return a.CommentID == nil && (a.PostID == nil || *a.PostID == postID)
+	}
+
+	assert.NoError(t, checkAttachable(attachments, []uint{2, 1}, 5, 2, forPost))
+	assert.ErrorIs(t, checkAttachable(attachments, []uint{1, 3}, 5, 2, forPost), ErrInvalidAttachment, "attached to another post")
+	assert.ErrorIs(t, checkAttachable(attachments, []uint{4}, 5, 2, forPost), ErrInvalidAttachment, "someone else's upload")
+	assert.ErrorIs(t, checkAttachable(attachments, []uint{5}, 5, 2, forPost), ErrInvalidAttachment, "uploaded to another club")
+	assert.ErrorIs(t, checkAttachable(attachments, []uint{1, 1}, 5, 2, forPost), ErrInvalidAttachment, "given twice")
+	assert.ErrorIs(t, checkAttachable(attachments, []uint{9}, 5, 2, forPost), ErrInvalidAttachment, "unknown")
+}
+
+func TestSniffContentType(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 2, 2))))
+	assert.Equal(t, "image/png", sniffContentType(buf.Bytes()))
+	assert.Equal(t, "application/pdf", sniffContentType([]byte("%PDF-1.7\n")))
+	assert.Equal(t, "text/plain", sniffContentType([]byte("just text")), "parameters are dropped")
+	assert.Equal(t, "text/html", sniffContentType([]byte("<html><script>")), "the name of the file plays no part")
+}
+
+func TestCleanFileName(t *testing.T) {
+	assert.Equal(t, "photo.jpg", cleanFileName("photo.jpg"))
+	assert.Equal(t, "photo.jpg", cleanFileName(`C:\Users\me\photo.jpg`))
+	assert.Equal(t, "passwd", cleanFileName("../../etc/passwd"))
+	assert.Equal(t, "ab.pdf", cleanFileName("a\x00b\n.pdf"))
+	assert.Equal(t, "file", cleanFileName(""))
+	assert.Equal(t, "file", cleanFileName("/"))
+
+	long := cleanFileName(strings.Repeat("ğ", 200))
+	assert.LessOrEqual(t, len(long), 255)
+	assert.Equal(t, strings.Repeat("ğ", 127), long, "cut on a character boundary")
+}
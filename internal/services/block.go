@@ -0,0 +1,49 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCannotBlockSelf = errors.New("you cannot block yourself")
+	ErrBlockNotFound   = errors.New("user is not blocked")
+)
+
+type BlockService struct {
+	blockRepo repository.BlockRepository
+	userRepo  repository.UserRepository
+}
+
+func NewBlockService(blockRepo repository.BlockRepository, userRepo repository.UserRepository) *BlockService {
+	return &BlockService{
+		blockRepo: blockRepo,
+		userRepo:  userRepo,
+	}
+}
+
+func (s *BlockService) BlockUser(blockerID, blockedID uint) error {
+	if blockerID == blockedID {
+		return ErrCannotBlockSelf
+	}
+	if _, err := s.userRepo.GetByID(blockedID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		}
+		return err
+	}
+	return s.blockRepo.Block(blockerID, blockedID)
+}
+
+func (s *BlockService) UnblockUser(blockerID, blockedID uint) error {
+	removed, err := s.blockRepo.Unblock(blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrBlockNotFound
+	}
+	return nil
+}
@@ -10,20 +10,23 @@ import (
 	"github.com/nevzattalhaozcan/forgotten/internal/config"
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
 	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
 )
 
 type BookService struct {
-	bookRepo   repository.BookRepository
-	bookClient clients.BookAPIClient
-	config     *config.Config
+	bookRepo    repository.BookRepository
+	readingRepo repository.ReadingRepository
+	bookClient  clients.BookAPIClient
+	config      *config.Config
 }
 
-func NewBookService(bookRepo repository.BookRepository, bookClient clients.BookAPIClient, config *config.Config) *BookService {
+func NewBookService(bookRepo repository.BookRepository, readingRepo repository.ReadingRepository, bookClient clients.BookAPIClient, config *config.Config) *BookService {
 	return &BookService{
-		bookRepo:   bookRepo,
-		bookClient: bookClient,
-		config:     config,
+		bookRepo:    bookRepo,
+		readingRepo: readingRepo,
+		bookClient:  bookClient,
+		config:      config,
 	}
 }
 
@@ -47,7 +50,10 @@ func (s *BookService) CreateBook(req *models.CreateBookRequest) (*models.BookRes
 	return &response, nil
 }
 
-func (s *BookService) GetBookByID(id uint) (*models.BookResponse, error) {
+// GetBookByID returns a book. viewerID is nil for anonymous viewers; signed
+// in viewers also get their own rating of the book and how far they are in
+// it.
+func (s *BookService) GetBookByID(id uint, viewerID *uint) (*models.BookResponse, error) {
 	book, err := s.bookRepo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -57,6 +63,26 @@ func (s *BookService) GetBookByID(id uint) (*models.BookResponse, error) {
 	}
 
 	response := book.ToResponse()
+	if viewerID == nil {
+		return &response, nil
+	}
+
+	rating, err := s.bookRepo.GetUserRating(book.ID, *viewerID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if rating != nil {
+		response.UserRating = &rating.Rating
+	}
+
+	progress, err := s.readingRepo.GetUserBookProgress(*viewerID, book.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if progress != nil {
+		status := string(progress.Status)
+		response.ReadingStatus = &status
+	}
 	return &response, nil
 }
 
@@ -117,19 +143,19 @@ func (s *BookService) DeleteBook(id uint) error {
 	return s.bookRepo.Delete(id)
 }
 
-func (s *BookService) ListBooks() ([]*models.BookResponse, error) {
-	books, err := s.bookRepo.List(50, 0)
+func (s *BookService) ListBooks(req pagination.Request) (*pagination.Page[models.BookResponse], error) {
+	page, err := req.Params()
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []*models.BookResponse
-	for _, book := range books {
-		response := book.ToResponse()
-		responses = append(responses, &response)
+	books, err := s.bookRepo.List(page)
+	if err != nil {
+		return nil, err
 	}
 
-	return responses, nil
+	responses := pagination.Map(books, (*models.Book).ToResponse)
+	return &responses, nil
 }
 
 // multi-source search with caching
@@ -0,0 +1,342 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBookmarkTargetNotFound = errors.New("bookmark target not found")
+	ErrAlreadyBookmarked      = errors.New("already bookmarked")
+	ErrBookmarkNotFound       = errors.New("bookmark not found")
+	ErrCollectionNotFound     = errors.New("collection not found")
+	ErrCollectionNameTaken    = errors.New("a collection with this name already exists")
+	ErrReorderMismatch        = errors.New("ids must list every item exactly once")
+)
+
+type BookmarkService struct {
+	bookmarkRepo   repository.BookmarkRepository
+	spoilerService *SpoilerService
+	reshares       *ReshareService
+}
+
+func NewBookmarkService(bookmarkRepo repository.BookmarkRepository, spoilerService *SpoilerService, reshares *ReshareService) *BookmarkService {
+	return &BookmarkService{
+		bookmarkRepo:   bookmarkRepo,
+		spoilerService: spoilerService,
+		reshares:       reshares,
+	}
+}
+
+// CreateBookmark saves a post or comment the user can read, at the end of the
+// requested collection.
+func (s *BookmarkService) CreateBookmark(userID uint, req *models.CreateBookmarkRequest) (*models.BookmarkResponse, error) {
+	if req.CollectionID != nil {
+		if _, err := s.getCollection(userID, *req.CollectionID); err != nil {
+			return nil, err
+		}
+	}
+
+	bookmark := &models.Bookmark{
+		UserID:       userID,
+		TargetType:   req.TargetType,
+		TargetID:     req.TargetID,
+		CollectionID: req.CollectionID,
+	}
+	// a target the user cannot read is reported as missing, not forbidden,
+	// so bookmarking does not reveal what exists in private clubs
+	resolved, err := s.resolve(userID, []models.Bookmark{*bookmark})
+	if err != nil {
+		return nil, err
+	}
+	if resolved[0].Unavailable {
+		return nil, ErrBookmarkTargetNotFound
+	}
+
+	created, err := s.bookmarkRepo.Create(bookmark)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrAlreadyBookmarked
+	}
+
+	response := resolved[0]
+	response.ID = bookmark.ID
+	response.Position = bookmark.Position
+	response.CreatedAt = bookmark.CreatedAt
+	return &response, nil
+}
+
+func (s *BookmarkService) ListBookmarks(userID uint, req *models.ListBookmarksRequest) (*pagination.Page[models.BookmarkResponse], error) {
+	if req.CollectionID != nil {
+		if _, err := s.getCollection(userID, *req.CollectionID); err != nil {
+			return nil, err
+		}
+	}
+
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarks, err := s.bookmarkRepo.List(userID, req.CollectionID, page)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.resolve(userID, bookmarks.Items)
+	if err != nil {
+		return nil, err
+	}
+	return &pagination.Page[models.BookmarkResponse]{Items: items, NextCursor: bookmarks.NextCursor, HasMore: bookmarks.HasMore}, nil
+}
+
+// MoveBookmark moves a bookmark to the end of another collection, or out of
+// any collection.
+func (s *BookmarkService) MoveBookmark(userID, id uint, req *models.MoveBookmarkRequest) (*models.BookmarkResponse, error) {
+	bookmark, err := s.getBookmark(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.CollectionID != nil {
+		if _, err := s.getCollection(userID, *req.CollectionID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.bookmarkRepo.Move(bookmark, req.CollectionID); err != nil {
+		return nil, err
+	}
+	resolved, err := s.resolve(userID, []models.Bookmark{*bookmark})
+	if err != nil {
+		return nil, err
+	}
+	return &resolved[0], nil
+}
+
+func (s *BookmarkService) DeleteBookmark(userID, id uint) error {
+	deleted, err := s.bookmarkRepo.Delete(userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrBookmarkNotFound
+	}
+	return nil
+}
+
+// ReorderBookmarks arranges a collection in the order of ids, which must list
+// every bookmark in it.
+func (s *BookmarkService) ReorderBookmarks(userID, collectionID uint, req *models.ReorderRequest) error {
+	if _, err := s.getCollection(userID, collectionID); err != nil {
+		return err
+	}
+	current, err := s.bookmarkRepo.ListIDsInCollection(userID, collectionID)
+	if err != nil {
+		return err
+	}
+	if !sameIDs(req.IDs, current) {
+		return ErrReorderMismatch
+	}
+	return s.bookmarkRepo.SetPositions(userID, collectionID, req.IDs)
+}
+
+func (s *BookmarkService) CreateCollection(userID uint, req *models.BookmarkCollectionRequest) (*models.BookmarkCollection, error) {
+	if err := s.checkNameFree(userID, req.Name); err != nil {
+		return nil, err
+	}
+	collection := &models.BookmarkCollection{UserID: userID, Name: req.Name}
+	if err := s.bookmarkRepo.CreateCollection(collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+func (s *BookmarkService) ListCollections(userID uint, req pagination.Request) (*pagination.Page[models.BookmarkCollectionResponse], error) {
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+	collections, err := s.bookmarkRepo.ListCollections(userID, page)
+	if err != nil {
+		return nil, err
+	}
+	return &collections, nil
+}
+
+func (s *BookmarkService) RenameCollection(userID, id uint, req *models.BookmarkCollectionRequest) (*models.BookmarkCollection, error) {
+	collection, err := s.getCollection(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if collection.Name == req.Name {
+		return collection, nil
+	}
+	if err := s.checkNameFree(userID, req.Name); err != nil {
+		return nil, err
+	}
+
+	collection.Name = req.Name
+	if err := s.bookmarkRepo.RenameCollection(collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// DeleteCollection deletes a collection and the bookmarks in it.
+func (s *BookmarkService) DeleteCollection(userID, id uint) error {
+	deleted, err := s.bookmarkRepo.DeleteCollection(userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrCollectionNotFound
+	}
+	return nil
+}
+
+// ReorderCollections arranges the user's collections in the order of ids,
+// which must list all of them.
+func (s *BookmarkService) ReorderCollections(userID uint, req *models.ReorderRequest) error {
+	current, err := s.bookmarkRepo.ListCollectionIDs(userID)
+	if err != nil {
+		return err
+	}
+	if !sameIDs(req.IDs, current) {
+		return ErrReorderMismatch
+	}
+	return s.bookmarkRepo.SetCollectionPositions(userID, req.IDs)
+}
+
+func (s *BookmarkService) getCollection(userID, id uint) (*models.BookmarkCollection, error) {
+	collection, err := s.bookmarkRepo.GetCollection(userID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, err
+	}
+	return collection, nil
+}
+
+func (s *BookmarkService) getBookmark(userID, id uint) (*models.Bookmark, error) {
+	bookmark, err := s.bookmarkRepo.Get(userID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookmarkNotFound
+		}
+		return nil, err
+	}
+	return bookmark, nil
+}
+
+func (s *BookmarkService) checkNameFree(userID uint, name string) error {
+	_, err := s.bookmarkRepo.GetCollectionByName(userID, name)
+	if err == nil {
+		return ErrCollectionNameTaken
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+// resolve loads what bookmarks point at. A bookmark whose post or comment was
+// deleted or unpublished, or whose club the user can no longer read, becomes
+// a tombstone that says so without showing any content.
+func (s *BookmarkService) resolve(userID uint, bookmarks []models.Bookmark) ([]models.BookmarkResponse, error) {
+	var postIDs, commentIDs []uint
+	for _, b := range bookmarks {
+		switch b.TargetType {
+		case models.BookmarkTargetPost:
+			postIDs = append(postIDs, b.TargetID)
+		case models.BookmarkTargetComment:
+			commentIDs = append(commentIDs, b.TargetID)
+		}
+	}
+
+	comments, err := s.bookmarkRepo.GetComments(commentIDs)
+	if err != nil {
+		return nil, err
+	}
+	commentsByID := make(map[uint]*models.Comment, len(comments))
+	for i := range comments {
+		commentsByID[comments[i].ID] = &comments[i]
+		postIDs = append(postIDs, comments[i].PostID)
+	}
+
+	posts, err := s.bookmarkRepo.GetPosts(postIDs)
+	if err != nil {
+		return nil, err
+	}
+	clubIDs := make([]uint, 0, len(posts))
+	for _, p := range posts {
+		clubIDs = append(clubIDs, p.ClubID)
+	}
+	readable, err := s.bookmarkRepo.ListReadableClubIDs(userID, clubIDs)
+	if err != nil {
+		return nil, err
+	}
+	visible := make(map[uint]*models.Post, len(posts))
+	for i := range posts {
+		if readable[posts[i].ClubID] {
+			visible[posts[i].ID] = &posts[i]
+		}
+	}
+
+	out := make([]models.BookmarkResponse, 0, len(bookmarks))
+	var shown []models.PostResponse
+	var shownAt []int
+	for _, b := range bookmarks {
+		response := b.ToResponse()
+		switch b.TargetType {
+		case models.BookmarkTargetPost:
+			if post, ok := visible[b.TargetID]; ok {
+				shown = append(shown, post.ToResponse())
+				shownAt = append(shownAt, len(out))
+			}
+		case models.BookmarkTargetComment:
+			if comment, ok := commentsByID[b.TargetID]; ok && !comment.IsDeleted && !comment.IsHidden && visible[comment.PostID] != nil {
+				commentResponse := comment.ToResponse()
+				response.Comment = &commentResponse
+			}
+		}
+		out = append(out, response)
+	}
+
+	if err := s.spoilerService.GatePosts(&userID, shown, false); err != nil {
+		return nil, err
+	}
+	if err := s.reshares.EmbedPosts(&userID, shown, false); err != nil {
+		return nil, err
+	}
+	for i, at := range shownAt {
+		out[at].Post = &shown[i]
+	}
+	for i := range out {
+		out[i].Unavailable = out[i].Post == nil && out[i].Comment == nil
+	}
+	return out, nil
+}
+
+// sameIDs reports whether ids lists every member of want exactly once.
+func sameIDs(ids, want []uint) bool {
+	if len(ids) != len(want) {
+		return false
+	}
+	pending := make(map[uint]bool, len(want))
+	for _, id := range want {
+		pending[id] = true
+	}
+	for _, id := range ids {
+		if !pending[id] {
+			return false
+		}
+		delete(pending, id)
+	}
+	return true
+}
@@ -0,0 +1,14 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSameIDs(t *testing.T) {
+	assert.True(t, sameIDs([]uint{3, 1, 2}, []uint{1, 2, 3}))
+	assert.False(t, sameIDs([]uint{1, 2}, []uint{1, 2, 3}), "every item must be listed")
+	assert.False(t, sameIDs([]uint{1, 1, 2}, []uint{1, 2, 3}), "items may not repeat")
+	assert.False(t, sameIDs([]uint{1, 2, 4}, []uint{1, 2, 3}), "unknown items are rejected")
+}
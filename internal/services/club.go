@@ -3,12 +3,15 @@ package services
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/lib/pq"
 	"github.com/nevzattalhaozcan/forgotten/internal/config"
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
 	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
 )
 
@@ -16,18 +19,24 @@ var (
 	ErrClubNotFound   = errors.New("club not found")
 	ErrMemberNotFound = errors.New("member not found")
 	ErrClubNameExists = errors.New("club name already exists")
+
+	ErrRatingNotFound = errors.New("rating not found")
 )
 
 type ClubService struct {
 	clubRepo       repository.ClubRepository
 	clubRatingRepo repository.ClubRatingRepository
+	tagRepo        repository.TagRepository
+	locationService *LocationService
 	config         *config.Config
 }
 
-func NewClubService(clubRepo repository.ClubRepository, clubRatingRepo repository.ClubRatingRepository, config *config.Config) *ClubService {
+func NewClubService(clubRepo repository.ClubRepository, clubRatingRepo repository.ClubRatingRepository, tagRepo repository.TagRepository, locationService *LocationService, config *config.Config) *ClubService {
 	return &ClubService{
 		clubRepo: clubRepo,
 		clubRatingRepo: clubRatingRepo,
+		tagRepo:  tagRepo,
+		locationService: locationService,
 		config:   config,
 	}
 }
@@ -58,17 +67,33 @@ func (s *ClubService) CreateClub(ownerID uint, req *models.CreateClubRequest) (*
 		return nil, ErrClubNameExists
 	}
 
+	tags, err := resolveTags(s.tagRepo, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	cityID, districtID, err := s.locationService.ResolveLocation(req.CityID, req.DistrictID)
+	if err != nil {
+		return nil, err
+	}
+
 	club := &models.Club{
 		Name:          req.Name,
 		Description:   req.Description,
 		Location:      req.Location,
+		CityID:        cityID,
+		DistrictID:    districtID,
 		MeetingType:   req.MeetingType,
 		Genre:         req.Genre,
 		CoverImageURL: req.CoverImageURL,
 		IsPrivate:     req.IsPrivate,
 		MaxMembers:    req.MaxMembers,
-		Tags:          req.Tags,
+		Tags:          tags,
 		OwnerID:       &ownerID,
+		RevisionVisibility: req.RevisionVisibility,
+	}
+	if club.RevisionVisibility == "" {
+		club.RevisionVisibility = models.RevisionVisibilityMembers
 	}
 
 	if err := s.clubRepo.Create(club); err != nil {
@@ -126,19 +151,22 @@ func (s *ClubService) GetClubByID(id uint) (*models.ClubResponse, error) {
 	return &response, nil
 }
 
-func (s *ClubService) GetAllClubs() ([]*models.ClubResponse, error) {
-	clubs, err := s.clubRepo.List(50, 0)
+func (s *ClubService) GetAllClubs(req pagination.Request) (*pagination.Page[models.ClubResponse], error) {
+	page, err := req.Params()
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []*models.ClubResponse
-	for _, club := range clubs {
-		resp := club.ToResponse()
-		responses = append(responses, &resp)
+	clubs, err := s.clubRepo.List(page)
+	if err != nil {
+		return nil, err
 	}
+	return clubResponsePage(clubs), nil
+}
 
-	return responses, nil
+func clubResponsePage(clubs pagination.Page[*models.Club]) *pagination.Page[models.ClubResponse] {
+	page := pagination.Map(clubs, (*models.Club).ToResponse)
+	return &page
 }
 
 func (s *ClubService) UpdateClub(id uint, req *models.UpdateClubRequest) (*models.ClubResponse, error) {
@@ -164,6 +192,13 @@ func (s *ClubService) UpdateClub(id uint, req *models.UpdateClubRequest) (*model
 	if req.Location != nil {
 		club.Location = req.Location
 	}
+	if req.CityID != nil || req.DistrictID != nil {
+		cityID, districtID, err := s.locationService.ResolveLocationUpdate(club.CityID, club.DistrictID, req.CityID, req.DistrictID)
+		if err != nil {
+			return nil, err
+		}
+		club.CityID, club.DistrictID = cityID, districtID
+	}
 	if req.MeetingType != nil {
 		club.MeetingType = req.MeetingType
 	}
@@ -179,8 +214,15 @@ func (s *ClubService) UpdateClub(id uint, req *models.UpdateClubRequest) (*model
 	if req.MaxMembers != nil {
 		club.MaxMembers = *req.MaxMembers
 	}
+	if req.RevisionVisibility != nil {
+		club.RevisionVisibility = *req.RevisionVisibility
+	}
 	if req.Tags != nil {
-		club.Tags = *req.Tags
+		tags, err := resolveTags(s.tagRepo, *req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		club.Tags = tags
 	}
 	if req.CurrentBook != nil {
 		cb, err := json.Marshal(req.CurrentBook)
@@ -259,6 +301,9 @@ func (s *ClubService) JoinClub(clubID, userID uint) (*models.ClubMembershipRespo
 		if err := s.clubRepo.Update(club); err != nil {
 			return nil, err
 		}
+		if err := s.setRatingMemberLeft(clubID, userID, nil); err != nil {
+			return nil, err
+		}
 	}
 
 	withUser, err := s.clubRepo.GetClubMemberByUserID(clubID, userID)
@@ -309,6 +354,10 @@ func (s *ClubService) LeaveClub(clubID, userID uint, ownerAction *models.OwnerLe
 					return err
 				}
 			}
+			leftAt := time.Now()
+			if err := s.setRatingMemberLeft(clubID, userID, &leftAt); err != nil {
+				return err
+			}
 			count, err := s.clubRepo.CountApprovedMembers(clubID)
 			if err != nil {
 				return err
@@ -334,6 +383,10 @@ func (s *ClubService) LeaveClub(clubID, userID uint, ownerAction *models.OwnerLe
 		}
 		return err
 	}
+	leftAt := time.Now()
+	if err := s.setRatingMemberLeft(clubID, userID, &leftAt); err != nil {
+		return err
+	}
 	
 	count, err := s.clubRepo.CountApprovedMembers(clubID)
 	if err != nil {
@@ -351,7 +404,7 @@ func (s *ClubService) LeaveClub(clubID, userID uint, ownerAction *models.OwnerLe
 	return nil
 }
 
-func (s *ClubService) ListClubMembers(clubID uint) ([]*models.ClubMembership, error) {
+func (s *ClubService) ListClubMembers(clubID uint, req pagination.Request) (*pagination.Page[*models.ClubMembership], error) {
 	_, err := s.clubRepo.GetByID(clubID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -359,7 +412,17 @@ func (s *ClubService) ListClubMembers(clubID uint) ([]*models.ClubMembership, er
 		}
 		return nil, err
 	}
-	return s.clubRepo.ListClubMembers(clubID)
+
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.clubRepo.ListClubMembers(clubID, page)
+	if err != nil {
+		return nil, err
+	}
+	return &members, nil
 }
 
 func (s *ClubService) UpdateClubMember(membership *models.ClubMembership) error {
@@ -417,9 +480,18 @@ func (s *ClubService) UpdateClubMemberFields(clubID, userID uint, req *models.Up
 		if err := s.clubRepo.Update(club); err != nil {
 			return nil, err
 		}
-	} else if wasApproved && !newApproved && club.MembersCount > 0 {
-		club.MembersCount--
-		if err := s.clubRepo.Update(club); err != nil {
+		if err := s.setRatingMemberLeft(clubID, userID, nil); err != nil {
+			return nil, err
+		}
+	} else if wasApproved && !newApproved {
+		if club.MembersCount > 0 {
+			club.MembersCount--
+			if err := s.clubRepo.Update(club); err != nil {
+				return nil, err
+			}
+		}
+		leftAt := time.Now()
+		if err := s.setRatingMemberLeft(clubID, userID, &leftAt); err != nil {
 			return nil, err
 		}
 	}
@@ -442,6 +514,19 @@ func (s *ClubService) RateClub(userID, clubID uint, req *models.RateClubRequest)
 		return nil, err
 	}
 
+	member, err := s.clubRepo.GetClubMemberByUserID(clubID, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err != nil || !member.IsApproved {
+		return nil, errors.New("only approved members can rate the club")
+	}
+	if minDays := s.config.Clubs.RatingMinMembershipDays; minDays > 0 {
+		if time.Since(member.JoinedAt) < time.Duration(minDays)*24*time.Hour {
+			return nil, fmt.Errorf("members can rate the club after %d days of membership", minDays)
+		}
+	}
+
 	cr := &models.ClubRating{
 		ClubID: clubID,
 		UserID: userID,
@@ -452,9 +537,7 @@ func (s *ClubService) RateClub(userID, clubID uint, req *models.RateClubRequest)
 		return nil, err
 	}
 
-	avg, count, err := s.clubRatingRepo.GetAggregateForClub(clubID)
-	if err != nil { return nil, err }
-	if err := s.clubRepo.UpdateRatingAggregate(clubID, avg, count); err != nil {
+	if err := s.refreshRatingAggregate(clubID); err != nil {
 		return nil, err
 	}
 
@@ -467,7 +550,7 @@ func (s *ClubService) RateClub(userID, clubID uint, req *models.RateClubRequest)
 	return &response, nil
 }
 
-func (s *ClubService) ListClubRatings(clubID uint, limit, offset int) ([]models.ClubRating, error) {
+func (s *ClubService) ListClubRatings(clubID uint, req pagination.Request) (*pagination.Page[models.ClubRating], error) {
 	_, err := s.clubRepo.GetByID(clubID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -475,7 +558,128 @@ func (s *ClubService) ListClubRatings(clubID uint, limit, offset int) ([]models.
 		}
 		return nil, err
 	}
-	return s.clubRatingRepo.ListByClub(clubID, limit, offset)
+
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	ratings, err := s.clubRatingRepo.ListByClub(clubID, page)
+	if err != nil {
+		return nil, err
+	}
+	return &ratings, nil
+}
+
+func (s *ClubService) GetRatingBreakdown(clubID uint) (*models.ClubRatingBreakdownResponse, error) {
+	club, err := s.clubRepo.GetByID(clubID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClubNotFound
+		}
+		return nil, err
+	}
+
+	histogram, err := s.clubRatingRepo.GetHistogramForClub(clubID, s.config.Clubs.FormerMemberRatingWeight > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ClubRatingBreakdownResponse{
+		ClubID:       club.ID,
+		Rating:       club.Rating,
+		RatingsCount: club.RatingsCount,
+		Histogram:    histogram,
+	}, nil
+}
+
+func (s *ClubService) ReplyToRating(clubID, ratingID, adminID uint, req *models.ReplyClubRatingRequest) (*models.ClubRating, error) {
+	rating, err := s.getClubRating(clubID, ratingID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rating.Reply = &req.Reply
+	rating.RepliedBy = &adminID
+	rating.RepliedAt = &now
+	if err := s.clubRatingRepo.Update(rating); err != nil {
+		return nil, err
+	}
+	return rating, nil
+}
+
+// HideRating takes a rating out of the club's listing and aggregate on a
+// moderator's decision.
+func (s *ClubService) HideRating(ratingID uint) error {
+	rating, err := s.getRating(ratingID)
+	if err != nil {
+		return err
+	}
+	if err := s.clubRatingRepo.Hide(ratingID); err != nil {
+		return err
+	}
+	return s.refreshRatingAggregate(rating.ClubID)
+}
+
+// DeleteRating deletes a rating on a moderator's decision.
+func (s *ClubService) DeleteRating(ratingID uint) error {
+	rating, err := s.getRating(ratingID)
+	if err != nil {
+		return err
+	}
+	if err := s.clubRatingRepo.Delete(ratingID); err != nil {
+		return err
+	}
+	return s.refreshRatingAggregate(rating.ClubID)
+}
+
+func (s *ClubService) getRating(ratingID uint) (*models.ClubRating, error) {
+	rating, err := s.clubRatingRepo.GetByID(ratingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRatingNotFound
+		}
+		return nil, err
+	}
+	return rating, nil
+}
+
+func (s *ClubService) getClubRating(clubID, ratingID uint) (*models.ClubRating, error) {
+	rating, err := s.getRating(ratingID)
+	if err != nil {
+		return nil, err
+	}
+	if rating.ClubID != clubID {
+		return nil, ErrRatingNotFound
+	}
+	return rating, nil
+}
+
+func (s *ClubService) refreshRatingAggregate(clubID uint) error {
+	weight := s.config.Clubs.FormerMemberRatingWeight
+	avg, count, err := s.clubRatingRepo.GetAggregateForClub(clubID, weight)
+	if err != nil {
+		return err
+	}
+	histogram, err := s.clubRatingRepo.GetHistogramForClub(clubID, weight > 0)
+	if err != nil {
+		return err
+	}
+	return s.clubRepo.UpdateRatingAggregate(clubID, avg, count, histogram)
+}
+
+// setRatingMemberLeft keeps the user's rating in step with their membership:
+// a nil leftAt marks them a current member again.
+func (s *ClubService) setRatingMemberLeft(clubID, userID uint, leftAt *time.Time) error {
+	affected, err := s.clubRatingRepo.SetMemberLeft(clubID, userID, leftAt)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return nil
+	}
+	return s.refreshRatingAggregate(clubID)
 }
 
 func (s *ClubService) TransferOwnership(clubID, currentOwnerID, newOwnerID uint) error {
@@ -519,30 +723,34 @@ func (s *ClubService) TransferOwnership(clubID, currentOwnerID, newOwnerID uint)
 	return nil
 }
 
-func (s *ClubService) ListUserClubs(userID uint) ([]models.ClubResponse, error) {
-	clubs, err := s.clubRepo.ListUserClubs(userID)
+func (s *ClubService) ListUserClubs(userID uint, req pagination.Request) (*pagination.Page[models.ClubResponse], error) {
+	page, err := req.Params()
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []models.ClubResponse
-	for _, club := range clubs {
-		responses = append(responses, club.ToResponse())
+	clubs, err := s.clubRepo.ListUserClubs(userID, page)
+	if err != nil {
+		return nil, err
 	}
-	return responses, nil
+	return clubResponsePage(clubs), nil
 }
 
-func (s *ClubService) GetClubsWithFilters(location, genre, meetingType string, minMembers, maxMembers, limit, offset int) ([]*models.ClubResponse, error) {
-    clubs, err := s.clubRepo.ListWithFilters(location, genre, meetingType, minMembers, maxMembers, limit, offset)
+func (s *ClubService) GetClubsWithFilters(location, cityID, districtID, genre, meetingType string, minMembers, maxMembers int, req pagination.Request) (*pagination.Page[models.ClubResponse], error) {
+    if cityID != "" || districtID != "" {
+        if _, _, err := s.locationService.ResolveLocation(&cityID, &districtID); err != nil {
+            return nil, err
+        }
+    }
+
+    page, err := req.Params()
     if err != nil {
         return nil, err
     }
 
-    var responses []*models.ClubResponse
-    for _, club := range clubs {
-        resp := club.ToResponse()
-        responses = append(responses, &resp)
+    clubs, err := s.clubRepo.ListWithFilters(location, cityID, districtID, genre, meetingType, minMembers, maxMembers, page)
+    if err != nil {
+        return nil, err
     }
-
-    return responses, nil
+    return clubResponsePage(clubs), nil
 }
\ No newline at end of file
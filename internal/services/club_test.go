@@ -0,0 +1,82 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/configtest"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestUnapprovingMemberMarksRatingAsFormer(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	db, err := test_helpers.SetupTestDB()
+	require.NoError(t, err)
+
+	club := &models.Club{Name: "Readers", MembersCount: 2}
+	require.NoError(t, db.Omit("Owner", "Members").Create(club).Error)
+	for _, m := range []models.ClubMembership{
+		{ClubID: club.ID, UserID: 1, Role: "member", IsApproved: true},
+		{ClubID: club.ID, UserID: 2, Role: "member", IsApproved: true},
+	} {
+		require.NoError(t, db.Omit("User", "Club").Create(&m).Error)
+	}
+	for _, r := range []models.ClubRating{
+		{ClubID: club.ID, UserID: 1, Rating: 5},
+		{ClubID: club.ID, UserID: 2, Rating: 1},
+	} {
+		require.NoError(t, db.Create(&r).Error)
+	}
+
+	clubs := NewClubService(repository.NewClubRepository(db), repository.NewClubRatingRepository(db), nil, nil, configtest.New())
+	approved := false
+	_, err = clubs.UpdateClubMemberFields(club.ID, 2, &models.UpdateClubMembershipRequest{IsApproved: &approved})
+	require.NoError(t, err)
+
+	var rating models.ClubRating
+	require.NoError(t, db.Where("club_id = ? AND user_id = ?", club.ID, 2).First(&rating).Error)
+	assert.NotNil(t, rating.MemberLeftAt, "an unapproved member's rating counts as a former member's")
+
+	var stored models.Club
+	require.NoError(t, db.First(&stored, club.ID).Error)
+	assert.Equal(t, 1, stored.MembersCount)
+	assert.Equal(t, 1, stored.RatingsCount, "former members' ratings are left out by default")
+	assert.Equal(t, float32(5), stored.Rating)
+}
+
+func TestClubRatingErrorsAreReturned(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	db, err := test_helpers.SetupTestDB()
+	require.NoError(t, err)
+
+	club := &models.Club{Name: "Readers", MembersCount: 1}
+	require.NoError(t, db.Omit("Owner", "Members").Create(club).Error)
+	member := models.ClubMembership{ClubID: club.ID, UserID: 1, Role: "member", IsApproved: true}
+	require.NoError(t, db.Omit("User", "Club").Create(&member).Error)
+	clubs := NewClubService(repository.NewClubRepository(db), repository.NewClubRatingRepository(db), nil, nil, configtest.New())
+
+	require.NoError(t, db.Migrator().DropTable(&models.ClubRating{}))
+	approved := false
+	_, err = clubs.UpdateClubMemberFields(club.ID, 1, &models.UpdateClubMembershipRequest{IsApproved: &approved})
+	assert.ErrorContains(t, err, "club_ratings", "a failed rating update is not swallowed")
+
+	clubs = NewClubService(failingMemberLookup{repository.NewClubRepository(db)}, repository.NewClubRatingRepository(db), nil, nil, configtest.New())
+	_, err = clubs.RateClub(1, club.ID, &models.RateClubRequest{Rating: 4})
+	assert.ErrorIs(t, err, errLookupFailed, "a failed membership lookup is not reported as a missing membership")
+}
+
+var errLookupFailed = errors.New("lookup failed")
+
+type failingMemberLookup struct {
+	repository.ClubRepository
+}
+
+func (failingMemberLookup) GetClubMemberByUserID(clubID, userID uint) (*models.ClubMembership, error) {
+	return nil, errLookupFailed
+}
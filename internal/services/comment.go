@@ -6,22 +6,47 @@ import (
 	"github.com/nevzattalhaozcan/forgotten/internal/config"
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
 	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrCommentDepthExceeded = errors.New("comment thread is too deep to reply to")
+	ErrInvalidParentComment = errors.New("parent comment not found on this post")
+)
+
+const defaultRepliesLimit = 3
+
 type CommentService struct {
-	commentRepo repository.CommentRepository
-	postRepo    repository.PostRepository
-	userRepo    repository.UserRepository
-	config      *config.Config
+	commentRepo    repository.CommentRepository
+	postRepo       repository.PostRepository
+	userRepo       repository.UserRepository
+	mentionService *MentionService
+	contentFilter  *ContentFilter
+	attachments    *AttachmentService
+	config         *config.Config
+	engagementListeners
 }
 
-func NewCommentService(commentRepo repository.CommentRepository, postRepo repository.PostRepository, userRepo repository.UserRepository, config *config.Config) *CommentService {
+func NewCommentService(commentRepo repository.CommentRepository, postRepo repository.PostRepository, userRepo repository.UserRepository, mentionService *MentionService, contentFilter *ContentFilter, attachments *AttachmentService, config *config.Config) *CommentService {
 	return &CommentService{
-		commentRepo: commentRepo,
-		postRepo:    postRepo,
-		userRepo:    userRepo,
-		config:      config,
+		commentRepo:    commentRepo,
+		postRepo:       postRepo,
+		userRepo:       userRepo,
+		mentionService: mentionService,
+		contentFilter:  contentFilter,
+		attachments:    attachments,
+		config:         config,
+	}
+}
+
+// syncMentions stores the comment's mentions. A failure is logged rather than
+// returned; the comment itself has already been saved.
+func (s *CommentService) syncMentions(comment *models.Comment, clubID uint) {
+	if err := s.mentionService.SyncCommentMentions(comment, clubID); err != nil {
+		logger.Warn("failed to store comment mentions", zap.Uint("comment_id", comment.ID), zap.Error(err))
 	}
 }
 
@@ -34,28 +59,93 @@ func (s *CommentService) CreateComment(postID, userID uint, req *models.CreateCo
 		return nil, err
 	}
 
-	_, err = s.postRepo.GetByID(postID)
+	post, err := s.postRepo.GetByID(postID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, errors.New("post not found")
 		}
 		return nil, err
 	}
+	if !post.IsPublished() {
+		return nil, errors.New("post not found")
+	}
+	if err := s.attachments.CheckForComment(userID, post.ClubID, req.AttachmentIDs); err != nil {
+		return nil, err
+	}
 
 	comment := &models.Comment{
 		PostID:  postID,
 		UserID:  userID,
 		Content: req.Content,
 	}
+	comment.RenderContent()
+
+	if req.ParentID != nil {
+		parent, err := s.commentRepo.GetByID(*req.ParentID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, ErrInvalidParentComment
+			}
+			return nil, err
+		}
+		if parent.PostID != postID || parent.IsDeleted {
+			return nil, ErrInvalidParentComment
+		}
+		if parent.Depth+1 > s.config.Comments.MaxDepth {
+			return nil, ErrCommentDepthExceeded
+		}
+		comment.ParentID = &parent.ID
+		comment.Depth = parent.Depth + 1
+	}
+
+	filterInput, verdict, err := s.filterComment(comment, post.ClubID)
+	if err != nil {
+		return nil, err
+	}
 
 	if err := s.commentRepo.Create(comment); err != nil {
 		return nil, err
 	}
+	if len(req.AttachmentIDs) > 0 {
+		if err := s.attachments.AttachToComment(comment.ID, req.AttachmentIDs); err != nil {
+			return nil, err
+		}
+	}
+	s.contentFilter.Queue(filterInput, verdict, comment.ID, comment.Content)
+	s.syncMentions(comment, post.ClubID)
+	if !comment.IsHeld {
+		s.notifyEngaged(postID)
+	}
 
-	response := comment.ToResponse()
+	created, err := s.commentRepo.GetByID(comment.ID)
+	if err != nil {
+		return nil, err
+	}
+	response := created.ToResponse()
 	return &response, nil
 }
 
+// filterComment runs a comment through the content filter. A held comment is
+// shown as a placeholder until a moderator approves it.
+func (s *CommentService) filterComment(comment *models.Comment, clubID uint) (*FilterInput, *FilterVerdict, error) {
+	input := &FilterInput{
+		Kind:     models.ReportTargetComment,
+		ID:       comment.ID,
+		AuthorID: comment.UserID,
+		ClubID:   clubID,
+		Text:     comment.Content,
+		Body:     comment.Content,
+	}
+	verdict, err := s.contentFilter.Check(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	if verdict.Held() {
+		comment.IsHeld = true
+	}
+	return input, verdict, nil
+}
+
 func (s *CommentService) GetCommentByID(id uint) (*models.CommentResponse, error) {
 	comment, err := s.commentRepo.GetByID(id)
 	if err != nil {
@@ -69,7 +159,7 @@ func (s *CommentService) GetCommentByID(id uint) (*models.CommentResponse, error
 	return &response, nil
 }
 
-func (s *CommentService) UpdateComment(id uint, req *models.UpdateCommentRequest) (*models.CommentResponse, error) {
+func (s *CommentService) UpdateComment(id, editorID uint, req *models.UpdateCommentRequest) (*models.CommentResponse, error) {
 	comment, err := s.commentRepo.GetByID(id)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -78,20 +168,44 @@ func (s *CommentService) UpdateComment(id uint, req *models.UpdateCommentRequest
 		return nil, err
 	}
 
+	if comment.IsDeleted {
+		return nil, errors.New("comment not found")
+	}
+
+	var post *models.Post
+	var filterInput *FilterInput
+	var verdict *FilterVerdict
 	if req.Content != nil {
 		comment.Content = *req.Content
+		comment.RenderContent()
+
+		if post, err = s.postRepo.GetByID(comment.PostID); err != nil {
+			return nil, err
+		}
+		if filterInput, verdict, err = s.filterComment(comment, post.ClubID); err != nil {
+			return nil, err
+		}
 	}
 
-	if err := s.commentRepo.Update(comment); err != nil {
+	if err := s.commentRepo.UpdateWithRevision(comment, editorID); err != nil {
 		return nil, err
 	}
 
-	response := comment.ToResponse()
+	if req.Content != nil {
+		s.contentFilter.Queue(filterInput, verdict, comment.ID, comment.Content)
+		s.syncMentions(comment, post.ClubID)
+	}
+
+	updated, err := s.commentRepo.GetByID(comment.ID)
+	if err != nil {
+		return nil, err
+	}
+	response := updated.ToResponse()
 	return &response, nil
 }
 
 func (s *CommentService) DeleteComment(id uint) error {
-	_, err := s.commentRepo.GetByID(id)
+	comment, err := s.commentRepo.GetByID(id)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.New("comment not found")
@@ -99,27 +213,136 @@ func (s *CommentService) DeleteComment(id uint) error {
 		return err
 	}
 
-	return s.commentRepo.Delete(id)
+	if err := s.commentRepo.Delete(id); err != nil {
+		return err
+	}
+	s.attachments.DeleteForComment(id)
+	s.notifyEngaged(comment.PostID)
+	return nil
+}
+
+// HideComment replaces a comment with a placeholder on a moderator's
+// decision. Unlike deleting it, its replies stay where they are.
+func (s *CommentService) HideComment(id uint) error {
+	if _, err := s.commentRepo.GetByID(id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("comment not found")
+		}
+		return err
+	}
+	return s.commentRepo.Hide(id)
+}
+
+// ReleaseComment shows a comment the content filter held, once a moderator
+// approved it. It returns false for comments that were not held.
+func (s *CommentService) ReleaseComment(id uint) (bool, error) {
+	comment, err := s.commentRepo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, errors.New("comment not found")
+		}
+		return false, err
+	}
+	if !comment.IsHeld {
+		return false, nil
+	}
+	if err := s.commentRepo.Release(id); err != nil {
+		return false, err
+	}
+	s.notifyEngaged(comment.PostID)
+	return true, nil
 }
 
-func (s *CommentService) ListCommentsByPostID(postID uint) ([]models.Comment, error) {
-	_, err := s.postRepo.GetByID(postID)
+// ListCommentsByPostID returns a page of top-level comments, each carrying its
+// first few replies; the rest of a thread is paged through ListReplies.
+func (s *CommentService) ListCommentsByPostID(postID uint, req *models.CommentThreadRequest) (*pagination.Page[models.CommentResponse], error) {
+	post, err := s.postRepo.GetByID(postID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, errors.New("post not found")
 		}
 		return nil, err
 	}
+	if !post.IsPublished() {
+		return nil, errors.New("post not found")
+	}
+
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+	repliesLimit := req.RepliesLimit
+	if repliesLimit <= 0 {
+		repliesLimit = defaultRepliesLimit
+	}
 
-	comments, err := s.commentRepo.ListByPostID(postID)
+	topLevel, err := s.commentRepo.ListTopLevelByPostID(postID, page)
 	if err != nil {
 		return nil, err
 	}
+	comments := topLevel.Items
 
-	return comments, nil
+	parentIDs := make([]uint, 0, len(comments))
+	for _, c := range comments {
+		if c.RepliesCount > 0 {
+			parentIDs = append(parentIDs, c.ID)
+		}
+	}
+
+	replies, err := s.commentRepo.ListFirstReplies(parentIDs, repliesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	byParent := make(map[uint][]models.Comment, len(parentIDs))
+	for _, r := range replies {
+		byParent[*r.ParentID] = append(byParent[*r.ParentID], r)
+	}
+
+	for i := range comments {
+		comments[i].Replies = byParent[comments[i].ID]
+	}
+	response := pagination.Map(topLevel, func(c models.Comment) models.CommentResponse { return c.ToResponse() })
+	return &response, nil
+}
+
+func (s *CommentService) ListReplies(commentID uint, req *models.CommentThreadRequest) (*pagination.Page[models.CommentResponse], error) {
+	_, err := s.commentRepo.GetByID(commentID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("comment not found")
+		}
+		return nil, err
+	}
+
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := s.commentRepo.ListReplies(commentID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	response := pagination.Map(replies, func(c models.Comment) models.CommentResponse { return c.ToResponse() })
+	return &response, nil
 }
 
-func (s *CommentService) ListCommentsByUserID(userID uint) ([]models.Comment, error) {
+func (s *CommentService) GetCommentThread(commentID uint) (*models.CommentResponse, error) {
+	comment, err := s.commentRepo.GetThread(commentID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("comment not found")
+		}
+		return nil, err
+	}
+
+	response := comment.ToResponse()
+	return &response, nil
+}
+
+func (s *CommentService) ListCommentsByUserID(userID uint, req pagination.Request) (*pagination.Page[models.Comment], error) {
 	_, err := s.userRepo.GetByID(userID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -128,11 +351,16 @@ func (s *CommentService) ListCommentsByUserID(userID uint) ([]models.Comment, er
 		return nil, err
 	}
 
-	comments, err := s.commentRepo.ListByUserID(userID)
+	page, err := req.Params()
 	if err != nil {
 		return nil, err
 	}
-	return comments, nil
+
+	comments, err := s.commentRepo.ListByUserID(userID, page)
+	if err != nil {
+		return nil, err
+	}
+	return &comments, nil
 }
 
 func (s *CommentService) LikeComment(userID, commentID uint) error {
@@ -151,6 +379,9 @@ func (s *CommentService) LikeComment(userID, commentID uint) error {
 		}
 		return err
 	}
+	if comment.IsDeleted {
+		return errors.New("comment not found")
+	}
 
 	hasLiked, err := s.commentRepo.HasUserLiked(userID, commentID)
 	if err != nil {
@@ -160,24 +391,7 @@ func (s *CommentService) LikeComment(userID, commentID uint) error {
 		return errors.New("user has already liked this comment")
 	}
 
-	if err := s.commentRepo.LikeComment(&models.CommentLike{
-		UserID:    userID,
-		CommentID: commentID,
-	}); err != nil {
-		return err
-	}
-
-	count, err := s.commentRepo.CountLikes(commentID)
-	if err != nil {
-		return err
-	}
-
-	comment.LikesCount = int(count)
-	if err := s.commentRepo.Update(comment); err != nil {
-		return err
-	}
-
-	return s.commentRepo.UpdateLikesCount(commentID, int(count))
+	return s.commentRepo.LikeComment(userID, commentID)
 }
 
 func (s *CommentService) UnlikeComment(userID, commentID uint) error {
@@ -205,19 +419,10 @@ func (s *CommentService) UnlikeComment(userID, commentID uint) error {
 		return errors.New("user has not liked this comment")
 	}
 
-	if err := s.commentRepo.UnlikeComment(userID, commentID); err != nil {
-		return err
-	}
-
-	count, err := s.commentRepo.CountLikes(commentID)
-	if err != nil {
-		return err
-	}
-
-	return s.commentRepo.UpdateLikesCount(commentID, int(count))
+	return s.commentRepo.UnlikeComment(userID, commentID)
 }
 
-func (s *CommentService) ListLikesByCommentID(commentID uint) ([]models.CommentLikeResponse, error) {
+func (s *CommentService) ListLikesByCommentID(commentID uint, req pagination.Request) (*pagination.Page[models.CommentLikeResponse], error) {
 	_, err := s.commentRepo.GetByID(commentID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -226,13 +431,14 @@ func (s *CommentService) ListLikesByCommentID(commentID uint) ([]models.CommentL
 		return nil, err
 	}
 
-	likes, err := s.commentRepo.ListCommentLikes(commentID)
+	page, err := req.Params()
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("no likes found for this comment")
-		}
 		return nil, err
 	}
 
-	return likes, nil
+	likes, err := s.commentRepo.ListCommentLikes(commentID, page)
+	if err != nil {
+		return nil, err
+	}
+	return &likes, nil
 }
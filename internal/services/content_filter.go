@@ -0,0 +1,500 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var ErrContentBlocked = errors.New("content rejected by the content filter")
+
+// recentContentLimit caps how much of an author's recent content the filter
+// compares new content against.
+const recentContentLimit = 100
+
+// FilterInput is a post or comment about to be saved.
+type FilterInput struct {
+	Kind     string // models.ReportTargetPost or models.ReportTargetComment
+	ID       uint   // zero for new content
+	AuthorID uint
+	ClubID   uint
+	Text     string // title and body, checked for words and links
+	Body     string // compared with the author's recent content
+
+	// filled in by ContentFilter.Check
+	AccountAge time.Duration
+	Recent     []models.RecentContent // the author's other content, newest first
+}
+
+// FilterMatch is one reason the filter acted on content.
+type FilterMatch struct {
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+	Detail string `json:"detail"`
+}
+
+// FilterVerdict is the outcome of the filter: the strictest action of its
+// matches, or empty when nothing matched.
+type FilterVerdict struct {
+	Action  string
+	Matches []FilterMatch
+}
+
+func (v *FilterVerdict) Held() bool {
+	return v != nil && v.Action == models.FilterActionHold
+}
+
+func (v *FilterVerdict) add(match FilterMatch) {
+	if filterActionRank(match.Action) == 0 {
+		return
+	}
+	v.Matches = append(v.Matches, match)
+	if filterActionRank(match.Action) > filterActionRank(v.Action) {
+		v.Action = match.Action
+	}
+}
+
+// describe lists the matches for moderators.
+func (v *FilterVerdict) describe() string {
+	parts := make([]string, 0, len(v.Matches))
+	for _, m := range v.Matches {
+		parts = append(parts, fmt.Sprintf("%s: %s (%s)", m.Rule, m.Detail, m.Action))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func filterActionRank(action string) int {
+	switch action {
+	case models.FilterActionFlag:
+		return 1
+	case models.FilterActionHold:
+		return 2
+	case models.FilterActionBlock:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// FilterSettings is the filter configuration in effect for one club: the
+// platform settings with the club's overrides applied.
+type FilterSettings struct {
+	Words                map[string]string // normalized word or phrase to its action
+	MaxLinks             int
+	ShortenerDomains     []string
+	LinkAction           string
+	RepeatWindow         time.Duration
+	RepeatMax            int
+	RepeatMinLength      int
+	RepeatAction         string
+	NewAccountAge        time.Duration
+	NewAccountMaxPerHour int
+	NewAccountRateAction string
+	NewAccountLinkAction string
+}
+
+// ContentRule is one check of the content filter. It returns a match per
+// problem found, leaving Rule empty, and nothing for content that passes.
+type ContentRule interface {
+	Name() string
+	Check(input *FilterInput, settings *FilterSettings) []FilterMatch
+}
+
+// ContentFilter runs new and edited posts and comments through a chain of
+// rules. Blocked content is rejected; held and flagged content is saved and
+// reported to the moderation queue, where dismissing the report approves
+// held content.
+type ContentFilter struct {
+	filterRepo repository.ContentFilterRepository
+	reportRepo repository.ReportRepository
+	userRepo   repository.UserRepository
+	config     *config.Config
+	rules      []ContentRule
+}
+
+func NewContentFilter(filterRepo repository.ContentFilterRepository, reportRepo repository.ReportRepository, userRepo repository.UserRepository, config *config.Config) *ContentFilter {
+	return &ContentFilter{
+		filterRepo: filterRepo,
+		reportRepo: reportRepo,
+		userRepo:   userRepo,
+		config:     config,
+		rules:      []ContentRule{wordListRule{}, linkRule{}, repeatRule{}, newAccountRule{}},
+	}
+}
+
+// AddRule appends a rule to the chain. It is not safe to call once the
+// server is handling requests.
+func (f *ContentFilter) AddRule(rule ContentRule) {
+	f.rules = append(f.rules, rule)
+}
+
+// Check runs input through the rules. Blocked content is returned as an
+// ErrContentBlocked error along with the verdict.
+func (f *ContentFilter) Check(input *FilterInput) (*FilterVerdict, error) {
+	verdict := &FilterVerdict{}
+	if !f.config.ContentFilter.Enabled {
+		return verdict, nil
+	}
+
+	settings, err := f.settings(input.ClubID)
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := f.userRepo.GetByID(input.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	input.AccountAge = now.Sub(author.CreatedAt)
+
+	since := now.Add(-max(settings.RepeatWindow, time.Hour))
+	recent, err := f.filterRepo.ListRecentContent(input.AuthorID, since, recentContentLimit)
+	if err != nil {
+		return nil, err
+	}
+	input.Recent = input.Recent[:0]
+	for _, r := range recent {
+		if r.Kind != input.Kind || r.ID != input.ID {
+			input.Recent = append(input.Recent, r)
+		}
+	}
+
+	for _, rule := range f.rules {
+		for _, match := range rule.Check(input, settings) {
+			match.Rule = rule.Name()
+			verdict.add(match)
+		}
+	}
+
+	if verdict.Action == models.FilterActionBlock {
+		var rules []string
+		for _, m := range verdict.Matches {
+			if m.Action == models.FilterActionBlock && !slices.Contains(rules, m.Rule) {
+				rules = append(rules, m.Rule)
+			}
+		}
+		return verdict, fmt.Errorf("%w (%s)", ErrContentBlocked, strings.Join(rules, ", "))
+	}
+	return verdict, nil
+}
+
+// Queue reports held or flagged content to the moderation queue once it has
+// been saved. Content already waiting in the queue is not reported again.
+// Failures are logged; the content has been saved either way.
+func (f *ContentFilter) Queue(input *FilterInput, verdict *FilterVerdict, targetID uint, snapshot string) {
+	if verdict == nil || filterActionRank(verdict.Action) == 0 {
+		return
+	}
+
+	details := verdict.describe()
+	if runes := []rune(details); len(runes) > 1000 {
+		details = string(runes[:1000])
+	}
+
+	open, err := f.reportRepo.HasOpen(input.Kind, targetID)
+	if err == nil && !open {
+		clubID := input.ClubID
+		_, err = f.reportRepo.Create(&models.Report{
+			TargetType:   input.Kind,
+			TargetID:     targetID,
+			TargetUserID: input.AuthorID,
+			ClubID:       &clubID,
+			Reason:       models.ReportReasonContentFilter,
+			Details:      details,
+			Snapshot:     snapshot,
+			Status:       models.ReportStatusOpen,
+		})
+	}
+	if err != nil {
+		logger.Warn("failed to queue filtered content",
+			zap.String("target_type", input.Kind),
+			zap.Uint("target_id", targetID),
+			zap.Error(err))
+		return
+	}
+
+	logger.Info("content filter matched",
+		zap.String("target_type", input.Kind),
+		zap.Uint("target_id", targetID),
+		zap.String("action", verdict.Action),
+		zap.String("matches", verdict.describe()))
+}
+
+// GetClubFilter returns a club's overrides; a club without any gets empty
+// ones.
+func (f *ContentFilter) GetClubFilter(clubID uint) (*models.ClubContentFilter, error) {
+	filter, err := f.filterRepo.GetClubFilter(clubID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.ClubContentFilter{ClubID: clubID}, nil
+	}
+	return filter, err
+}
+
+// UpdateClubFilter replaces a club's overrides.
+func (f *ContentFilter) UpdateClubFilter(clubID, userID uint, req *models.UpdateClubContentFilterRequest) (*models.ClubContentFilter, error) {
+	filter := &models.ClubContentFilter{
+		ClubID:               clubID,
+		BlockWords:           cleanFilterWords(req.BlockWords),
+		HoldWords:            cleanFilterWords(req.HoldWords),
+		FlagWords:            cleanFilterWords(req.FlagWords),
+		AllowWords:           cleanFilterWords(req.AllowWords),
+		MaxLinks:             req.MaxLinks,
+		LinkAction:           req.LinkAction,
+		RepeatAction:         req.RepeatAction,
+		NewAccountRateAction: req.NewAccountRateAction,
+		NewAccountLinkAction: req.NewAccountLinkAction,
+		UpdatedBy:            userID,
+	}
+	if err := f.filterRepo.SaveClubFilter(filter); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func (f *ContentFilter) settings(clubID uint) (*FilterSettings, error) {
+	club, err := f.filterRepo.GetClubFilter(clubID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		club = nil
+	}
+	return buildFilterSettings(f.config.ContentFilter, club), nil
+}
+
+// buildFilterSettings applies a club's overrides, if any, to the platform
+// settings. A word on several lists takes the strictest action.
+func buildFilterSettings(cfg config.ContentFilterConfig, club *models.ClubContentFilter) *FilterSettings {
+	settings := &FilterSettings{
+		Words:                map[string]string{},
+		MaxLinks:             cfg.MaxLinks,
+		ShortenerDomains:     cfg.ShortenerDomains,
+		LinkAction:           cfg.LinkAction,
+		RepeatWindow:         time.Duration(cfg.RepeatWindowHours) * time.Hour,
+		RepeatMax:            cfg.RepeatMax,
+		RepeatMinLength:      cfg.RepeatMinLength,
+		RepeatAction:         cfg.RepeatAction,
+		NewAccountAge:        time.Duration(cfg.NewAccountDays) * 24 * time.Hour,
+		NewAccountMaxPerHour: cfg.NewAccountMaxPerHour,
+		NewAccountRateAction: cfg.NewAccountRateAction,
+		NewAccountLinkAction: cfg.NewAccountLinkAction,
+	}
+
+	allowed := map[string]bool{}
+	if club != nil {
+		for _, w := range club.AllowWords {
+			allowed[filterWordKey(w)] = true
+		}
+	}
+	addWords := func(words []string, action string, allowable bool) {
+		for _, w := range words {
+			key := filterWordKey(w)
+			if key == "" || (allowable && allowed[key]) {
+				continue
+			}
+			if filterActionRank(action) > filterActionRank(settings.Words[key]) {
+				settings.Words[key] = action
+			}
+		}
+	}
+	addWords(cfg.BlockWords, models.FilterActionBlock, false)
+	addWords(cfg.HoldWords, models.FilterActionHold, true)
+	addWords(cfg.FlagWords, models.FilterActionFlag, true)
+	if club == nil {
+		return settings
+	}
+
+	addWords(club.BlockWords, models.FilterActionBlock, false)
+	addWords(club.HoldWords, models.FilterActionHold, false)
+	addWords(club.FlagWords, models.FilterActionFlag, false)
+	if club.MaxLinks != nil {
+		settings.MaxLinks = *club.MaxLinks
+	}
+	if club.LinkAction != nil {
+		settings.LinkAction = *club.LinkAction
+	}
+	if club.RepeatAction != nil {
+		settings.RepeatAction = *club.RepeatAction
+	}
+	if club.NewAccountRateAction != nil {
+		settings.NewAccountRateAction = *club.NewAccountRateAction
+	}
+	if club.NewAccountLinkAction != nil {
+		settings.NewAccountLinkAction = *club.NewAccountLinkAction
+	}
+	return settings
+}
+
+// filterWords splits text into words folded like place names (see
+// normalizeText), so "İSTANBUL" and "istanbul" match and punctuation between
+// words does not hide them.
+func filterWords(text string) []string {
+	return strings.FieldsFunc(normalizeText(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// filterWordKey is the normalized form of a listed word or phrase.
+func filterWordKey(word string) string {
+	return strings.Join(filterWords(word), " ")
+}
+
+// cleanFilterWords trims listed words and drops empty and duplicate entries.
+func cleanFilterWords(words []string) []string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, w := range words {
+		w = strings.Join(strings.Fields(w), " ")
+		key := filterWordKey(w)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, w)
+	}
+	return out
+}
+
+// wordListRule matches listed words and phrases as whole words.
+type wordListRule struct{}
+
+func (wordListRule) Name() string { return "word_list" }
+
+func (wordListRule) Check(input *FilterInput, settings *FilterSettings) []FilterMatch {
+	if len(settings.Words) == 0 {
+		return nil
+	}
+	text := " " + strings.Join(filterWords(input.Text), " ") + " "
+
+	keys := make([]string, 0, len(settings.Words))
+	for key := range settings.Words {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var matches []FilterMatch
+	for _, key := range keys {
+		if strings.Contains(text, " "+key+" ") {
+			matches = append(matches, FilterMatch{Action: settings.Words[key], Detail: fmt.Sprintf("contains %q", key)})
+		}
+	}
+	return matches
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>()\[\]"']+`)
+
+// linkHosts returns the host of every link in text, lowercased and without
+// "www.".
+func linkHosts(text string) []string {
+	links := linkPattern.FindAllString(text, -1)
+	hosts := make([]string, 0, len(links))
+	for _, link := range links {
+		host := strings.ToLower(link)
+		if i := strings.Index(host, "://"); i >= 0 {
+			host = host[i+3:]
+		}
+		if i := strings.IndexAny(host, "/?#:"); i >= 0 {
+			host = host[:i]
+		}
+		hosts = append(hosts, strings.TrimPrefix(host, "www."))
+	}
+	return hosts
+}
+
+// linkRule catches link spam: too many links, or links hidden behind URL
+// shorteners.
+type linkRule struct{}
+
+func (linkRule) Name() string { return "links" }
+
+func (linkRule) Check(input *FilterInput, settings *FilterSettings) []FilterMatch {
+	hosts := linkHosts(input.Text)
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	var matches []FilterMatch
+	if len(hosts) > settings.MaxLinks {
+		matches = append(matches, FilterMatch{Action: settings.LinkAction, Detail: fmt.Sprintf("%d links", len(hosts))})
+	}
+	for _, host := range hosts {
+		for _, domain := range settings.ShortenerDomains {
+			domain = strings.ToLower(domain)
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return append(matches, FilterMatch{Action: settings.LinkAction, Detail: "shortened link to " + host})
+			}
+		}
+	}
+	return matches
+}
+
+// repeatRule catches the same text posted over and over, across posts and
+// comments.
+type repeatRule struct{}
+
+func (repeatRule) Name() string { return "repeated_content" }
+
+func (repeatRule) Check(input *FilterInput, settings *FilterSettings) []FilterMatch {
+	if settings.RepeatMax <= 0 {
+		return nil
+	}
+	body := filterWordKey(input.Body)
+	if len([]rune(body)) < settings.RepeatMinLength {
+		return nil
+	}
+
+	since := time.Now().Add(-settings.RepeatWindow)
+	times := 1
+	for _, r := range input.Recent {
+		if r.CreatedAt.After(since) && filterWordKey(r.Text) == body {
+			times++
+		}
+	}
+	if times < settings.RepeatMax {
+		return nil
+	}
+	return []FilterMatch{{Action: settings.RepeatAction, Detail: fmt.Sprintf("posted %d times within %s", times, settings.RepeatWindow)}}
+}
+
+// newAccountRule holds new accounts to stricter limits: how much they post
+// per hour, and whether they post links at all.
+type newAccountRule struct{}
+
+func (newAccountRule) Name() string { return "new_account" }
+
+func (newAccountRule) Check(input *FilterInput, settings *FilterSettings) []FilterMatch {
+	if input.AccountAge >= settings.NewAccountAge {
+		return nil
+	}
+
+	var matches []FilterMatch
+	if settings.NewAccountMaxPerHour > 0 {
+		hourAgo := time.Now().Add(-time.Hour)
+		count := 1
+		for _, r := range input.Recent {
+			if r.CreatedAt.After(hourAgo) {
+				count++
+			}
+		}
+		if count > settings.NewAccountMaxPerHour {
+			matches = append(matches, FilterMatch{Action: settings.NewAccountRateAction, Detail: fmt.Sprintf("%d posts and comments within an hour", count)})
+		}
+	}
+	if len(linkHosts(input.Text)) > 0 {
+		matches = append(matches, FilterMatch{Action: settings.NewAccountLinkAction, Detail: "links from an account created " + input.AccountAge.Round(time.Hour).String() + " ago"})
+	}
+	return matches
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testFilterConfig() config.ContentFilterConfig {
+	return config.ContentFilterConfig{
+		Enabled:              true,
+		BlockWords:           []string{"Kötü Söz"},
+		HoldWords:            []string{"casino"},
+		FlagWords:            []string{"spoiler alert", "casino"},
+		MaxLinks:             2,
+		ShortenerDomains:     []string{"bit.ly"},
+		LinkAction:           models.FilterActionFlag,
+		RepeatWindowHours:    24,
+		RepeatMax:            3,
+		RepeatMinLength:      10,
+		RepeatAction:         models.FilterActionHold,
+		NewAccountDays:       3,
+		NewAccountMaxPerHour: 2,
+		NewAccountRateAction: models.FilterActionHold,
+		NewAccountLinkAction: models.FilterActionFlag,
+	}
+}
+
+func TestBuildFilterSettings_ClubOverrides(t *testing.T) {
+	settings := buildFilterSettings(testFilterConfig(), nil)
+	assert.Equal(t, map[string]string{
+		"kotu soz":      models.FilterActionBlock,
+		"casino":        models.FilterActionHold,
+		"spoiler alert": models.FilterActionFlag,
+	}, settings.Words, "a word on several lists takes the strictest action")
+
+	off := models.FilterActionOff
+	maxLinks := 10
+	settings = buildFilterSettings(testFilterConfig(), &models.ClubContentFilter{
+		HoldWords:  pq.StringArray{"Rakip Kulüp"},
+		AllowWords: pq.StringArray{"CASINO", "kötü söz"},
+		MaxLinks:   &maxLinks,
+		LinkAction: &off,
+	})
+	assert.Equal(t, map[string]string{
+		"kotu soz":      models.FilterActionBlock,
+		"spoiler alert": models.FilterActionFlag,
+		"rakip kulup":   models.FilterActionHold,
+	}, settings.Words, "clubs cannot allow platform block words")
+	assert.Equal(t, 10, settings.MaxLinks)
+	assert.Equal(t, models.FilterActionOff, settings.LinkAction)
+	assert.Equal(t, models.FilterActionHold, settings.RepeatAction)
+}
+
+func TestWordListRule_MatchesWholeFoldedWords(t *testing.T) {
+	settings := buildFilterSettings(testFilterConfig(), nil)
+	check := func(text string) []FilterMatch {
+		return wordListRule{}.Check(&FilterInput{Text: text}, settings)
+	}
+
+	matches := check("Bu tamamen KÖTÜ-SÖZ!")
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, models.FilterActionBlock, matches[0].Action)
+	}
+	assert.Len(t, check("Spoiler   alert: the butler did it"), 1)
+	assert.Empty(t, check("casinos and kötüsöz are not listed words"))
+}
+
+func TestLinkRule(t *testing.T) {
+	settings := buildFilterSettings(testFilterConfig(), nil)
+	check := func(text string) []FilterMatch {
+		return linkRule{}.Check(&FilterInput{Text: text}, settings)
+	}
+
+	assert.Empty(t, check("see https://example.com/book and www.example.org"))
+	assert.Len(t, check("a https://a.com b http://b.com c www.c.com"), 1)
+
+	matches := check("free books at https://www.BIT.LY/x")
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, "shortened link to bit.ly", matches[0].Detail)
+	}
+
+	settings.LinkAction = models.FilterActionOff
+	verdict := &FilterVerdict{}
+	for _, m := range check("https://bit.ly/x") {
+		verdict.add(m)
+	}
+	assert.Empty(t, verdict.Action, "rules set to off never act")
+}
+
+func TestRepeatRule(t *testing.T) {
+	settings := buildFilterSettings(testFilterConfig(), nil)
+	now := time.Now()
+	same := models.RecentContent{Text: "Join my club, link in bio!", CreatedAt: now.Add(-time.Hour)}
+	old := models.RecentContent{Text: "join my club link in bio", CreatedAt: now.Add(-48 * time.Hour)}
+
+	input := &FilterInput{Body: "JOIN my club... link in bio", Recent: []models.RecentContent{same, old}}
+	assert.Empty(t, repeatRule{}.Check(input, settings), "repeats outside the window do not count")
+
+	input.Recent = append(input.Recent, same)
+	matches := repeatRule{}.Check(input, settings)
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, models.FilterActionHold, matches[0].Action)
+	}
+
+	short := &FilterInput{Body: "thanks!", Recent: []models.RecentContent{{Text: "thanks!", CreatedAt: now}, {Text: "thanks", CreatedAt: now}}}
+	assert.Empty(t, repeatRule{}.Check(short, settings))
+}
+
+func TestNewAccountRule(t *testing.T) {
+	settings := buildFilterSettings(testFilterConfig(), nil)
+	now := time.Now()
+	busy := []models.RecentContent{{CreatedAt: now.Add(-10 * time.Minute)}, {CreatedAt: now.Add(-20 * time.Minute)}}
+
+	established := &FilterInput{AccountAge: 30 * 24 * time.Hour, Text: "https://example.com", Recent: busy}
+	assert.Empty(t, newAccountRule{}.Check(established, settings))
+
+	fresh := &FilterInput{AccountAge: time.Hour, Text: "hello", Recent: busy[:1]}
+	assert.Empty(t, newAccountRule{}.Check(fresh, settings))
+
+	fresh.Recent = busy
+	fresh.Text = "read https://example.com"
+	verdict := &FilterVerdict{}
+	for _, m := range (newAccountRule{}).Check(fresh, settings) {
+		verdict.add(m)
+	}
+	assert.Len(t, verdict.Matches, 2)
+	assert.Equal(t, models.FilterActionHold, verdict.Action)
+	assert.True(t, verdict.Held())
+}
+
+func TestCleanFilterWords(t *testing.T) {
+	assert.Equal(t, []string{"kötü söz", "casino"}, cleanFilterWords([]string{"  kötü   söz ", "KÖTÜ SÖZ", "casino", "!!"}))
+	assert.True(t, strings.HasPrefix((&FilterVerdict{Matches: []FilterMatch{{Rule: "links", Detail: "3 links", Action: "flag"}}}).describe(), "links: 3 links"))
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"github.com/nevzattalhaozcan/forgotten/pkg/markdown"
+	"go.uber.org/zap"
+)
+
+const contentRerenderBatchSize = 500
+
+// ContentRenderService re-renders stored post and comment HTML after the
+// Markdown options or sanitizer policy change (see markdown.RenderVersion).
+type ContentRenderService struct {
+	contentRepo repository.ContentRepository
+}
+
+func NewContentRenderService(contentRepo repository.ContentRepository) *ContentRenderService {
+	return &ContentRenderService{contentRepo: contentRepo}
+}
+
+// Run re-renders every row with an older render version. Rows are only
+// touched once, so running it again is cheap.
+func (s *ContentRenderService) Run() (*models.ContentRerenderResponse, error) {
+	resp := models.ContentRerenderResponse{RenderVersion: markdown.RenderVersion}
+	var err error
+
+	if resp.Posts, err = s.rerenderPosts(); err != nil {
+		return nil, err
+	}
+	if resp.Comments, err = s.rerenderComments(); err != nil {
+		return nil, err
+	}
+
+	logger.Info("content re-render completed",
+		zap.Int("render_version", resp.RenderVersion),
+		zap.Int("posts", resp.Posts),
+		zap.Int("comments", resp.Comments))
+	return &resp, nil
+}
+
+func (s *ContentRenderService) rerenderPosts() (int, error) {
+	rendered := 0
+	var afterID uint
+	for {
+		posts, err := s.contentRepo.ListStalePosts(markdown.RenderVersion, afterID, contentRerenderBatchSize)
+		if err != nil {
+			return rendered, err
+		}
+		if len(posts) == 0 {
+			return rendered, nil
+		}
+
+		for i := range posts {
+			afterID = posts[i].ID
+			posts[i].RenderContent()
+			if err := s.contentRepo.SavePostRendering(&posts[i]); err != nil {
+				return rendered, err
+			}
+			rendered++
+		}
+	}
+}
+
+func (s *ContentRenderService) rerenderComments() (int, error) {
+	rendered := 0
+	var afterID uint
+	for {
+		comments, err := s.contentRepo.ListStaleComments(markdown.RenderVersion, afterID, contentRerenderBatchSize)
+		if err != nil {
+			return rendered, err
+		}
+		if len(comments) == 0 {
+			return rendered, nil
+		}
+
+		for i := range comments {
+			afterID = comments[i].ID
+			comments[i].RenderContent()
+			if err := s.contentRepo.SaveCommentRendering(&comments[i]); err != nil {
+				return rendered, err
+			}
+			rendered++
+		}
+	}
+}
@@ -8,20 +8,23 @@ import (
 	"github.com/nevzattalhaozcan/forgotten/internal/config"
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
 	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
 )
 
 type EventService struct {
-	eventRepo repository.EventRepository
-	clubRepo  repository.ClubRepository
-	config    *config.Config
+	eventRepo       repository.EventRepository
+	clubRepo        repository.ClubRepository
+	locationService *LocationService
+	config          *config.Config
 }
 
-func NewEventService(eventRepo repository.EventRepository, clubRepo repository.ClubRepository, config *config.Config) *EventService {
+func NewEventService(eventRepo repository.EventRepository, clubRepo repository.ClubRepository, locationService *LocationService, config *config.Config) *EventService {
 	return &EventService{
-		eventRepo: eventRepo,
-		clubRepo:  clubRepo,
-		config:    config,
+		eventRepo:       eventRepo,
+		clubRepo:        clubRepo,
+		locationService: locationService,
+		config:          config,
 	}
 }
 
@@ -34,6 +37,11 @@ func (s *EventService) CreateEvent(clubID uint, req *models.CreateEventRequest)
 		return nil, err
 	}
 
+	cityID, districtID, err := s.locationService.ResolveLocation(req.CityID, req.DistrictID)
+	if err != nil {
+		return nil, err
+	}
+
 	event := &models.Event{
 		Title:        req.Title,
 		Description:  req.Description,
@@ -42,6 +50,8 @@ func (s *EventService) CreateEvent(clubID uint, req *models.CreateEventRequest)
 		EventDate:    req.EventDate.Time,
 		EventTime:    models.DBTime{Time: req.EventTime.Time},
 		Location:     req.Location,
+		CityID:       cityID,
+		DistrictID:   districtID,
 		OnlineLink:   req.OnlineLink,
 		MaxAttendees: req.MaxAttendees,
 		IsPublic:     req.IsPublic,
@@ -56,7 +66,7 @@ func (s *EventService) CreateEvent(clubID uint, req *models.CreateEventRequest)
 	return &response, nil
 }
 
-func (s *EventService) GetClubEvents(clubID uint) ([]models.EventResponse, error) {
+func (s *EventService) GetClubEvents(clubID uint, req pagination.Request) (*pagination.Page[models.EventResponse], error) {
 	_, err := s.clubRepo.GetByID(clubID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -65,18 +75,21 @@ func (s *EventService) GetClubEvents(clubID uint) ([]models.EventResponse, error
 		return nil, err
 	}
 
-	events, err := s.eventRepo.GetClubEvents(clubID)
+	page, err := req.Params()
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []models.EventResponse
-	for _, event := range events {
-		response := event.ToResponse()
-		responses = append(responses, response)
+	events, err := s.eventRepo.GetClubEvents(clubID, page)
+	if err != nil {
+		return nil, err
 	}
+	return eventResponsePage(events), nil
+}
 
-	return responses, nil
+func eventResponsePage(events pagination.Page[models.Event]) *pagination.Page[models.EventResponse] {
+	page := pagination.Map(events, func(e models.Event) models.EventResponse { return e.ToResponse() })
+	return &page
 }
 
 func (s *EventService) GetEventByID(id uint) (*models.EventResponse, error) {
@@ -118,6 +131,13 @@ func (s *EventService) UpdateEvent(id uint, req *models.UpdateEventRequest) (*mo
 	if req.Location != nil {
 		event.Location = *req.Location
 	}
+	if req.CityID != nil || req.DistrictID != nil {
+		cityID, districtID, err := s.locationService.ResolveLocationUpdate(event.CityID, event.DistrictID, req.CityID, req.DistrictID)
+		if err != nil {
+			return nil, err
+		}
+		event.CityID, event.DistrictID = cityID, districtID
+	}
 	if req.OnlineLink != nil {
 		event.OnlineLink = *req.OnlineLink
 	}
@@ -166,7 +186,7 @@ func (s *EventService) RSVPToEvent(id uint, rsvp *models.EventRSVP) error {
 	return s.eventRepo.RSVP(id, rsvp)
 }
 
-func (s *EventService) GetEventAttendees(eventID uint) ([]models.EventRSVP, error) {
+func (s *EventService) GetEventAttendees(eventID uint, req pagination.Request) (*pagination.Page[models.EventRSVP], error) {
 	_, err := s.eventRepo.GetByID(eventID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -174,11 +194,34 @@ func (s *EventService) GetEventAttendees(eventID uint) ([]models.EventRSVP, erro
 		}
 		return nil, err
 	}
-	return s.eventRepo.GetEventAttendees(eventID)
+
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	attendees, err := s.eventRepo.GetEventAttendees(eventID, page)
+	if err != nil {
+		return nil, err
+	}
+	return &attendees, nil
+}
+
+func (s *EventService) MarkAttendance(eventID uint, req *models.MarkAttendanceRequest) (int64, error) {
+	_, err := s.eventRepo.GetByID(eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("event not found")
+		}
+		return 0, err
+	}
+	return s.eventRepo.MarkAttendance(eventID, req.UserIDs, req.Attended)
 }
 
 func (s *EventService) refreshClubNextMeeting(clubID uint) error {
-	events, err := s.eventRepo.GetClubEvents(clubID)
+	events, err := pagination.All(func(p pagination.Params) (pagination.Page[models.Event], error) {
+		return s.eventRepo.GetClubEvents(clubID, p)
+	})
 	if err != nil {
 		return err
 	}
@@ -255,17 +298,21 @@ func (s *EventService) ClubRepo() repository.ClubRepository {
 	return s.clubRepo
 }
 
-func (s *EventService) GetPublicEvents() ([]models.EventResponse, error) {
-	events, err := s.eventRepo.GetPublicEvents()
+func (s *EventService) GetPublicEvents(cityID, districtID string, req pagination.Request) (*pagination.Page[models.EventResponse], error) {
+	if cityID != "" || districtID != "" {
+		if _, _, err := s.locationService.ResolveLocation(&cityID, &districtID); err != nil {
+			return nil, err
+		}
+	}
+
+	page, err := req.Params()
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []models.EventResponse
-	for _, event := range events {
-		response := event.ToResponse()
-		responses = append(responses, response)
+	events, err := s.eventRepo.GetPublicEvents(cityID, districtID, page)
+	if err != nil {
+		return nil, err
 	}
-
-	return responses, nil
+	return eventResponsePage(events), nil
 }
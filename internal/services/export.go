@@ -0,0 +1,221 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+const exportTimeLayout = "2006-01-02 15:04"
+
+var ErrInvalidExportType = errors.New("export type must be one of: members, attendance, reading, posts")
+
+type ExportService struct {
+	clubRepo        repository.ClubRepository
+	eventRepo       repository.EventRepository
+	clubReadingRepo repository.ClubReadingRepository
+	config          *config.Config
+}
+
+func NewExportService(
+	clubRepo repository.ClubRepository,
+	eventRepo repository.EventRepository,
+	clubReadingRepo repository.ClubReadingRepository,
+	config *config.Config,
+) *ExportService {
+	return &ExportService{
+		clubRepo:        clubRepo,
+		eventRepo:       eventRepo,
+		clubReadingRepo: clubReadingRepo,
+		config:          config,
+	}
+}
+
+// ClubExport is a validated export that has not been written yet, so callers
+// can still report errors before the response starts streaming.
+type ClubExport struct {
+	Filename string
+	excel    bool
+	write    func(cw *csv.Writer) error
+}
+
+// WriteCSV streams the export. A UTF-8 BOM is always written so spreadsheet
+// applications pick up Turkish characters; the excel format additionally uses
+// semicolons and CRLF line endings as expected by Excel in Turkish locales.
+func (e *ClubExport) WriteCSV(w io.Writer) error {
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if e.excel {
+		cw.Comma = ';'
+		cw.UseCRLF = true
+	}
+	if err := e.write(cw); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (s *ExportService) ClubExport(clubID uint, req *models.ClubExportRequest) (*ClubExport, error) {
+	club, err := s.clubRepo.GetByID(clubID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClubNotFound
+		}
+		return nil, err
+	}
+
+	export := &ClubExport{
+		Filename: fmt.Sprintf("club-%d-%s-%s.csv", club.ID, req.Type, time.Now().UTC().Format("20060102")),
+		excel:    req.Format == models.ExportFormatExcel,
+	}
+
+	switch req.Type {
+	case models.ExportTypeMembers:
+		export.write = func(cw *csv.Writer) error { return s.writeMembers(cw, clubID) }
+	case models.ExportTypeAttendance:
+		export.write = func(cw *csv.Writer) error { return s.writeAttendance(cw, clubID) }
+	case models.ExportTypeReading:
+		export.write = func(cw *csv.Writer) error { return s.writeReading(cw, clubID) }
+	case models.ExportTypePosts:
+		export.write = func(cw *csv.Writer) error { return s.writePosts(cw, clubID) }
+	default:
+		return nil, ErrInvalidExportType
+	}
+	return export, nil
+}
+
+func (s *ExportService) writeMembers(cw *csv.Writer, clubID uint) error {
+	if err := cw.Write([]string{"user_id", "username", "first_name", "last_name", "role", "joined_at"}); err != nil {
+		return err
+	}
+	return pagination.Each(func(p pagination.Params) (pagination.Page[*models.ClubMembership], error) {
+		return s.clubRepo.ListClubMembers(clubID, p)
+	}, func(members []*models.ClubMembership) error {
+		for _, m := range members {
+			if err := cw.Write(csvRow(
+				m.UserID, m.User.Username, m.User.FirstName, m.User.LastName, m.Role, m.JoinedAt,
+			)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+}
+
+// writeAttendance walks the club's events a page at a time and reads the
+// RSVPs of each page in one query.
+func (s *ExportService) writeAttendance(cw *csv.Writer, clubID uint) error {
+	if err := cw.Write([]string{"event_id", "event_title", "event_date", "user_id", "username", "rsvp_status", "attended"}); err != nil {
+		return err
+	}
+	return pagination.Each(func(p pagination.Params) (pagination.Page[models.Event], error) {
+		return s.eventRepo.GetClubEvents(clubID, p)
+	}, func(events []models.Event) error {
+		ids := make([]uint, len(events))
+		for i, e := range events {
+			ids[i] = e.ID
+		}
+		rsvps, err := s.eventRepo.ListEventsAttendees(ids)
+		if err != nil {
+			return err
+		}
+		byEvent := make(map[uint][]models.EventRSVP, len(events))
+		for _, r := range rsvps {
+			byEvent[r.EventID] = append(byEvent[r.EventID], r)
+		}
+
+		for _, e := range events {
+			for _, r := range byEvent[e.ID] {
+				if err := cw.Write(csvRow(
+					e.ID, e.Title, e.EventDate.Format("2006-01-02"), r.UserID, r.User.Username, string(r.Status), r.Attended,
+				)); err != nil {
+					return err
+				}
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+}
+
+func (s *ExportService) writeReading(cw *csv.Writer, clubID uint) error {
+	if err := cw.Write([]string{
+		"assignment_id", "book_id", "book_title", "assignment_status", "due_date",
+		"user_id", "username", "reading_status", "current_page", "percent", "started_at", "finished_at",
+	}); err != nil {
+		return err
+	}
+	return s.clubReadingRepo.EachAssignmentProgress(clubID, func(r *models.ReadingExportRow) error {
+		status := string(models.ReadingNotStarted)
+		if r.ReadingStatus != nil {
+			status = *r.ReadingStatus
+		}
+		return cw.Write(csvRow(
+			r.AssignmentID, r.BookID, r.BookTitle, r.AssignmentStatus, r.DueDate, r.UserID, r.Username,
+			status, r.CurrentPage, r.Percent, r.StartedAt, r.FinishedAt,
+		))
+	})
+}
+
+func (s *ExportService) writePosts(cw *csv.Writer, clubID uint) error {
+	if err := cw.Write([]string{"user_id", "username", "role", "posts", "comments"}); err != nil {
+		return err
+	}
+	return s.clubRepo.EachMemberPostActivity(clubID, func(m *models.MemberPostActivity) error {
+		return cw.Write(csvRow(m.UserID, m.Username, m.Role, m.Posts, m.Comments))
+	})
+}
+
+// csvRow formats values for a CSV row. Text cells starting with a formula
+// character are prefixed with a quote so spreadsheets do not evaluate them.
+func csvRow(values ...interface{}) []string {
+	row := make([]string, len(values))
+	for i, v := range values {
+		switch val := v.(type) {
+		case nil:
+			row[i] = ""
+		case string:
+			if val != "" && strings.ContainsRune("=+-@\t\r", rune(val[0])) {
+				val = "'" + val
+			}
+			row[i] = val
+		case uint:
+			row[i] = strconv.FormatUint(uint64(val), 10)
+		case int:
+			row[i] = strconv.Itoa(val)
+		case bool:
+			row[i] = strconv.FormatBool(val)
+		case time.Time:
+			row[i] = val.Format(exportTimeLayout)
+		case *time.Time:
+			if val != nil {
+				row[i] = val.Format(exportTimeLayout)
+			}
+		case *int:
+			if val != nil {
+				row[i] = strconv.Itoa(*val)
+			}
+		case *float32:
+			if val != nil {
+				row[i] = strconv.FormatFloat(float64(*val), 'f', 2, 32)
+			}
+		default:
+			row[i] = fmt.Sprint(val)
+		}
+	}
+	return row
+}
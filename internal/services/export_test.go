@@ -0,0 +1,29 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVRowEscapesFormulas(t *testing.T) {
+	count := 3
+	row := csvRow("=SUM(A1:A2)", "@cmd", "okuyucu", uint(7), &count, nil)
+	assert.Equal(t, []string{"'=SUM(A1:A2)", "'@cmd", "okuyucu", "7", "3", ""}, row)
+}
+
+func TestClubExportWriteCSV(t *testing.T) {
+	export := &ClubExport{
+		Filename: "club-1-members.csv",
+		excel:    true,
+		write: func(w *csv.Writer) error {
+			return w.Write([]string{"user_id", "username"})
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, export.WriteCSV(&buf))
+	assert.Equal(t, "\xEF\xBB\xBFuser_id;username\r\n", buf.String())
+}
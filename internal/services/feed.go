@@ -0,0 +1,437 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"go.uber.org/zap"
+)
+
+const (
+	// feedStoreTimeout bounds each round of feed store calls.
+	feedStoreTimeout = 2 * time.Second
+	// feedAffinityDays is how far back the reader's activity in a club counts
+	// towards its affinity.
+	feedAffinityDays = 90
+	// feedEngagementSaturation is the weighted engagement at which the
+	// engagement signal reaches 1.
+	feedEngagementSaturation = 100
+)
+
+// FeedCandidate is a post considered for a reader's home feed, with the
+// reader-specific signals it is ranked on.
+type FeedCandidate struct {
+	models.FeedPost
+	Reason   string
+	Affinity float64 // 0 to 1: the reader's activity in the post's club, 1 for followed authors
+	Unread   bool
+}
+
+// FeedScorer ranks home feed candidates for one reader; higher scores come
+// first.
+type FeedScorer interface {
+	Score(c *FeedCandidate, now time.Time) float64
+}
+
+// WeightedFeedScorer adds up recency, engagement, affinity and unread, each
+// between 0 and 1, by their weights.
+type WeightedFeedScorer struct {
+	RecencyHalfLife  time.Duration
+	RecencyWeight    float64
+	EngagementWeight float64
+	AffinityWeight   float64
+	UnreadWeight     float64
+}
+
+func NewWeightedFeedScorer(cfg config.FeedConfig) *WeightedFeedScorer {
+	return &WeightedFeedScorer{
+		RecencyHalfLife:  time.Duration(cfg.RecencyHalfLifeHours * float64(time.Hour)),
+		RecencyWeight:    cfg.RecencyWeight,
+		EngagementWeight: cfg.EngagementWeight,
+		AffinityWeight:   cfg.AffinityWeight,
+		UnreadWeight:     cfg.UnreadWeight,
+	}
+}
+
+func (w *WeightedFeedScorer) Score(c *FeedCandidate, now time.Time) float64 {
+	recency := 1.0
+	if w.RecencyHalfLife > 0 {
+		age := math.Max(now.Sub(c.PublishedAt).Hours(), 0)
+		recency = math.Pow(0.5, age/w.RecencyHalfLife.Hours())
+	}
+
+	// a comment takes more than a like; the log keeps one runaway post from
+	// burying everything else
+	engagement := math.Log1p(float64(c.LikesCount+2*c.CommentsCount)) / math.Log1p(feedEngagementSaturation)
+	engagement = math.Min(engagement, 1)
+
+	unread := 0.0
+	if c.Unread {
+		unread = 1
+	}
+
+	return w.RecencyWeight*recency + w.EngagementWeight*engagement + w.AffinityWeight*c.Affinity + w.UnreadWeight*unread
+}
+
+// FeedService builds home feeds. Posts in clubs of up to FanoutMaxMembers
+// approved members are pushed to the members' inboxes when published; larger
+// clubs, followed users and trending posts are read when a feed is built.
+type FeedService struct {
+	feedRepo       repository.FeedRepository
+	followRepo     repository.FollowRepository
+	store          FeedStore
+	scorer         FeedScorer
+	spoilerService *SpoilerService
+	reshares       *ReshareService
+	config         *config.Config
+	fanout         bool
+}
+
+func NewFeedService(feedRepo repository.FeedRepository, followRepo repository.FollowRepository, store FeedStore, scorer FeedScorer, spoilerService *SpoilerService, reshares *ReshareService, config *config.Config) *FeedService {
+	return &FeedService{
+		feedRepo:       feedRepo,
+		followRepo:     followRepo,
+		store:          store,
+		scorer:         scorer,
+		spoilerService: spoilerService,
+		reshares:       reshares,
+		config:         config,
+		fanout:         true,
+	}
+}
+
+// DisableFanout reads every club when a feed is built instead of relying on
+// inboxes. Use it when the store is not shared between server instances; it
+// is not safe to call once the server is handling requests.
+func (s *FeedService) DisableFanout() {
+	s.fanout = false
+}
+
+// PostPublished pushes a post in a small club to the inboxes of its members.
+// Failures are logged, and the post is then missing from those feeds.
+func (s *FeedService) PostPublished(post *models.Post, scheduled bool) {
+	if !s.fanout {
+		return
+	}
+
+	memberIDs, err := s.feedRepo.ListMemberIDs(post.ClubID)
+	if err != nil {
+		logger.Warn("failed to list club members for feed fan-out", zap.Uint("post_id", post.ID), zap.Error(err))
+		return
+	}
+	if len(memberIDs) > s.config.Feed.FanoutMaxMembers {
+		return
+	}
+
+	recipients := make([]uint, 0, len(memberIDs))
+	for _, id := range memberIDs {
+		if id != post.UserID {
+			recipients = append(recipients, id)
+		}
+	}
+
+	at := post.CreatedAt
+	if post.PublishedAt != nil {
+		at = *post.PublishedAt
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), feedStoreTimeout)
+	defer cancel()
+	if err := s.store.Push(ctx, recipients, post.ID, at); err != nil {
+		logger.Warn("failed to fan out post to feeds", zap.Uint("post_id", post.ID), zap.Error(err))
+	}
+}
+
+// HomeFeed returns a page of userID's home feed. The first page reuses the
+// cached ranking unless req.Refresh is set; later pages are cut from the
+// ranking their cursor was issued for, so posts neither repeat nor move
+// between pages. If that ranking expired, paging continues in a fresh one
+// after the last post the client was shown.
+func (s *FeedService) HomeFeed(userID uint, req *models.HomeFeedRequest, now time.Time) (*pagination.Page[models.FeedItem], error) {
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), feedStoreTimeout)
+	defer cancel()
+
+	var version int64
+	offset := 0
+	if page.After != nil {
+		if version, offset, err = parseFeedCursor(page.After); err != nil {
+			return nil, err
+		}
+	}
+
+	var feed *RankedFeed
+	if page.After != nil || !req.Refresh {
+		if feed, err = s.store.LoadRanked(ctx, userID); err != nil {
+			logger.Warn("failed to load cached feed", zap.Uint("user_id", userID), zap.Error(err))
+		}
+	}
+	if feed == nil || (page.After != nil && feed.Version != version) {
+		if feed, err = s.rebuild(ctx, userID, now); err != nil {
+			return nil, err
+		}
+		if page.After != nil {
+			offset = resumeOffset(feed, page.After.ID, offset)
+		}
+	}
+
+	end := min(offset+page.Size(), len(feed.Items))
+	ranked := feed.Items[min(offset, end):end]
+
+	items, err := s.loadItems(ranked, userID, req.RevealSpoilers)
+	if err != nil {
+		return nil, err
+	}
+
+	shown := make([]uint, 0, len(items))
+	for _, item := range items {
+		shown = append(shown, item.Post.ID)
+	}
+	if err := s.store.MarkSeen(ctx, userID, shown, now); err != nil {
+		logger.Warn("failed to mark feed posts seen", zap.Uint("user_id", userID), zap.Error(err))
+	}
+
+	out := &pagination.Page[models.FeedItem]{Items: items}
+	if end < len(feed.Items) {
+		out.HasMore = true
+		out.NextCursor = pagination.CursorFor(ranked[len(ranked)-1].PostID, feed.Version, end).Encode()
+	}
+	return out, nil
+}
+
+func parseFeedCursor(c *pagination.Cursor) (int64, int, error) {
+	if len(c.Values) != 2 {
+		return 0, 0, pagination.ErrInvalidCursor
+	}
+	version, err := strconv.ParseInt(c.Values[0], 10, 64)
+	if err != nil {
+		return 0, 0, pagination.ErrInvalidCursor
+	}
+	offset, err := strconv.Atoi(c.Values[1])
+	if err != nil || offset < 0 {
+		return 0, 0, pagination.ErrInvalidCursor
+	}
+	return version, offset, nil
+}
+
+// resumeOffset finds where to continue in a new ranking: right after lastID
+// if it is still ranked, otherwise at the old offset.
+func resumeOffset(feed *RankedFeed, lastID uint, offset int) int {
+	for i, item := range feed.Items {
+		if item.PostID == lastID {
+			return i + 1
+		}
+	}
+	return min(offset, len(feed.Items))
+}
+
+func (s *FeedService) rebuild(ctx context.Context, userID uint, now time.Time) (*RankedFeed, error) {
+	candidates, err := s.collect(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &RankedFeed{
+		Version: now.UnixNano(),
+		Items:   rankFeed(candidates, s.scorer, now, s.config.Feed.MaxItems),
+	}
+	ttl := time.Duration(s.config.Feed.CacheTTLSeconds) * time.Second
+	if err := s.store.SaveRanked(ctx, userID, feed, ttl); err != nil {
+		logger.Warn("failed to cache feed", zap.Uint("user_id", userID), zap.Error(err))
+	}
+	return feed, nil
+}
+
+// collect gathers the candidates for userID's feed, deduplicated, with their
+// reader-specific signals filled in.
+func (s *FeedService) collect(ctx context.Context, userID uint, now time.Time) ([]*FeedCandidate, error) {
+	cfg := s.config.Feed
+	since := now.Add(-time.Duration(cfg.WindowHours) * time.Hour)
+
+	clubs, err := s.feedRepo.ListMemberClubs(userID)
+	if err != nil {
+		return nil, err
+	}
+	memberOf := make(map[uint]bool, len(clubs))
+	var fanIn []uint
+	for _, c := range clubs {
+		memberOf[c.ClubID] = true
+		if !s.fanout || c.MembersCount > cfg.FanoutMaxMembers {
+			fanIn = append(fanIn, c.ClubID)
+		}
+	}
+
+	var (
+		candidates []*FeedCandidate
+		byID       = map[uint]*FeedCandidate{}
+	)
+	// sources are added in models.FeedReason* order, so a post found by
+	// several keeps the first reason
+	add := func(posts []models.FeedPost, reason string) {
+		for _, p := range posts {
+			if p.AuthorID == userID || byID[p.PostID] != nil {
+				continue
+			}
+			c := &FeedCandidate{FeedPost: p, Reason: reason}
+			byID[p.PostID] = c
+			candidates = append(candidates, c)
+		}
+	}
+
+	if s.fanout {
+		ids, err := s.store.Inbox(ctx, userID, since)
+		if err != nil {
+			return nil, err
+		}
+		posts, err := s.feedRepo.GetFeedPosts(ids)
+		if err != nil {
+			return nil, err
+		}
+		// a reader who left a club keeps its posts in their inbox until they
+		// age out
+		current := posts[:0]
+		for _, p := range posts {
+			if memberOf[p.ClubID] {
+				current = append(current, p)
+			}
+		}
+		add(current, models.FeedReasonClub)
+	}
+
+	clubPosts, err := s.feedRepo.ListClubPosts(fanIn, since, cfg.SourceLimit)
+	if err != nil {
+		return nil, err
+	}
+	add(clubPosts, models.FeedReasonClub)
+
+	followees, err := s.followRepo.ListFolloweeIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	followedPosts, err := s.feedRepo.ListAuthorPosts(followees, userID, since, cfg.SourceLimit)
+	if err != nil {
+		return nil, err
+	}
+	add(followedPosts, models.FeedReasonFollowing)
+
+	trending, err := s.feedRepo.ListTrendingPosts(since, cfg.SourceLimit)
+	if err != nil {
+		return nil, err
+	}
+	add(trending, models.FeedReasonTrending)
+
+	activity, err := s.feedRepo.CountClubActivity(userID, now.AddDate(0, 0, -feedAffinityDays))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.PostID)
+	}
+	seen, err := s.store.Seen(ctx, userID, ids)
+	if err != nil {
+		logger.Warn("failed to load seen feed posts", zap.Uint("user_id", userID), zap.Error(err))
+		seen = map[uint]bool{}
+	}
+
+	followed := make(map[uint]bool, len(followees))
+	for _, id := range followees {
+		followed[id] = true
+	}
+	fillFeedSignals(candidates, activity, followed, seen)
+	return candidates, nil
+}
+
+// fillFeedSignals sets the affinity and unread signals. Affinity is the
+// reader's activity in the post's club relative to their most active club,
+// on a log scale; posts by followed authors get full affinity.
+func fillFeedSignals(candidates []*FeedCandidate, activity map[uint]int, followed, seen map[uint]bool) {
+	most := 0
+	for _, n := range activity {
+		most = max(most, n)
+	}
+	for _, c := range candidates {
+		switch {
+		case followed[c.AuthorID]:
+			c.Affinity = 1
+		case most > 0:
+			c.Affinity = math.Log1p(float64(activity[c.ClubID])) / math.Log1p(float64(most))
+		}
+		c.Unread = !seen[c.PostID]
+	}
+}
+
+// rankFeed orders candidates by score, newest post first on ties, and keeps
+// at most limit.
+func rankFeed(candidates []*FeedCandidate, scorer FeedScorer, now time.Time, limit int) []RankedItem {
+	scores := make(map[uint]float64, len(candidates))
+	for _, c := range candidates {
+		scores[c.PostID] = scorer.Score(c, now)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if scores[a.PostID] != scores[b.PostID] {
+			return scores[a.PostID] > scores[b.PostID]
+		}
+		return a.PostID > b.PostID
+	})
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	items := make([]RankedItem, 0, len(candidates))
+	for _, c := range candidates {
+		items = append(items, RankedItem{PostID: c.PostID, Reason: c.Reason})
+	}
+	return items
+}
+
+// loadItems loads the ranked posts for display, in ranking order. Posts
+// deleted or unpublished since the ranking was built are skipped.
+func (s *FeedService) loadItems(ranked []RankedItem, viewerID uint, revealSpoilers bool) ([]models.FeedItem, error) {
+	ids := make([]uint, 0, len(ranked))
+	for _, item := range ranked {
+		ids = append(ids, item.PostID)
+	}
+	posts, err := s.feedRepo.GetPostsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*models.Post, len(posts))
+	for i := range posts {
+		byID[posts[i].ID] = &posts[i]
+	}
+
+	responses := make([]models.PostResponse, 0, len(ranked))
+	reasons := make([]string, 0, len(ranked))
+	for _, item := range ranked {
+		if post, ok := byID[item.PostID]; ok {
+			responses = append(responses, post.ToResponse())
+			reasons = append(reasons, item.Reason)
+		}
+	}
+	if err := s.spoilerService.GatePosts(&viewerID, responses, revealSpoilers); err != nil {
+		return nil, err
+	}
+	if err := s.reshares.EmbedPosts(&viewerID, responses, revealSpoilers); err != nil {
+		return nil, err
+	}
+
+	items := make([]models.FeedItem, 0, len(responses))
+	for i := range responses {
+		items = append(items, models.FeedItem{Post: responses[i], Reason: reasons[i]})
+	}
+	return items, nil
+}
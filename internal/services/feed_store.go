@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// feedInboxSize caps how many fanned-out posts an inbox keeps; older ones
+	// fall off the end.
+	feedInboxSize = 500
+	// feedSeenSize caps how many shown posts are remembered per user for the
+	// unread signal.
+	feedSeenSize = 1000
+)
+
+// RankedFeed is a home feed as it was ranked at Version (unix nanoseconds).
+// Pages are cut from it until it expires, so a reader paging through it
+// neither sees posts twice nor has them reshuffled under them.
+type RankedFeed struct {
+	Version int64        `json:"v"`
+	Items   []RankedItem `json:"items"`
+}
+
+type RankedItem struct {
+	PostID uint   `json:"p"`
+	Reason string `json:"r"`
+}
+
+// FeedStore keeps the per-user state of home feeds: the inbox posts are
+// fanned out to, the cached ranked feed, and which posts were already shown.
+type FeedStore interface {
+	// Push adds postID, published at, to the inboxes of userIDs.
+	Push(ctx context.Context, userIDs []uint, postID uint, at time.Time) error
+	// Inbox returns the posts in userID's inbox published at or after since.
+	Inbox(ctx context.Context, userID uint, since time.Time) ([]uint, error)
+	// SaveRanked caches userID's ranked feed for ttl.
+	SaveRanked(ctx context.Context, userID uint, feed *RankedFeed, ttl time.Duration) error
+	// LoadRanked returns the cached ranked feed, or nil if it expired.
+	LoadRanked(ctx context.Context, userID uint) (*RankedFeed, error)
+	// MarkSeen remembers that postIDs were shown to userID.
+	MarkSeen(ctx context.Context, userID uint, postIDs []uint, at time.Time) error
+	// Seen reports which of postIDs were already shown to userID.
+	Seen(ctx context.Context, userID uint, postIDs []uint) (map[uint]bool, error)
+}
+
+type feedStamp struct {
+	postID uint
+	at     time.Time
+}
+
+// keepNewest sorts stamps newest first and drops everything past max.
+func keepNewest(stamps []feedStamp, max int) []feedStamp {
+	sort.Slice(stamps, func(i, j int) bool { return stamps[i].at.After(stamps[j].at) })
+	if len(stamps) > max {
+		stamps = stamps[:max]
+	}
+	return stamps
+}
+
+type memoryRankedFeed struct {
+	feed    *RankedFeed
+	expires time.Time
+}
+
+// memoryFeedStore keeps feed state in process. It is used when Redis is not
+// available; the server then turns fan-out off, since an inbox in one process
+// would miss posts published through another.
+type memoryFeedStore struct {
+	mu     sync.Mutex
+	inbox  map[uint][]feedStamp
+	seen   map[uint][]feedStamp
+	ranked map[uint]memoryRankedFeed
+}
+
+func NewMemoryFeedStore() *memoryFeedStore {
+	return &memoryFeedStore{
+		inbox:  map[uint][]feedStamp{},
+		seen:   map[uint][]feedStamp{},
+		ranked: map[uint]memoryRankedFeed{},
+	}
+}
+
+func (m *memoryFeedStore) Push(_ context.Context, userIDs []uint, postID uint, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, userID := range userIDs {
+		m.inbox[userID] = keepNewest(append(m.inbox[userID], feedStamp{postID: postID, at: at}), feedInboxSize)
+	}
+	return nil
+}
+
+func (m *memoryFeedStore) Inbox(_ context.Context, userID uint, since time.Time) ([]uint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ids []uint
+	for _, s := range m.inbox[userID] {
+		if !s.at.Before(since) {
+			ids = append(ids, s.postID)
+		}
+	}
+	return ids, nil
+}
+
+func (m *memoryFeedStore) SaveRanked(_ context.Context, userID uint, feed *RankedFeed, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ranked[userID] = memoryRankedFeed{feed: feed, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (m *memoryFeedStore) LoadRanked(_ context.Context, userID uint) (*RankedFeed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cached, ok := m.ranked[userID]
+	if !ok {
+		return nil, nil
+	}
+	if time.Now().After(cached.expires) {
+		delete(m.ranked, userID)
+		return nil, nil
+	}
+	return cached.feed, nil
+}
+
+func (m *memoryFeedStore) MarkSeen(_ context.Context, userID uint, postIDs []uint, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seen := m.seen[userID]
+	for _, id := range postIDs {
+		seen = append(seen, feedStamp{postID: id, at: at})
+	}
+	m.seen[userID] = keepNewest(seen, feedSeenSize)
+	return nil
+}
+
+func (m *memoryFeedStore) Seen(_ context.Context, userID uint, postIDs []uint) (map[uint]bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wanted := make(map[uint]bool, len(postIDs))
+	for _, id := range postIDs {
+		wanted[id] = true
+	}
+	seen := map[uint]bool{}
+	for _, s := range m.seen[userID] {
+		if wanted[s.postID] {
+			seen[s.postID] = true
+		}
+	}
+	return seen, nil
+}
+
+const (
+	redisFeedInboxPrefix  = "feed:inbox:"
+	redisFeedSeenPrefix   = "feed:seen:"
+	redisFeedRankedPrefix = "feed:ranked:"
+)
+
+// redisFeedStore keeps inboxes and seen posts in sorted sets scored by time,
+// and ranked feeds as JSON strings.
+type redisFeedStore struct {
+	rdb    *redis.Client
+	window time.Duration // inboxes and seen sets idle this long expire
+}
+
+func NewRedisFeedStore(rdb *redis.Client, window time.Duration) *redisFeedStore {
+	return &redisFeedStore{rdb: rdb, window: window}
+}
+
+// pushNewest adds members to a sorted set, trims it to its max newest
+// members and refreshes its expiry.
+func (r *redisFeedStore) pushNewest(ctx context.Context, pipe redis.Pipeliner, key string, members []redis.Z, max int) {
+	pipe.ZAdd(ctx, key, members...)
+	pipe.ZRemRangeByRank(ctx, key, 0, int64(-max-1))
+	pipe.Expire(ctx, key, r.window)
+}
+
+func (r *redisFeedStore) Push(ctx context.Context, userIDs []uint, postID uint, at time.Time) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	member := []redis.Z{{Score: float64(at.Unix()), Member: postID}}
+	pipe := r.rdb.Pipeline()
+	for _, userID := range userIDs {
+		r.pushNewest(ctx, pipe, fmt.Sprintf("%s%d", redisFeedInboxPrefix, userID), member, feedInboxSize)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *redisFeedStore) Inbox(ctx context.Context, userID uint, since time.Time) ([]uint, error) {
+	members, err := r.rdb.ZRangeByScore(ctx, fmt.Sprintf("%s%d", redisFeedInboxPrefix, userID), &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return parseFeedMembers(members), nil
+}
+
+func (r *redisFeedStore) SaveRanked(ctx context.Context, userID uint, feed *RankedFeed, ttl time.Duration) error {
+	raw, err := json.Marshal(feed)
+	if err != nil {
+		return err
+	}
+	return r.rdb.Set(ctx, fmt.Sprintf("%s%d", redisFeedRankedPrefix, userID), raw, ttl).Err()
+}
+
+func (r *redisFeedStore) LoadRanked(ctx context.Context, userID uint) (*RankedFeed, error) {
+	raw, err := r.rdb.Get(ctx, fmt.Sprintf("%s%d", redisFeedRankedPrefix, userID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var feed RankedFeed
+	if err := json.Unmarshal(raw, &feed); err != nil {
+		// an entry written by an older version; rebuild it
+		return nil, nil
+	}
+	return &feed, nil
+}
+
+func (r *redisFeedStore) MarkSeen(ctx context.Context, userID uint, postIDs []uint, at time.Time) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+	members := make([]redis.Z, 0, len(postIDs))
+	for _, id := range postIDs {
+		members = append(members, redis.Z{Score: float64(at.Unix()), Member: id})
+	}
+	pipe := r.rdb.Pipeline()
+	r.pushNewest(ctx, pipe, fmt.Sprintf("%s%d", redisFeedSeenPrefix, userID), members, feedSeenSize)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *redisFeedStore) Seen(ctx context.Context, userID uint, postIDs []uint) (map[uint]bool, error) {
+	seen := map[uint]bool{}
+	if len(postIDs) == 0 {
+		return seen, nil
+	}
+	key := fmt.Sprintf("%s%d", redisFeedSeenPrefix, userID)
+	pipe := r.rdb.Pipeline()
+	scores := make([]*redis.FloatCmd, len(postIDs))
+	for i, id := range postIDs {
+		scores[i] = pipe.ZScore(ctx, key, strconv.FormatUint(uint64(id), 10))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	for i, cmd := range scores {
+		if cmd.Err() == nil {
+			seen[postIDs[i]] = true
+		}
+	}
+	return seen, nil
+}
+
+func parseFeedMembers(members []string) []uint {
+	ids := make([]uint, 0, len(members))
+	for _, m := range members {
+		if id, err := strconv.ParseUint(m, 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
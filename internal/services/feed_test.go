@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFeedScorer() *WeightedFeedScorer {
+	return NewWeightedFeedScorer(config.FeedConfig{
+		RecencyHalfLifeHours: 24,
+		RecencyWeight:        1,
+		EngagementWeight:     0.5,
+		AffinityWeight:       0.5,
+		UnreadWeight:         0.3,
+	})
+}
+
+func feedCandidate(id uint, publishedAt time.Time) *FeedCandidate {
+	return &FeedCandidate{FeedPost: models.FeedPost{PostID: id, ClubID: 1, AuthorID: 1, PublishedAt: publishedAt}}
+}
+
+func TestWeightedFeedScorer(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	scorer := testFeedScorer()
+
+	fresh := feedCandidate(1, now)
+	dayOld := feedCandidate(2, now.Add(-24*time.Hour))
+	assert.InDelta(t, 1.0, scorer.Score(fresh, now), 1e-9)
+	assert.InDelta(t, 0.5, scorer.Score(dayOld, now), 1e-9, "one half-life halves recency")
+
+	dayOld.LikesCount = 80
+	dayOld.CommentsCount = 10
+	assert.InDelta(t, 1.0, scorer.Score(dayOld, now), 1e-9, "engagement saturates")
+
+	fresh.Unread = true
+	fresh.Affinity = 1
+	assert.InDelta(t, 1.8, scorer.Score(fresh, now), 1e-9)
+
+	future := feedCandidate(3, now.Add(time.Hour))
+	assert.InDelta(t, 1.0, scorer.Score(future, now), 1e-9, "clock skew does not boost recency")
+}
+
+func TestRankFeed(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	old := feedCandidate(1, now.Add(-48*time.Hour))
+	tieLow := feedCandidate(2, now.Add(-time.Hour))
+	tieHigh := feedCandidate(3, now.Add(-time.Hour))
+	newest := feedCandidate(4, now)
+	newest.Reason = models.FeedReasonFollowing
+
+	ranked := rankFeed([]*FeedCandidate{old, tieLow, newest, tieHigh}, testFeedScorer(), now, 3)
+	assert.Equal(t, []RankedItem{
+		{PostID: 4, Reason: models.FeedReasonFollowing},
+		{PostID: 3},
+		{PostID: 2},
+	}, ranked)
+}
+
+func TestFillFeedSignals(t *testing.T) {
+	now := time.Now()
+	busy := feedCandidate(1, now)
+	busy.ClubID = 10
+	quiet := feedCandidate(2, now)
+	quiet.ClubID = 20
+	idle := feedCandidate(3, now)
+	idle.ClubID = 30
+	followed := feedCandidate(4, now)
+	followed.ClubID = 30
+	followed.AuthorID = 7
+
+	fillFeedSignals([]*FeedCandidate{busy, quiet, idle, followed},
+		map[uint]int{10: 9, 20: 1},
+		map[uint]bool{7: true},
+		map[uint]bool{1: true})
+
+	assert.InDelta(t, 1.0, busy.Affinity, 1e-9)
+	assert.Greater(t, quiet.Affinity, 0.0)
+	assert.Less(t, quiet.Affinity, 0.5)
+	assert.Zero(t, idle.Affinity)
+	assert.Equal(t, 1.0, followed.Affinity)
+	assert.False(t, busy.Unread)
+	assert.True(t, quiet.Unread)
+}
+
+func TestMemoryFeedStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryFeedStore()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, store.Push(ctx, []uint{1, 2}, 100, at.Add(-48*time.Hour)))
+	require.NoError(t, store.Push(ctx, []uint{1}, 101, at))
+	ids, err := store.Inbox(ctx, 1, at.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []uint{101}, ids)
+	ids, _ = store.Inbox(ctx, 2, at.Add(-72*time.Hour))
+	assert.Equal(t, []uint{100}, ids)
+
+	require.NoError(t, store.MarkSeen(ctx, 1, []uint{100, 101}, at))
+	seen, err := store.Seen(ctx, 1, []uint{101, 102})
+	require.NoError(t, err)
+	assert.Equal(t, map[uint]bool{101: true}, seen)
+
+	feed := &RankedFeed{Version: 1, Items: []RankedItem{{PostID: 101}}}
+	require.NoError(t, store.SaveRanked(ctx, 1, feed, time.Minute))
+	loaded, err := store.LoadRanked(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, feed, loaded)
+
+	require.NoError(t, store.SaveRanked(ctx, 2, feed, -time.Second))
+	loaded, err = store.LoadRanked(ctx, 2)
+	require.NoError(t, err)
+	assert.Nil(t, loaded, "expired rankings are rebuilt")
+}
+
+func TestMemoryFeedStore_TrimsInbox(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryFeedStore()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < feedInboxSize+10; i++ {
+		require.NoError(t, store.Push(ctx, []uint{1}, uint(i+1), at.Add(time.Duration(i)*time.Second)))
+	}
+	ids, _ := store.Inbox(ctx, 1, time.Time{})
+	require.Len(t, ids, feedInboxSize)
+	assert.Equal(t, uint(feedInboxSize+10), ids[0], "the newest posts are kept")
+}
+
+func TestFeedCursor(t *testing.T) {
+	valid := pagination.CursorFor(7, int64(42), 20)
+	version, offset, err := parseFeedCursor(&valid)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), version)
+	assert.Equal(t, 20, offset)
+
+	for _, bad := range []pagination.Cursor{
+		pagination.CursorFor(7, "x", 20),
+		pagination.CursorFor(7, int64(42), -1),
+		pagination.CursorFor(7, int64(42)),
+	} {
+		_, _, err = parseFeedCursor(&bad)
+		assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+	}
+
+	feed := &RankedFeed{Items: []RankedItem{{PostID: 5}, {PostID: 7}, {PostID: 9}}}
+	assert.Equal(t, 2, resumeOffset(feed, 7, 1), "continue after the last post shown")
+	assert.Equal(t, 1, resumeOffset(feed, 8, 1), "fall back to the old offset")
+	assert.Equal(t, 3, resumeOffset(feed, 8, 10))
+}
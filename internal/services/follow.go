@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCannotFollowSelf = errors.New("you cannot follow yourself")
+	ErrFollowBlocked    = errors.New("this user has blocked you")
+	ErrFollowNotFound   = errors.New("user is not followed")
+)
+
+type FollowService struct {
+	followRepo repository.FollowRepository
+	blockRepo  repository.BlockRepository
+	userRepo   repository.UserRepository
+}
+
+func NewFollowService(followRepo repository.FollowRepository, blockRepo repository.BlockRepository, userRepo repository.UserRepository) *FollowService {
+	return &FollowService{
+		followRepo: followRepo,
+		blockRepo:  blockRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// FollowUser adds followeeID's posts to followerID's home feed. Their
+// already cached feed picks the posts up when it is next rebuilt.
+func (s *FollowService) FollowUser(followerID, followeeID uint) error {
+	if followerID == followeeID {
+		return ErrCannotFollowSelf
+	}
+	if _, err := s.userRepo.GetByID(followeeID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		}
+		return err
+	}
+	blockers, err := s.blockRepo.ListBlockersOf(followerID, []uint{followeeID})
+	if err != nil {
+		return err
+	}
+	if len(blockers) > 0 {
+		return ErrFollowBlocked
+	}
+	return s.followRepo.Follow(followerID, followeeID)
+}
+
+func (s *FollowService) UnfollowUser(followerID, followeeID uint) error {
+	removed, err := s.followRepo.Unfollow(followerID, followeeID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrFollowNotFound
+	}
+	return nil
+}
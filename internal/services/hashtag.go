@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/utils"
+)
+
+const (
+	// maxHashtagsPerPost caps how many distinct tags one post is indexed
+	// under; further tags stay in the text but are not searchable.
+	maxHashtagsPerPost   = 10
+	defaultTrendingLimit = 10
+)
+
+var ErrInvalidHashtag = errors.New("invalid hashtag")
+
+type HashtagService struct {
+	hashtagRepo    repository.HashtagRepository
+	spoilerService *SpoilerService
+	reshares       *ReshareService
+	config         *config.Config
+}
+
+func NewHashtagService(hashtagRepo repository.HashtagRepository, spoilerService *SpoilerService, reshares *ReshareService, config *config.Config) *HashtagService {
+	return &HashtagService{
+		hashtagRepo:    hashtagRepo,
+		spoilerService: spoilerService,
+		reshares:       reshares,
+		config:         config,
+	}
+}
+
+// SyncPostHashtags indexes the tags in the post's content, replacing what
+// was stored before.
+func (s *HashtagService) SyncPostHashtags(post *models.Post) error {
+	tags := utils.Hashtags(post.Content)
+	if len(tags) > maxHashtagsPerPost {
+		tags = tags[:maxHashtagsPerPost]
+	}
+	return s.hashtagRepo.Replace(post.ID, post.ClubID, tags)
+}
+
+// ListPostsByTag returns the published posts using tag that viewerID can
+// read. tag may be given with or without the leading '#' and in any case.
+func (s *HashtagService) ListPostsByTag(tag string, viewerID *uint, req *models.ListHashtagPostsRequest) (*models.HashtagPostsResponse, error) {
+	normalized := utils.NormalizeHashtag(tag)
+	if tags := utils.Hashtags("#" + normalized); len(tags) != 1 || tags[0] != normalized {
+		return nil, ErrInvalidHashtag
+	}
+
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.hashtagRepo.ListPostsByTag(normalized, viewerID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	out := postResponsePage(posts)
+	if err := s.spoilerService.GatePosts(viewerID, out.Items, req.RevealSpoilers); err != nil {
+		return nil, err
+	}
+	if err := s.reshares.EmbedPosts(viewerID, out.Items, req.RevealSpoilers); err != nil {
+		return nil, err
+	}
+	return &models.HashtagPostsResponse{Tag: normalized, Page: *out}, nil
+}
+
+// TrendingHashtags ranks the tags used in public clubs over the configured
+// window. Each use adds 0.5^(age/half-life), so a burst of recent posts
+// outranks a steady trickle from days ago.
+func (s *HashtagService) TrendingHashtags(req *models.TrendingHashtagsRequest, now time.Time) (*models.TrendingHashtagsResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultTrendingLimit
+	}
+
+	window := time.Duration(s.config.Hashtags.TrendingWindowHours) * time.Hour
+	uses, err := s.hashtagRepo.ListRecentUses(now.Add(-window))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TrendingHashtagsResponse{
+		Hashtags:    rankHashtags(uses, now, s.config.Hashtags.TrendingHalfLifeHours, limit),
+		WindowHours: s.config.Hashtags.TrendingWindowHours,
+	}, nil
+}
+
+func rankHashtags(uses []models.HashtagUse, now time.Time, halfLifeHours float64, limit int) []models.TrendingHashtag {
+	byTag := map[string]*models.TrendingHashtag{}
+	for _, use := range uses {
+		t, ok := byTag[use.Tag]
+		if !ok {
+			t = &models.TrendingHashtag{Tag: use.Tag}
+			byTag[use.Tag] = t
+		}
+		age := math.Max(now.Sub(use.PublishedAt).Hours(), 0)
+		weight := 1.0
+		if halfLifeHours > 0 {
+			weight = math.Pow(0.5, age/halfLifeHours)
+		}
+		t.Score += weight
+		t.PostCount++
+	}
+
+	ranked := make([]models.TrendingHashtag, 0, len(byTag))
+	for _, t := range byTag {
+		t.Score = math.Round(t.Score*1000) / 1000
+		ranked = append(ranked, *t)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Tag < ranked[j].Tag
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
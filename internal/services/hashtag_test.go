@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankHashtags_DecaysOlderUses(t *testing.T) {
+	now := time.Now()
+	uses := []models.HashtagUse{
+		{Tag: "eski", PublishedAt: now.Add(-48 * time.Hour)},
+		{Tag: "eski", PublishedAt: now.Add(-48 * time.Hour)},
+		{Tag: "eski", PublishedAt: now.Add(-48 * time.Hour)},
+		{Tag: "yeni", PublishedAt: now},
+		{Tag: "orta", PublishedAt: now.Add(-12 * time.Hour)},
+	}
+
+	ranked := rankHashtags(uses, now, 12, 10)
+
+	assert.Equal(t, []models.TrendingHashtag{
+		{Tag: "yeni", Score: 1, PostCount: 1},
+		{Tag: "orta", Score: 0.5, PostCount: 1},
+		{Tag: "eski", Score: 0.188, PostCount: 3},
+	}, ranked)
+}
+
+func TestRankHashtags_Limit(t *testing.T) {
+	now := time.Now()
+	uses := []models.HashtagUse{
+		{Tag: "b", PublishedAt: now},
+		{Tag: "a", PublishedAt: now},
+		{Tag: "c", PublishedAt: now.Add(-time.Hour)},
+	}
+
+	ranked := rankHashtags(uses, now, 12, 2)
+
+	assert.Len(t, ranked, 2)
+	assert.Equal(t, "a", ranked[0].Tag, "ties are broken alphabetically")
+	assert.Equal(t, "b", ranked[1].Tag)
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// hotRefreshBatchSize is how many posts a refresh scores per query.
+const hotRefreshBatchSize = 500
+
+// PostEngagementListener is told when the likes, reactions, comments or
+// views of posts change.
+type PostEngagementListener interface {
+	PostEngaged(postIDs []uint)
+}
+
+// engagementListeners is embedded by the services that change engagement
+// counts.
+type engagementListeners struct {
+	listeners []PostEngagementListener
+}
+
+// AddEngagementListener registers a listener for engagement changes. It is
+// not safe to call once the service is handling requests.
+func (e *engagementListeners) AddEngagementListener(listener PostEngagementListener) {
+	e.listeners = append(e.listeners, listener)
+}
+
+func (e *engagementListeners) notifyEngaged(postIDs ...uint) {
+	if len(postIDs) == 0 {
+		return
+	}
+	for _, listener := range e.listeners {
+		listener.PostEngaged(postIDs)
+	}
+}
+
+// HotScorer scores posts by weighted engagement divided by (age in hours +
+// 2) to the power of Gravity, so a post needs ever more engagement to stay on
+// top as it ages.
+type HotScorer struct {
+	Gravity        float64
+	LikeWeight     float64
+	CommentWeight  float64
+	ReactionWeight float64
+	ViewWeight     float64
+}
+
+func NewHotScorer(cfg config.HotConfig) *HotScorer {
+	return &HotScorer{
+		Gravity:        cfg.Gravity,
+		LikeWeight:     cfg.LikeWeight,
+		CommentWeight:  cfg.CommentWeight,
+		ReactionWeight: cfg.ReactionWeight,
+		ViewWeight:     cfg.ViewWeight,
+	}
+}
+
+func (h *HotScorer) Score(e models.PostEngagement, now time.Time) float64 {
+	points := h.LikeWeight*float64(e.LikesCount) +
+		h.CommentWeight*float64(e.CommentsCount) +
+		h.ReactionWeight*float64(e.ReactionsCount) +
+		h.ViewWeight*float64(e.ViewsCount)
+	age := math.Max(now.Sub(e.PublishedAt).Hours(), 0)
+	return points / math.Pow(age+2, h.Gravity)
+}
+
+// HotRankService keeps the stored hot scores of posts current. A post is
+// rescored when its engagement changes, and every recent post is rescored by
+// HotScoreRefresher, since scores decay even when nothing happens. Scores
+// computed at different times are compared as they are; the refresh interval
+// bounds the skew.
+type HotRankService struct {
+	postRepo repository.PostRepository
+	scorer   *HotScorer
+	config   *config.Config
+}
+
+func NewHotRankService(postRepo repository.PostRepository, scorer *HotScorer, config *config.Config) *HotRankService {
+	return &HotRankService{
+		postRepo: postRepo,
+		scorer:   scorer,
+		config:   config,
+	}
+}
+
+// PostEngaged rescores posts whose engagement changed. Failures are logged;
+// the next refresh catches up.
+func (s *HotRankService) PostEngaged(postIDs []uint) {
+	engagement, err := s.postRepo.GetEngagement(postIDs)
+	if err == nil {
+		err = s.postRepo.UpdateHotScores(s.score(engagement, time.Now()))
+	}
+	if err != nil {
+		logger.Warn("failed to update hot scores", zap.Uints("post_ids", postIDs), zap.Error(err))
+	}
+}
+
+// Refresh rescores every post published within the hot window and zeroes the
+// scores of posts that dropped out of it. It returns how many posts it
+// rescored.
+func (s *HotRankService) Refresh(now time.Time) (int, error) {
+	since := now.AddDate(0, 0, -s.config.Hot.WindowDays)
+	if _, err := s.postRepo.ClearHotScoresBefore(since); err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	var afterID uint
+	for {
+		batch, err := s.postRepo.ListEngagementSince(since, afterID, hotRefreshBatchSize)
+		if err != nil {
+			return refreshed, err
+		}
+		if len(batch) == 0 {
+			return refreshed, nil
+		}
+		if err := s.postRepo.UpdateHotScores(s.score(batch, now)); err != nil {
+			return refreshed, err
+		}
+		refreshed += len(batch)
+		afterID = batch[len(batch)-1].PostID
+	}
+}
+
+func (s *HotRankService) score(engagement []models.PostEngagement, now time.Time) map[uint]float64 {
+	scores := make(map[uint]float64, len(engagement))
+	for _, e := range engagement {
+		scores[e.PostID] = s.scorer.Score(e, now)
+	}
+	return scores
+}
+
+// HotScoreRefresher periodically rescores recent posts so hot scores keep
+// decaying between engagements. Several server instances may run one; they
+// only repeat each other's work.
+type HotScoreRefresher struct {
+	hotRankService *HotRankService
+	interval       time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+func NewHotScoreRefresher(hotRankService *HotRankService, interval time.Duration) *HotScoreRefresher {
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	return &HotScoreRefresher{
+		hotRankService: hotRankService,
+		interval:       interval,
+		stop:           make(chan struct{}),
+	}
+}
+
+// Start runs the refresher in the background until Stop is called.
+func (r *HotScoreRefresher) Start() {
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		r.RunOnce(time.Now())
+		for {
+			select {
+			case <-r.stop:
+				return
+			case now := <-ticker.C:
+				r.RunOnce(now)
+			}
+		}
+	}()
+}
+
+func (r *HotScoreRefresher) Stop() {
+	r.stopOnce.Do(func() { close(r.stop) })
+}
+
+// RunOnce rescores recent posts as of now.
+func (r *HotScoreRefresher) RunOnce(now time.Time) {
+	refreshed, err := r.hotRankService.Refresh(now)
+	if err != nil {
+		logger.Error("failed to refresh hot scores", zap.Error(err))
+	}
+	if refreshed > 0 {
+		logger.Debug("refreshed hot scores", zap.Int("posts", refreshed))
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHotScorer() *HotScorer {
+	return NewHotScorer(config.HotConfig{
+		Gravity:        1.8,
+		LikeWeight:     1,
+		CommentWeight:  2,
+		ReactionWeight: 0.5,
+		ViewWeight:     0.05,
+	})
+}
+
+func TestHotScorer_FreshPostsBeatOldFavorites(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	scorer := testHotScorer()
+
+	old := models.PostEngagement{PublishedAt: now.AddDate(0, 0, -30), LikesCount: 20}
+	fresh := models.PostEngagement{PublishedAt: now.Add(-time.Hour), LikesCount: 15}
+	assert.Greater(t, scorer.Score(fresh, now), scorer.Score(old, now))
+
+	liked := models.PostEngagement{PublishedAt: now, LikesCount: 2}
+	discussed := models.PostEngagement{PublishedAt: now, CommentsCount: 2}
+	viewed := models.PostEngagement{PublishedAt: now, LikesCount: 2, ViewsCount: 100}
+	assert.Greater(t, scorer.Score(discussed, now), scorer.Score(liked, now))
+	assert.Greater(t, scorer.Score(viewed, now), scorer.Score(liked, now))
+
+	assert.Zero(t, scorer.Score(models.PostEngagement{PublishedAt: now}, now))
+	future := models.PostEngagement{PublishedAt: now.Add(time.Hour), LikesCount: 2}
+	assert.Equal(t, scorer.Score(liked, now), scorer.Score(future, now), "clock skew does not boost a post")
+}
+
+func TestHotScorer_Decays(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	scorer := testHotScorer()
+	post := models.PostEngagement{PublishedAt: now, LikesCount: 10, CommentsCount: 3}
+
+	previous := scorer.Score(post, now)
+	for _, hours := range []int{1, 6, 24, 72} {
+		score := scorer.Score(post, now.Add(time.Duration(hours)*time.Hour))
+		assert.Less(t, score, previous)
+		previous = score
+	}
+}
+
+func TestPostListOptions(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	opts := postListOptions(&models.PostListRequest{}, models.PostSortHot, now)
+	assert.Equal(t, models.PostListOptions{Sort: models.PostSortHot}, opts)
+
+	opts = postListOptions(&models.PostListRequest{Sort: models.PostSortNew, Window: models.TopWindowDay}, models.PostSortHot, now)
+	assert.Nil(t, opts.Since, "windows only apply to top")
+
+	opts = postListOptions(&models.PostListRequest{Sort: models.PostSortTop}, models.PostSortNew, now)
+	assert.Nil(t, opts.Since, "top defaults to all time")
+
+	for window, want := range map[string]time.Time{
+		models.TopWindowDay:   now.AddDate(0, 0, -1),
+		models.TopWindowWeek:  now.AddDate(0, 0, -7),
+		models.TopWindowMonth: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	} {
+		opts = postListOptions(&models.PostListRequest{Sort: models.PostSortTop, Window: window}, models.PostSortNew, now)
+		require.NotNil(t, opts.Since, window)
+		assert.Equal(t, want, *opts.Since, window)
+	}
+}
@@ -2,24 +2,30 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
 )
 
+var ErrInvalidLocation = errors.New("invalid city or district")
+
 type LocationService struct {
 	cities    []models.City
 	districts []models.District
 	cityMap   map[string]models.City
+
+	districtMap    map[string]models.District
+	cityByName     map[string]models.City
+	districtByName map[string][]models.District
 }
 
 func NewLocationService() (*LocationService, error) {
-	service := &LocationService{
-		cityMap: make(map[string]models.City),
-	}
+	service := newLocationService()
 
 	if err := service.loadData(); err != nil {
 		return nil, err
@@ -27,6 +33,15 @@ func NewLocationService() (*LocationService, error) {
 	return service, nil
 }
 
+func newLocationService() *LocationService {
+	return &LocationService{
+		cityMap:        make(map[string]models.City),
+		districtMap:    make(map[string]models.District),
+		cityByName:     make(map[string]models.City),
+		districtByName: make(map[string][]models.District),
+	}
+}
+
 func (s *LocationService) loadData() error {
 	citiesData, err := os.ReadFile("data/sehirler.json")
 	if err != nil {
@@ -47,8 +62,7 @@ func (s *LocationService) loadData() error {
 			ID:   city.SehirID,
 			Name: city.SehirAdi,
 		}
-		s.cities = append(s.cities, c)
-		s.cityMap[city.SehirID] = c
+		s.addCity(c)
 	}
 
 	districtsData, err := os.ReadFile("data/ilceler.json")
@@ -68,7 +82,7 @@ func (s *LocationService) loadData() error {
 	}
 
 	for _, district := range rawDistricts {
-		s.districts = append(s.districts, models.District{
+		s.addDistrict(models.District{
 			ID:     district.IlceID,
 			Name:   district.IlceAdi,
 			CityID: district.SehirID,
@@ -79,15 +93,117 @@ func (s *LocationService) loadData() error {
 	return nil
 }
 
-func normalizeText(text string) string {
-	text = strings.ToLower(text)
+func (s *LocationService) addCity(c models.City) {
+	s.cities = append(s.cities, c)
+	s.cityMap[c.ID] = c
+	s.cityByName[normalizeText(c.Name)] = c
+}
 
+func (s *LocationService) addDistrict(d models.District) {
+	s.districts = append(s.districts, d)
+	s.districtMap[d.ID] = d
+	key := normalizeText(d.Name)
+	s.districtByName[key] = append(s.districtByName[key], d)
+}
+
+func normalizeText(text string) string {
 	replacer := strings.NewReplacer(
 		"ç", "c", "ğ", "g", "ı", "i", "ö", "o", "ş", "s", "ü", "u",
         "Ç", "c", "Ğ", "g", "İ", "i", "Ö", "o", "Ş", "s", "Ü", "u",
 	)
 
-	return replacer.Replace(text)
+	// fold before lowercasing, otherwise "İ" turns into "i" plus a combining dot
+	return strings.ToLower(replacer.Replace(text))
+}
+
+// ResolveLocation validates a city/district pair. A district implies its city;
+// empty IDs are treated as unset.
+func (s *LocationService) ResolveLocation(cityID, districtID *string) (*string, *string, error) {
+	if cityID != nil && *cityID == "" {
+		cityID = nil
+	}
+	if districtID != nil && *districtID == "" {
+		districtID = nil
+	}
+
+	if districtID != nil {
+		district, ok := s.districtMap[*districtID]
+		if !ok {
+			return nil, nil, ErrInvalidLocation
+		}
+		if cityID != nil && *cityID != district.CityID {
+			return nil, nil, ErrInvalidLocation
+		}
+		city := district.CityID
+		return &city, districtID, nil
+	}
+
+	if cityID != nil {
+		if _, ok := s.cityMap[*cityID]; !ok {
+			return nil, nil, ErrInvalidLocation
+		}
+	}
+	return cityID, nil, nil
+}
+
+// ResolveLocationUpdate applies a partial update to an existing city/district
+// pair. Changing the city without naming a district clears the old district.
+func (s *LocationService) ResolveLocationUpdate(curCityID, curDistrictID, cityID, districtID *string) (*string, *string, error) {
+	city, district := curCityID, curDistrictID
+	if cityID != nil {
+		if districtID == nil && (curCityID == nil || *curCityID != *cityID) {
+			district = nil
+		}
+		city = cityID
+	}
+	if districtID != nil {
+		district = districtID
+	}
+	return s.ResolveLocation(city, district)
+}
+
+// ParseLocation extracts a city and district from free text such as
+// "Kadıköy, İstanbul". A district without a city is only accepted when its
+// name is unique across the country.
+func (s *LocationService) ParseLocation(text string) (cityID, districtID *string) {
+	words := strings.FieldsFunc(normalizeText(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	// district names have at most two words ("19 Mayıs")
+	var candidates []string
+	for i, w := range words {
+		candidates = append(candidates, w)
+		if i+1 < len(words) {
+			candidates = append(candidates, w+" "+words[i+1])
+		}
+	}
+
+	var city *models.City
+	for _, c := range candidates {
+		if found, ok := s.cityByName[c]; ok {
+			city = &found
+			break
+		}
+	}
+
+	for _, c := range candidates {
+		for _, d := range s.districtByName[c] {
+			if city != nil && d.CityID != city.ID {
+				continue
+			}
+			if city == nil && len(s.districtByName[c]) > 1 {
+				break
+			}
+			cid, did := d.CityID, d.ID
+			return &cid, &did
+		}
+	}
+
+	if city != nil {
+		return &city.ID, nil
+	}
+	return nil, nil
 }
 
 func (s *LocationService) SearchLocations(query string, searchType string, limit int) []models.LocationSearchResponse {
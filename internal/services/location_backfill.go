@@ -0,0 +1,75 @@
+package services
+
+import (
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const locationBackfillBatchSize = 500
+
+// LocationBackfillService fills city_id/district_id from the free-text
+// location of users, clubs and events created before those fields existed.
+type LocationBackfillService struct {
+	locationRepo    repository.LocationRepository
+	locationService *LocationService
+}
+
+func NewLocationBackfillService(locationRepo repository.LocationRepository, locationService *LocationService) *LocationBackfillService {
+	return &LocationBackfillService{
+		locationRepo:    locationRepo,
+		locationService: locationService,
+	}
+}
+
+// Run processes every row that has a location text but no city. Rows whose
+// text cannot be matched are left untouched, so running it again is safe.
+func (s *LocationBackfillService) Run() (*models.LocationBackfillResponse, error) {
+	var resp models.LocationBackfillResponse
+	var err error
+
+	if resp.Users, err = s.backfillTable("users"); err != nil {
+		return nil, err
+	}
+	if resp.Clubs, err = s.backfillTable("clubs"); err != nil {
+		return nil, err
+	}
+	if resp.Events, err = s.backfillTable("events"); err != nil {
+		return nil, err
+	}
+
+	logger.Info("location backfill completed",
+		zap.Int("users_resolved", resp.Users.Resolved),
+		zap.Int("clubs_resolved", resp.Clubs.Resolved),
+		zap.Int("events_resolved", resp.Events.Resolved))
+	return &resp, nil
+}
+
+func (s *LocationBackfillService) backfillTable(table string) (models.LocationBackfillStats, error) {
+	var stats models.LocationBackfillStats
+	var afterID uint
+	for {
+		records, err := s.locationRepo.ListUnresolved(table, afterID, locationBackfillBatchSize)
+		if err != nil {
+			return stats, err
+		}
+		if len(records) == 0 {
+			return stats, nil
+		}
+
+		for _, rec := range records {
+			afterID = rec.ID
+			stats.Scanned++
+
+			cityID, districtID := s.locationService.ParseLocation(rec.Location)
+			if cityID == nil {
+				continue
+			}
+			if err := s.locationRepo.SetLocation(table, rec.ID, cityID, districtID); err != nil {
+				return stats, err
+			}
+			stats.Resolved++
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testLocationService() *LocationService {
+	s := newLocationService()
+	s.addCity(models.City{ID: "34", Name: "İSTANBUL"})
+	s.addCity(models.City{ID: "6", Name: "ANKARA"})
+	s.addDistrict(models.District{ID: "1421", Name: "KADIKÖY", CityID: "34", City: "İSTANBUL"})
+	s.addDistrict(models.District{ID: "1231", Name: "ÇANKAYA", CityID: "6", City: "ANKARA"})
+	s.addDistrict(models.District{ID: "2001", Name: "MERKEZ", CityID: "34", City: "İSTANBUL"})
+	s.addDistrict(models.District{ID: "2002", Name: "MERKEZ", CityID: "6", City: "ANKARA"})
+	return s
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestParseLocation(t *testing.T) {
+	s := testLocationService()
+
+	city, district := s.ParseLocation("Kadıköy, İstanbul")
+	assert.Equal(t, "34", *city)
+	assert.Equal(t, "1421", *district)
+
+	city, district = s.ParseLocation("cankaya")
+	assert.Equal(t, "6", *city)
+	assert.Equal(t, "1231", *district)
+
+	city, district = s.ParseLocation("Istanbul")
+	assert.Equal(t, "34", *city)
+	assert.Nil(t, district)
+
+	// ambiguous district without a city
+	city, district = s.ParseLocation("Merkez")
+	assert.Nil(t, city)
+	assert.Nil(t, district)
+
+	city, district = s.ParseLocation("Ankara Merkez")
+	assert.Equal(t, "6", *city)
+	assert.Equal(t, "2002", *district)
+}
+
+func TestResolveLocation(t *testing.T) {
+	s := testLocationService()
+
+	city, district, err := s.ResolveLocation(nil, strPtr("1421"))
+	assert.NoError(t, err)
+	assert.Equal(t, "34", *city)
+	assert.Equal(t, "1421", *district)
+
+	_, _, err = s.ResolveLocation(strPtr("6"), strPtr("1421"))
+	assert.ErrorIs(t, err, ErrInvalidLocation)
+
+	_, _, err = s.ResolveLocation(strPtr("99"), nil)
+	assert.ErrorIs(t, err, ErrInvalidLocation)
+
+	// moving to another city drops the old district
+	city, district, err = s.ResolveLocationUpdate(strPtr("34"), strPtr("1421"), strPtr("6"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "6", *city)
+	assert.Nil(t, district)
+}
+
+func TestNormalizeText(t *testing.T) {
+	for in, want := range map[string]string{
+		"İSTANBUL": "istanbul",
+		"İstanbul": "istanbul",
+		"istanbul": "istanbul",
+		"ISTANBUL": "istanbul",
+		"ıstanbul": "istanbul",
+		"IĞDIR":    "igdir",
+		"Iğdır":    "igdir",
+		"KADIKÖY":  "kadikoy",
+		"Kadıköy":  "kadikoy",
+		"ÇANKAYA":  "cankaya",
+		"Şişli":    "sisli",
+		"Üsküdar":  "uskudar",
+	} {
+		assert.Equal(t, want, normalizeText(in), in)
+	}
+}
+
+func TestSearchLocationsFoldsTurkishCase(t *testing.T) {
+	s := testLocationService()
+
+	for _, query := range []string{"İstanbul", "istanbul", "ISTANBUL", "ıstanbul", "İST"} {
+		results := s.SearchLocations(query, "city", 10)
+		if assert.Len(t, results, 1, query) {
+			assert.Equal(t, "34", results[0].ID, query)
+		}
+	}
+	for _, query := range []string{"Kadıköy", "kadikoy", "KADIKÖY", "KADIKOY", "dıkö"} {
+		results := s.SearchLocations(query, "district", 10)
+		if assert.Len(t, results, 1, query) {
+			assert.Equal(t, "1421", results[0].ID, query)
+		}
+	}
+
+	results := s.SearchLocations("çan", "", 10)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "1231", results[0].ID)
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"github.com/nevzattalhaozcan/forgotten/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// maxMentionsPerContent caps how many distinct users one post or comment can
+// mention; further names are ignored.
+const maxMentionsPerContent = 20
+
+type MentionService struct {
+	mentionRepo repository.MentionRepository
+	blockRepo   repository.BlockRepository
+	userRepo    repository.UserRepository
+	clubRepo    repository.ClubRepository
+}
+
+func NewMentionService(mentionRepo repository.MentionRepository, blockRepo repository.BlockRepository, userRepo repository.UserRepository, clubRepo repository.ClubRepository) *MentionService {
+	return &MentionService{
+		mentionRepo: mentionRepo,
+		blockRepo:   blockRepo,
+		userRepo:    userRepo,
+		clubRepo:    clubRepo,
+	}
+}
+
+// SyncPostMentions stores the users mentioned in the post's content,
+// replacing what was stored before.
+func (s *MentionService) SyncPostMentions(post *models.Post) error {
+	return s.sync(models.MentionTargetPost, post.ID, post.ID, post.ClubID, post.UserID, post.Content)
+}
+
+func (s *MentionService) SyncCommentMentions(comment *models.Comment, clubID uint) error {
+	return s.sync(models.MentionTargetComment, comment.ID, comment.PostID, clubID, comment.UserID, comment.Content)
+}
+
+// sync resolves @usernames and keeps only users who can see the club and
+// have not blocked the author. Unknown usernames are ignored.
+func (s *MentionService) sync(targetType string, targetID, postID, clubID, authorID uint, content string) error {
+	usernames := utils.MentionedUsernames(content)
+	if len(usernames) > maxMentionsPerContent {
+		usernames = usernames[:maxMentionsPerContent]
+	}
+
+	var candidates []uint
+	for _, username := range usernames {
+		user, err := s.userRepo.GetByUsername(username)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return err
+		}
+		if user.ID != authorID {
+			candidates = append(candidates, user.ID)
+		}
+	}
+
+	var mentions []models.Mention
+	if len(candidates) > 0 {
+		club, err := s.clubRepo.GetByID(clubID)
+		if err != nil {
+			return err
+		}
+
+		blockers, err := s.blockRepo.ListBlockersOf(authorID, candidates)
+		if err != nil {
+			return err
+		}
+		blocked := make(map[uint]bool, len(blockers))
+		for _, id := range blockers {
+			blocked[id] = true
+		}
+
+		for _, userID := range candidates {
+			if blocked[userID] {
+				continue
+			}
+			canSee, err := s.canSeeClub(club, userID)
+			if err != nil {
+				return err
+			}
+			if !canSee {
+				continue
+			}
+			mentions = append(mentions, models.Mention{
+				TargetType:      targetType,
+				TargetID:        targetID,
+				MentionedUserID: userID,
+				PostID:          postID,
+				ClubID:          clubID,
+				AuthorID:        authorID,
+			})
+		}
+	}
+
+	return s.mentionRepo.Replace(targetType, targetID, mentions)
+}
+
+// canSeeClub mirrors who can read a club's posts: anyone for public clubs,
+// the owner and approved members for private ones.
+func (s *MentionService) canSeeClub(club *models.Club, userID uint) (bool, error) {
+	if !club.IsPrivate {
+		return true, nil
+	}
+	if club.OwnerID != nil && *club.OwnerID == userID {
+		return true, nil
+	}
+
+	member, err := s.clubRepo.GetClubMemberByUserID(club.ID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return member.IsApproved, nil
+}
+
+// ListMentions returns the posts and comments that mention userID.
+func (s *MentionService) ListMentions(userID uint, req *models.ListMentionsRequest) (*pagination.Page[models.MentionResponse], error) {
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := s.mentionRepo.ListByMentionedUser(userID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	response := pagination.Map(mentions, func(m models.Mention) models.MentionResponse { return m.ToResponse() })
+	return &response, nil
+}
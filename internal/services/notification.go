@@ -0,0 +1,103 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"go.uber.org/zap"
+)
+
+type NotificationService struct {
+	notificationRepo repository.NotificationRepository
+}
+
+func NewNotificationService(notificationRepo repository.NotificationRepository) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+	}
+}
+
+// Notify stores notifications. Failures are logged rather than returned, since
+// whatever the notifications are about has already happened.
+func (s *NotificationService) Notify(notifications ...models.Notification) {
+	if err := s.notificationRepo.Create(notifications); err != nil {
+		logger.Warn("failed to store notifications", zap.Int("count", len(notifications)), zap.Error(err))
+	}
+}
+
+func (s *NotificationService) ListNotifications(userID uint, req *models.ListNotificationsRequest) (*pagination.Page[models.Notification], error) {
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+	notifications, err := s.notificationRepo.List(userID, req.Unread, page)
+	if err != nil {
+		return nil, err
+	}
+	return &notifications, nil
+}
+
+func (s *NotificationService) CountUnread(userID uint) (int64, error) {
+	return s.notificationRepo.CountUnread(userID)
+}
+
+// MarkRead marks the listed notifications as read, or all of them when the
+// request lists none. It returns how many were unread.
+func (s *NotificationService) MarkRead(userID uint, req *models.MarkNotificationsReadRequest) (int64, error) {
+	return s.notificationRepo.MarkRead(userID, req.IDs, time.Now())
+}
+
+// ScheduledPostNotifier tells the author and the club's members when the
+// scheduler publishes a post. Posts published straight away are left to the
+// feeds.
+type ScheduledPostNotifier struct {
+	notificationService *NotificationService
+	feedRepo            repository.FeedRepository
+}
+
+func NewScheduledPostNotifier(notificationService *NotificationService, feedRepo repository.FeedRepository) *ScheduledPostNotifier {
+	return &ScheduledPostNotifier{
+		notificationService: notificationService,
+		feedRepo:            feedRepo,
+	}
+}
+
+func (n *ScheduledPostNotifier) PostPublished(post *models.Post, scheduled bool) {
+	if !scheduled {
+		return
+	}
+	memberIDs, err := n.feedRepo.ListMemberIDs(post.ClubID)
+	if err != nil {
+		logger.Warn("failed to list club members for post notifications", zap.Uint("post_id", post.ID), zap.Error(err))
+	}
+	n.notificationService.Notify(scheduledPostNotifications(post, memberIDs)...)
+}
+
+// scheduledPostNotifications are the notifications for a scheduled post that
+// went out: one for its author and one for each other member of its club.
+func scheduledPostNotifications(post *models.Post, memberIDs []uint) []models.Notification {
+	notifications := []models.Notification{{
+		UserID:     post.UserID,
+		Type:       models.NotificationPostScheduled,
+		Message:    fmt.Sprintf("Your scheduled post %q is now published.", post.Title),
+		TargetType: models.ReportTargetPost,
+		TargetID:   post.ID,
+	}}
+	for _, id := range memberIDs {
+		if id == post.UserID {
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			UserID:     id,
+			Type:       models.NotificationClubPost,
+			Message:    fmt.Sprintf("New post in your club: %q.", post.Title),
+			TargetType: models.ReportTargetPost,
+			TargetID:   post.ID,
+		})
+	}
+	return notifications
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"github.com/nevzattalhaozcan/forgotten/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestScheduledPublishNotifies(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	db, err := test_helpers.SetupTestDB()
+	require.NoError(t, err)
+
+	for _, m := range []models.ClubMembership{
+		{UserID: 1, ClubID: 1, IsApproved: true},
+		{UserID: 2, ClubID: 1, IsApproved: true},
+		{UserID: 3, ClubID: 1, IsApproved: false},
+	} {
+		require.NoError(t, db.Omit("User", "Club").Create(&m).Error)
+	}
+
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	scheduled := &models.Post{Title: "Meeting moved", Content: "text", Type: "announcement", UserID: 1, ClubID: 1, Status: models.PostStatusScheduled, PublishAt: &past}
+	require.NoError(t, db.Omit("Club", "User").Create(scheduled).Error)
+
+	notificationRepo := repository.NewNotificationRepository(db)
+	posts := &PostService{postRepo: repository.NewPostRepository(db)}
+	posts.AddPublishListener(NewScheduledPostNotifier(NewNotificationService(notificationRepo), repository.NewFeedRepository(db)))
+
+	published, err := posts.PublishDuePosts(now)
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+
+	var notifications []models.Notification
+	require.NoError(t, db.Order("user_id").Find(&notifications).Error)
+	require.Len(t, notifications, 2, "the author and the other approved member")
+	assert.Equal(t, uint(1), notifications[0].UserID)
+	assert.Equal(t, models.NotificationPostScheduled, notifications[0].Type)
+	assert.Equal(t, uint(2), notifications[1].UserID)
+	assert.Equal(t, models.NotificationClubPost, notifications[1].Type)
+	for _, n := range notifications {
+		assert.Equal(t, models.ReportTargetPost, n.TargetType)
+		assert.Equal(t, scheduled.ID, n.TargetID)
+	}
+}
+
+func TestScheduledPostNotificationsSkipImmediatePublishes(t *testing.T) {
+	db, err := test_helpers.SetupTestDB()
+	require.NoError(t, err)
+
+	notifier := NewScheduledPostNotifier(NewNotificationService(repository.NewNotificationRepository(db)), repository.NewFeedRepository(db))
+	notifier.PostPublished(&models.Post{ID: 1, Title: "Hello", UserID: 1, ClubID: 1}, false)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Notification{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
@@ -0,0 +1,509 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidPoll       = errors.New("invalid poll")
+	ErrNotPoll           = errors.New("post is not a poll")
+	ErrPollClosed        = repository.ErrPollClosed
+	ErrPollAlreadyVoted  = errors.New("multiple votes not allowed")
+	ErrPollResultsHidden = errors.New("poll results are not visible yet")
+	ErrPollVotersHidden  = errors.New("poll is anonymous")
+	ErrNotPollManager    = errors.New("only the poll's author or a club moderator can close it")
+)
+
+// preparePollData checks the poll of a new post and fills in its defaults
+// and option ids. Votes, close state and results cannot be set by the author.
+func preparePollData(typeData interface{}, now time.Time) (*models.PollData, error) {
+	raw, err := json.Marshal(typeData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPoll, err)
+	}
+	var data models.PollData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPoll, err)
+	}
+	withPollDefaults(&data)
+
+	switch {
+	case strings.TrimSpace(data.Question) == "":
+		return nil, fmt.Errorf("%w: question is required", ErrInvalidPoll)
+	case len(data.Options) < 2 || len(data.Options) > 10:
+		return nil, fmt.Errorf("%w: polls need 2 to 10 options", ErrInvalidPoll)
+	case data.Method != models.PollMethodPlurality && data.Method != models.PollMethodRankedChoice:
+		return nil, fmt.Errorf("%w: unknown method %q", ErrInvalidPoll, data.Method)
+	case data.VoteVisibility != models.PollVotesPublic && data.VoteVisibility != models.PollVotesAnonymous:
+		return nil, fmt.Errorf("%w: unknown vote visibility %q", ErrInvalidPoll, data.VoteVisibility)
+	case data.ResultsVisibility != models.PollResultsAlways && data.ResultsVisibility != models.PollResultsAfterVote && data.ResultsVisibility != models.PollResultsAfterClose:
+		return nil, fmt.Errorf("%w: unknown results visibility %q", ErrInvalidPoll, data.ResultsVisibility)
+	case data.Method == models.PollMethodRankedChoice && data.AllowMultiple:
+		return nil, fmt.Errorf("%w: ranked-choice polls take one ranked ballot per voter", ErrInvalidPoll)
+	case data.ExpiresAt != nil && !data.ExpiresAt.After(now):
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidPoll)
+	}
+
+	for i := range data.Options {
+		if strings.TrimSpace(data.Options[i].Text) == "" {
+			return nil, fmt.Errorf("%w: options need text", ErrInvalidPoll)
+		}
+		data.Options[i].ID = fmt.Sprintf("opt_%d", i+1)
+		data.Options[i].Votes = 0
+	}
+	data.ClosedAt = nil
+	data.ClosedBy = nil
+	data.FinalResults = nil
+	return &data, nil
+}
+
+// withPollDefaults fills in the settings polls created before they existed
+// leave empty.
+func withPollDefaults(data *models.PollData) {
+	if data.Method == "" {
+		data.Method = models.PollMethodPlurality
+	}
+	if data.VoteVisibility == "" {
+		data.VoteVisibility = models.PollVotesPublic
+	}
+	if data.ResultsVisibility == "" {
+		data.ResultsVisibility = models.PollResultsAlways
+	}
+}
+
+// pollResultsVisible reports whether a viewer sees a poll's results.
+func pollResultsVisible(data *models.PollData, closed, voted bool) bool {
+	switch data.ResultsVisibility {
+	case models.PollResultsAfterVote:
+		return closed || voted
+	case models.PollResultsAfterClose:
+		return closed
+	default:
+		return true
+	}
+}
+
+// checkBallot checks the options a voter chose: they must exist, appear once
+// and, on single-choice plurality polls, be a single option.
+func checkBallot(data *models.PollData, optionIDs []string) error {
+	if data.Method == models.PollMethodPlurality && !data.AllowMultiple && len(optionIDs) > 1 {
+		return errors.New("this poll allows only one option")
+	}
+	seen := make(map[string]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if !slices.ContainsFunc(data.Options, func(o models.PollOption) bool { return o.ID == id }) {
+			return fmt.Errorf("invalid option ID: %s", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("option %s chosen more than once", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// pollTally is what is stored about the votes on one poll: the option
+// counters, the number of voters and, for ranked-choice polls only, the
+// ballots an instant runoff needs.
+type pollTally struct {
+	counts map[string]int
+	voters int
+	votes  []models.PollVote
+}
+
+// tallyPoll works out a poll's results. The ballots must be grouped by voter
+// in rank order, as ListPollVotes returns them.
+func tallyPoll(data *models.PollData, tally *pollTally) *models.PollResults {
+	results := &models.PollResults{
+		TotalVoters: tally.voters,
+		Options:     make([]models.PollOptionResult, len(data.Options)),
+		WinnerIDs:   []string{},
+	}
+	optionIDs := make([]string, len(data.Options))
+	for i, o := range data.Options {
+		optionIDs[i] = o.ID
+		results.Options[i] = models.PollOptionResult{ID: o.ID, Text: o.Text, Votes: tally.counts[o.ID]}
+	}
+
+	if data.Method == models.PollMethodRankedChoice {
+		results.Rounds, results.WinnerIDs = instantRunoff(optionIDs, ballotsOf(tally.votes))
+		return results
+	}
+	top := 0
+	for _, o := range results.Options {
+		top = max(top, o.Votes)
+	}
+	for _, o := range results.Options {
+		if top > 0 && o.Votes == top {
+			results.WinnerIDs = append(results.WinnerIDs, o.ID)
+		}
+	}
+	return results
+}
+
+// ballotsOf splits votes grouped by voter into one ballot per voter.
+func ballotsOf(votes []models.PollVote) [][]string {
+	var (
+		ballots [][]string
+		voter   uint
+	)
+	for i, v := range votes {
+		if i == 0 || v.UserID != voter {
+			ballots = append(ballots, nil)
+			voter = v.UserID
+		}
+		ballots[len(ballots)-1] = append(ballots[len(ballots)-1], v.OptionID)
+	}
+	return ballots
+}
+
+// instantRunoff decides a ranked-choice poll. Each round gives every ballot
+// to its highest ranked option still running; an option with more than half
+// of the ballots still in play wins. Otherwise every option with the fewest
+// ballots is eliminated at once, and when all the options left are tied they
+// share the win.
+func instantRunoff(optionIDs []string, ballots [][]string) ([]models.PollRound, []string) {
+	if len(ballots) == 0 {
+		return nil, []string{}
+	}
+	running := slices.Clone(optionIDs)
+	var rounds []models.PollRound
+	for {
+		round := models.PollRound{Counts: make(map[string]int, len(running))}
+		for _, id := range running {
+			round.Counts[id] = 0
+		}
+		for _, ballot := range ballots {
+			i := slices.IndexFunc(ballot, func(id string) bool { return slices.Contains(running, id) })
+			if i < 0 {
+				round.Exhausted++
+				continue
+			}
+			round.Counts[ballot[i]]++
+		}
+
+		active := len(ballots) - round.Exhausted
+		fewest, most := active, 0
+		for _, id := range running {
+			fewest = min(fewest, round.Counts[id])
+			most = max(most, round.Counts[id])
+		}
+
+		switch {
+		case active == 0:
+			return append(rounds, round), []string{}
+		case most*2 > active:
+			rounds = append(rounds, round)
+			for _, id := range running {
+				if round.Counts[id] == most {
+					return rounds, []string{id}
+				}
+			}
+		case fewest == most:
+			return append(rounds, round), running
+		}
+
+		kept := running[:0:0]
+		for _, id := range running {
+			if round.Counts[id] == fewest {
+				round.Eliminated = append(round.Eliminated, id)
+			} else {
+				kept = append(kept, id)
+			}
+		}
+		rounds = append(rounds, round)
+		running = kept
+	}
+}
+
+// getPoll loads a published poll post with its settings filled in.
+func (s *PostService) getPoll(postID uint) (*models.Post, *models.PollData, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("post not found")
+		}
+		return nil, nil, err
+	}
+	if !post.IsPublished() {
+		return nil, nil, errors.New("post not found")
+	}
+	if post.Type != "poll" {
+		return nil, nil, ErrNotPoll
+	}
+	data, err := post.GetPollData()
+	if err != nil || data == nil {
+		return nil, nil, errors.New("invalid poll data")
+	}
+	withPollDefaults(data)
+	return post, data, nil
+}
+
+// savePollData writes the poll back into the post's type data.
+func savePollData(repo repository.PostRepository, post *models.Post, data *models.PollData) error {
+	typeDataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	post.TypeData = models.PostTypeData(typeDataBytes)
+	return repo.UpdateTypeData(post.ID, post.TypeData)
+}
+
+// snapshotPoll stores the final results of a closed poll.
+func snapshotPoll(repo repository.PostRepository, post *models.Post, data *models.PollData, results *models.PollResults) error {
+	data.FinalResults = results
+	return savePollData(repo, post, data)
+}
+
+// loadPollTallies reads the counters of the given polls, and the ballots of
+// those among them that are decided by instant runoff.
+func loadPollTallies(repo repository.PostRepository, ids, rankedIDs []uint) (map[uint]*pollTally, error) {
+	tallies := make(map[uint]*pollTally, len(ids))
+	for _, id := range ids {
+		tallies[id] = &pollTally{counts: map[string]int{}}
+	}
+	counts, err := repo.ListPollCounts(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range counts {
+		tallies[c.PostID].counts[c.OptionID] = c.Votes
+	}
+	voters, err := repo.CountPollVoters(ids)
+	if err != nil {
+		return nil, err
+	}
+	for id, n := range voters {
+		tallies[id].voters = n
+	}
+	ballots, err := repo.ListPollVotes(rankedIDs, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range ballots {
+		tallies[v.PostID].votes = append(tallies[v.PostID].votes, v)
+	}
+	return tallies, nil
+}
+
+// ClosePoll ends voting on a poll before it expires and saves its final
+// results. The author, the club's owner and moderators, and platform
+// moderators can close a poll.
+func (s *PostService) ClosePoll(postID, userID uint, role string) (*models.PollView, error) {
+	post, data, err := s.getPoll(postID)
+	if err != nil {
+		return nil, err
+	}
+	if data.IsClosed(time.Now()) {
+		return nil, ErrPollClosed
+	}
+	allowed, err := s.canManagePoll(post, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrNotPollManager
+	}
+
+	var ranked []uint
+	if data.Method == models.PollMethodRankedChoice {
+		ranked = []uint{postID}
+	}
+	// the tally is read and stored under the poll's lock, so no vote can
+	// land after it
+	err = s.postRepo.ClosePoll(postID, func(tx repository.PostRepository) error {
+		tallies, err := loadPollTallies(tx, []uint{postID}, ranked)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		data.ClosedAt = &now
+		data.ClosedBy = &userID
+		return snapshotPoll(tx, post, data, tallyPoll(data, tallies[postID]))
+	})
+	if err != nil {
+		return nil, err
+	}
+	own, err := s.postRepo.GetUserPollVotes(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("poll closed", zap.Uint("post_id", postID), zap.Uint("closed_by", userID))
+	return pollView(data, true, data.FinalResults, ballotOf(own, userID, postID)), nil
+}
+
+func (s *PostService) canManagePoll(post *models.Post, userID uint, role string) (bool, error) {
+	if post.UserID == userID || isPlatformModerator(role) {
+		return true, nil
+	}
+	club, err := s.clubRepo.GetByID(post.ClubID)
+	if err != nil {
+		return false, err
+	}
+	if club.OwnerID != nil && *club.OwnerID == userID {
+		return true, nil
+	}
+	member, err := s.clubRepo.GetClubMemberByUserID(post.ClubID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return member.IsApproved && (member.Role == "club_admin" || member.Role == "moderator"), nil
+}
+
+// ListPollVoters lists who chose an option. Anonymous polls never list their
+// voters, and others only once the viewer can see the results.
+func (s *PostService) ListPollVoters(postID, viewerID uint, req *models.ListPollVotersRequest) (*pagination.Page[models.PollVoter], error) {
+	_, data, err := s.getPoll(postID)
+	if err != nil {
+		return nil, err
+	}
+	if data.VoteVisibility == models.PollVotesAnonymous {
+		return nil, ErrPollVotersHidden
+	}
+	if !slices.ContainsFunc(data.Options, func(o models.PollOption) bool { return o.ID == req.OptionID }) {
+		return nil, fmt.Errorf("invalid option ID: %s", req.OptionID)
+	}
+	if data.ResultsVisibility != models.PollResultsAlways {
+		own, err := s.postRepo.GetUserPollVotes(postID, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		if !pollResultsVisible(data, data.IsClosed(time.Now()), len(own) > 0) {
+			return nil, ErrPollResultsHidden
+		}
+	}
+
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+	voters, err := s.postRepo.ListPollVoters(postID, req.OptionID, page)
+	if err != nil {
+		return nil, err
+	}
+	return &voters, nil
+}
+
+// attachPollViews sets the poll state viewerID sees on the poll posts among
+// responses, and fills the option counts of their type data in where the
+// viewer may see them. Polls that expired without a saved result get one now.
+func (s *PostService) attachPollViews(viewerID *uint, posts []models.Post, responses []models.PostResponse) error {
+	now := time.Now()
+	polls := make(map[uint]*models.PollData, len(posts))
+	var ids, tallied, ranked []uint
+	for _, p := range posts {
+		if p.Type != "poll" {
+			continue
+		}
+		data, err := p.GetPollData()
+		if err != nil || data == nil {
+			continue
+		}
+		withPollDefaults(data)
+		polls[p.ID] = data
+		ids = append(ids, p.ID)
+		if data.FinalResults == nil {
+			tallied = append(tallied, p.ID)
+			if data.Method == models.PollMethodRankedChoice {
+				ranked = append(ranked, p.ID)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tallies, err := loadPollTallies(s.postRepo, tallied, ranked)
+	if err != nil {
+		return err
+	}
+	var own []models.PollVote
+	if viewerID != nil {
+		if own, err = s.postRepo.ListPollVotes(ids, viewerID); err != nil {
+			return err
+		}
+	}
+
+	views := make(map[uint]*models.PollView, len(ids))
+	for i := range posts {
+		post := &posts[i]
+		data, ok := polls[post.ID]
+		if !ok {
+			continue
+		}
+
+		closed := data.IsClosed(now)
+		results := data.FinalResults
+		if results == nil {
+			results = tallyPoll(data, tallies[post.ID])
+			if closed {
+				if err := snapshotPoll(s.postRepo, post, data, results); err != nil {
+					logger.Warn("failed to save expired poll results", zap.Uint("post_id", post.ID), zap.Error(err))
+				}
+			}
+		}
+		var ballot []string
+		if viewerID != nil {
+			ballot = ballotOf(own, *viewerID, post.ID)
+		}
+		views[post.ID] = pollView(data, closed, results, ballot)
+
+		for j := range data.Options {
+			data.Options[j].Votes = 0
+			if views[post.ID].ResultsVisible {
+				data.Options[j].Votes = results.Options[j].Votes
+			}
+		}
+	}
+
+	for i := range responses {
+		if view, ok := views[responses[i].ID]; ok {
+			responses[i].Poll = view
+			responses[i].TypeData = polls[responses[i].ID]
+		}
+	}
+	return nil
+}
+
+// ballotOf returns the options userID chose on postID, in rank order.
+func ballotOf(votes []models.PollVote, userID, postID uint) []string {
+	var ballot []string
+	for _, v := range votes {
+		if v.UserID == userID && v.PostID == postID {
+			ballot = append(ballot, v.OptionID)
+		}
+	}
+	return ballot
+}
+
+func pollView(data *models.PollData, closed bool, results *models.PollResults, ballot []string) *models.PollView {
+	view := &models.PollView{
+		Method:            data.Method,
+		VoteVisibility:    data.VoteVisibility,
+		ResultsVisibility: data.ResultsVisibility,
+		Closed:            closed,
+		ClosedAt:          data.ClosedAt,
+		ResultsVisible:    pollResultsVisible(data, closed, len(ballot) > 0),
+		UserVotes:         ballot,
+	}
+	if view.ResultsVisible {
+		view.Results = results
+	}
+	return view
+}
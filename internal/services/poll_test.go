@@ -0,0 +1,134 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreparePollData(t *testing.T) {
+	now := time.Now()
+	data, err := preparePollData(map[string]interface{}{
+		"question":  "Next book?",
+		"options":   []interface{}{map[string]interface{}{"text": "Dune", "votes": 40}, map[string]interface{}{"text": "Emma"}},
+		"closed_at": now.Format(time.RFC3339),
+	}, now)
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.PollMethodPlurality, data.Method)
+		assert.Equal(t, models.PollVotesPublic, data.VoteVisibility)
+		assert.Equal(t, models.PollResultsAlways, data.ResultsVisibility)
+		assert.Equal(t, []models.PollOption{{ID: "opt_1", Text: "Dune"}, {ID: "opt_2", Text: "Emma"}}, data.Options)
+		assert.Nil(t, data.ClosedAt, "authors cannot create a closed poll")
+	}
+
+	past := now.Add(-time.Hour)
+	for name, poll := range map[string]models.PollData{
+		"one option":      {Question: "q", Options: []models.PollOption{{Text: "a"}}},
+		"ranked multiple": {Question: "q", Options: []models.PollOption{{Text: "a"}, {Text: "b"}}, Method: models.PollMethodRankedChoice, AllowMultiple: true},
+		"bad visibility":  {Question: "q", Options: []models.PollOption{{Text: "a"}, {Text: "b"}}, ResultsVisibility: "never"},
+		"already expired": {Question: "q", Options: []models.PollOption{{Text: "a"}, {Text: "b"}}, ExpiresAt: &past},
+	} {
+		_, err := preparePollData(poll, now)
+		assert.True(t, errors.Is(err, ErrInvalidPoll), name)
+	}
+}
+
+func TestPollResultsVisible(t *testing.T) {
+	afterVote := &models.PollData{ResultsVisibility: models.PollResultsAfterVote}
+	afterClose := &models.PollData{ResultsVisibility: models.PollResultsAfterClose}
+
+	assert.True(t, pollResultsVisible(&models.PollData{ResultsVisibility: models.PollResultsAlways}, false, false))
+	assert.False(t, pollResultsVisible(afterVote, false, false))
+	assert.True(t, pollResultsVisible(afterVote, false, true))
+	assert.True(t, pollResultsVisible(afterVote, true, false))
+	assert.False(t, pollResultsVisible(afterClose, false, true))
+	assert.True(t, pollResultsVisible(afterClose, true, false))
+
+	view := pollView(afterClose, false, &models.PollResults{TotalVoters: 3}, []string{"opt_1"})
+	assert.Nil(t, view.Results)
+	assert.Equal(t, []string{"opt_1"}, view.UserVotes, "voters always see their own ballot")
+}
+
+func TestCheckBallot(t *testing.T) {
+	data := &models.PollData{Method: models.PollMethodPlurality, Options: []models.PollOption{{ID: "opt_1"}, {ID: "opt_2"}}}
+	assert.NoError(t, checkBallot(data, []string{"opt_2"}))
+	assert.Error(t, checkBallot(data, []string{"opt_1", "opt_2"}), "single-choice polls take one option")
+	assert.Error(t, checkBallot(data, []string{"opt_3"}))
+
+	data.Method = models.PollMethodRankedChoice
+	assert.NoError(t, checkBallot(data, []string{"opt_2", "opt_1"}))
+	assert.Error(t, checkBallot(data, []string{"opt_2", "opt_2"}))
+}
+
+// ballots turns rankings into votes the way ListPollVotes returns them.
+func ballots(rankings ...[]string) []models.PollVote {
+	var votes []models.PollVote
+	for i, ranking := range rankings {
+		for rank, id := range ranking {
+			votes = append(votes, models.PollVote{UserID: uint(i + 1), OptionID: id, Rank: rank + 1})
+		}
+	}
+	return votes
+}
+
+func TestTallyPoll_Plurality(t *testing.T) {
+	data := &models.PollData{
+		Method:        models.PollMethodPlurality,
+		AllowMultiple: true,
+		Options:       []models.PollOption{{ID: "a", Text: "A"}, {ID: "b", Text: "B"}, {ID: "c", Text: "C"}},
+	}
+	results := tallyPoll(data, &pollTally{counts: map[string]int{"a": 2, "b": 2}, voters: 3})
+	assert.Equal(t, 3, results.TotalVoters)
+	assert.Equal(t, []models.PollOptionResult{{ID: "a", Text: "A", Votes: 2}, {ID: "b", Text: "B", Votes: 2}, {ID: "c", Text: "C", Votes: 0}}, results.Options)
+	assert.Equal(t, []string{"a", "b"}, results.WinnerIDs)
+	assert.Empty(t, results.Rounds)
+
+	assert.Empty(t, tallyPoll(data, &pollTally{}).WinnerIDs)
+}
+
+func TestTallyPoll_InstantRunoff(t *testing.T) {
+	data := &models.PollData{
+		Method:  models.PollMethodRankedChoice,
+		Options: []models.PollOption{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}},
+	}
+	results := tallyPoll(data, &pollTally{
+		counts: map[string]int{"a": 2, "b": 2, "c": 1},
+		voters: 5,
+		votes: ballots(
+			[]string{"a", "b"},
+			[]string{"a"},
+			[]string{"b", "a"},
+			[]string{"b"},
+			[]string{"c", "b"},
+		),
+	})
+
+	assert.Equal(t, 5, results.TotalVoters)
+	assert.Equal(t, 2, results.Options[0].Votes, "option votes are the first preference counters")
+	assert.Equal(t, []models.PollRound{
+		{Counts: map[string]int{"a": 2, "b": 2, "c": 1, "d": 0}, Eliminated: []string{"d"}},
+		{Counts: map[string]int{"a": 2, "b": 2, "c": 1}, Eliminated: []string{"c"}},
+		{Counts: map[string]int{"a": 2, "b": 3}},
+	}, results.Rounds)
+	assert.Equal(t, []string{"b"}, results.WinnerIDs)
+}
+
+func TestInstantRunoff_TiesAndExhaustedBallots(t *testing.T) {
+	rounds, winners := instantRunoff([]string{"a", "b", "c"}, [][]string{{"a"}, {"b"}, {"b"}, {"c", "a"}, {"a"}})
+	assert.Equal(t, []string{"c"}, rounds[0].Eliminated, "the lone last place goes first")
+	assert.Equal(t, []string{"a"}, winners, "transferred preferences decide the poll")
+
+	rounds, winners = instantRunoff([]string{"a", "b", "c"}, [][]string{{"a"}, {"a"}, {"b"}, {"c"}})
+	assert.Equal(t, []string{"b", "c"}, rounds[0].Eliminated, "tied last places go together")
+	assert.Equal(t, []string{"a"}, winners)
+
+	rounds, winners = instantRunoff([]string{"a", "b", "c"}, [][]string{{"a"}, {"a"}, {"b"}, {"b"}, {"c"}})
+	assert.Equal(t, 1, rounds[1].Exhausted)
+	assert.Equal(t, []string{"a", "b"}, winners, "a tie between every option left is shared")
+
+	_, winners = instantRunoff([]string{"a", "b"}, nil)
+	assert.Empty(t, winners)
+}
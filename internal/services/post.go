@@ -9,9 +9,29 @@ import (
 	"github.com/nevzattalhaozcan/forgotten/internal/config"
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
 	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrPublishAtRequired    = errors.New("scheduled posts need a publish_at in the future")
+	ErrPostAlreadyPublished = errors.New("published posts cannot be moved back to draft or rescheduled")
+	ErrPostHidden           = errors.New("post was hidden by a moderator")
+	ErrPostHeld             = errors.New("post is awaiting moderator review")
+)
+
+// duePostsBatchSize caps how many scheduled posts one PublishDuePosts call
+// publishes; the rest are picked up on the next run.
+const duePostsBatchSize = 100
+
+// PostPublishListener is told when a post becomes visible to the club, either
+// straight away or through the scheduler; scheduled is set for the latter.
+type PostPublishListener interface {
+	PostPublished(post *models.Post, scheduled bool)
+}
+
 type PostService struct {
 	postRepo repository.PostRepository
 	userRepo repository.UserRepository
@@ -19,16 +39,65 @@ type PostService struct {
 	bookRepo repository.BookRepository
 	db       *gorm.DB
 	config   *config.Config
+
+	mentionService   *MentionService
+	hashtagService   *HashtagService
+	spoilerService   *SpoilerService
+	contentFilter    *ContentFilter
+	attachments      *AttachmentService
+	reshares         *ReshareService
+	publishListeners []PostPublishListener
+	engagementListeners
 }
 
-func NewPostService(postRepo repository.PostRepository, userRepo repository.UserRepository, clubRepo repository.ClubRepository, bookRepo repository.BookRepository, db *gorm.DB, config *config.Config) *PostService {
+func NewPostService(postRepo repository.PostRepository, userRepo repository.UserRepository, clubRepo repository.ClubRepository, bookRepo repository.BookRepository, mentionService *MentionService, hashtagService *HashtagService, spoilerService *SpoilerService, contentFilter *ContentFilter, attachments *AttachmentService, reshares *ReshareService, db *gorm.DB, config *config.Config) *PostService {
 	return &PostService{
-		postRepo: postRepo,
-		userRepo: userRepo,
-		clubRepo: clubRepo,
-		bookRepo: bookRepo,
-		db:       db,
-		config:   config,
+		postRepo:       postRepo,
+		userRepo:       userRepo,
+		clubRepo:       clubRepo,
+		bookRepo:       bookRepo,
+		mentionService: mentionService,
+		hashtagService: hashtagService,
+		spoilerService: spoilerService,
+		contentFilter:  contentFilter,
+		attachments:    attachments,
+		reshares:       reshares,
+		db:             db,
+		config:         config,
+	}
+}
+
+// syncMentions stores the post's mentions. A failure is logged rather than
+// returned; the post itself has already been saved.
+func (s *PostService) syncMentions(post *models.Post) {
+	if err := s.mentionService.SyncPostMentions(post); err != nil {
+		logger.Warn("failed to store post mentions", zap.Uint("post_id", post.ID), zap.Error(err))
+	}
+}
+
+// syncHashtags indexes the post's hashtags, logging failures like
+// syncMentions.
+func (s *PostService) syncHashtags(post *models.Post) {
+	if err := s.hashtagService.SyncPostHashtags(post); err != nil {
+		logger.Warn("failed to store post hashtags", zap.Uint("post_id", post.ID), zap.Error(err))
+	}
+}
+
+// AddPublishListener registers a listener for newly published posts. It is not
+// safe to call once the server is handling requests.
+func (s *PostService) AddPublishListener(listener PostPublishListener) {
+	s.publishListeners = append(s.publishListeners, listener)
+}
+
+func (s *PostService) notifyPublished(post *models.Post, scheduled bool) {
+	logger.Info("post published",
+		zap.Uint("post_id", post.ID),
+		zap.Uint("club_id", post.ClubID),
+		zap.Uint("user_id", post.UserID),
+		zap.Bool("scheduled", scheduled),
+	)
+	for _, listener := range s.publishListeners {
+		listener.PostPublished(post, scheduled)
 	}
 }
 
@@ -48,6 +117,9 @@ func (s *PostService) CreatePost(userID uint, req *models.CreatePostRequest) (*m
 		}
 		return nil, err
 	}
+	if err := s.attachments.CheckForPost(userID, req.ClubID, 0, req.AttachmentIDs); err != nil {
+		return nil, err
+	}
 
 	post := &models.Post{
 		Title:   req.Title,
@@ -56,21 +128,44 @@ func (s *PostService) CreatePost(userID uint, req *models.CreatePostRequest) (*m
 		ClubID:  req.ClubID,
 		UserID:  userID,
 	}
+	post.RenderContent()
+
+	if req.Type == "post" {
+		sharedID := sharedPostID(req)
+		if sharedID == 0 {
+			return nil, ErrSharedPostRequired
+		}
+		original, err := s.reshares.CheckShare(userID, req.ClubID, sharedID)
+		if err != nil {
+			return nil, err
+		}
+		post.SharedPostID = &original.ID
+		// the original is shown live, so nothing of it is copied
+		req.TypeData = nil
+	} else if req.SharedPostID != nil {
+		return nil, ErrReshareType
+	}
 
-	if req.TypeData != nil {
+	status := req.Status
+	if status == "" {
+		status = models.PostStatusPublished
+	}
+	if err := applyPostStatus(post, status, req.PublishAt, time.Now()); err != nil {
+		return nil, err
+	}
+	filterInput, verdict, err := s.filterPost(post)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TypeData != nil || req.Type == "review" {
 		switch req.Type {
 		case "review":
-			if reviewData, ok := req.TypeData.(map[string]interface{}); ok {
-				if bookIDFloat, exists := reviewData["book_id"].(float64); exists {
-					if bookID := uint(bookIDFloat); bookID > 0 {
-						if book, err := s.bookRepo.GetByID(bookID); err == nil {
-							reviewData["book_title"] = book.Title
-							reviewData["book_author"] = book.Author
-						}
-					}
-				}
-				req.TypeData = reviewData
+			reviewData, err := s.prepareReview(userID, nil, req.TypeData)
+			if err != nil {
+				return nil, err
 			}
+			req.TypeData = reviewData
 		case "annotation":
 			if annotationData, ok := req.TypeData.(map[string]interface{}); ok {
 				if bookIDFloat, exists := annotationData["book_id"].(float64); exists {
@@ -84,17 +179,11 @@ func (s *PostService) CreatePost(userID uint, req *models.CreatePostRequest) (*m
 				req.TypeData = annotationData
 			}
 		case "poll":
-			if pollData, ok := req.TypeData.(map[string]interface{}); ok {
-				if options, exists := pollData["options"].([]interface{}); exists {
-					for i, option := range options {
-						if optionMap, ok := option.(map[string]interface{}); ok {
-							optionMap["id"] = fmt.Sprintf("opt_%d", i+1)
-							optionMap["votes"] = 0
-						}
-					}
-				}
-				req.TypeData = pollData
+			pollData, err := preparePollData(req.TypeData, time.Now())
+			if err != nil {
+				return nil, err
 			}
+			req.TypeData = pollData
 		}
 
 		typeDataBytes, err := json.Marshal(req.TypeData)
@@ -105,18 +194,100 @@ func (s *PostService) CreatePost(userID uint, req *models.CreatePostRequest) (*m
 	}
 
 	if err := s.postRepo.Create(post); err != nil {
+		if isDuplicateReview(err) {
+			return nil, ErrAlreadyReviewed
+		}
 		return nil, err
 	}
+	if len(req.AttachmentIDs) > 0 {
+		if err := s.attachments.AttachToPost(post.ID, req.AttachmentIDs); err != nil {
+			return nil, err
+		}
+	}
+	s.queueFiltered(post, filterInput, verdict)
+	s.syncMentions(post)
+	s.syncHashtags(post)
 
 	created, err := s.postRepo.GetByID(post.ID)
 	if err != nil {
 		return nil, err
 	}
-	response := created.ToResponse()
-	return &response, nil
+	if created.IsPublished() {
+		s.notifyPublished(created, false)
+	}
+	return s.renderOwn(created)
+}
+
+// renderOwn is the response to the author after a change to their post.
+func (s *PostService) renderOwn(post *models.Post) (*models.PostResponse, error) {
+	responses := []models.PostResponse{post.ToResponse()}
+	if err := s.reshares.EmbedPosts(&post.UserID, responses, false); err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+// filterPost runs a post that is being published or scheduled through the
+// content filter; drafts are only seen by their author and are checked once
+// they go out. A held post is kept unpublished until a moderator approves it.
+func (s *PostService) filterPost(post *models.Post) (*FilterInput, *FilterVerdict, error) {
+	if !post.IsPublished() && post.Status != models.PostStatusScheduled {
+		return nil, nil, nil
+	}
+	input := &FilterInput{
+		Kind:     models.ReportTargetPost,
+		ID:       post.ID,
+		AuthorID: post.UserID,
+		ClubID:   post.ClubID,
+		Text:     post.Title + "\n\n" + post.Content,
+		Body:     post.Content,
+	}
+	verdict, err := s.contentFilter.Check(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	if verdict.Held() {
+		post.Status = models.PostStatusHeld
+		post.PublishAt = nil
+		// an edited post keeps its publish time; a new one is published on approval
+		if post.ID == 0 {
+			post.PublishedAt = nil
+		}
+	}
+	return input, verdict, nil
+}
+
+func (s *PostService) queueFiltered(post *models.Post, input *FilterInput, verdict *FilterVerdict) {
+	if input != nil {
+		s.contentFilter.Queue(input, verdict, post.ID, post.Title+"\n\n"+post.Content)
+	}
 }
 
-func (s *PostService) GetPostByID(id uint) (*models.PostResponse, error) {
+// applyPostStatus moves an unpublished post to status. Scheduled posts must
+// have a publish time in the future.
+func applyPostStatus(post *models.Post, status string, publishAt *time.Time, now time.Time) error {
+	switch status {
+	case models.PostStatusScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return ErrPublishAtRequired
+		}
+		post.PublishAt = publishAt
+		post.PublishedAt = nil
+	case models.PostStatusPublished:
+		post.PublishAt = nil
+		post.PublishedAt = &now
+	default:
+		post.PublishAt = nil
+		post.PublishedAt = nil
+	}
+	post.Status = status
+	return nil
+}
+
+// GetPostByID returns a post. Drafts and scheduled posts are only found for
+// their author; viewerID is nil for anonymous requests. Spoilers for the
+// viewer are collapsed unless revealSpoilers is set.
+func (s *PostService) GetPostByID(id uint, viewerID *uint, revealSpoilers bool) (*models.PostResponse, error) {
 	post, err := s.postRepo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -124,12 +295,27 @@ func (s *PostService) GetPostByID(id uint) (*models.PostResponse, error) {
 		}
 		return nil, err
 	}
+	if !post.IsPublished() && (viewerID == nil || *viewerID != post.UserID) {
+		return nil, errors.New("post not found")
+	}
 
-	response := post.ToResponse()
-	return &response, nil
+	responses := []models.PostResponse{post.ToResponse()}
+	if err := s.attachPollViews(viewerID, []models.Post{*post}, responses); err != nil {
+		return nil, err
+	}
+	if err := s.spoilerService.GatePosts(viewerID, responses, revealSpoilers); err != nil {
+		return nil, err
+	}
+	if err := s.reshares.EmbedPosts(viewerID, responses, revealSpoilers); err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
 }
 
-func (s *PostService) UpdatePost(id uint, req *models.UpdatePostRequest) (*models.PostResponse, error) {
+// UpdatePost applies the changes and keeps the previous version in the post's
+// edit history. Drafts and scheduled posts have no history until they are
+// published, and only their author can change them.
+func (s *PostService) UpdatePost(id, editorID uint, req *models.UpdatePostRequest) (*models.PostResponse, error) {
 	post, err := s.postRepo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -138,29 +324,110 @@ func (s *PostService) UpdatePost(id uint, req *models.UpdatePostRequest) (*model
 		return nil, err
 	}
 
+	wasPublished := post.IsPublished()
+	if !wasPublished && post.UserID != editorID {
+		return nil, errors.New("post not found")
+	}
+	if post.Status == models.PostStatusHidden {
+		return nil, ErrPostHidden
+	}
+	if post.Status == models.PostStatusHeld {
+		return nil, ErrPostHeld
+	}
+
+	if req.Status != nil || req.PublishAt != nil {
+		status := post.Status
+		if req.Status != nil {
+			status = *req.Status
+		}
+		if wasPublished && (status != models.PostStatusPublished || req.PublishAt != nil) {
+			return nil, ErrPostAlreadyPublished
+		}
+		if !wasPublished {
+			publishAt := post.PublishAt
+			if req.PublishAt != nil {
+				publishAt = req.PublishAt
+			}
+			if err := applyPostStatus(post, status, publishAt, time.Now()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if req.Title != nil {
 		post.Title = *req.Title
 	}
 	if req.Content != nil {
 		post.Content = *req.Content
+		post.RenderContent()
 	}
-	if req.Type != nil {
+	typeChanged := req.Type != nil && *req.Type != post.Type
+	if typeChanged {
+		if post.SharedPostID != nil || *req.Type == "post" {
+			return nil, ErrReshareType
+		}
 		post.Type = *req.Type
 	}
+	// the rating of a review feeds its book's rating, so new review data is
+	// checked like a new review's
+	if post.Type == "review" && (req.TypeData != nil || typeChanged) {
+		reviewData, err := s.prepareReview(post.UserID, post, req.TypeData)
+		if err != nil {
+			return nil, err
+		}
+		typeData, err := json.Marshal(reviewData)
+		if err != nil {
+			return nil, err
+		}
+		post.TypeData = models.PostTypeData(typeData)
+	}
 	if req.IsPinned != nil {
 		post.IsPinned = *req.IsPinned
 	}
+	if req.AttachmentIDs != nil {
+		if err := s.attachments.CheckForPost(post.UserID, post.ClubID, post.ID, *req.AttachmentIDs); err != nil {
+			return nil, err
+		}
+	}
 
-	if err := s.postRepo.Update(post); err != nil {
+	var filterInput *FilterInput
+	var verdict *FilterVerdict
+	if req.Title != nil || req.Content != nil || req.Status != nil || req.PublishAt != nil {
+		if filterInput, verdict, err = s.filterPost(post); err != nil {
+			return nil, err
+		}
+	}
+
+	if wasPublished {
+		err = s.postRepo.UpdateWithRevision(post, editorID)
+	} else {
+		err = s.postRepo.Update(post)
+	}
+	if err != nil {
+		if isDuplicateReview(err) {
+			return nil, ErrAlreadyReviewed
+		}
 		return nil, err
 	}
+	if req.AttachmentIDs != nil {
+		if err := s.attachments.AttachToPost(post.ID, *req.AttachmentIDs); err != nil {
+			return nil, err
+		}
+	}
+	s.queueFiltered(post, filterInput, verdict)
+	if req.Content != nil {
+		s.syncMentions(post)
+		s.syncHashtags(post)
+	}
 
 	created, err := s.postRepo.GetByID(post.ID)
 	if err != nil {
 		return nil, err
 	}
-	response := created.ToResponse()
-	return &response, nil
+	if !wasPublished && created.IsPublished() {
+		s.notifyPublished(created, false)
+	}
+	return s.renderOwn(created)
 }
 
 func (s *PostService) DeletePost(id uint) error {
@@ -172,10 +439,52 @@ func (s *PostService) DeletePost(id uint) error {
 		return err
 	}
 
-	return s.postRepo.Delete(id)
+	if err := s.postRepo.Delete(id); err != nil {
+		return err
+	}
+	s.attachments.DeleteForPost(id)
+	return nil
+}
+
+// HidePost takes a post out of every listing on a moderator's decision. The
+// author still sees it but can no longer edit or republish it.
+func (s *PostService) HidePost(id uint) error {
+	if _, err := s.postRepo.GetByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("post not found")
+		}
+		return err
+	}
+	return s.postRepo.SetStatus(id, models.PostStatusHidden)
+}
+
+// ReleasePost publishes a post the content filter held, once a moderator
+// approved it. It returns false for posts that were not held.
+func (s *PostService) ReleasePost(id uint) (bool, error) {
+	post, err := s.postRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, errors.New("post not found")
+		}
+		return false, err
+	}
+	if post.Status != models.PostStatusHeld {
+		return false, nil
+	}
+
+	post.Status = models.PostStatusPublished
+	if post.PublishedAt == nil {
+		now := time.Now()
+		post.PublishedAt = &now
+	}
+	if err := s.postRepo.Update(post); err != nil {
+		return false, err
+	}
+	s.notifyPublished(post, false)
+	return true, nil
 }
 
-func (s *PostService) ListPostsByUserID(userID uint) ([]models.PostResponse, error) {
+func (s *PostService) ListPostsByUserID(userID uint, req pagination.Request) (*pagination.Page[models.PostResponse], error) {
 	_, err := s.userRepo.GetByID(userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -184,22 +493,19 @@ func (s *PostService) ListPostsByUserID(userID uint) ([]models.PostResponse, err
 		return nil, err
 	}
 
-	posts, err := s.postRepo.ListByUserID(userID)
+	page, err := req.Params()
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("no posts found for this user")
-		}
 		return nil, err
 	}
 
-	var responses []models.PostResponse
-	for _, post := range posts {
-		responses = append(responses, post.ToResponse())
+	posts, err := s.postRepo.ListByUserID(userID, page)
+	if err != nil {
+		return nil, err
 	}
-	return responses, nil
+	return s.publicResponsePage(posts)
 }
 
-func (s *PostService) ListPostsByClubID(clubID uint) ([]models.PostResponse, error) {
+func (s *PostService) ListPostsByClubID(clubID uint, req pagination.Request) (*pagination.Page[models.PostResponse], error) {
 	_, err := s.clubRepo.GetByID(clubID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -208,54 +514,152 @@ func (s *PostService) ListPostsByClubID(clubID uint) ([]models.PostResponse, err
 		return nil, err
 	}
 
-	posts, err := s.postRepo.ListByClubID(clubID)
+	page, err := req.Params()
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("no posts found for this club")
-		}
 		return nil, err
 	}
 
-	var responses []models.PostResponse
-	for _, post := range posts {
-		responses = append(responses, post.ToResponse())
+	posts, err := s.postRepo.ListByClubID(clubID, page)
+	if err != nil {
+		return nil, err
+	}
+	return s.publicResponsePage(posts)
+}
+
+func (s *PostService) ListAllPosts(req pagination.Request) (*pagination.Page[models.PostResponse], error) {
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.postRepo.ListAll(page)
+	if err != nil {
+		return nil, err
+	}
+	return s.publicResponsePage(posts)
+}
+
+func postResponsePage(posts pagination.Page[models.Post]) *pagination.Page[models.PostResponse] {
+	page := pagination.Map(posts, func(p models.Post) models.PostResponse { return p.ToResponse() })
+	return &page
+}
+
+// publicResponsePage is postResponsePage for listings that do not know the
+// viewer: reshares only preview originals in public clubs.
+func (s *PostService) publicResponsePage(posts pagination.Page[models.Post]) (*pagination.Page[models.PostResponse], error) {
+	responses := postResponsePage(posts)
+	if err := s.reshares.EmbedPosts(nil, responses.Items, false); err != nil {
+		return nil, err
 	}
 	return responses, nil
 }
 
-func (s *PostService) ListAllPosts() ([]models.PostResponse, error) {
-	posts, err := s.postRepo.ListAll()
+// ListPostsByStatus lists the user's own drafts or scheduled posts.
+func (s *PostService) ListPostsByStatus(userID uint, status string, req pagination.Request) (*pagination.Page[models.PostResponse], error) {
+	page, err := req.Params()
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []models.PostResponse
-	for _, post := range posts {
-		responses = append(responses, post.ToResponse())
+	posts, err := s.postRepo.ListByStatusForUser(userID, status, page)
+	if err != nil {
+		return nil, err
+	}
+	responses := postResponsePage(posts)
+	if err := s.reshares.EmbedPosts(&userID, responses.Items, false); err != nil {
+		return nil, err
 	}
 	return responses, nil
 }
 
-func (s *PostService) ListPostSummaries(clubID uint, userID *uint, limit, offset int) ([]models.PostSummary, error) {
-	posts, err := s.postRepo.ListPostSummaries(clubID, userID, limit, offset)
+// PublishDuePosts publishes scheduled posts whose publish time has passed and
+// returns how many it published.
+func (s *PostService) PublishDuePosts(now time.Time) (int, error) {
+	due, err := s.postRepo.ListDueScheduled(now, duePostsBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for i := range due {
+		post := &due[i]
+		ok, err := s.postRepo.Publish(post.ID, now)
+		if err != nil {
+			return published, err
+		}
+		if !ok {
+			continue
+		}
+		post.Status = models.PostStatusPublished
+		post.PublishAt = nil
+		post.PublishedAt = &now
+		s.notifyPublished(post, true)
+		published++
+	}
+	return published, nil
+}
+
+// ListPostSummaries lists a club's posts, newest first unless req asks for
+// another sort.
+func (s *PostService) ListPostSummaries(clubID uint, userID *uint, req *models.PostListRequest, revealSpoilers bool) (*pagination.Page[models.PostSummary], error) {
+	page, err := req.Params()
 	if err != nil {
 		return nil, err
 	}
 
-	return posts, nil
+	posts, err := s.postRepo.ListPostSummaries(clubID, userID, postListOptions(req, models.PostSortNew, time.Now()), page)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.spoilerService.GateSummaries(userID, posts.Items, revealSpoilers); err != nil {
+		return nil, err
+	}
+	if err := s.reshares.EmbedSummaries(userID, posts.Items, revealSpoilers); err != nil {
+		return nil, err
+	}
+
+	return &posts, nil
 }
 
-func (s *PostService) ListPublicPosts() ([]models.PostResponse, error) {
-	posts, err := s.postRepo.ListPublicPosts()
+// ListPublicPosts lists posts in public clubs, hottest first unless req asks
+// for another sort.
+func (s *PostService) ListPublicPosts(req *models.PostListRequest) (*pagination.Page[models.PostResponse], error) {
+	page, err := req.Params()
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []models.PostResponse
-	for _, post := range posts {
-		responses = append(responses, post.ToResponse())
+	posts, err := s.postRepo.ListPublicPosts(postListOptions(req, models.PostSortHot, time.Now()), page)
+	if err != nil {
+		return nil, err
 	}
-	return responses, nil
+	return s.publicResponsePage(posts)
+}
+
+// postListOptions resolves req at now. fallback is the sort used when req
+// names none; top without a window covers all time, and a month is 30 days.
+func postListOptions(req *models.PostListRequest, fallback string, now time.Time) models.PostListOptions {
+	opts := models.PostListOptions{Sort: req.Sort}
+	if opts.Sort == "" {
+		opts.Sort = fallback
+	}
+	if opts.Sort != models.PostSortTop {
+		return opts
+	}
+
+	var since time.Time
+	switch req.Window {
+	case models.TopWindowDay:
+		since = now.AddDate(0, 0, -1)
+	case models.TopWindowWeek:
+		since = now.AddDate(0, 0, -7)
+	case models.TopWindowMonth:
+		since = now.AddDate(0, 0, -30)
+	default:
+		return opts
+	}
+	opts.Since = &since
+	return opts
 }
 
 func (s *PostService) ListPopularPublicPosts(limit int) ([]models.PostResponse, error) {
@@ -268,6 +672,9 @@ func (s *PostService) ListPopularPublicPosts(limit int) ([]models.PostResponse,
 	for _, post := range posts {
 		responses = append(responses, post.ToResponse())
 	}
+	if err := s.reshares.EmbedPosts(nil, responses, false); err != nil {
+		return nil, err
+	}
 	return responses, nil
 }
 
@@ -287,6 +694,9 @@ func (s *PostService) LikePost(userID, postID uint) error {
 		}
 		return err
 	}
+	if !post.IsPublished() {
+		return errors.New("post not found")
+	}
 
 	hasLiked, err := s.postRepo.HasUserLiked(userID, postID)
 	if err != nil {
@@ -296,25 +706,11 @@ func (s *PostService) LikePost(userID, postID uint) error {
 		return errors.New("user has already liked this post")
 	}
 
-	err = s.postRepo.AddLike(&models.PostLike{
-		UserID: userID,
-		PostID: postID,
-	})
-	if err != nil {
+	if err := s.postRepo.AddLike(userID, postID); err != nil {
 		return err
 	}
-
-	count, err := s.postRepo.CountLikes(postID)
-	if err != nil {
-		return err
-	}
-
-	post.LikesCount = int(count)
-	if err := s.postRepo.Update(post); err != nil {
-		return err
-	}
-
-	return s.postRepo.UpdateLikesCount(postID, int(count))
+	s.notifyEngaged(postID)
+	return nil
 }
 
 func (s *PostService) UnlikePost(userID, postID uint) error {
@@ -345,16 +741,11 @@ func (s *PostService) UnlikePost(userID, postID uint) error {
 	if err := s.postRepo.RemoveLike(userID, postID); err != nil {
 		return err
 	}
-
-	count, err := s.postRepo.CountLikes(postID)
-	if err != nil {
-		return err
-	}
-
-	return s.postRepo.UpdateLikesCount(postID, int(count))
+	s.notifyEngaged(postID)
+	return nil
 }
 
-func (s *PostService) ListLikesByPostID(postID uint) ([]models.PostLikeResponse, error) {
+func (s *PostService) ListLikesByPostID(postID uint, req pagination.Request) (*pagination.Page[models.PostLikeResponse], error) {
 	_, err := s.postRepo.GetByID(postID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -363,105 +754,52 @@ func (s *PostService) ListLikesByPostID(postID uint) ([]models.PostLikeResponse,
 		return nil, err
 	}
 
-	likes, err := s.postRepo.ListLikesByPostID(postID)
+	page, err := req.Params()
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("no likes found for this post")
-		}
 		return nil, err
 	}
 
-	return likes, nil
+	likes, err := s.postRepo.ListLikesByPostID(postID, page)
+	if err != nil {
+		return nil, err
+	}
+	return &likes, nil
 }
 
+// VoteOnPoll records a vote. Plurality votes add to the user's earlier ones
+// where the poll allows several options; a ranked ballot replaces the
+// user's earlier ballot.
 func (s *PostService) VoteOnPoll(postID, userID uint, req *models.PollVoteRequest) error {
-	post, err := s.postRepo.GetByID(postID)
+	_, pollData, err := s.getPoll(postID)
 	if err != nil {
 		return err
 	}
-
-	if post.Type != "poll" {
-		return errors.New("post is not a poll")
-	}
-
-	pollData, err := post.GetPollData()
-	if err != nil || pollData == nil {
-		return errors.New("invalid poll data")
-	}
-
-	if pollData.ExpiresAt != nil && pollData.ExpiresAt.Before(time.Now()) {
-		return errors.New("poll has expired")
+	if pollData.IsClosed(time.Now()) {
+		return ErrPollClosed
 	}
-
-	existingVotes, err := s.postRepo.GetUserPollVotes(postID, userID)
-	if err != nil {
+	if err := checkBallot(pollData, req.OptionIDs); err != nil {
 		return err
 	}
 
-	if len(existingVotes) > 0 && !pollData.AllowMultiple {
-		return errors.New("multiple votes not allowed")
+	votes := make([]models.PollVote, len(req.OptionIDs))
+	for i, optionID := range req.OptionIDs {
+		votes[i] = models.PollVote{PostID: postID, UserID: userID, OptionID: optionID}
 	}
-
-	validOptions := make(map[string]bool)
-	for _, option := range pollData.Options {
-		validOptions[option.ID] = true
-	}
-
-	for _, optionID := range req.OptionIDs {
-		if !validOptions[optionID] {
-			return fmt.Errorf("invalid option ID: %s", optionID)
+	if pollData.Method == models.PollMethodRankedChoice {
+		for i := range votes {
+			votes[i].Rank = i + 1
 		}
+		return s.postRepo.ReplacePollVotes(postID, userID, votes)
 	}
 
-	if !pollData.AllowMultiple {
-		for _, vote := range existingVotes {
-			if err := s.postRepo.RemoveVoteFromPoll(postID, userID, vote.OptionID); err != nil {
-				return err
-			}
-		}
-	}
-
-	for _, optionID := range req.OptionIDs {
-		vote := &models.PollVote{
-			PostID:   postID,
-			UserID:   userID,
-			OptionID: optionID,
-		}
-		if err := s.postRepo.VoteOnPoll(vote); err != nil {
-			return err
-		}
-	}
-
-	return s.updatePollCounts(postID)
-}
-
-func (s *PostService) updatePollCounts(postID uint) error {
-	var votes []models.PollVote
-	s.db.Where("post_id = ?", postID).Find(&votes)
-
-	voteCounts := make(map[string]int)
-	for _, vote := range votes {
-		voteCounts[vote.OptionID]++
-	}
-
-	post, err := s.postRepo.GetByID(postID)
+	added, err := s.postRepo.AddPollVotes(postID, userID, votes, !pollData.AllowMultiple)
 	if err != nil {
 		return err
 	}
-
-	pollData, err := post.GetPollData()
-	if err != nil {
-		return err
+	if !added {
+		return ErrPollAlreadyVoted
 	}
-
-	for i := range pollData.Options {
-		pollData.Options[i].Votes = voteCounts[pollData.Options[i].ID]
-	}
-
-	typeDataBytes, _ := json.Marshal(pollData)
-	post.TypeData = models.PostTypeData(typeDataBytes)
-
-	return s.postRepo.Update(post)
+	return nil
 }
 
 func (s *PostService) GetPostByIDForUser(postID, userID uint) (*models.PostResponse, error) {
@@ -469,23 +807,32 @@ func (s *PostService) GetPostByIDForUser(postID, userID uint) (*models.PostRespo
     if err != nil {
         return nil, err
     }
+    if !post.IsPublished() && post.UserID != userID {
+        return nil, errors.New("post not found")
+    }
     
     response := post.ToResponse()
     
     if post.Type == "poll" {
-        userVotes, err := s.postRepo.GetUserPollVotes(postID, userID)
-        if err == nil {
-            response.UserVoted = len(userVotes) > 0
-            for _, vote := range userVotes {
-                response.UserVotes = append(response.UserVotes, vote.OptionID)
-            }
+        responses := []models.PostResponse{response}
+        if err := s.attachPollViews(&userID, []models.Post{*post}, responses); err != nil {
+            return nil, err
         }
+        response = responses[0]
+        if response.Poll != nil {
+            response.UserVoted = len(response.Poll.UserVotes) > 0
+            response.UserVotes = response.Poll.UserVotes
+        }
+    }
+    responses := []models.PostResponse{response}
+    if err := s.reshares.EmbedPosts(&userID, responses, false); err != nil {
+        return nil, err
     }
     
-    return &response, nil
+    return &responses[0], nil
 }
 
-func (s *PostService) GetReviewsByBook(bookID uint) ([]models.PostResponse, error) {
+func (s *PostService) GetReviewsByBook(bookID uint, viewerID *uint, req pagination.Request, revealSpoilers bool) (*pagination.Page[models.PostResponse], error) {
 	_, err := s.bookRepo.GetByID(bookID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -494,35 +841,51 @@ func (s *PostService) GetReviewsByBook(bookID uint) ([]models.PostResponse, erro
 		return nil, err
 	}
 
-	posts, err := s.postRepo.GetReviewPostsByBookID(bookID)
+	page, err := req.Params()
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("no reviews found for this book")
-		}
 		return nil, err
 	}
 
-	var responses []models.PostResponse
-	for _, post := range posts {
-		responses = append(responses, post.ToResponse())
+	posts, err := s.postRepo.GetReviewPostsByBookID(bookID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := postResponsePage(posts)
+	if err := s.spoilerService.GatePosts(viewerID, responses.Items, revealSpoilers); err != nil {
+		return nil, err
 	}
 	return responses, nil
 }
 
-func (s *PostService) GetPostsByType(postType string, limit, offset int) ([]models.PostResponse, error) {
-	posts, err := s.postRepo.GetPostsByType(postType, limit, offset)
+func (s *PostService) GetPostsByType(postType string, req pagination.Request, viewerID *uint, revealSpoilers bool) (*pagination.Page[models.PostResponse], error) {
+	page, err := req.Params()
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []models.PostResponse
-	for _, post := range posts {
-		responses = append(responses, post.ToResponse())
+	posts, err := s.postRepo.GetPostsByType(postType, page)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := postResponsePage(posts)
+	if err := s.attachPollViews(viewerID, posts.Items, responses.Items); err != nil {
+		return nil, err
+	}
+	if err := s.spoilerService.GatePosts(viewerID, responses.Items, revealSpoilers); err != nil {
+		return nil, err
+	}
+	if err := s.reshares.EmbedPosts(viewerID, responses.Items, revealSpoilers); err != nil {
+		return nil, err
 	}
 	return responses, nil
 }
 
-func (s *PostService) GetPollPostsByClubID(clubID uint, includeExpired bool) ([]models.PostResponse, error) {
+// GetPollPostsByClubID lists a club's polls with the poll state viewerID
+// sees. Unless includeExpired is set, polls that expired or were closed are
+// left out.
+func (s *PostService) GetPollPostsByClubID(clubID, viewerID uint, includeExpired bool, req pagination.Request) (*pagination.Page[models.PostResponse], error) {
 	_, err := s.clubRepo.GetByID(clubID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -531,26 +894,30 @@ func (s *PostService) GetPollPostsByClubID(clubID uint, includeExpired bool) ([]
 		return nil, err
 	}
 
-	posts, err := s.postRepo.GetPollPostsByClubID(clubID, includeExpired)
+	page, err := req.Params()
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []models.PostResponse
-	for _, post := range posts {
-		responses = append(responses, post.ToResponse())
+	posts, err := s.postRepo.GetPollPostsByClubID(clubID, includeExpired, page)
+	if err != nil {
+		return nil, err
+	}
+	responses := postResponsePage(posts)
+	if err := s.attachPollViews(&viewerID, posts.Items, responses.Items); err != nil {
+		return nil, err
 	}
 	return responses, nil
 }
 
 func (s *PostService) RemoveVoteFromPoll(postID, userID uint, optionID string) error {
-	_, err := s.postRepo.GetByID(postID)
+	_, pollData, err := s.getPoll(postID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("post not found")
-		}
 		return err
 	}
+	if pollData.IsClosed(time.Now()) {
+		return ErrPollClosed
+	}
 
 	_, err = s.userRepo.GetByID(userID)
 	if err != nil {
@@ -560,6 +927,20 @@ func (s *PostService) RemoveVoteFromPoll(postID, userID uint, optionID string) e
 		return err
 	}
 
+	if pollData.Method == models.PollMethodRankedChoice {
+		// the rest of the ballot moves up so its first preference is counted
+		votes, err := s.postRepo.GetUserPollVotes(postID, userID)
+		if err != nil {
+			return err
+		}
+		ballot := make([]models.PollVote, 0, len(votes))
+		for _, v := range votes {
+			if v.OptionID != optionID {
+				ballot = append(ballot, models.PollVote{PostID: postID, UserID: userID, OptionID: v.OptionID, Rank: len(ballot) + 1})
+			}
+		}
+		return s.postRepo.ReplacePollVotes(postID, userID, ballot)
+	}
 	return s.postRepo.RemoveVoteFromPoll(postID, userID, optionID)
 }
 
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// PostScheduler periodically publishes scheduled posts whose publish time has
+// passed. Publishing is conditional in the database, so several server
+// instances can run a scheduler at the same time.
+type PostScheduler struct {
+	postService *PostService
+	interval    time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+func NewPostScheduler(postService *PostService, interval time.Duration) *PostScheduler {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &PostScheduler{
+		postService: postService,
+		interval:    interval,
+		stop:        make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler in the background until Stop is called.
+func (s *PostScheduler) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.RunOnce(time.Now())
+		for {
+			select {
+			case <-s.stop:
+				return
+			case now := <-ticker.C:
+				s.RunOnce(now)
+			}
+		}
+	}()
+}
+
+func (s *PostScheduler) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// RunOnce publishes everything that is due at now.
+func (s *PostScheduler) RunOnce(now time.Time) {
+	published, err := s.postService.PublishDuePosts(now)
+	if err != nil {
+		logger.Error("failed to publish scheduled posts", zap.Error(err))
+	}
+	if published > 0 {
+		logger.Info("published scheduled posts", zap.Int("count", published))
+	}
+}
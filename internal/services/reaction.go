@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReactionNotAllowed     = errors.New("reaction is not allowed")
+	ErrAlreadyReacted         = errors.New("reaction already added")
+	ErrReactionNotFound       = errors.New("reaction not found")
+	ErrReactionTargetNotFound = errors.New("reaction target not found")
+)
+
+type ReactionService struct {
+	reactionRepo repository.ReactionRepository
+	postRepo     repository.PostRepository
+	commentRepo  repository.CommentRepository
+	config       *config.Config
+	engagementListeners
+}
+
+func NewReactionService(reactionRepo repository.ReactionRepository, postRepo repository.PostRepository, commentRepo repository.CommentRepository, config *config.Config) *ReactionService {
+	return &ReactionService{
+		reactionRepo: reactionRepo,
+		postRepo:     postRepo,
+		commentRepo:  commentRepo,
+		config:       config,
+	}
+}
+
+func (s *ReactionService) AllowedReactions() []string {
+	return s.config.Reactions.Allowed
+}
+
+func (s *ReactionService) isAllowed(emoji string) bool {
+	for _, allowed := range s.config.Reactions.Allowed {
+		if allowed == emoji {
+			return true
+		}
+	}
+	return false
+}
+
+// React adds one of the configured reactions and returns the updated summary
+// of the target.
+func (s *ReactionService) React(userID uint, targetType string, targetID uint, emoji string) ([]models.ReactionSummary, error) {
+	if !s.isAllowed(emoji) {
+		return nil, ErrReactionNotAllowed
+	}
+	if _, err := s.targetCounts(targetType, targetID); err != nil {
+		return nil, err
+	}
+
+	added, err := s.reactionRepo.Add(&models.Reaction{
+		TargetType: targetType,
+		TargetID:   targetID,
+		UserID:     userID,
+		Emoji:      emoji,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, ErrAlreadyReacted
+	}
+	s.reactedTo(targetType, targetID)
+
+	return s.summaries(userID, targetType, targetID)
+}
+
+func (s *ReactionService) Unreact(userID uint, targetType string, targetID uint, emoji string) ([]models.ReactionSummary, error) {
+	if _, err := s.targetCounts(targetType, targetID); err != nil {
+		return nil, err
+	}
+
+	removed, err := s.reactionRepo.Remove(targetType, targetID, userID, emoji)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		return nil, ErrReactionNotFound
+	}
+	s.reactedTo(targetType, targetID)
+
+	return s.summaries(userID, targetType, targetID)
+}
+
+// reactedTo tells engagement listeners about reactions on posts; reactions
+// on comments do not count towards a post's engagement.
+func (s *ReactionService) reactedTo(targetType string, targetID uint) {
+	if targetType == models.ReactionTargetPost {
+		s.notifyEngaged(targetID)
+	}
+}
+
+// ListReactions pages through who reacted, optionally for a single emoji.
+func (s *ReactionService) ListReactions(targetType string, targetID uint, req *models.ListReactionsRequest) (*pagination.Page[models.ReactionResponse], error) {
+	if _, err := s.targetCounts(targetType, targetID); err != nil {
+		return nil, err
+	}
+
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	reactions, err := s.reactionRepo.List(targetType, targetID, req.Emoji, page)
+	if err != nil {
+		return nil, err
+	}
+
+	response := pagination.Map(reactions, models.Reaction.ToResponse)
+	return &response, nil
+}
+
+func (s *ReactionService) summaries(userID uint, targetType string, targetID uint) ([]models.ReactionSummary, error) {
+	counts, err := s.targetCounts(targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	reacted, err := s.reactionRepo.ListUserReactions(targetType, userID, []uint{targetID})
+	if err != nil {
+		return nil, err
+	}
+	return counts.Summaries(reacted[targetID]), nil
+}
+
+func (s *ReactionService) targetCounts(targetType string, targetID uint) (models.ReactionCounts, error) {
+	switch targetType {
+	case models.ReactionTargetPost:
+		post, err := s.postRepo.GetByID(targetID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrReactionTargetNotFound
+			}
+			return nil, err
+		}
+		if !post.IsPublished() {
+			return nil, ErrReactionTargetNotFound
+		}
+		return post.ReactionCounts, nil
+	case models.ReactionTargetComment:
+		comment, err := s.commentRepo.GetByID(targetID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrReactionTargetNotFound
+			}
+			return nil, err
+		}
+		if comment.IsDeleted {
+			return nil, ErrReactionTargetNotFound
+		}
+		return comment.ReactionCounts, nil
+	default:
+		return nil, ErrReactionTargetNotFound
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/nevzattalhaozcan/forgotten/internal/config"
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
 	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"gorm.io/gorm"
 )
 
@@ -193,30 +194,46 @@ func hasBadge(badges []string, badge string) bool {
 	return slices.Contains(badges, badge)
 }
 
-func (s *ReadingService) ListUserProgress(userID uint) ([]*models.UserBookProgressResponse, error) {
-	entries, err := s.readRepo.ListUserProgress(userID)
+func (s *ReadingService) ListUserProgress(userID uint, req pagination.Request) (*pagination.Page[*models.UserBookProgressResponse], error) {
+	p, err := req.Params()
 	if err != nil {
 		return nil, err
 	}
-	var out []*models.UserBookProgressResponse
-	for _, e := range entries {
+	entries, err := s.readRepo.ListUserProgress(userID, p)
+	if err != nil {
+		return nil, err
+	}
+	out := &pagination.Page[*models.UserBookProgressResponse]{
+		Items:      []*models.UserBookProgressResponse{},
+		NextCursor: entries.NextCursor,
+		HasMore:    entries.HasMore,
+	}
+	for _, e := range entries.Items {
 		book, berr := s.bookRepo.GetByID(e.BookID)
 		if berr != nil {
 			continue
 		}
 		r := e.ToResponse(book)
-		out = append(out, &r)
+		out.Items = append(out.Items, &r)
 	}
 	return out, nil
 }
 
-func (s *ReadingService) UserReadingHistory(userID uint) ([]models.UserReadingHistoryItem, error) {
-	finished, err := s.readRepo.ListUserFinished(userID)
+func (s *ReadingService) UserReadingHistory(userID uint, req pagination.Request) (*pagination.Page[models.UserReadingHistoryItem], error) {
+	p, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+	finished, err := s.readRepo.ListUserFinished(userID, p)
 	if err != nil {
 		return nil, err
 	}
-	var out []models.UserReadingHistoryItem
-	for _, p := range finished {
+	out := &pagination.Page[models.UserReadingHistoryItem]{
+		Items:      []models.UserReadingHistoryItem{},
+		NextCursor: finished.NextCursor,
+		HasMore:    finished.HasMore,
+	}
+	for _, p := range finished.Items {
 		book, berr := s.bookRepo.GetByID(p.BookID)
 		if berr != nil {
 			continue
@@ -232,7 +249,7 @@ func (s *ReadingService) UserReadingHistory(userID uint) ([]models.UserReadingHi
 			}(),
 			Logs: logs,
 		}
-		out = append(out, item)
+		out.Items = append(out.Items, item)
 	}
 	return out, nil
 }
@@ -311,18 +328,26 @@ func (s *ReadingService) CompleteClubAssignment(clubID uint) (*models.ClubAssign
 	return resp, nil
 }
 
-func (s *ReadingService) ListClubAssignments(clubID uint) ([]models.ClubAssignmentResponse, error) {
-	as, err := s.clubReadRepo.ListAssignments(clubID)
+func (s *ReadingService) ListClubAssignments(clubID uint, req pagination.Request) (*pagination.Page[models.ClubAssignmentResponse], error) {
+	p, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+	as, err := s.clubReadRepo.ListAssignments(clubID, p)
 	if err != nil {
 		return nil, err
 	}
-	var out []models.ClubAssignmentResponse
-	for _, a := range as {
+	out := &pagination.Page[models.ClubAssignmentResponse]{
+		Items:      []models.ClubAssignmentResponse{},
+		NextCursor: as.NextCursor,
+		HasMore:    as.HasMore,
+	}
+	for _, a := range as.Items {
 		book, berr := s.bookRepo.GetByID(a.BookID)
 		if berr != nil {
 			continue
 		}
-		out = append(out, models.ClubAssignmentResponse{
+		out.Items = append(out.Items, models.ClubAssignmentResponse{
 			ID: a.ID, ClubID: a.ClubID, Book: *book, Status: string(a.Status),
 			StartDate: a.StartDate, DueDate: a.DueDate,
 		})
@@ -336,7 +361,9 @@ func (s *ReadingService) SyncUserStats(userID uint) error {
 		return err
 	}
 
-	finished, err := s.readRepo.ListUserFinished(userID)
+	finished, err := pagination.All(func(p pagination.Params) (pagination.Page[*models.UserBookProgress], error) {
+		return s.readRepo.ListUserFinished(userID, p)
+	})
 	if err != nil {
 		return err
 	}
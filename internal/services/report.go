@@ -0,0 +1,534 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReportTargetNotFound  = errors.New("report target not found")
+	ErrCannotReportSelf      = errors.New("you cannot report yourself or your own content")
+	ErrAlreadyReported       = errors.New("already reported")
+	ErrReportNotFound        = errors.New("report not found")
+	ErrReportAlreadySettled  = errors.New("report already settled")
+	ErrNotModerator          = errors.New("you do not moderate these reports")
+	ErrSuspendNotAllowed     = errors.New("only platform moderators can suspend users")
+	ErrInvalidModerationStep = errors.New("users can only be warned or suspended")
+)
+
+// ReportService files reports and lets moderators decide them. Club
+// moderators and owners moderate reports about their club's posts, comments
+// and ratings; platform moderators (see models.PlatformModeratorRoles)
+// moderate every report, including reports about users, and are the only ones
+// who can suspend.
+type ReportService struct {
+	reportRepo          repository.ReportRepository
+	postRepo            repository.PostRepository
+	commentRepo         repository.CommentRepository
+	clubRatingRepo      repository.ClubRatingRepository
+	userRepo            repository.UserRepository
+	postService         *PostService
+	commentService      *CommentService
+	clubService         *ClubService
+	notificationService *NotificationService
+}
+
+func NewReportService(reportRepo repository.ReportRepository, postRepo repository.PostRepository, commentRepo repository.CommentRepository, clubRatingRepo repository.ClubRatingRepository, userRepo repository.UserRepository, postService *PostService, commentService *CommentService, clubService *ClubService, notificationService *NotificationService) *ReportService {
+	return &ReportService{
+		reportRepo:          reportRepo,
+		postRepo:            postRepo,
+		commentRepo:         commentRepo,
+		clubRatingRepo:      clubRatingRepo,
+		userRepo:            userRepo,
+		postService:         postService,
+		commentService:      commentService,
+		clubService:         clubService,
+		notificationService: notificationService,
+	}
+}
+
+// CreateReport files a report about something the reporter can see.
+func (s *ReportService) CreateReport(reporterID uint, req *models.CreateReportRequest) (*models.Report, error) {
+	report := &models.Report{
+		ReporterID: &reporterID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+		Details:    req.Details,
+		Status:     models.ReportStatusOpen,
+	}
+	if err := s.describeTarget(report); err != nil {
+		return nil, err
+	}
+	if report.TargetUserID == reporterID {
+		return nil, ErrCannotReportSelf
+	}
+	if report.ClubID != nil {
+		readable, err := s.reportRepo.ListReadableClubIDs(reporterID, []uint{*report.ClubID})
+		if err != nil {
+			return nil, err
+		}
+		if !readable[*report.ClubID] {
+			return nil, ErrReportTargetNotFound
+		}
+	}
+
+	created, err := s.reportRepo.Create(report)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrAlreadyReported
+	}
+
+	// ratings also keep their own report count, shown to club admins
+	if report.TargetType == models.ReportTargetRating {
+		if _, err := s.clubRatingRepo.Report(&models.ClubRatingReport{
+			RatingID:   report.TargetID,
+			ReporterID: reporterID,
+			Reason:     reportReasonText(report),
+		}); err != nil {
+			logger.Warn("failed to count rating report", zap.Uint("rating_id", report.TargetID), zap.Error(err))
+		}
+	}
+	return report, nil
+}
+
+// ReportClubRating files a report through the older rating report endpoint,
+// which only takes free text.
+func (s *ReportService) ReportClubRating(clubID, ratingID, reporterID uint, req *models.ReportClubRatingRequest) (*models.Report, error) {
+	rating, err := s.clubRatingRepo.GetByID(ratingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRatingNotFound
+		}
+		return nil, err
+	}
+	if rating.ClubID != clubID {
+		return nil, ErrRatingNotFound
+	}
+
+	report, err := s.CreateReport(reporterID, &models.CreateReportRequest{
+		TargetType: models.ReportTargetRating,
+		TargetID:   ratingID,
+		Reason:     models.ReportReasonOther,
+		Details:    req.Reason,
+	})
+	if errors.Is(err, ErrReportTargetNotFound) {
+		return nil, ErrRatingNotFound
+	}
+	return report, err
+}
+
+// describeTarget fills in who and which club a report is about, and what the
+// reported content said.
+func (s *ReportService) describeTarget(report *models.Report) error {
+	switch report.TargetType {
+	case models.ReportTargetPost:
+		post, err := s.getPublishedPost(report.TargetID)
+		if err != nil {
+			return err
+		}
+		report.TargetUserID = post.UserID
+		report.ClubID = &post.ClubID
+		report.Snapshot = post.Title + "\n\n" + post.Content
+
+	case models.ReportTargetComment:
+		comment, err := s.commentRepo.GetByID(report.TargetID)
+		if err != nil {
+			return notFoundAs(err, ErrReportTargetNotFound)
+		}
+		if comment.IsDeleted || comment.IsHidden {
+			return ErrReportTargetNotFound
+		}
+		post, err := s.getPublishedPost(comment.PostID)
+		if err != nil {
+			return err
+		}
+		report.TargetUserID = comment.UserID
+		report.ClubID = &post.ClubID
+		report.Snapshot = comment.Content
+
+	case models.ReportTargetRating:
+		rating, err := s.clubRatingRepo.GetByID(report.TargetID)
+		if err != nil {
+			return notFoundAs(err, ErrReportTargetNotFound)
+		}
+		if rating.IsHidden {
+			return ErrReportTargetNotFound
+		}
+		report.TargetUserID = rating.UserID
+		report.ClubID = &rating.ClubID
+		report.Snapshot = fmt.Sprintf("%.1f stars", rating.Rating)
+		if rating.Comment != nil {
+			report.Snapshot += "\n\n" + *rating.Comment
+		}
+
+	case models.ReportTargetUser:
+		user, err := s.userRepo.GetByID(report.TargetID)
+		if err != nil {
+			return notFoundAs(err, ErrReportTargetNotFound)
+		}
+		report.TargetUserID = user.ID
+		report.Snapshot = user.Username
+		if user.Bio != nil {
+			report.Snapshot += "\n\n" + *user.Bio
+		}
+
+	default:
+		return ErrReportTargetNotFound
+	}
+	return nil
+}
+
+func (s *ReportService) getPublishedPost(id uint) (*models.Post, error) {
+	post, err := s.postRepo.GetByID(id)
+	if err != nil {
+		return nil, notFoundAs(err, ErrReportTargetNotFound)
+	}
+	if !post.IsPublished() {
+		return nil, ErrReportTargetNotFound
+	}
+	return post, nil
+}
+
+// ListReports returns the moderation queue of the reports the user moderates,
+// open ones by default.
+func (s *ReportService) ListReports(userID uint, role string, req *models.ListReportsRequest) (*pagination.Page[models.Report], error) {
+	clubIDs, err := s.scope(userID, role, req.ClubID)
+	if err != nil {
+		return nil, err
+	}
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := models.ReportFilter{Status: req.Status, TargetType: req.TargetType, ClubIDs: clubIDs}
+	if filter.Status == "" {
+		filter.Status = models.ReportStatusOpen
+	}
+	reports, err := s.reportRepo.List(filter, page)
+	if err != nil {
+		return nil, err
+	}
+	return &reports, nil
+}
+
+func (s *ReportService) GetReport(userID uint, role string, id uint) (*models.Report, error) {
+	return s.getModeratedReport(userID, role, id)
+}
+
+// ResolveReport applies a moderator's decision to the reported target and
+// settles every open report on it. The decision is logged, the reporters are
+// told the outcome, and a warned or suspended user is told why.
+func (s *ReportService) ResolveReport(moderatorID uint, role string, id uint, req *models.ResolveReportRequest) (*models.Report, error) {
+	report, err := s.getModeratedReport(moderatorID, role, id)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != models.ReportStatusOpen {
+		return nil, ErrReportAlreadySettled
+	}
+	if err := checkModerationAction(report.TargetType, req.Action, isPlatformModerator(role)); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	status := models.ReportStatusResolved
+	if req.Action == models.ModerationDismiss {
+		status = models.ReportStatusDismissed
+	}
+	log := &models.ModerationLog{
+		ModeratorID:  moderatorID,
+		ReportID:     report.ID,
+		TargetType:   report.TargetType,
+		TargetID:     report.TargetID,
+		TargetUserID: report.TargetUserID,
+		ClubID:       report.ClubID,
+		Action:       req.Action,
+		Note:         req.Note,
+	}
+
+	// Settle the reports before acting on them, so when two moderators
+	// resolve the same report only the one who settled it applies an action.
+	settled, err := s.reportRepo.Resolve(log, status, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(settled) == 0 {
+		return nil, ErrReportAlreadySettled
+	}
+
+	released, err := s.apply(report, req, now)
+	if err != nil {
+		ids := make([]uint, len(settled))
+		for i, r := range settled {
+			ids[i] = r.ID
+		}
+		if reopenErr := s.reportRepo.Reopen(log, ids); reopenErr != nil {
+			logger.Error("failed to reopen reports after a failed moderation action",
+				zap.Uint("report_id", report.ID),
+				zap.Error(reopenErr),
+			)
+		}
+		return nil, err
+	}
+
+	notifications := make([]models.Notification, 0, len(settled)+1)
+	for _, r := range settled {
+		if r.ID == report.ID {
+			*report = r
+		}
+		if r.ReporterID == nil {
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			UserID:     *r.ReporterID,
+			Type:       models.NotificationReportResolved,
+			Message:    reportOutcomeMessage(r.TargetType, req.Action),
+			TargetType: "report",
+			TargetID:   r.ID,
+		})
+	}
+	if n := authorNotification(report, req, now); n != nil {
+		notifications = append(notifications, *n)
+	}
+	if released {
+		notifications = append(notifications, models.Notification{
+			UserID:     report.TargetUserID,
+			Type:       models.NotificationContentApproved,
+			Message:    "A moderator approved your " + report.TargetType + "; it is now visible to others.",
+			TargetType: report.TargetType,
+			TargetID:   report.TargetID,
+		})
+	}
+	s.notificationService.Notify(notifications...)
+	return report, nil
+}
+
+// apply carries out a decision. Dismissing a report approves content the
+// content filter held, and apply says whether it did. Content that is already
+// gone counts as hidden or deleted.
+func (s *ReportService) apply(report *models.Report, req *models.ResolveReportRequest, now time.Time) (bool, error) {
+	var released bool
+	var err error
+	switch req.Action {
+	case models.ModerationDismiss:
+		switch report.TargetType {
+		case models.ReportTargetPost:
+			released, err = s.postService.ReleasePost(report.TargetID)
+		case models.ReportTargetComment:
+			released, err = s.commentService.ReleaseComment(report.TargetID)
+		}
+	case models.ModerationHide:
+		switch report.TargetType {
+		case models.ReportTargetPost:
+			err = s.postService.HidePost(report.TargetID)
+		case models.ReportTargetComment:
+			err = s.commentService.HideComment(report.TargetID)
+		case models.ReportTargetRating:
+			err = s.clubService.HideRating(report.TargetID)
+		}
+	case models.ModerationDelete:
+		switch report.TargetType {
+		case models.ReportTargetPost:
+			err = s.postService.DeletePost(report.TargetID)
+		case models.ReportTargetComment:
+			err = s.commentService.DeleteComment(report.TargetID)
+		case models.ReportTargetRating:
+			err = s.clubService.DeleteRating(report.TargetID)
+		}
+	case models.ModerationSuspend:
+		err = s.suspend(report.TargetUserID, now.AddDate(0, 0, req.SuspendDays))
+	}
+	if err != nil && targetGone(err) {
+		return false, nil
+	}
+	return released, err
+}
+
+// suspend keeps the user from logging in until the given time. A longer
+// suspension already in place is kept.
+func (s *ReportService) suspend(userID uint, until time.Time) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.SuspendedUntil != nil && user.SuspendedUntil.After(until) {
+		return nil
+	}
+	user.SuspendedUntil = &until
+	return s.userRepo.Update(user)
+}
+
+// ListModerationLog returns the decisions taken on the reports the user
+// moderates, newest first.
+func (s *ReportService) ListModerationLog(userID uint, role string, req *models.ListModerationLogRequest) (*pagination.Page[models.ModerationLog], error) {
+	clubIDs, err := s.scope(userID, role, req.ClubID)
+	if err != nil {
+		return nil, err
+	}
+	page, err := req.Params()
+	if err != nil {
+		return nil, err
+	}
+	logs, err := s.reportRepo.ListLogs(clubIDs, page)
+	if err != nil {
+		return nil, err
+	}
+	return &logs, nil
+}
+
+// scope returns the clubs whose reports the user moderates, narrowed to
+// clubID when given. It returns nil for platform moderators looking at every
+// club.
+func (s *ReportService) scope(userID uint, role string, clubID *uint) ([]uint, error) {
+	if isPlatformModerator(role) {
+		if clubID != nil {
+			return []uint{*clubID}, nil
+		}
+		return nil, nil
+	}
+
+	clubIDs, err := s.reportRepo.ListModeratedClubIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(clubIDs) == 0 {
+		return nil, ErrNotModerator
+	}
+	if clubID != nil {
+		if !slices.Contains(clubIDs, *clubID) {
+			return nil, ErrNotModerator
+		}
+		return []uint{*clubID}, nil
+	}
+	return clubIDs, nil
+}
+
+// getModeratedReport returns a report the user moderates. Reports they do
+// not moderate are reported as missing.
+func (s *ReportService) getModeratedReport(userID uint, role string, id uint) (*models.Report, error) {
+	report, err := s.reportRepo.GetByID(id)
+	if err != nil {
+		return nil, notFoundAs(err, ErrReportNotFound)
+	}
+	if isPlatformModerator(role) {
+		return report, nil
+	}
+	if report.ClubID == nil {
+		return nil, ErrReportNotFound
+	}
+	clubIDs, err := s.reportRepo.ListModeratedClubIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(clubIDs, *report.ClubID) {
+		return nil, ErrReportNotFound
+	}
+	return report, nil
+}
+
+func isPlatformModerator(role string) bool {
+	return slices.Contains(models.PlatformModeratorRoles, role)
+}
+
+// checkModerationAction reports whether action can be taken on a report
+// about targetType by a platform or club moderator.
+func checkModerationAction(targetType, action string, platform bool) error {
+	if targetType == models.ReportTargetUser && (action == models.ModerationHide || action == models.ModerationDelete) {
+		return ErrInvalidModerationStep
+	}
+	if action == models.ModerationSuspend && !platform {
+		return ErrSuspendNotAllowed
+	}
+	return nil
+}
+
+func reportOutcomeMessage(targetType, action string) string {
+	what := "the " + targetType + " you reported"
+	switch action {
+	case models.ModerationDismiss:
+		return "A moderator reviewed " + what + " and found no violation."
+	case models.ModerationHide:
+		return "A moderator reviewed " + what + " and hid it."
+	case models.ModerationDelete:
+		return "A moderator reviewed " + what + " and removed it."
+	case models.ModerationWarn:
+		if targetType == models.ReportTargetUser {
+			return "A moderator reviewed " + what + " and warned them."
+		}
+		return "A moderator reviewed " + what + " and warned its author."
+	default:
+		if targetType == models.ReportTargetUser {
+			return "A moderator reviewed " + what + " and suspended them."
+		}
+		return "A moderator reviewed " + what + " and suspended its author."
+	}
+}
+
+// authorNotification tells a warned or suspended user why.
+func authorNotification(report *models.Report, req *models.ResolveReportRequest, now time.Time) *models.Notification {
+	var n models.Notification
+	switch req.Action {
+	case models.ModerationWarn:
+		n.Type = models.NotificationModerationWarn
+		n.Message = "A moderator warned you"
+	case models.ModerationSuspend:
+		n.Type = models.NotificationAccountSuspended
+		n.Message = "A moderator suspended your account until " + now.AddDate(0, 0, req.SuspendDays).Format("2006-01-02")
+	default:
+		return nil
+	}
+	if report.TargetType != models.ReportTargetUser {
+		n.Message += " about your " + report.TargetType
+	}
+	n.Message += "."
+	if req.Note != "" {
+		n.Message += " " + req.Note
+	}
+	n.UserID = report.TargetUserID
+	n.TargetType = report.TargetType
+	n.TargetID = report.TargetID
+	return &n
+}
+
+// reportReasonText is the free text kept with a rating's own report count,
+// which holds at most 500 characters.
+func reportReasonText(report *models.Report) string {
+	text := report.Reason
+	if report.Details != "" {
+		text = report.Details
+	}
+	if runes := []rune(text); len(runes) > 500 {
+		text = string(runes[:500])
+	}
+	return text
+}
+
+func notFoundAs(err, notFound error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound
+	}
+	return err
+}
+
+// targetGone reports whether err says the content a decision applies to no
+// longer exists.
+func targetGone(err error) bool {
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, ErrRatingNotFound) {
+		return true
+	}
+	msg := err.Error()
+	return msg == "post not found" || msg == "comment not found"
+}
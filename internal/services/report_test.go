@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckModerationAction(t *testing.T) {
+	assert.NoError(t, checkModerationAction(models.ReportTargetPost, models.ModerationHide, false))
+	assert.NoError(t, checkModerationAction(models.ReportTargetComment, models.ModerationWarn, false))
+	assert.NoError(t, checkModerationAction(models.ReportTargetUser, models.ModerationSuspend, true))
+
+	assert.ErrorIs(t, checkModerationAction(models.ReportTargetUser, models.ModerationDelete, true), ErrInvalidModerationStep)
+	assert.ErrorIs(t, checkModerationAction(models.ReportTargetRating, models.ModerationSuspend, false), ErrSuspendNotAllowed,
+		"club moderators cannot suspend platform accounts")
+}
+
+func TestAuthorNotification(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	report := &models.Report{TargetType: models.ReportTargetComment, TargetID: 4, TargetUserID: 9}
+
+	assert.Nil(t, authorNotification(report, &models.ResolveReportRequest{Action: models.ModerationHide}, now),
+		"authors are only told about warnings and suspensions")
+
+	n := authorNotification(report, &models.ResolveReportRequest{Action: models.ModerationWarn, Note: "Keep it civil."}, now)
+	require.NotNil(t, n)
+	assert.Equal(t, uint(9), n.UserID)
+	assert.Equal(t, models.NotificationModerationWarn, n.Type)
+	assert.Equal(t, "A moderator warned you about your comment. Keep it civil.", n.Message)
+
+	user := &models.Report{TargetType: models.ReportTargetUser, TargetID: 9, TargetUserID: 9}
+	n = authorNotification(user, &models.ResolveReportRequest{Action: models.ModerationSuspend, SuspendDays: 7}, now)
+	require.NotNil(t, n)
+	assert.Equal(t, models.NotificationAccountSuspended, n.Type)
+	assert.Equal(t, "A moderator suspended your account until 2026-03-08.", n.Message)
+}
+
+func TestReportOutcomeMessage(t *testing.T) {
+	assert.Equal(t, "A moderator reviewed the post you reported and found no violation.",
+		reportOutcomeMessage(models.ReportTargetPost, models.ModerationDismiss))
+	assert.Equal(t, "A moderator reviewed the user you reported and suspended them.",
+		reportOutcomeMessage(models.ReportTargetUser, models.ModerationSuspend))
+}
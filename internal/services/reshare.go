@@ -0,0 +1,201 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/markdown"
+)
+
+var (
+	ErrSharedPostRequired = errors.New("posts of type post need a shared_post_id")
+	ErrSharedPostNotFound = errors.New("post to share not found")
+	ErrShareNotPublic     = errors.New("only posts in public clubs can be shared into another club")
+	ErrReshareType        = errors.New("only posts of type post share another post, and a reshare stays one")
+)
+
+// ReshareService checks new reshares and embeds a live preview of the
+// original in reshares, as each viewer may see it.
+type ReshareService struct {
+	reshareRepo    repository.ReshareRepository
+	spoilerService *SpoilerService
+}
+
+func NewReshareService(reshareRepo repository.ReshareRepository, spoilerService *SpoilerService) *ReshareService {
+	return &ReshareService{
+		reshareRepo:    reshareRepo,
+		spoilerService: spoilerService,
+	}
+}
+
+// sharedPostID is the post a new post of type post shares. Older clients
+// name it in the type data.
+func sharedPostID(req *models.CreatePostRequest) uint {
+	if req.SharedPostID != nil {
+		return *req.SharedPostID
+	}
+	raw, err := json.Marshal(req.TypeData)
+	if err != nil {
+		return 0
+	}
+	var data models.PostData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return 0
+	}
+	return data.PostID
+}
+
+// CheckShare returns the post userID wants to reshare into clubID. It must be
+// a published post they can read, and posts in private clubs can only be
+// shared within their club.
+func (s *ReshareService) CheckShare(userID, clubID, originalID uint) (*models.Post, error) {
+	originals, err := s.reshareRepo.GetOriginals([]uint{originalID})
+	if err != nil {
+		return nil, err
+	}
+	if len(originals) == 0 {
+		return nil, ErrSharedPostNotFound
+	}
+	original := &originals[0]
+
+	readable, err := s.reshareRepo.ListReadableClubIDs(userID, []uint{original.ClubID})
+	if err != nil {
+		return nil, err
+	}
+	if !readable[original.ClubID] {
+		return nil, ErrSharedPostNotFound
+	}
+	if original.ClubID != clubID && original.Club.IsPrivate {
+		return nil, ErrShareNotPublic
+	}
+	return original, nil
+}
+
+// EmbedPosts fills in the preview of the original in each reshare among
+// posts. viewerID is nil for anonymous viewers, who only see originals in
+// public clubs. Originals that are spoilers for the viewer are collapsed
+// unless reveal is set.
+func (s *ReshareService) EmbedPosts(viewerID *uint, posts []models.PostResponse, reveal bool) error {
+	var ids []uint
+	for i := range posts {
+		if posts[i].SharedPostID != nil {
+			ids = append(ids, *posts[i].SharedPostID)
+		}
+	}
+	previews, err := s.previews(viewerID, ids, reveal)
+	if err != nil {
+		return err
+	}
+	for i := range posts {
+		if posts[i].SharedPostID != nil {
+			posts[i].SharedPost = previews[*posts[i].SharedPostID]
+		}
+	}
+	return nil
+}
+
+// EmbedSummaries is EmbedPosts for post summaries.
+func (s *ReshareService) EmbedSummaries(viewerID *uint, posts []models.PostSummary, reveal bool) error {
+	var ids []uint
+	for i := range posts {
+		if posts[i].SharedPostID != nil {
+			ids = append(ids, *posts[i].SharedPostID)
+		}
+	}
+	previews, err := s.previews(viewerID, ids, reveal)
+	if err != nil {
+		return err
+	}
+	for i := range posts {
+		if posts[i].SharedPostID != nil {
+			posts[i].SharedPost = previews[*posts[i].SharedPostID]
+		}
+	}
+	return nil
+}
+
+// previews builds the previews of the originals with the given ids, keyed
+// by id. An original that is gone is shown as removed, and one the viewer
+// cannot read as unavailable.
+func (s *ReshareService) previews(viewerID *uint, ids []uint, reveal bool) (map[uint]*models.SharedPost, error) {
+	previews := make(map[uint]*models.SharedPost, len(ids))
+	if len(ids) == 0 {
+		return previews, nil
+	}
+
+	originals, err := s.reshareRepo.GetOriginals(ids)
+	if err != nil {
+		return nil, err
+	}
+	clubIDs := make([]uint, 0, len(originals))
+	for _, o := range originals {
+		clubIDs = append(clubIDs, o.ClubID)
+	}
+	var userID uint
+	if viewerID != nil {
+		userID = *viewerID
+	}
+	readable, err := s.reshareRepo.ListReadableClubIDs(userID, clubIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		previews[id] = &models.SharedPost{ID: id, Removed: true}
+	}
+	var shown []*models.Post
+	var gated []models.PostResponse
+	for i := range originals {
+		o := &originals[i]
+		if !readable[o.ClubID] {
+			previews[o.ID] = &models.SharedPost{ID: o.ID, Unavailable: true}
+			continue
+		}
+		shown = append(shown, o)
+		gated = append(gated, o.ToResponse())
+	}
+	if err := s.spoilerService.GatePosts(viewerID, gated, reveal); err != nil {
+		return nil, err
+	}
+	for i, o := range shown {
+		previews[o.ID] = sharedPostPreview(o, &gated[i])
+	}
+	return previews, nil
+}
+
+// sharedPostPreview is the preview of a readable original; gated is the
+// original as spoiler gating left it for the viewer.
+func sharedPostPreview(original *models.Post, gated *models.PostResponse) *models.SharedPost {
+	createdAt := original.CreatedAt
+	if original.PublishedAt != nil {
+		createdAt = *original.PublishedAt
+	}
+	preview := &models.SharedPost{
+		ID:         original.ID,
+		Title:      original.Title,
+		Excerpt:    original.Excerpt,
+		Type:       original.Type,
+		ShareCount: original.ShareCount,
+		User: &models.UserSummary{
+			ID:        original.User.ID,
+			Username:  original.User.Username,
+			AvatarURL: original.User.AvatarURL,
+		},
+		Club: &models.ClubSummary{
+			ID:   original.Club.ID,
+			Name: original.Club.Name,
+		},
+		Spoiler:   gated.Spoiler,
+		Collapsed: gated.Collapsed,
+		CreatedAt: &createdAt,
+	}
+	if original.RenderVersion < markdown.RenderVersion {
+		preview.Excerpt = markdown.Excerpt(markdown.Render(original.Content).Text, models.PostExcerptLength)
+	}
+	if gated.Collapsed {
+		preview.Excerpt = ""
+	}
+	return preview
+}
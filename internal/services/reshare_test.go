@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/pkg/markdown"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedPostID(t *testing.T) {
+	id := uint(7)
+	assert.Equal(t, uint(7), sharedPostID(&models.CreatePostRequest{SharedPostID: &id}))
+	assert.Equal(t, uint(7), sharedPostID(&models.CreatePostRequest{
+		SharedPostID: &id,
+		TypeData:     map[string]interface{}{"post_id": float64(9)},
+	}), "shared_post_id wins over type data")
+	assert.Equal(t, uint(9), sharedPostID(&models.CreatePostRequest{
+		TypeData: map[string]interface{}{"post_id": float64(9), "post_title": "Dune"},
+	}), "older clients name the post in type data")
+	assert.Zero(t, sharedPostID(&models.CreatePostRequest{}))
+	assert.Zero(t, sharedPostID(&models.CreatePostRequest{TypeData: "nonsense"}))
+}
+
+func TestSharedPostPreview(t *testing.T) {
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	published := created.Add(time.Hour)
+	original := &models.Post{
+		ID:            3,
+		Title:         "Dune, part two",
+		Content:       "The *spice* must flow.",
+		Type:          "review",
+		ShareCount:    4,
+		UserID:        5,
+		User:          models.User{ID: 5, Username: "paul"},
+		ClubID:        2,
+		Club:          models.Club{ID: 2, Name: "Arrakis"},
+		CreatedAt:     created,
+		PublishedAt:   &published,
+		Excerpt:       "The spice must flow.",
+		RenderVersion: markdown.RenderVersion,
+	}
+
+	preview := sharedPostPreview(original, &models.PostResponse{})
+	assert.Equal(t, "The spice must flow.", preview.Excerpt)
+	assert.Equal(t, 4, preview.ShareCount)
+	assert.Equal(t, "paul", preview.User.Username)
+	assert.Equal(t, "Arrakis", preview.Club.Name)
+	assert.Equal(t, published, *preview.CreatedAt, "dated from when the original went out")
+	assert.False(t, preview.Removed)
+	assert.False(t, preview.Unavailable)
+
+	spoiler := &models.SpoilerInfo{BookID: 1, Reason: models.SpoilerReasonReview}
+	preview = sharedPostPreview(original, &models.PostResponse{Spoiler: spoiler, Collapsed: true})
+	assert.Empty(t, preview.Excerpt, "collapsed originals show no excerpt")
+	assert.True(t, preview.Collapsed)
+	assert.Equal(t, spoiler, preview.Spoiler)
+	assert.Equal(t, "Dune, part two", preview.Title)
+
+	original.Excerpt = ""
+	original.RenderVersion = 0
+	preview = sharedPostPreview(original, &models.PostResponse{})
+	assert.Equal(t, "The spice must flow.", preview.Excerpt, "stale renderings are redone")
+}
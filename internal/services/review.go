@@ -0,0 +1,100 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"gorm.io/gorm"
+)
+
+// bookRatingUserIndex keeps one rating per user and book.
+const bookRatingUserIndex = "idx_book_ratings_book_user"
+
+var (
+	ErrInvalidReview   = errors.New("invalid review")
+	ErrAlreadyReviewed = errors.New("you have already reviewed this book")
+)
+
+// parseReviewData checks the type data of a review: it names a book and
+// rates it from 1 to 5.
+func parseReviewData(typeData interface{}) (*models.ReviewData, error) {
+	raw, err := json.Marshal(typeData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReview, err)
+	}
+	var data models.ReviewData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReview, err)
+	}
+
+	switch {
+	case data.BookID == 0:
+		return nil, fmt.Errorf("%w: book_id is required", ErrInvalidReview)
+	case data.Rating < 1 || data.Rating > 5:
+		return nil, fmt.Errorf("%w: rating must be between 1 and 5", ErrInvalidReview)
+	}
+	return &data, nil
+}
+
+// prepareReview checks the review userID is writing in current, which is nil
+// for a new post, and fills in the book it rates. A user reviews a book
+// once; deleting the review lets them write another.
+func (s *PostService) prepareReview(userID uint, current *models.Post, typeData interface{}) (*models.ReviewData, error) {
+	data, err := parseReviewData(typeData)
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := s.bookRepo.GetByID(data.BookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: book not found", ErrInvalidReview)
+		}
+		return nil, err
+	}
+	data.BookTitle = book.Title
+	data.BookAuthor = ""
+	if book.Author != nil {
+		data.BookAuthor = *book.Author
+	}
+
+	existing, err := s.bookRepo.GetUserRating(data.BookID, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existing != nil && (current == nil || existing.PostID != current.ID) && !isLegacyReviewOf(current, data.BookID) {
+		return nil, ErrAlreadyReviewed
+	}
+	return data, nil
+}
+
+// isLegacyReviewOf reports whether post already reviews bookID. Reviews
+// written before ratings were kept may repeat a book; only the latest one
+// rates it, and the older ones can still be edited as long as they stay on
+// that book.
+func isLegacyReviewOf(post *models.Post, bookID uint) bool {
+	if post == nil {
+		return false
+	}
+	previous, err := post.GetReviewData()
+	return err == nil && previous != nil && previous.BookID == bookID
+}
+
+// isDuplicateReview reports whether err is a second rating by the same user
+// on the same book, which a concurrent review can still run into after
+// prepareReview has checked.
+func isDuplicateReview(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505" && pqErr.Constraint == bookRatingUserIndex
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505" && pgErr.ConstraintName == bookRatingUserIndex
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReviewData(t *testing.T) {
+	data, err := parseReviewData(map[string]interface{}{"book_id": float64(3), "rating": 4.5, "book_title": "Made up"})
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), data.BookID)
+	assert.Equal(t, float32(4.5), data.Rating)
+
+	for name, typeData := range map[string]interface{}{
+		"missing":     nil,
+		"no book":     map[string]interface{}{"rating": 4},
+		"no rating":   map[string]interface{}{"book_id": 3},
+		"too low":     map[string]interface{}{"book_id": 3, "rating": 0.5},
+		"too high":    map[string]interface{}{"book_id": 3, "rating": 6},
+		"wrong shape": "five stars",
+	} {
+		_, err := parseReviewData(typeData)
+		assert.ErrorIs(t, err, ErrInvalidReview, name)
+	}
+}
+
+func TestIsLegacyReviewOf(t *testing.T) {
+	review := &models.Post{Type: "review", TypeData: models.PostTypeData(`{"book_id":3,"rating":4}`)}
+	assert.True(t, isLegacyReviewOf(review, 3))
+	assert.False(t, isLegacyReviewOf(review, 4), "an older review cannot move onto a book the user already rated")
+	assert.False(t, isLegacyReviewOf(nil, 3), "new reviews are never legacy")
+	assert.False(t, isLegacyReviewOf(&models.Post{Type: "discussion"}, 3))
+}
+
+func TestIsDuplicateReview(t *testing.T) {
+	assert.True(t, isDuplicateReview(fmt.Errorf("create: %w", &pgconn.PgError{Code: "23505", ConstraintName: bookRatingUserIndex})))
+	assert.True(t, isDuplicateReview(&pq.Error{Code: "23505", Constraint: bookRatingUserIndex}))
+	assert.False(t, isDuplicateReview(&pgconn.PgError{Code: "23505", ConstraintName: "book_ratings_pkey"}))
+	assert.False(t, isDuplicateReview(errors.New("boom")))
+}
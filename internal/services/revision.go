@@ -0,0 +1,169 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var ErrRevisionsHidden = errors.New("edit history is not visible to you")
+
+type RevisionService struct {
+	revisionRepo repository.RevisionRepository
+	postRepo     repository.PostRepository
+	commentRepo  repository.CommentRepository
+	clubRepo     repository.ClubRepository
+	config       *config.Config
+}
+
+func NewRevisionService(revisionRepo repository.RevisionRepository, postRepo repository.PostRepository, commentRepo repository.CommentRepository, clubRepo repository.ClubRepository, config *config.Config) *RevisionService {
+	return &RevisionService{
+		revisionRepo: revisionRepo,
+		postRepo:     postRepo,
+		commentRepo:  commentRepo,
+		clubRepo:     clubRepo,
+		config:       config,
+	}
+}
+
+// GetPostRevisions returns the edit history of a post. viewerID is nil for
+// anonymous requests.
+func (s *RevisionService) GetPostRevisions(postID uint, viewerID *uint, viewerRole string) (*models.RevisionHistoryResponse, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("post not found")
+		}
+		return nil, err
+	}
+
+	if err := s.checkVisibility(post.ClubID, post.UserID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+
+	return s.history(models.RevisionTargetPost, post.ID, post.EditCount)
+}
+
+func (s *RevisionService) GetCommentRevisions(commentID uint, viewerID *uint, viewerRole string) (*models.RevisionHistoryResponse, error) {
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("comment not found")
+		}
+		return nil, err
+	}
+	if comment.IsDeleted {
+		return nil, errors.New("comment not found")
+	}
+
+	post, err := s.postRepo.GetByID(comment.PostID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("post not found")
+		}
+		return nil, err
+	}
+
+	if err := s.checkVisibility(post.ClubID, comment.UserID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+
+	return s.history(models.RevisionTargetComment, comment.ID, comment.EditCount)
+}
+
+// checkVisibility applies the club's revision_visibility setting. Authors and
+// site admins can always read their history.
+func (s *RevisionService) checkVisibility(clubID, authorID uint, viewerID *uint, viewerRole string) error {
+	club, err := s.clubRepo.GetByID(clubID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrClubNotFound
+		}
+		return err
+	}
+
+	if club.RevisionVisibility == models.RevisionVisibilityPublic {
+		return nil
+	}
+	if viewerID == nil {
+		return ErrRevisionsHidden
+	}
+	if viewerRole == "admin" || viewerRole == "superuser" || *viewerID == authorID {
+		return nil
+	}
+	if club.OwnerID != nil && *club.OwnerID == *viewerID {
+		return nil
+	}
+
+	member, err := s.clubRepo.GetClubMemberByUserID(clubID, *viewerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRevisionsHidden
+		}
+		return err
+	}
+	if !member.IsApproved {
+		return ErrRevisionsHidden
+	}
+
+	if club.RevisionVisibility == models.RevisionVisibilityModerators &&
+		member.Role != "moderator" && member.Role != "club_admin" {
+		return ErrRevisionsHidden
+	}
+	return nil
+}
+
+func (s *RevisionService) history(targetType string, targetID uint, editCount int) (*models.RevisionHistoryResponse, error) {
+	revisions, err := s.revisionRepo.ListByTarget(targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RevisionHistoryResponse{
+		TargetType: targetType,
+		TargetID:   targetID,
+		EditCount:  editCount,
+		Revisions:  buildRevisionResponses(revisions),
+	}, nil
+}
+
+// buildRevisionResponses diffs every revision against the one before it.
+func buildRevisionResponses(revisions []models.Revision) []models.RevisionResponse {
+	out := make([]models.RevisionResponse, 0, len(revisions))
+	for i, rev := range revisions {
+		resp := models.RevisionResponse{
+			Version: rev.Version,
+			Editor: models.UserSummary{
+				ID:        rev.Editor.ID,
+				Username:  rev.Editor.Username,
+				AvatarURL: rev.Editor.AvatarURL,
+			},
+			Title:     rev.Title,
+			Content:   rev.Content,
+			CreatedAt: rev.CreatedAt,
+		}
+
+		if len(rev.TypeData) > 0 {
+			var typeData interface{}
+			if err := json.Unmarshal(rev.TypeData, &typeData); err == nil {
+				resp.TypeData = typeData
+			}
+		}
+
+		if i > 0 {
+			prev := revisions[i-1]
+			resp.TitleChanged = prev.Title != rev.Title
+			resp.TypeDataChanged = !bytes.Equal(prev.TypeData, rev.TypeData)
+			resp.Diff = utils.DiffLines(prev.Content, rev.Content)
+		}
+
+		out = append(out, resp)
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+// SpoilerService flags reviews and annotations that are ahead of the viewer's
+// reading progress and collapses them unless the viewer opted out or asked to
+// reveal them.
+type SpoilerService struct {
+	readingRepo repository.ReadingRepository
+	userRepo    repository.UserRepository
+}
+
+func NewSpoilerService(readingRepo repository.ReadingRepository, userRepo repository.UserRepository) *SpoilerService {
+	return &SpoilerService{
+		readingRepo: readingRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// spoilerViewer is what gating needs to know about one viewer.
+type spoilerViewer struct {
+	userID           uint
+	protect          bool
+	protectUnstarted bool
+	progress         map[uint]*models.UserBookProgress
+}
+
+// check returns why a post by authorID that discusses ref is a spoiler for
+// the viewer, or nil. Progress only records pages, so chapter references and
+// reviews count as spoilers until the viewer finishes the book.
+func (v *spoilerViewer) check(authorID uint, ref *models.SpoilerRef) *models.SpoilerInfo {
+	if ref == nil || authorID == v.userID {
+		return nil
+	}
+
+	p := v.progress[ref.BookID]
+	if p != nil && p.Status == models.ReadingFinished {
+		return nil
+	}
+	if (p == nil || p.Status == models.ReadingNotStarted) && !v.protectUnstarted {
+		return nil
+	}
+
+	switch {
+	case ref.Page != nil:
+		if p != nil && p.CurrentPage != nil && *p.CurrentPage >= *ref.Page {
+			return nil
+		}
+		return ref.Info(models.SpoilerReasonPage)
+	case ref.Chapter != nil:
+		return ref.Info(models.SpoilerReasonChapter)
+	default:
+		return ref.Info(models.SpoilerReasonReview)
+	}
+}
+
+// viewer loads the viewer's preferences and reading progress. Anonymous
+// viewers have no progress, so nothing is gated for them.
+func (s *SpoilerService) viewer(viewerID *uint) (*spoilerViewer, error) {
+	if viewerID == nil {
+		return nil, nil
+	}
+
+	user, err := s.userRepo.GetByID(*viewerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	progress, err := pagination.All(func(p pagination.Params) (pagination.Page[*models.UserBookProgress], error) {
+		return s.readingRepo.ListUserProgress(*viewerID, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	byBook := make(map[uint]*models.UserBookProgress, len(progress))
+	for _, p := range progress {
+		byBook[p.BookID] = p
+	}
+
+	return &spoilerViewer{
+		userID:           *viewerID,
+		protect:          user.Preferences.GetBool(models.PREF_SPOILER_PROTECTION, true),
+		protectUnstarted: user.Preferences.GetBool(models.PREF_SPOILER_PROTECT_UNSTARTED, false),
+		progress:         byBook,
+	}, nil
+}
+
+// GatePosts flags spoilers in posts and collapses them unless reveal is set
+// or the viewer turned spoiler protection off.
+func (s *SpoilerService) GatePosts(viewerID *uint, posts []models.PostResponse, reveal bool) error {
+	v, err := s.viewer(viewerID)
+	if err != nil || v == nil {
+		return err
+	}
+
+	for i := range posts {
+		info := v.check(posts[i].UserID, posts[i].SpoilerRef())
+		if info == nil {
+			continue
+		}
+		posts[i].Spoiler = info
+		if v.protect && !reveal {
+			posts[i].Collapse()
+		}
+	}
+	return nil
+}
+
+// GateSummaries is GatePosts for post summaries.
+func (s *SpoilerService) GateSummaries(viewerID *uint, posts []models.PostSummary, reveal bool) error {
+	v, err := s.viewer(viewerID)
+	if err != nil || v == nil {
+		return err
+	}
+
+	for i := range posts {
+		info := v.check(posts[i].UserID, posts[i].SpoilerRef())
+		if info == nil {
+			continue
+		}
+		posts[i].Spoiler = info
+		if v.protect && !reveal {
+			posts[i].Collapse()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestSpoilerViewerCheck(t *testing.T) {
+	const book = uint(7)
+	v := &spoilerViewer{
+		userID: 1,
+		progress: map[uint]*models.UserBookProgress{
+			book: {BookID: book, Status: models.ReadingActive, CurrentPage: intPtr(120)},
+			8:    {BookID: 8, Status: models.ReadingFinished},
+		},
+	}
+
+	behind := &models.SpoilerRef{BookID: book, Page: intPtr(200)}
+	info := v.check(2, behind)
+	if assert.NotNil(t, info) {
+		assert.Equal(t, models.SpoilerReasonPage, info.Reason)
+		assert.Equal(t, 200, *info.Page)
+	}
+
+	assert.Nil(t, v.check(2, &models.SpoilerRef{BookID: book, Page: intPtr(120)}), "pages already read are not spoilers")
+	assert.Nil(t, v.check(1, behind), "authors are never gated on their own posts")
+	assert.Nil(t, v.check(2, &models.SpoilerRef{BookID: 8, Page: intPtr(300)}), "finished books are never gated")
+
+	info = v.check(2, &models.SpoilerRef{BookID: book, Chapter: intPtr(3)})
+	if assert.NotNil(t, info) {
+		assert.Equal(t, models.SpoilerReasonChapter, info.Reason)
+	}
+
+	info = v.check(2, &models.SpoilerRef{BookID: book})
+	if assert.NotNil(t, info) {
+		assert.Equal(t, models.SpoilerReasonReview, info.Reason)
+	}
+}
+
+func TestSpoilerViewerCheck_UnstartedBooks(t *testing.T) {
+	ref := &models.SpoilerRef{BookID: 9}
+
+	v := &spoilerViewer{userID: 1, progress: map[uint]*models.UserBookProgress{}}
+	assert.Nil(t, v.check(2, ref))
+
+	v.protectUnstarted = true
+	assert.NotNil(t, v.check(2, ref))
+}
+
+func TestSpoilerRefFromTypeData(t *testing.T) {
+	annotation := map[string]interface{}{"book_id": 7, "page": 42, "quote": "..."}
+	ref := models.SpoilerRefFromTypeData("annotation", annotation)
+	if assert.NotNil(t, ref) {
+		assert.Equal(t, uint(7), ref.BookID)
+		assert.Equal(t, 42, *ref.Page)
+	}
+
+	assert.Nil(t, models.SpoilerRefFromTypeData("annotation", map[string]interface{}{"book_id": 7}), "annotations without a position are not spoilers")
+	assert.NotNil(t, models.SpoilerRefFromTypeData("review", map[string]interface{}{"book_id": 7, "rating": 4}))
+	assert.Nil(t, models.SpoilerRefFromTypeData("discussion", nil))
+}
+
+func TestPostResponseCollapse(t *testing.T) {
+	resp := models.PostResponse{
+		Title:       "Chapter 12 thoughts",
+		Content:     "He dies",
+		ContentHTML: "<p>He dies</p>",
+		TypeData:    map[string]interface{}{"quote": "He dies"},
+	}
+	resp.Collapse()
+
+	assert.True(t, resp.Collapsed)
+	assert.Equal(t, "Chapter 12 thoughts", resp.Title)
+	assert.Empty(t, resp.Content)
+	assert.Empty(t, resp.ContentHTML)
+	assert.Nil(t, resp.TypeData)
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/lib/pq"
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	tagSearchDefaultLimit = 10
+	tagSearchCandidates   = 50
+)
+
+var (
+	ErrTagNotFound    = errors.New("tag not found")
+	ErrTagExists      = errors.New("tag or alias already exists")
+	ErrInvalidTagName = errors.New("tag name must contain letters or digits")
+)
+
+type TagService struct {
+	tagRepo repository.TagRepository
+	config  *config.Config
+}
+
+func NewTagService(tagRepo repository.TagRepository, config *config.Config) *TagService {
+	return &TagService{
+		tagRepo: tagRepo,
+		config:  config,
+	}
+}
+
+// normalizeTagKey folds case and Turkish characters and drops everything but
+// letters and digits, so "Bilim Kurgu", "bilimkurgu" and "BİLİM-KURGU" share
+// a key.
+func normalizeTagKey(tag string) string {
+	var b strings.Builder
+	for _, r := range normalizeText(tag) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func cleanTagName(tag string) string {
+	return strings.Join(strings.Fields(tag), " ")
+}
+
+// resolveTags maps free-form tags onto canonical tag names, registering tags
+// that are not known yet. Duplicates are dropped and order is preserved.
+func resolveTags(tagRepo repository.TagRepository, raw []string) (pq.StringArray, error) {
+	out := pq.StringArray{}
+	seen := make(map[string]bool, len(raw))
+	for _, t := range raw {
+		name := cleanTagName(t)
+		key := normalizeTagKey(name)
+		if key == "" {
+			continue
+		}
+
+		tag, err := tagRepo.FindByKey(key)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			tag = &models.Tag{Name: name, Key: key}
+			if err = tagRepo.Create(tag); err != nil && isUniqueViolation(err) {
+				tag, err = tagRepo.FindByKey(key)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[tag.Name] {
+			seen[tag.Name] = true
+			out = append(out, tag.Name)
+		}
+	}
+	return out, nil
+}
+
+// SeedDefaults registers the starter taxonomy from path, skipping tags and
+// aliases that already exist.
+func (s *TagService) SeedDefaults(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read tags file: %w", err)
+	}
+
+	var entries []struct {
+		Name string   `json:"name"`
+		TR   []string `json:"tr"`
+		EN   []string `json:"en"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to unmarshal tags data: %w", err)
+	}
+
+	for _, e := range entries {
+		tag, err := s.tagRepo.FindByKey(normalizeTagKey(e.Name))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			tag = &models.Tag{Name: cleanTagName(e.Name), Key: normalizeTagKey(e.Name)}
+			err = s.tagRepo.Create(tag)
+		}
+		if err != nil {
+			return err
+		}
+
+		aliases := make([]models.TagAliasRequest, 0, len(e.TR)+len(e.EN))
+		for _, a := range e.TR {
+			aliases = append(aliases, models.TagAliasRequest{Alias: a, Language: "tr"})
+		}
+		for _, a := range e.EN {
+			aliases = append(aliases, models.TagAliasRequest{Alias: a, Language: "en"})
+		}
+		for _, a := range aliases {
+			if _, err := s.addAlias(tag.ID, a); err != nil && !errors.Is(err, ErrTagExists) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *TagService) SearchTags(req *models.TagSearchRequest) ([]models.TagResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = tagSearchDefaultLimit
+	}
+	key := normalizeTagKey(req.Query)
+
+	tags, err := s.tagRepo.Search(key, tagSearchCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	counts, err := s.tagRepo.UsageCounts(names)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make(map[uint]bool, len(tags))
+	for _, t := range tags {
+		prefix[t.ID] = strings.HasPrefix(t.Key, key)
+		for _, a := range t.Aliases {
+			if strings.HasPrefix(a.Key, key) {
+				prefix[t.ID] = true
+			}
+		}
+	}
+
+	// prefix matches first, then the most used tags
+	sort.SliceStable(tags, func(i, j int) bool {
+		a, b := tags[i], tags[j]
+		if prefix[a.ID] != prefix[b.ID] {
+			return prefix[a.ID]
+		}
+		return counts[a.Name] > counts[b.Name]
+	})
+	if len(tags) > limit {
+		tags = tags[:limit]
+	}
+
+	out := make([]models.TagResponse, 0, len(tags))
+	for i := range tags {
+		out = append(out, tags[i].ToResponse(counts[tags[i].Name]))
+	}
+	return out, nil
+}
+
+func (s *TagService) CreateTag(req *models.CreateTagRequest) (*models.TagResponse, error) {
+	name := cleanTagName(req.Name)
+	key := normalizeTagKey(name)
+	if key == "" {
+		return nil, ErrInvalidTagName
+	}
+	if _, err := s.tagRepo.FindByKey(key); err == nil {
+		return nil, ErrTagExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	tag := &models.Tag{Name: name, Key: key}
+	if err := s.tagRepo.Create(tag); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrTagExists
+		}
+		return nil, err
+	}
+	for _, a := range req.Aliases {
+		if _, err := s.addAlias(tag.ID, a); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.getTagResponse(tag.ID)
+}
+
+func (s *TagService) AddAlias(tagID uint, req *models.TagAliasRequest) (*models.TagResponse, error) {
+	if _, err := s.tagRepo.GetByID(tagID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+	if _, err := s.addAlias(tagID, *req); err != nil {
+		return nil, err
+	}
+	return s.getTagResponse(tagID)
+}
+
+func (s *TagService) addAlias(tagID uint, req models.TagAliasRequest) (*models.TagAlias, error) {
+	alias := cleanTagName(req.Alias)
+	key := normalizeTagKey(alias)
+	if key == "" {
+		return nil, ErrInvalidTagName
+	}
+	if _, err := s.tagRepo.FindByKey(key); err == nil {
+		return nil, ErrTagExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	a := &models.TagAlias{TagID: tagID, Alias: alias, Key: key, Language: req.Language}
+	if err := s.tagRepo.AddAlias(a); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrTagExists
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+// MergeTags folds the source tags into the target tag and rewrites the tags of
+// existing clubs to the target's name, all in one transaction.
+func (s *TagService) MergeTags(req *models.MergeTagsRequest) (*models.MergeTagsResponse, error) {
+	target, err := s.tagRepo.GetByID(req.TargetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+
+	mergedKeys := map[string]bool{target.Key: true}
+	for _, a := range target.Aliases {
+		mergedKeys[a.Key] = true
+	}
+
+	var sources []models.Tag
+	for _, id := range req.SourceIDs {
+		if id == target.ID {
+			return nil, errors.New("cannot merge a tag into itself")
+		}
+		source, err := s.tagRepo.GetByID(id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrTagNotFound
+			}
+			return nil, err
+		}
+		mergedKeys[source.Key] = true
+		for _, a := range source.Aliases {
+			mergedKeys[a.Key] = true
+		}
+		sources = append(sources, *source)
+	}
+
+	updated, err := s.tagRepo.Merge(target, sources, func(tags pq.StringArray) (pq.StringArray, bool) {
+		return rewriteTags(tags, mergedKeys, target.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("merged tags",
+		zap.Uint("target_id", target.ID),
+		zap.Int("sources", len(sources)),
+		zap.Int64("clubs_updated", updated))
+
+	resp, err := s.getTagResponse(target.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.MergeTagsResponse{Tag: *resp, ClubsUpdated: updated}, nil
+}
+
+// rewriteTags replaces every tag whose key is in keys with name, dropping the
+// duplicates this creates.
+func rewriteTags(tags pq.StringArray, keys map[string]bool, name string) (pq.StringArray, bool) {
+	out := make(pq.StringArray, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	changed := false
+	for _, t := range tags {
+		if keys[normalizeTagKey(t)] {
+			if t != name {
+				changed = true
+			}
+			t = name
+		}
+		if seen[t] {
+			changed = true
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	return out, changed
+}
+
+func (s *TagService) getTagResponse(id uint) (*models.TagResponse, error) {
+	tag, err := s.tagRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.tagRepo.UsageCounts([]string{tag.Name})
+	if err != nil {
+		return nil, err
+	}
+	resp := tag.ToResponse(counts[tag.Name])
+	return &resp, nil
+}
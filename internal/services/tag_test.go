@@ -0,0 +1,29 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTagKey(t *testing.T) {
+	assert.Equal(t, "bilimkurgu", normalizeTagKey("Bilim Kurgu"))
+	assert.Equal(t, "bilimkurgu", normalizeTagKey("bilimkurgu"))
+	assert.Equal(t, "bilimkurgu", normalizeTagKey("BİLİM-KURGU"))
+	assert.Equal(t, "siir", normalizeTagKey("Şiir"))
+	assert.Equal(t, "scifi", normalizeTagKey(" sci-fi "))
+	assert.Equal(t, "", normalizeTagKey("--"))
+}
+
+func TestRewriteTags(t *testing.T) {
+	keys := map[string]bool{"bilimkurgu": true, "scifi": true}
+
+	out, changed := rewriteTags(pq.StringArray{"sci-fi", "Felsefe", "bilimkurgu"}, keys, "Bilim Kurgu")
+	assert.True(t, changed)
+	assert.Equal(t, pq.StringArray{"Bilim Kurgu", "Felsefe"}, out)
+
+	out, changed = rewriteTags(pq.StringArray{"Bilim Kurgu", "Felsefe"}, keys, "Bilim Kurgu")
+	assert.False(t, changed)
+	assert.Equal(t, pq.StringArray{"Bilim Kurgu", "Felsefe"}, out)
+}
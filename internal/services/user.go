@@ -2,24 +2,29 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/nevzattalhaozcan/forgotten/internal/config"
 	"github.com/nevzattalhaozcan/forgotten/internal/models"
 	"github.com/nevzattalhaozcan/forgotten/internal/repository"
 	"github.com/nevzattalhaozcan/forgotten/pkg/metrics"
+	"github.com/nevzattalhaozcan/forgotten/pkg/pagination"
 	"github.com/nevzattalhaozcan/forgotten/pkg/utils"
 	"gorm.io/gorm"
 )
 
 type UserService struct {
-	userRepo repository.UserRepository
-	config   *config.Config
+	userRepo        repository.UserRepository
+	locationService *LocationService
+	config          *config.Config
 }
 
-func NewUserService(userRepo repository.UserRepository, config *config.Config) *UserService {
+func NewUserService(userRepo repository.UserRepository, locationService *LocationService, config *config.Config) *UserService {
 	return &UserService{
-		userRepo: userRepo,
-		config:   config,
+		userRepo:        userRepo,
+		locationService: locationService,
+		config:          config,
 	}
 }
 
@@ -34,6 +39,14 @@ func (s *UserService) Register(req *models.RegisterRequest) (*models.UserRespons
 		return nil, errors.New("username already exists")
 	}
 
+	var cityID, districtID *string
+	if req.CityID != "" || req.DistrictID != "" {
+		cityID, districtID, err = s.locationService.ResolveLocation(&req.CityID, &req.DistrictID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
 		return nil, err
@@ -53,6 +66,8 @@ func (s *UserService) Register(req *models.RegisterRequest) (*models.UserRespons
 		Role:           req.Role,
 		AvatarURL:      &req.AvatarURL,
 		Location:       &req.Location,
+		CityID:         cityID,
+		DistrictID:     districtID,
 		FavoriteGenres: req.FavoriteGenres,
 		Bio:            &req.Bio,
 		ReadingGoal:    req.ReadingGoal,
@@ -83,6 +98,11 @@ func (s *UserService) Login(req *models.LoginRequest) (string, *models.UserRespo
 		return "", nil, errors.New("invalid email or password")
 	}
 
+	if user.SuspendedUntil != nil && time.Now().Before(*user.SuspendedUntil) {
+		metrics.RecordAuthAttempt(false)
+		return "", nil, fmt.Errorf("account suspended until %s", user.SuspendedUntil.Format(time.RFC3339))
+	}
+
 	token, err := utils.GenerateJWT(
 		user.ID,
 		user.Email,
@@ -184,18 +204,19 @@ func (s *UserService) SearchPublicUsers(query string, limit int) ([]models.Publi
 	return profiles, nil
 }
 
-func (s *UserService) GetAllUsers() ([]models.UserResponse, error) {
-	users, err := s.userRepo.List(50, 0)
+func (s *UserService) GetAllUsers(req pagination.Request) (*pagination.Page[models.UserResponse], error) {
+	page, err := req.Params()
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []models.UserResponse
-	for _, user := range users {
-		responses = append(responses, user.ToResponse())
+	users, err := s.userRepo.List(page)
+	if err != nil {
+		return nil, err
 	}
 
-	return responses, nil
+	responses := pagination.Map(users, (*models.User).ToResponse)
+	return &responses, nil
 }
 
 func (s *UserService) UpdateUser(id uint, req *models.UpdateUserRequest) (*models.UserResponse, error) {
@@ -255,6 +276,14 @@ func (s *UserService) UpdateUser(id uint, req *models.UpdateUserRequest) (*model
 		user.Location = req.Location
 	}
 
+	if req.CityID != nil || req.DistrictID != nil {
+		cityID, districtID, err := s.locationService.ResolveLocationUpdate(user.CityID, user.DistrictID, req.CityID, req.DistrictID)
+		if err != nil {
+			return nil, err
+		}
+		user.CityID, user.DistrictID = cityID, districtID
+	}
+
 	if req.FavoriteGenres != nil {
 		user.FavoriteGenres = *req.FavoriteGenres
 	}
@@ -339,6 +368,14 @@ func (s *UserService) UpdateProfile(id uint, req *models.UpdateProfileRequest) (
 		user.Location = req.Location
 	}
 
+	if req.CityID != nil || req.DistrictID != nil {
+		cityID, districtID, err := s.locationService.ResolveLocationUpdate(user.CityID, user.DistrictID, req.CityID, req.DistrictID)
+		if err != nil {
+			return nil, err
+		}
+		user.CityID, user.DistrictID = cityID, districtID
+	}
+
 	if req.FavoriteGenres != nil {
 		user.FavoriteGenres = *req.FavoriteGenres
 	}
@@ -19,7 +19,7 @@ func TestUserService_Register(t *testing.T) {
 
 	mockUserRepo := mock_repository.NewMockUserRepository(ctrl)
 	cfg := configtest.New()
-	userService := NewUserService(mockUserRepo, cfg)
+	userService := NewUserService(mockUserRepo, nil, cfg)
 
 	t.Run("successful registration", func(t *testing.T) {
 		req, err := test_helpers.CreateRegisterRequest()
@@ -75,7 +75,7 @@ func TestUserService_Login(t *testing.T) {
 
 	mockUserRepo := mock_repository.NewMockUserRepository(ctrl)
 	cfg := configtest.New()
-	userService := NewUserService(mockUserRepo, cfg)
+	userService := NewUserService(mockUserRepo, nil, cfg)
 
 	t.Run("successful login", func(t *testing.T) {
 		req, err := test_helpers.CreateLoginRequest()
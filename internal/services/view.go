@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/repository"
+	"github.com/nevzattalhaozcan/forgotten/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var ErrPostViewsForbidden = errors.New("only the author and club admins can see post views")
+
+// ViewedPost is a post shown to a viewer.
+type ViewedPost struct {
+	ID       uint
+	AuthorID uint
+}
+
+type ViewService struct {
+	counter  ViewCounter
+	viewRepo repository.ViewRepository
+	postRepo repository.PostRepository
+	clubRepo repository.ClubRepository
+	engagementListeners
+}
+
+func NewViewService(counter ViewCounter, viewRepo repository.ViewRepository, postRepo repository.PostRepository, clubRepo repository.ClubRepository) *ViewService {
+	return &ViewService{
+		counter:  counter,
+		viewRepo: viewRepo,
+		postRepo: postRepo,
+		clubRepo: clubRepo,
+	}
+}
+
+// ViewerKey identifies a viewer for deduplication: the user when signed in,
+// otherwise a hash of the client address and user agent.
+func ViewerKey(userID *uint, clientIP, userAgent string) string {
+	if userID != nil {
+		return fmt.Sprintf("u%d", *userID)
+	}
+	sum := sha256.Sum256([]byte(clientIP + "|" + userAgent))
+	return "a" + hex.EncodeToString(sum[:12])
+}
+
+// RecordView counts a viewer opening a post. Authors viewing their own posts
+// are not counted. Failures are logged; a lost view never fails a request.
+func (s *ViewService) RecordView(post ViewedPost, userID *uint, viewer string) {
+	s.record(models.ViewKindView, []ViewedPost{post}, userID, viewer)
+}
+
+// RecordImpressions counts posts shown to a viewer in a feed.
+func (s *ViewService) RecordImpressions(posts []ViewedPost, userID *uint, viewer string) {
+	s.record(models.ViewKindImpression, posts, userID, viewer)
+}
+
+func (s *ViewService) record(kind string, posts []ViewedPost, userID *uint, viewer string) {
+	now := time.Now()
+	for _, post := range posts {
+		if userID != nil && *userID == post.AuthorID {
+			continue
+		}
+		if _, err := s.counter.Record(context.Background(), kind, post.ID, viewer, now); err != nil {
+			logger.Warn("failed to record post view", zap.String("kind", kind), zap.Uint("post_id", post.ID), zap.Error(err))
+			return
+		}
+	}
+}
+
+// Flush writes the buffered counts to the database and returns how many
+// post-day rows it touched.
+func (s *ViewService) Flush() (int, error) {
+	flushed := 0
+	var viewed []uint
+	err := s.counter.Drain(context.Background(), func(deltas []models.PostViewDelta) error {
+		if err := s.viewRepo.ApplyDeltas(deltas); err != nil {
+			return err
+		}
+		flushed = len(deltas)
+		seen := make(map[uint]bool, len(deltas))
+		for _, d := range deltas {
+			if d.Views > 0 && !seen[d.PostID] {
+				seen[d.PostID] = true
+				viewed = append(viewed, d.PostID)
+			}
+		}
+		return nil
+	})
+	s.notifyEngaged(viewed...)
+	return flushed, err
+}
+
+// GetPostViews returns the post's daily views and impressions. Only the
+// author, club owner and admins, and platform admins may see them.
+func (s *ViewService) GetPostViews(postID, viewerID uint, viewerRole string, req *models.PostViewsRequest) (*models.PostViewsResponse, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("post not found")
+		}
+		return nil, err
+	}
+	if err := s.checkStatsAccess(post, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+
+	from, to, err := analyticsRange(req.From, req.To, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.viewRepo.ListDaily(postID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.PostViewsResponse{
+		PostID:     post.ID,
+		ViewsCount: post.ViewsCount,
+		From:       from,
+		To:         to.AddDate(0, 0, -1),
+		Points:     make([]models.PostViewPoint, 0, len(stats)),
+	}
+	for _, st := range stats {
+		resp.Points = append(resp.Points, models.PostViewPoint{Day: st.Day.UTC(), Views: st.Views, Impressions: st.Impressions})
+		resp.TotalViews += st.Views
+		resp.TotalImpressions += st.Impressions
+	}
+	return resp, nil
+}
+
+func (s *ViewService) checkStatsAccess(post *models.Post, viewerID uint, viewerRole string) error {
+	if viewerRole == "admin" || viewerRole == "superuser" || post.UserID == viewerID {
+		return nil
+	}
+
+	club, err := s.clubRepo.GetByID(post.ClubID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrClubNotFound
+		}
+		return err
+	}
+	if club.OwnerID != nil && *club.OwnerID == viewerID {
+		return nil
+	}
+
+	member, err := s.clubRepo.GetClubMemberByUserID(post.ClubID, viewerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPostViewsForbidden
+		}
+		return err
+	}
+	if !member.IsApproved || member.Role != "club_admin" {
+		return ErrPostViewsForbidden
+	}
+	return nil
+}
+
+// ViewFlusher periodically writes buffered view counts to the database. It
+// flushes once more when stopped so in-memory counts survive a clean shutdown.
+type ViewFlusher struct {
+	viewService *ViewService
+	interval    time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewViewFlusher(viewService *ViewService, interval time.Duration) *ViewFlusher {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &ViewFlusher{
+		viewService: viewService,
+		interval:    interval,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start runs the flusher in the background until Stop is called.
+func (f *ViewFlusher) Start() {
+	go func() {
+		defer close(f.done)
+		ticker := time.NewTicker(f.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-f.stop:
+				f.RunOnce()
+				return
+			case <-ticker.C:
+				f.RunOnce()
+			}
+		}
+	}()
+}
+
+// Stop ends the flusher and waits for its final flush.
+func (f *ViewFlusher) Stop() {
+	f.stopOnce.Do(func() {
+		close(f.stop)
+		<-f.done
+	})
+}
+
+func (f *ViewFlusher) RunOnce() {
+	flushed, err := f.viewService.Flush()
+	if err != nil {
+		logger.Error("failed to flush post views", zap.Error(err))
+	}
+	if flushed > 0 {
+		logger.Debug("flushed post views", zap.Int("rows", flushed))
+	}
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// ViewCounter deduplicates views per viewer and time window and buffers the
+// resulting counts until they are drained into the database.
+type ViewCounter interface {
+	// Record counts viewer seeing postID at, unless the viewer was already
+	// counted for the post in the same window. It reports whether it counted.
+	Record(ctx context.Context, kind string, postID uint, viewer string, at time.Time) (bool, error)
+	// Drain hands the buffered counts to apply and clears them. If apply
+	// fails the counts are kept for the next drain.
+	Drain(ctx context.Context, apply func([]models.PostViewDelta) error) error
+}
+
+type viewDeltaKey struct {
+	postID uint
+	day    int64
+}
+
+func viewWindow(at time.Time, window time.Duration) int64 {
+	return at.Unix() / int64(window/time.Second)
+}
+
+func addViewDelta(pending map[viewDeltaKey]*models.PostViewDelta, kind string, postID uint, day time.Time, n int) {
+	key := viewDeltaKey{postID: postID, day: day.Unix()}
+	d, ok := pending[key]
+	if !ok {
+		d = &models.PostViewDelta{PostID: postID, Day: day}
+		pending[key] = d
+	}
+	if kind == models.ViewKindImpression {
+		d.Impressions += n
+	} else {
+		d.Views += n
+	}
+}
+
+func viewDeltaList(pending map[viewDeltaKey]*models.PostViewDelta) []models.PostViewDelta {
+	out := make([]models.PostViewDelta, 0, len(pending))
+	for _, d := range pending {
+		out = append(out, *d)
+	}
+	return out
+}
+
+// memoryViewCounter keeps everything in process. It is used when Redis is not
+// available, so each instance deduplicates its own viewers only.
+type memoryViewCounter struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	seen    map[string]int64 // kind:post:viewer -> window it was last counted in
+	pending map[viewDeltaKey]*models.PostViewDelta
+}
+
+func NewMemoryViewCounter(window time.Duration) *memoryViewCounter {
+	return &memoryViewCounter{
+		window:  window,
+		seen:    map[string]int64{},
+		pending: map[viewDeltaKey]*models.PostViewDelta{},
+	}
+}
+
+func (m *memoryViewCounter) Record(_ context.Context, kind string, postID uint, viewer string, at time.Time) (bool, error) {
+	w := viewWindow(at, m.window)
+	key := fmt.Sprintf("%s:%d:%s", kind, postID, viewer)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if last, ok := m.seen[key]; ok && last == w {
+		return false, nil
+	}
+	m.seen[key] = w
+	addViewDelta(m.pending, kind, postID, startOfDay(at), 1)
+	return true, nil
+}
+
+func (m *memoryViewCounter) Drain(_ context.Context, apply func([]models.PostViewDelta) error) error {
+	m.mu.Lock()
+	pending := m.pending
+	m.pending = map[viewDeltaKey]*models.PostViewDelta{}
+
+	// viewers from earlier windows can no longer suppress a count
+	current := viewWindow(time.Now(), m.window)
+	for key, w := range m.seen {
+		if w < current {
+			delete(m.seen, key)
+		}
+	}
+	m.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	if err := apply(viewDeltaList(pending)); err != nil {
+		m.mu.Lock()
+		for key, d := range pending {
+			addViewDelta(m.pending, models.ViewKindView, key.postID, d.Day, d.Views)
+			addViewDelta(m.pending, models.ViewKindImpression, key.postID, d.Day, d.Impressions)
+		}
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+const (
+	redisViewSeenPrefix   = "post_views:seen:"
+	redisViewPendingKey   = "post_views:pending"
+	redisViewFlushingPref = "post_views:flushing:"
+)
+
+// redisViewCounter deduplicates with one HyperLogLog per post and window, so
+// memory stays flat however many viewers a post gets. HyperLogLog may rarely
+// miss a new viewer, which slightly undercounts.
+type redisViewCounter struct {
+	rdb    *redis.Client
+	window time.Duration
+}
+
+func NewRedisViewCounter(rdb *redis.Client, window time.Duration) *redisViewCounter {
+	return &redisViewCounter{rdb: rdb, window: window}
+}
+
+func (r *redisViewCounter) Record(ctx context.Context, kind string, postID uint, viewer string, at time.Time) (bool, error) {
+	seenKey := fmt.Sprintf("%s%s:%d:%d", redisViewSeenPrefix, kind, postID, viewWindow(at, r.window))
+
+	pipe := r.rdb.TxPipeline()
+	added := pipe.PFAdd(ctx, seenKey, viewer)
+	pipe.Expire(ctx, seenKey, 2*r.window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	if added.Val() == 0 {
+		return false, nil
+	}
+
+	field := fmt.Sprintf("%s:%d:%d", kind, postID, startOfDay(at).Unix())
+	if err := r.rdb.HIncrBy(ctx, redisViewPendingKey, field, 1).Err(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Drain renames the pending hash before reading it, so views recorded during
+// the flush land in a fresh hash and several instances never apply the same
+// counts twice.
+func (r *redisViewCounter) Drain(ctx context.Context, apply func([]models.PostViewDelta) error) error {
+	flushing := fmt.Sprintf("%s%d", redisViewFlushingPref, time.Now().UnixNano())
+	if err := r.rdb.Rename(ctx, redisViewPendingKey, flushing).Err(); err != nil {
+		if strings.Contains(err.Error(), "no such key") {
+			return nil
+		}
+		return err
+	}
+
+	fields, err := r.rdb.HGetAll(ctx, flushing).Result()
+	if err != nil {
+		return err
+	}
+
+	pending := map[viewDeltaKey]*models.PostViewDelta{}
+	for field, value := range fields {
+		kind, postID, day, ok := parseViewField(field)
+		n, err := strconv.Atoi(value)
+		if !ok || err != nil {
+			continue
+		}
+		addViewDelta(pending, kind, postID, day, n)
+	}
+
+	if err := apply(viewDeltaList(pending)); err != nil {
+		// put the counts back for the next flush
+		pipe := r.rdb.TxPipeline()
+		for field, value := range fields {
+			if n, convErr := strconv.ParseInt(value, 10, 64); convErr == nil {
+				pipe.HIncrBy(ctx, redisViewPendingKey, field, n)
+			}
+		}
+		pipe.Del(ctx, flushing)
+		if _, restoreErr := pipe.Exec(ctx); restoreErr != nil {
+			return fmt.Errorf("%w (restoring counts failed: %v)", err, restoreErr)
+		}
+		return err
+	}
+	return r.rdb.Del(ctx, flushing).Err()
+}
+
+func parseViewField(field string) (string, uint, time.Time, bool) {
+	parts := strings.Split(field, ":")
+	if len(parts) != 3 {
+		return "", 0, time.Time{}, false
+	}
+	postID, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return "", 0, time.Time{}, false
+	}
+	day, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", 0, time.Time{}, false
+	}
+	return parts[0], uint(postID), time.Unix(day, 0).UTC(), true
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryViewCounter_DedupesPerWindow(t *testing.T) {
+	ctx := context.Background()
+	counter := NewMemoryViewCounter(30 * time.Minute)
+	at := time.Date(2026, 3, 1, 10, 5, 0, 0, time.UTC)
+
+	counted, _ := counter.Record(ctx, models.ViewKindView, 1, "u1", at)
+	assert.True(t, counted)
+	counted, _ = counter.Record(ctx, models.ViewKindView, 1, "u1", at.Add(10*time.Minute))
+	assert.False(t, counted, "same viewer in the same window")
+	counted, _ = counter.Record(ctx, models.ViewKindImpression, 1, "u1", at)
+	assert.True(t, counted, "views and impressions are deduplicated separately")
+	counted, _ = counter.Record(ctx, models.ViewKindView, 1, "u2", at)
+	assert.True(t, counted)
+	counted, _ = counter.Record(ctx, models.ViewKindView, 1, "u1", at.Add(time.Hour))
+	assert.True(t, counted, "a new window counts again")
+
+	var got []models.PostViewDelta
+	require.NoError(t, counter.Drain(ctx, func(d []models.PostViewDelta) error {
+		got = d
+		return nil
+	}))
+	require.Len(t, got, 1)
+	assert.Equal(t, models.PostViewDelta{PostID: 1, Day: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Views: 3, Impressions: 1}, got[0])
+
+	called := false
+	require.NoError(t, counter.Drain(ctx, func([]models.PostViewDelta) error {
+		called = true
+		return nil
+	}))
+	assert.False(t, called, "nothing left to drain")
+}
+
+func TestMemoryViewCounter_KeepsCountsWhenApplyFails(t *testing.T) {
+	ctx := context.Background()
+	counter := NewMemoryViewCounter(30 * time.Minute)
+	at := time.Now()
+	_, _ = counter.Record(ctx, models.ViewKindView, 1, "u1", at)
+
+	err := counter.Drain(ctx, func([]models.PostViewDelta) error { return errors.New("db down") })
+	assert.Error(t, err)
+
+	var got []models.PostViewDelta
+	require.NoError(t, counter.Drain(ctx, func(d []models.PostViewDelta) error {
+		got = d
+		return nil
+	}))
+	require.Len(t, got, 1)
+	assert.Equal(t, 1, got[0].Views)
+	assert.Equal(t, 0, got[0].Impressions)
+}
+
+func TestViewerKey(t *testing.T) {
+	id := uint(42)
+	assert.Equal(t, "u42", ViewerKey(&id, "1.2.3.4", "ua"))
+
+	anon := ViewerKey(nil, "1.2.3.4", "ua")
+	assert.Equal(t, anon, ViewerKey(nil, "1.2.3.4", "ua"))
+	assert.NotEqual(t, anon, ViewerKey(nil, "1.2.3.5", "ua"))
+}
@@ -0,0 +1,161 @@
+// Package markdown renders user-written Markdown into sanitized HTML and plain
+// text.
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// RenderVersion identifies the Markdown options and sanitizer policy below.
+// Bump it whenever either changes so stored HTML gets re-rendered.
+const RenderVersion = 2
+
+// linkRel is set on every link in user content.
+const linkRel = "nofollow ugc"
+
+// spoilerPlaceholder stands in for spoiler text in plain text and excerpts.
+const spoilerPlaceholder = "[spoiler]"
+
+type Rendered struct {
+	HTML string
+	Text string
+}
+
+var (
+	md = goldmark.New(
+		goldmark.WithExtensions(extension.GFM, spoilerExtension{}),
+		goldmark.WithParserOptions(
+			parser.WithASTTransformers(util.Prioritized(linkRelTransformer{}, 100)),
+		),
+		// raw HTML in the source is dropped rather than passed to the sanitizer
+		goldmark.WithRendererOptions(goldmarkhtml.WithHardWraps()),
+	)
+
+	policy = newPolicy()
+)
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("rel").Matching(regexp.MustCompile(`^` + linkRel + `$`)).OnElements("a")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^` + spoilerClass + `$`)).OnElements("span")
+	return p
+}
+
+// Render converts Markdown source to sanitized HTML and to plain text. Links,
+// including bare URLs, get rel="nofollow ugc". ||Spoilers|| become
+// <span class="spoiler"> and are left out of the plain text.
+func Render(source string) Rendered {
+	src := []byte(source)
+	doc := md.Parser().Parse(text.NewReader(src))
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, src, doc); err != nil {
+		// rendering only fails on writer errors; fall back to escaped text
+		return Rendered{
+			HTML: "<p>" + html.EscapeString(source) + "</p>",
+			Text: collapseSpace(source),
+		}
+	}
+
+	return Rendered{
+		HTML: policy.Sanitize(buf.String()),
+		Text: plainText(doc, src),
+	}
+}
+
+// Excerpt shortens text to at most maxRunes runes, cutting at a word boundary
+// where possible.
+func Excerpt(text string, maxRunes int) string {
+	if utf8.RuneCountInString(text) <= maxRunes {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := string(runes[:maxRunes])
+	if i := strings.LastIndexByte(cut, ' '); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:") + "…"
+}
+
+type linkRelTransformer struct{}
+
+func (linkRelTransformer) Transform(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case ast.KindLink, ast.KindAutoLink:
+			n.SetAttributeString("rel", []byte(linkRel))
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// plainText joins the text of every block, one line per block.
+func plainText(doc ast.Node, src []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if n.Type() == ast.TypeBlock && !entering && b.Len() > 0 {
+			b.WriteByte('\n')
+			return ast.WalkContinue, nil
+		}
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Text:
+			b.Write(node.Segment.Value(src))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(node.Value)
+		case *ast.AutoLink:
+			b.Write(node.URL(src))
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeBlock, *ast.FencedCodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				seg := lines.At(i)
+				b.Write(seg.Value(src))
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.RawHTML, *ast.HTMLBlock:
+			return ast.WalkSkipChildren, nil
+		case *Spoiler:
+			b.WriteString(spoilerPlaceholder)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return collapseSpace(b.String())
+}
+
+var spaceRun = regexp.MustCompile(`[ \t]+`)
+var blankLines = regexp.MustCompile(`\n{2,}`)
+
+func collapseSpace(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spaceRun.ReplaceAllString(line, " "))
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n"))
+}
@@ -0,0 +1,55 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender_Formatting(t *testing.T) {
+	out := Render("# Chapter 1\n\nIt was **the best** of times.")
+
+	assert.Contains(t, out.HTML, "<h1")
+	assert.Contains(t, out.HTML, "<strong>the best</strong>")
+	assert.Equal(t, "Chapter 1\nIt was the best of times.", out.Text)
+}
+
+func TestRender_LinksAreNofollowUGC(t *testing.T) {
+	out := Render("See [the map](https://example.com/map) or https://example.com/bare")
+
+	assert.Contains(t, out.HTML, `<a href="https://example.com/map" rel="nofollow ugc">the map</a>`)
+	assert.Contains(t, out.HTML, `<a href="https://example.com/bare" rel="nofollow ugc">https://example.com/bare</a>`)
+	assert.Equal(t, "See the map or https://example.com/bare", out.Text)
+}
+
+func TestRender_StripsUnsafeContent(t *testing.T) {
+	out := Render("hello <script>alert(1)</script>\n\n<img src=x onerror=alert(1)>\n\n[click](javascript:alert(1))")
+
+	assert.NotContains(t, out.HTML, "<script")
+	assert.NotContains(t, out.HTML, "onerror")
+	assert.NotContains(t, out.HTML, "javascript:")
+	assert.NotContains(t, out.Text, "<script")
+}
+
+func TestExcerpt(t *testing.T) {
+	assert.Equal(t, "short", Excerpt("short", 10))
+
+	long := strings.Repeat("word ", 20)
+	excerpt := Excerpt(long, 22)
+	assert.Equal(t, "word word word word…", excerpt)
+}
+
+func TestRender_Spoilers(t *testing.T) {
+	out := Render("Turns out ||the butler did it||, who knew | not me.")
+
+	assert.Contains(t, out.HTML, `<span class="spoiler">the butler did it</span>`)
+	assert.Contains(t, out.HTML, "who knew | not me.")
+	assert.Equal(t, "Turns out [spoiler], who knew | not me.", out.Text)
+}
+
+func TestRender_SpoilerClassIsNotUserControlled(t *testing.T) {
+	out := Render(`<span class="spoiler other">x</span>`)
+
+	assert.NotContains(t, out.HTML, "<span")
+}
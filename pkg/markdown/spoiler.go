@@ -0,0 +1,87 @@
+package markdown
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// spoilerClass marks spoiler spans; clients blur them until tapped.
+const spoilerClass = "spoiler"
+
+// KindSpoiler is the node kind of ||spoiler|| text.
+var KindSpoiler = ast.NewNodeKind("Spoiler")
+
+// Spoiler is inline text the author marked with ||double bars||.
+type Spoiler struct {
+	ast.BaseInline
+}
+
+func (n *Spoiler) Kind() ast.NodeKind {
+	return KindSpoiler
+}
+
+func (n *Spoiler) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+type spoilerDelimiterProcessor struct{}
+
+func (spoilerDelimiterProcessor) IsDelimiter(b byte) bool {
+	return b == '|'
+}
+
+func (spoilerDelimiterProcessor) CanOpenCloser(opener, closer *parser.Delimiter) bool {
+	return opener.Char == closer.Char
+}
+
+func (spoilerDelimiterProcessor) OnMatch(consumes int) ast.Node {
+	return &Spoiler{}
+}
+
+// spoilerParser handles exactly two bars; single bars stay literal so table
+// cells and prose are unaffected.
+type spoilerParser struct{}
+
+func (spoilerParser) Trigger() []byte {
+	return []byte{'|'}
+}
+
+func (spoilerParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	before := block.PrecendingCharacter()
+	line, segment := block.PeekLine()
+	node := parser.ScanDelimiter(line, before, 2, spoilerDelimiterProcessor{})
+	if node == nil || node.OriginalLength != 2 || before == '|' {
+		return nil
+	}
+
+	node.Segment = segment.WithStop(segment.Start + node.OriginalLength)
+	block.Advance(node.OriginalLength)
+	pc.PushDelimiter(node)
+	return node
+}
+
+func (spoilerParser) CloseBlock(parent ast.Node, pc parser.Context) {}
+
+type spoilerRenderer struct{}
+
+func (spoilerRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindSpoiler, func(w util.BufWriter, _ []byte, _ ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			_, _ = w.WriteString(`<span class="` + spoilerClass + `">`)
+		} else {
+			_, _ = w.WriteString("</span>")
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+type spoilerExtension struct{}
+
+func (spoilerExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(util.Prioritized(spoilerParser{}, 500)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(spoilerRenderer{}, 500)))
+}
@@ -0,0 +1,325 @@
+// Package pagination implements keyset (cursor) pagination for list
+// endpoints. A page is ordered by a fixed set of columns followed by the row
+// ID, and the cursor carries the values of the last row returned, so the next
+// page starts right after it however many rows were inserted or deleted in
+// between.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Request is the query part shared by every paginated endpoint.
+type Request struct {
+	Cursor string `form:"cursor" json:"cursor,omitempty"`
+	Limit  int    `form:"limit" json:"limit,omitempty" validate:"omitempty,gte=1,lte=100"`
+}
+
+// Params is a decoded Request.
+type Params struct {
+	Limit int
+	After *Cursor
+}
+
+// Params decodes the cursor and applies the default limit.
+func (r Request) Params() (Params, error) {
+	p := Params{Limit: r.Limit}
+	if r.Cursor != "" {
+		after, err := Decode(r.Cursor)
+		if err != nil {
+			return Params{}, err
+		}
+		p.After = after
+	}
+	return p, nil
+}
+
+// First returns the params for the first page of limit rows.
+func First(limit int) Params {
+	return Params{Limit: limit}
+}
+
+// Size is the page size with the default and the maximum applied.
+func (p Params) Size() int {
+	switch {
+	case p.Limit <= 0:
+		return DefaultLimit
+	case p.Limit > MaxLimit:
+		return MaxLimit
+	default:
+		return p.Limit
+	}
+}
+
+// Cursor is the position of a row: its sort values, in keyset order, and its
+// ID. It is handed to clients as an opaque string.
+type Cursor struct {
+	Values []string `json:"v,omitempty"`
+	ID     uint     `json:"id"`
+}
+
+// CursorFor builds the cursor of a row from its ID and sort values. Values
+// may be time.Time, integers, floats or strings.
+func CursorFor(id uint, values ...interface{}) Cursor {
+	c := Cursor{ID: id, Values: make([]string, 0, len(values))}
+	for _, v := range values {
+		switch v := v.(type) {
+		case time.Time:
+			c.Values = append(c.Values, v.UTC().Format(time.RFC3339Nano))
+		case string:
+			c.Values = append(c.Values, v)
+		default:
+			c.Values = append(c.Values, fmt.Sprint(v))
+		}
+	}
+	return c
+}
+
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func Decode(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Kind is how a sort value is decoded from a cursor.
+type Kind int
+
+const (
+	Int Kind = iota
+	Time
+	String
+	Float
+)
+
+// Column is one sort key. Expr is a column or SQL expression that must not
+// be NULL.
+type Column struct {
+	Expr string
+	Kind Kind
+	Desc bool
+}
+
+// Keyset is the ordering of a list: Columns, then ID to break ties.
+type Keyset struct {
+	Columns []Column
+	ID      string
+	Desc    bool
+}
+
+// ByID orders by the ID column only.
+func ByID(id string, desc bool) Keyset {
+	return Keyset{ID: id, Desc: desc}
+}
+
+// Apply orders query by the keyset, skips everything up to and including the
+// cursor and fetches one row more than the limit, which tells NewPage whether
+// another page follows.
+func (k Keyset) Apply(query *gorm.DB, p Params) (*gorm.DB, error) {
+	if p.After != nil {
+		where, args, err := k.after(*p.After)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(where, args...)
+	}
+
+	order := make([]string, 0, len(k.Columns)+1)
+	for _, col := range k.Columns {
+		order = append(order, col.Expr+direction(col.Desc))
+	}
+	order = append(order, k.ID+direction(k.Desc))
+
+	return query.Order(strings.Join(order, ", ")).Limit(p.Size() + 1), nil
+}
+
+// after builds "(c1, ..., id) > (v1, ..., id)" spelled out as ORs, since
+// row-value comparison cannot mix directions and is not portable.
+func (k Keyset) after(c Cursor) (string, []interface{}, error) {
+	if len(c.Values) != len(k.Columns) {
+		return "", nil, ErrInvalidCursor
+	}
+
+	values := make([]interface{}, 0, len(k.Columns)+1)
+	for i, col := range k.Columns {
+		v, err := col.decode(c.Values[i])
+		if err != nil {
+			return "", nil, err
+		}
+		values = append(values, v)
+	}
+	values = append(values, c.ID)
+
+	exprs := make([]string, 0, len(k.Columns)+1)
+	descs := make([]bool, 0, len(k.Columns)+1)
+	for _, col := range k.Columns {
+		exprs = append(exprs, col.Expr)
+		descs = append(descs, col.Desc)
+	}
+	exprs = append(exprs, k.ID)
+	descs = append(descs, k.Desc)
+
+	var (
+		terms []string
+		args  []interface{}
+	)
+	for i := range exprs {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, exprs[j]+" = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if descs[i] {
+			op = " < ?"
+		}
+		parts = append(parts, exprs[i]+op)
+		args = append(args, values[i])
+		terms = append(terms, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(terms, " OR ") + ")", args, nil
+}
+
+func (col Column) decode(s string) (interface{}, error) {
+	switch col.Kind {
+	case Time:
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return t, nil
+	case Int:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return n, nil
+	case Float:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return f, nil
+	default:
+		return s, nil
+	}
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+// Page is the envelope every paginated endpoint responds with. NextCursor is
+// set when HasMore is.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// NewPage trims the extra row fetched by Keyset.Apply and points the next
+// cursor at the last row kept.
+func NewPage[T any](items []T, p Params, key func(T) Cursor) Page[T] {
+	limit := p.Size()
+	page := Page[T]{Items: items}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.HasMore = true
+		page.NextCursor = key(page.Items[limit-1]).Encode()
+	}
+	return page
+}
+
+// Find runs query ordered and bounded by k and returns the page.
+func Find[T any](query *gorm.DB, k Keyset, p Params, key func(T) Cursor) (Page[T], error) {
+	query, err := k.Apply(query, p)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	var items []T
+	if err := query.Find(&items).Error; err != nil {
+		return Page[T]{}, err
+	}
+	return NewPage(items, p, key), nil
+}
+
+// Map converts the items of a page, keeping its cursor.
+func Map[T, U any](page Page[T], fn func(T) U) Page[U] {
+	out := Page[U]{
+		Items:      make([]U, 0, len(page.Items)),
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+	}
+	for _, item := range page.Items {
+		out.Items = append(out.Items, fn(item))
+	}
+	return out
+}
+
+// All walks every page of fetch. It is meant for internal callers that
+// really need the whole list.
+func All[T any](fetch func(Params) (Page[T], error)) ([]T, error) {
+	var all []T
+	err := Each(fetch, func(items []T) error {
+		all = append(all, items...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// Each walks every page of fetch and hands each page to fn before fetching
+// the next one, so exports never hold more than a page in memory.
+func Each[T any](fetch func(Params) (Page[T], error), fn func(items []T) error) error {
+	p := First(MaxLimit)
+	for {
+		page, err := fetch(p)
+		if err != nil {
+			return err
+		}
+		if err := fn(page.Items); err != nil {
+			return err
+		}
+		if !page.HasMore {
+			return nil
+		}
+		after, err := Decode(page.NextCursor)
+		if err != nil {
+			return err
+		}
+		p.After = after
+	}
+}
@@ -0,0 +1,166 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type item struct {
+	ID        uint
+	Score     int
+	Weight    float64
+	CreatedAt time.Time
+}
+
+func setupItems(t *testing.T, n int) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&item{}))
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 1; i <= n; i++ {
+		// pairs of rows share a timestamp and scores repeat, so ties must be
+		// broken by ID
+		require.NoError(t, db.Create(&item{ID: uint(i), Score: i % 3, Weight: float64(i%4) / 3, CreatedAt: base.Add(time.Duration(i/2) * time.Minute)}).Error)
+	}
+	return db
+}
+
+func collect(t *testing.T, db *gorm.DB, k Keyset, limit int, key func(item) Cursor) []uint {
+	var ids []uint
+	req := Request{Limit: limit}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 50, "pagination does not terminate")
+		p, err := req.Params()
+		require.NoError(t, err)
+
+		page, err := Find(db.Model(&item{}), k, p, key)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(page.Items), limit)
+		for _, it := range page.Items {
+			ids = append(ids, it.ID)
+		}
+		if !page.HasMore {
+			assert.Empty(t, page.NextCursor)
+			return ids
+		}
+		req.Cursor = page.NextCursor
+	}
+}
+
+func TestKeysetWalksEveryRowOnce(t *testing.T) {
+	db := setupItems(t, 23)
+
+	newest := Keyset{Columns: []Column{{Expr: "created_at", Kind: Time, Desc: true}}, ID: "id", Desc: true}
+	ids := collect(t, db, newest, 5, func(it item) Cursor { return CursorFor(it.ID, it.CreatedAt) })
+	require.Len(t, ids, 23)
+	for i := range ids {
+		assert.Equal(t, uint(23-i), ids[i])
+	}
+
+	mixed := Keyset{Columns: []Column{{Expr: "score", Kind: Int, Desc: true}, {Expr: "created_at", Kind: Time}}, ID: "id"}
+	ids = collect(t, db, mixed, 4, func(it item) Cursor { return CursorFor(it.ID, it.Score, it.CreatedAt) })
+	require.Len(t, ids, 23)
+
+	var want []item
+	require.NoError(t, db.Order("score DESC, created_at ASC, id ASC").Find(&want).Error)
+	for i := range want {
+		assert.Equal(t, want[i].ID, ids[i])
+	}
+
+	// floats must survive the round trip through the cursor exactly
+	weighted := Keyset{Columns: []Column{{Expr: "weight", Kind: Float, Desc: true}}, ID: "id", Desc: true}
+	ids = collect(t, db, weighted, 3, func(it item) Cursor { return CursorFor(it.ID, it.Weight) })
+	require.Len(t, ids, 23)
+
+	want = nil
+	require.NoError(t, db.Order("weight DESC, id DESC").Find(&want).Error)
+	for i := range want {
+		assert.Equal(t, want[i].ID, ids[i])
+	}
+}
+
+func TestKeysetSeesRowsAddedBehindTheCursor(t *testing.T) {
+	db := setupItems(t, 6)
+	k := ByID("id", false)
+
+	p, err := Request{Limit: 3}.Params()
+	require.NoError(t, err)
+	first, err := Find(db.Model(&item{}), k, p, func(it item) Cursor { return CursorFor(it.ID) })
+	require.NoError(t, err)
+	require.True(t, first.HasMore)
+
+	// deleting a row already seen does not shift the next page
+	require.NoError(t, db.Delete(&item{}, 1).Error)
+
+	p, err = Request{Limit: 3, Cursor: first.NextCursor}.Params()
+	require.NoError(t, err)
+	second, err := Find(db.Model(&item{}), k, p, func(it item) Cursor { return CursorFor(it.ID) })
+	require.NoError(t, err)
+	require.Len(t, second.Items, 3)
+	assert.Equal(t, uint(4), second.Items[0].ID)
+	assert.False(t, second.HasMore)
+}
+
+func TestInvalidCursor(t *testing.T) {
+	_, err := Request{Cursor: "not a cursor!"}.Params()
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	// a cursor from a list with a different ordering
+	p, err := Request{Cursor: CursorFor(3).Encode()}.Params()
+	require.NoError(t, err)
+	k := Keyset{Columns: []Column{{Expr: "created_at", Kind: Time}}, ID: "id"}
+	_, err = k.Apply(setupItems(t, 1), p)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	p, err = Request{Cursor: CursorFor(3, "yesterday").Encode()}.Params()
+	require.NoError(t, err)
+	_, err = k.Apply(setupItems(t, 1), p)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestNewPageAndMap(t *testing.T) {
+	page := NewPage([]int{1, 2, 3}, First(2), func(n int) Cursor { return CursorFor(uint(n)) })
+	assert.Equal(t, []int{1, 2}, page.Items)
+	assert.True(t, page.HasMore)
+
+	after, err := Decode(page.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), after.ID)
+
+	doubled := Map(page, func(n int) int { return n * 2 })
+	assert.Equal(t, []int{2, 4}, doubled.Items)
+	assert.Equal(t, page.NextCursor, doubled.NextCursor)
+
+	empty := NewPage[int](nil, First(0), nil)
+	assert.NotNil(t, empty.Items, "empty pages encode as [] rather than null")
+	assert.False(t, empty.HasMore)
+}
+
+func TestAll(t *testing.T) {
+	db := setupItems(t, 205)
+	all, err := All(func(p Params) (Page[item], error) {
+		return Find(db.Model(&item{}), ByID("id", false), p, func(it item) Cursor { return CursorFor(it.ID) })
+	})
+	require.NoError(t, err)
+	assert.Len(t, all, 205)
+}
+
+func TestEach(t *testing.T) {
+	db := setupItems(t, 205)
+	var sizes []int
+	err := Each(func(p Params) (Page[item], error) {
+		return Find(db.Model(&item{}), ByID("id", false), p, func(it item) Cursor { return CursorFor(it.ID) })
+	}, func(items []item) error {
+		sizes = append(sizes, len(items))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{100, 100, 5}, sizes)
+}
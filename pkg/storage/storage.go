@@ -0,0 +1,61 @@
+// Package storage keeps uploaded files on local disk or in an S3-compatible
+// bucket behind one interface.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nevzattalhaozcan/forgotten/internal/config"
+)
+
+var ErrNotFound = errors.New("file not found")
+
+// Storage stores files under slash-separated keys. URL returns where clients
+// download a file from; the file itself is public to anyone with the URL.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// New returns the storage cfg.Storage names: "local" or "s3".
+func New(cfg *config.AttachmentsConfig) (Storage, error) {
+	switch cfg.Storage {
+	case "", "local":
+		return NewLocal(cfg.LocalDir, cfg.PublicBaseURL)
+	case "s3":
+		return NewS3(S3Options{
+			Endpoint:      cfg.S3Endpoint,
+			Region:        cfg.S3Region,
+			Bucket:        cfg.S3Bucket,
+			AccessKey:     cfg.S3AccessKey,
+			SecretKey:     cfg.S3SecretKey,
+			PathStyle:     cfg.S3PathStyle,
+			PublicBaseURL: cfg.PublicBaseURL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown attachment storage %q", cfg.Storage)
+	}
+}
+
+// checkKey rejects keys that could escape the storage root.
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	return nil
+}
+
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}
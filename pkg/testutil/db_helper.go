@@ -1,7 +1,7 @@
 package test_helpers
 
 import (
-	"github.com/nevzattalhaozcan/forgotten/internal/models"
+	"github.com/nevzattalhaozcan/forgotten/internal/database"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -15,12 +15,8 @@ func SetupTestDB() (*gorm.DB, error) {
 		return nil, err
 	}
 
-	err = db.AutoMigrate(
-		&models.User{},
-		&models.Club{},
-        &models.Event{},
-        &models.EventRSVP{},
-	)
+	// the whole schema, so tests need not list the tables they touch
+	err = db.AutoMigrate(database.Models()...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,114 @@
+// Package thumbnail makes small previews of uploaded images using only the
+// standard library decoders: JPEG, PNG and GIF.
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// maxPixels keeps a small file that decodes to a huge image from using up
+// the server's memory.
+const maxPixels = 50_000_000
+
+var ErrTooLarge = errors.New("image is too large to make a thumbnail")
+
+// Thumbnail is a preview of an image. Data is nil when the image already fits
+// the requested size and can be shown as is.
+type Thumbnail struct {
+	Data        []byte
+	ContentType string
+	Width       int // of the original image
+	Height      int
+}
+
+// Make scales the image in src down so its longer side is at most size
+// pixels. GIFs and PNGs, which may be transparent, give PNG thumbnails; the
+// rest give JPEGs. Animated GIFs keep their first frame.
+func Make(src []byte, size int) (*Thumbnail, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+	t := &Thumbnail{Width: cfg.Width, Height: cfg.Height}
+	if size <= 0 || (cfg.Width <= size && cfg.Height <= size) {
+		return t, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	w, h := fit(cfg.Width, cfg.Height, size)
+	small := scale(img, w, h)
+
+	var buf bytes.Buffer
+	if format == "png" || format == "gif" {
+		err = png.Encode(&buf, small)
+		t.ContentType = "image/png"
+	} else {
+		err = jpeg.Encode(&buf, small, &jpeg.Options{Quality: 80})
+		t.ContentType = "image/jpeg"
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Data = buf.Bytes()
+	return t, nil
+}
+
+// fit returns the size of a w×h image scaled to fit a size×size box.
+func fit(w, h, size int) (int, int) {
+	if w >= h {
+		return size, max(1, h*size/w)
+	}
+	return max(1, w*size/h), size
+}
+
+// scale resizes img to w×h, averaging the source pixels that fall into each
+// target pixel.
+func scale(img image.Image, w, h int) *image.RGBA {
+	b := img.Bounds()
+	src, ok := img.(*image.RGBA)
+	if !ok {
+		src = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	}
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	origin := src.Bounds().Min
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+			var r, g, bl, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.PixOffset(origin.X+x0, origin.Y+sy)
+				for sx := x0; sx < x1; sx++ {
+					p := src.Pix[row : row+4 : row+4]
+					r += int(p[0])
+					g += int(p[1])
+					bl += int(p[2])
+					a += int(p[3])
+					n++
+					row += 4
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(bl / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
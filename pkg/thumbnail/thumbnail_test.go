@@ -0,0 +1,73 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, color.NRGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.NRGBA{B: 255, A: 255})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestMake(t *testing.T) {
+	thumb, err := Make(encodePNG(t, 400, 100), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 400, thumb.Width)
+	assert.Equal(t, 100, thumb.Height)
+	assert.Equal(t, "image/png", thumb.ContentType)
+
+	img, err := png.Decode(bytes.NewReader(thumb.Data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 100, 25), img.Bounds())
+	r, _, b, _ := img.At(10, 10).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+	assert.Equal(t, uint32(0), b)
+	r, _, b, _ = img.At(90, 10).RGBA()
+	assert.Equal(t, uint32(0), r)
+	assert.Equal(t, uint32(0xffff), b)
+}
+
+func TestMakeJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 90, 300)), nil))
+
+	thumb, err := Make(buf.Bytes(), 60)
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", thumb.ContentType)
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(thumb.Data))
+	require.NoError(t, err)
+	assert.Equal(t, 18, cfg.Width)
+	assert.Equal(t, 60, cfg.Height)
+}
+
+func TestMakeSmallImage(t *testing.T) {
+	thumb, err := Make(encodePNG(t, 40, 30), 100)
+	require.NoError(t, err)
+	assert.Nil(t, thumb.Data, "small images are shown as is")
+	assert.Equal(t, 40, thumb.Width)
+	assert.Equal(t, 30, thumb.Height)
+}
+
+func TestMakeNotAnImage(t *testing.T) {
+	_, err := Make([]byte("%PDF-1.4"), 100)
+	assert.Error(t, err)
+}
@@ -0,0 +1,84 @@
+package utils
+
+import "strings"
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// maxDiffCells bounds the LCS table; larger inputs are reported as a full
+// replacement instead.
+const maxDiffCells = 4_000_000
+
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffLines returns the line operations that turn a into b.
+func DiffLines(a, b string) []DiffLine {
+	before := splitLines(a)
+	after := splitLines(b)
+	n, m := len(before), len(after)
+
+	if n*m > maxDiffCells {
+		out := make([]DiffLine, 0, n+m)
+		for _, line := range before {
+			out = append(out, DiffLine{Op: DiffDelete, Text: line})
+		}
+		for _, line := range after {
+			out = append(out, DiffLine{Op: DiffInsert, Text: line})
+		}
+		return out
+	}
+
+	// lcs[i][j] is the longest common subsequence of before[i:] and after[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	out := make([]DiffLine, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case before[i] == after[j]:
+			out = append(out, DiffLine{Op: DiffEqual, Text: before[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, DiffLine{Op: DiffDelete, Text: before[i]})
+			i++
+		default:
+			out = append(out, DiffLine{Op: DiffInsert, Text: after[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		out = append(out, DiffLine{Op: DiffDelete, Text: before[i]})
+	}
+	for ; j < m; j++ {
+		out = append(out, DiffLine{Op: DiffInsert, Text: after[j]})
+	}
+	return out
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	diff := DiffLines("first\nsecond\nthird", "first\nchanged\nthird\nfourth")
+
+	assert.Equal(t, []DiffLine{
+		{Op: DiffEqual, Text: "first"},
+		{Op: DiffDelete, Text: "second"},
+		{Op: DiffInsert, Text: "changed"},
+		{Op: DiffEqual, Text: "third"},
+		{Op: DiffInsert, Text: "fourth"},
+	}, diff)
+}
+
+func TestDiffLines_FromEmpty(t *testing.T) {
+	assert.Equal(t, []DiffLine{{Op: DiffInsert, Text: "hello"}}, DiffLines("", "hello"))
+	assert.Empty(t, DiffLines("", ""))
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// hashtagPattern matches #tag when it is not part of a word, a URL fragment
+// or an HTML entity. Tags are 1-50 letters, digits or underscores.
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_#&/])#([\p{L}\p{N}_]{1,50})`)
+
+// HashtagMatch is one #tag in a text. Tag is the normalized form, Display the
+// tag as written. Start and End are rune offsets of the whole "#tag", End
+// exclusive.
+type HashtagMatch struct {
+	Tag     string `json:"tag"`
+	Display string `json:"display"`
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+}
+
+// NormalizeHashtag lowercases a tag with Turkish casing rules, so "#Kitap",
+// "#kitap" and "#KİTAP" are the same tag. A leading '#' is dropped.
+func NormalizeHashtag(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	return strings.ToLowerSpecial(unicode.TurkishCase, tag)
+}
+
+// ParseHashtags returns every #tag in text, in order. Tags made only of
+// digits and underscores, like "#1", are not hashtags.
+func ParseHashtags(text string) []HashtagMatch {
+	var matches []HashtagMatch
+	for _, loc := range hashtagPattern.FindAllStringSubmatchIndex(text, -1) {
+		display := text[loc[2]:loc[3]]
+		if strings.IndexFunc(display, unicode.IsLetter) < 0 {
+			continue
+		}
+
+		start := utf8.RuneCountInString(text[:loc[2]-1])
+		matches = append(matches, HashtagMatch{
+			Tag:     NormalizeHashtag(display),
+			Display: display,
+			Start:   start,
+			End:     start + 1 + utf8.RuneCountInString(display),
+		})
+	}
+	return matches
+}
+
+// Hashtags returns the distinct normalized tags in text, in order of first
+// appearance.
+func Hashtags(text string) []string {
+	seen := map[string]bool{}
+	var tags []string
+	for _, m := range ParseHashtags(text) {
+		if !seen[m.Tag] {
+			seen[m.Tag] = true
+			tags = append(tags, m.Tag)
+		}
+	}
+	return tags
+}
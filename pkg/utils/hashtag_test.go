@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHashtags(t *testing.T) {
+	matches := ParseHashtags("#Kitap önerisi: #bilim_kurgu, #2024okumaları")
+
+	assert.Equal(t, []HashtagMatch{
+		{Tag: "kitap", Display: "Kitap", Start: 0, End: 6},
+		{Tag: "bilim_kurgu", Display: "bilim_kurgu", Start: 16, End: 28},
+		{Tag: "2024okumaları", Display: "2024okumaları", Start: 30, End: 44},
+	}, matches)
+}
+
+func TestParseHashtags_IgnoresNumbersAnchorsAndEntities(t *testing.T) {
+	assert.Empty(t, ParseHashtags("chapter #1, see https://example.com/page#intro, a&#39;b or C#"))
+}
+
+func TestNormalizeHashtag_TurkishCase(t *testing.T) {
+	assert.Equal(t, "kitap", NormalizeHashtag("#KİTAP"))
+	assert.Equal(t, "kitap", NormalizeHashtag("Kitap"))
+	assert.Equal(t, "ışık", NormalizeHashtag("IŞIK"))
+}
+
+func TestHashtags_Dedupes(t *testing.T) {
+	assert.Equal(t, []string{"kitap", "roman"}, Hashtags("#Kitap #roman #kitap #KİTAP"))
+}
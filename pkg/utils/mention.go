@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// mentionPattern matches @username when it is not part of a word or an email
+// address. Usernames are 3-50 letters, digits, underscores or dots.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_.]{3,50})`)
+
+// MentionMatch is one @username in a text. Start and End are rune offsets of
+// the whole "@username", End exclusive.
+type MentionMatch struct {
+	Username string `json:"username"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+}
+
+// ParseMentions returns every @username in text, in order. A trailing dot is
+// treated as punctuation, not part of the name.
+func ParseMentions(text string) []MentionMatch {
+	var matches []MentionMatch
+	for _, loc := range mentionPattern.FindAllStringSubmatchIndex(text, -1) {
+		name := strings.TrimRight(text[loc[2]:loc[3]], ".")
+		if utf8.RuneCountInString(name) < 3 {
+			continue
+		}
+
+		start := utf8.RuneCountInString(text[:loc[2]-1])
+		matches = append(matches, MentionMatch{
+			Username: name,
+			Start:    start,
+			End:      start + 1 + utf8.RuneCountInString(name),
+		})
+	}
+	return matches
+}
+
+// MentionedUsernames returns the distinct usernames mentioned in text, in
+// order of first appearance.
+func MentionedUsernames(text string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, m := range ParseMentions(text) {
+		if !seen[m.Username] {
+			seen[m.Username] = true
+			names = append(names, m.Username)
+		}
+	}
+	return names
+}
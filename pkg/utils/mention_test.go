@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMentions(t *testing.T) {
+	matches := ParseMentions("@alice, what did you and @bob_k think? Ask @carol.")
+
+	assert.Equal(t, []MentionMatch{
+		{Username: "alice", Start: 0, End: 6},
+		{Username: "bob_k", Start: 25, End: 31},
+		{Username: "carol", Start: 43, End: 49},
+	}, matches)
+}
+
+func TestParseMentions_IgnoresEmailsAndShortNames(t *testing.T) {
+	assert.Empty(t, ParseMentions("mail me at reader@example.com or @ab"))
+}
+
+func TestParseMentions_RuneOffsets(t *testing.T) {
+	matches := ParseMentions("çok güzel @ayşe_fan")
+
+	assert.Len(t, matches, 0, "non-ASCII usernames cannot be mentioned")
+
+	matches = ParseMentions("çok güzel @ayse")
+	assert.Equal(t, []MentionMatch{{Username: "ayse", Start: 10, End: 15}}, matches)
+}
+
+func TestMentionedUsernames_Dedupes(t *testing.T) {
+	assert.Equal(t, []string{"alice", "bob"}, MentionedUsernames("@alice @bob @alice"))
+}